@@ -1,5 +1,7 @@
 package helpers
 
+import "github.com/ivanehh/go-boiler-lib/pkg/typeutil"
+
 type Config interface {
 	Sources() Sources
 }
@@ -29,15 +31,9 @@ type Credentials interface {
 	Password() string
 }
 
-type Structurable interface {
-	Mapable
-	JSONable
-}
-
-type Mapable interface {
-	AsMap() map[string]any
-}
-
-type JSONable interface {
-	AsJSON() []byte
-}
+// Structurable, Mapable and JSONable moved to pkg/typeutil; the aliases keep the internal call sites compiling
+type (
+	Structurable = typeutil.Structurable
+	Mapable      = typeutil.Mapable
+	JSONable     = typeutil.JSONable
+)
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrNoConfigFile = errors.New("the configuration was not loaded from a file")
+
+// ChangeFunc receives the dotted yaml path of a changed value, e.g. "logging.level", with its old and new value;
+// values added or removed by a reload are nil on the missing side
+type ChangeFunc func(path string, old, new any)
+
+// Change is a single value changed by a reload
+type Change struct {
+	Path string
+	Old  any
+	New  any
+}
+
+type subscription struct {
+	id    int
+	paths []string
+	fn    ChangeFunc
+}
+
+// matches reports whether the subscription covers path: no paths cover everything, a path covers itself and the values below it
+func (s subscription) matches(path string) bool {
+	if len(s.paths) == 0 {
+		return true
+	}
+	for _, p := range s.paths {
+		if path == p || strings.HasPrefix(path, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// OnChange calls fn for every value a later Apply or Reload changes; when paths are given only the changes at or below them are delivered.
+// The returned func cancels the subscription
+func (c *Config[B]) OnChange(fn ChangeFunc, paths ...string) (cancel func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextSub++
+	id := c.nextSub
+	c.subs = append(c.subs, subscription{id: id, paths: paths, fn: fn})
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.subs = slices.DeleteFunc(c.subs, func(s subscription) bool { return s.id == id })
+	}
+}
+
+// Snapshot returns a copy of the base that is safe to read while another goroutine reloads the configuration
+func (c *Config[B]) Snapshot() B {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Base
+}
+
+// Apply replaces the base and the feature flags with those of next, keeping the environment and command line flag overrides,
+// and notifies the subscribers of every changed value; the changes are returned in field order
+func (c *Config[B]) Apply(next *Config[B]) []Change {
+	c.mu.Lock()
+	base := next.Base
+	reapplyTagged(reflect.ValueOf(&base).Elem(), "env", c.Env)
+	reapplyTagged(reflect.ValueOf(&base).Elem(), "flag", c.Flags)
+	var changes []Change
+	diffValues("", reflect.ValueOf(c.Base), reflect.ValueOf(base), &changes)
+	c.Base = base
+	if len(next.source) != 0 {
+		c.source, c.location, c.present = next.source, next.location, next.present
+	}
+	if c.Features != nil && next.Features != nil {
+		c.Features.Update(next.Features)
+	}
+	subs := slices.Clone(c.subs)
+	c.mu.Unlock()
+
+	for _, ch := range changes {
+		for _, s := range subs {
+			if s.matches(ch.Path) {
+				s.fn(ch.Path, ch.Old, ch.New)
+			}
+		}
+	}
+	return changes
+}
+
+// Reload re-reads the file the configuration was loaded from and applies it
+func (c *Config[B]) Reload() ([]Change, error) {
+	if len(c.path) == 0 {
+		return nil, ErrNoConfigFile
+	}
+	f, err := os.Open(c.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	next, err := decodeConfig[B](f)
+	if err != nil {
+		return nil, err
+	}
+	next.source, next.location = SourceFile, c.path
+	return c.Apply(next), nil
+}
+
+// WatchConfig reloads the configuration whenever the modification time of its file changes, until ctx is done;
+// read and parse errors are passed to onErr and keep the current configuration.
+// Remote configurations are hot reloaded with WatchRemote by passing every new configuration to Apply
+func WatchConfig[B any](ctx context.Context, c *Config[B], interval time.Duration, onErr func(error)) {
+	if len(c.path) == 0 {
+		onErr(ErrNoConfigFile)
+		return
+	}
+	modified := c.modified
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		info, err := os.Stat(c.path)
+		if err != nil {
+			onErr(err)
+			continue
+		}
+		if info.ModTime().Equal(modified) {
+			continue
+		}
+		if _, err = c.Reload(); err != nil {
+			onErr(err)
+			continue
+		}
+		modified = info.ModTime()
+	}
+}
+
+var (
+	yamlMarshalerType = reflect.TypeFor[yaml.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// diffValues appends the leaf values that differ between old and new; structs and maps are compared field by field and key by key,
+// everything else, including slices and types with their own yaml or text form, as a whole
+func diffValues(path string, old, new reflect.Value, changes *[]Change) {
+	if !old.IsValid() || !new.IsValid() || old.Type() != new.Type() {
+		if !old.IsValid() && !new.IsValid() {
+			return
+		}
+		*changes = append(*changes, Change{Path: path, Old: valueOf(old), New: valueOf(new)})
+		return
+	}
+	t := old.Type()
+	switch {
+	case t.Implements(yamlMarshalerType) || t.Implements(textMarshalerType):
+	case t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface:
+		if !old.IsNil() && !new.IsNil() {
+			diffValues(path, old.Elem(), new.Elem(), changes)
+			return
+		}
+	case t.Kind() == reflect.Struct:
+		for i := range t.NumField() {
+			sf := t.Field(i)
+			name, inline := yamlName(sf)
+			if !sf.IsExported() || name == "-" {
+				continue
+			}
+			fieldPath := joinPath(path, name)
+			if inline {
+				fieldPath = path
+			}
+			diffValues(fieldPath, old.Field(i), new.Field(i), changes)
+		}
+		return
+	case t.Kind() == reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, k := range append(old.MapKeys(), new.MapKeys()...) {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			diffValues(joinPath(path, name), old.MapIndex(keys[name]), new.MapIndex(keys[name]), changes)
+		}
+		return
+	}
+	if !reflect.DeepEqual(valueOf(old), valueOf(new)) {
+		*changes = append(*changes, Change{Path: path, Old: valueOf(old), New: valueOf(new)})
+	}
+}
+
+// yamlName returns the key yaml.v3 uses for the field and whether it is inlined
+func yamlName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("yaml")
+	name, opts, _ := strings.Cut(tag, ",")
+	inline := slices.Contains(strings.Split(opts, ","), "inline")
+	if len(name) == 0 {
+		name = strings.ToLower(sf.Name)
+	}
+	return name, inline
+}
+
+func joinPath(path string, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+	return path + "." + name
+}
+
+func valueOf(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
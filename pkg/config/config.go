@@ -1,7 +1,12 @@
 package config
 
 import (
+	"bytes"
+	"io"
 	"os"
+	"reflect"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -9,27 +14,72 @@ import (
 type Config[B any] struct {
 	// sourced from a yaml configuration
 	Base B
-	// sources from a .env file
-	// Environment E
+	// sourced from environment variables
+	Env map[string]any
 	// sources from cmd flags
 	Flags map[string]any
+	// sourced from the flags section of the yaml configuration; empty when B has a flags field of its own
+	Features *FeatureFlags
+
+	mu       sync.RWMutex
+	subs     []subscription
+	nextSub  int
+	path     string
+	modified time.Time
+	// the layer the base was decoded from and the yaml paths its document sets
+	source   Source
+	location string
+	present  map[string]bool
 }
 
 type ConfigOpt[B any, E any] func(*Config[B])
 
 func NewConfig[B any](path string) (*Config[B], error) {
-	base := new(B)
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	c, err := decodeConfig[B](f)
+	if err != nil {
+		return nil, err
+	}
+	c.path = path
+	c.modified = info.ModTime()
+	c.source, c.location = SourceFile, path
+	return c, nil
+}
+
+func decodeConfig[B any](r io.Reader) (*Config[B], error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	base := new(B)
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	if err := dec.Decode(base); err != nil {
+		return nil, err
+	}
+	// a base with a flags field of its own keeps the section to itself
+	features := NewFeatureFlags()
+	if !hasYAMLKey(reflect.TypeFor[B](), flagsKey) {
+		if features, err = decodeFeatureFlags(content); err != nil {
+			return nil, err
+		}
+	}
 
-	dec := yaml.NewDecoder(f)
-	if err = dec.Decode(base); err != nil {
+	present, err := documentPaths(content)
+	if err != nil {
 		return nil, err
 	}
 
 	config := new(Config[B])
 	config.Base = *base
+	config.Features = features
+	config.present = present
 	return config, nil
 }
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+)
+
+var (
+	ErrUnsupportedEnvType = errors.New("the field type can not be read from an environment variable")
+	ErrInvalidEnvValue    = errors.New("the environment variable can not be parsed into the field")
+)
+
+// ApplyEnv overrides every field of the configuration base tagged with `env:"NAME"` whose variable is set;
+// parsed values are recorded in Config.Env and survive Apply and Reload.
+// Flags take precedence over the environment, so apply it before parsing the flags
+func ApplyEnv[B any](c *Config[B]) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Env == nil {
+		c.Env = make(map[string]any)
+	}
+	return walkTaggedFields(reflect.ValueOf(&c.Base).Elem(), "env", func(name, _ string, sf reflect.StructField, field reflect.Value) error {
+		fv := &fieldValue{name: name, field: field, set: c.Env}
+		if !fv.supported() {
+			return fmt.Errorf("%w; field:%s;type:%s", ErrUnsupportedEnvType, sf.Name, field.Type())
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil
+		}
+		if err := fv.Set(value); err != nil {
+			return fmt.Errorf("%w; variable:%s;%w", ErrInvalidEnvValue, name, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source is the layer that supplied a configuration value
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+	SourceRemote  Source = "remote"
+)
+
+// Provenance is the effective value at a dotted yaml path and the layer that supplied it;
+// Key names the flag, environment variable, file or remote path, empty for defaults
+type Provenance struct {
+	Path   string
+	Value  any
+	Source Source
+	Key    string
+}
+
+func (p Provenance) String() string {
+	switch p.Source {
+	case SourceDefault:
+		return fmt.Sprintf("%s=%v (default)", p.Path, p.Value)
+	case SourceFlag:
+		return fmt.Sprintf("%s=%v (flag -%s)", p.Path, p.Value, p.Key)
+	}
+	return fmt.Sprintf("%s=%v (%s %s)", p.Path, p.Value, p.Source, p.Key)
+}
+
+// Explain lists every value of the base in field order with the layer it came from: a set flag wins over a set environment variable,
+// which wins over the file or remote document; values none of them set keep their default
+func (c *Config[B]) Explain() []Provenance {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var out []Provenance
+	c.explainValue("", reflect.ValueOf(c.Base), nil, &out)
+	return out
+}
+
+// explainValue descends like diffValues; sf is the struct field holding v, nil for map entries
+func (c *Config[B]) explainValue(path string, v reflect.Value, sf *reflect.StructField, out *[]Provenance) {
+	if v.IsValid() && !overridable(sf) {
+		t := v.Type()
+		switch {
+		case t.Implements(yamlMarshalerType) || t.Implements(textMarshalerType):
+		case t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface:
+			if !v.IsNil() {
+				c.explainValue(path, v.Elem(), sf, out)
+				return
+			}
+		case t.Kind() == reflect.Struct:
+			for i := range t.NumField() {
+				field := t.Field(i)
+				name, inline := yamlName(field)
+				if !field.IsExported() || name == "-" {
+					continue
+				}
+				fieldPath := joinPath(path, name)
+				if inline {
+					fieldPath = path
+				}
+				c.explainValue(fieldPath, v.Field(i), &field, out)
+			}
+			return
+		case t.Kind() == reflect.Map:
+			keys := v.MapKeys()
+			slices.SortFunc(keys, func(a, b reflect.Value) int {
+				return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+			})
+			for _, k := range keys {
+				c.explainValue(joinPath(path, fmt.Sprint(k.Interface())), v.MapIndex(k), nil, out)
+			}
+			return
+		}
+	}
+	p := Provenance{Path: path, Value: valueOf(v), Source: SourceDefault}
+	if name, ok := tagName(sf, "flag"); ok && hasKey(c.Flags, name) {
+		p.Source, p.Key = SourceFlag, name
+	} else if name, ok := tagName(sf, "env"); ok && hasKey(c.Env, name) {
+		p.Source, p.Key = SourceEnv, name
+	} else if c.present[path] {
+		p.Source, p.Key = c.source, c.location
+	}
+	*out = append(*out, p)
+}
+
+// overridable reports whether the field is bound to a flag or an environment variable, which makes it a single value
+func overridable(sf *reflect.StructField) bool {
+	_, flag := tagName(sf, "flag")
+	_, env := tagName(sf, "env")
+	return flag || env
+}
+
+func tagName(sf *reflect.StructField, tag string) (string, bool) {
+	if sf == nil {
+		return "", false
+	}
+	value, ok := sf.Tag.Lookup(tag)
+	name, _, _ := strings.Cut(value, ",")
+	return name, ok && len(name) != 0 && name != "-"
+}
+
+func hasKey(m map[string]any, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// documentPaths returns the dotted path of every key the yaml document sets, following aliases and merge keys
+func documentPaths(content []byte) (map[string]bool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	paths := make(map[string]bool)
+	collectPaths("", &doc, paths)
+	return paths, nil
+}
+
+func collectPaths(path string, n *yaml.Node, paths map[string]bool) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, child := range n.Content {
+			collectPaths(path, child, paths)
+		}
+	case yaml.AliasNode:
+		collectPaths(path, n.Alias, paths)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Tag == "!!merge" {
+				merged := []*yaml.Node{value}
+				if value.Kind == yaml.SequenceNode {
+					merged = value.Content
+				}
+				for _, m := range merged {
+					collectPaths(path, m, paths)
+				}
+				continue
+			}
+			p := joinPath(path, key.Value)
+			paths[p] = true
+			collectPaths(p, value, paths)
+		}
+	}
+}
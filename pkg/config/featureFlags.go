@@ -0,0 +1,323 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrBadFeatureFlag = errors.New("invalid feature flag")
+
+// rolloutBuckets is the resolution of percentage rollouts; 10000 buckets allow rollouts such as 12.5%
+const rolloutBuckets = 10000
+
+type featureFlag struct {
+	enabled   bool
+	value     any
+	rollout   float64
+	overrides map[string]bool
+}
+
+type featureFlagSpec struct {
+	Enabled   *bool           `yaml:"enabled"`
+	Value     any             `yaml:"value"`
+	Rollout   *float64        `yaml:"rollout"`
+	Overrides map[string]bool `yaml:"overrides"`
+}
+
+// FeatureFlags holds the flags section of a configuration; a flag is either a scalar or a mapping such as
+//
+//	flags:
+//	  batch_size: 500
+//	  new_parser:
+//	    enabled: true
+//	    rollout: 25
+//	    overrides: {gradec: true, sofia: false}
+//
+// rollout is the percentage of stable IDs (plants, devices) the flag is enabled for and overrides pin the flag per ID;
+// the flags are safe for concurrent use and Update swaps them in place, so a reloaded configuration reaches every holder
+type FeatureFlags struct {
+	mu    sync.RWMutex
+	flags map[string]featureFlag
+}
+
+func NewFeatureFlags() *FeatureFlags {
+	return &FeatureFlags{flags: make(map[string]featureFlag)}
+}
+
+func (ff *FeatureFlags) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("%w:the flags section must be a mapping", ErrBadFeatureFlag)
+	}
+	flags := make(map[string]featureFlag, len(value.Content)/2)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		name, node := value.Content[i].Value, value.Content[i+1]
+		f, err := decodeFeatureFlag(node)
+		if err != nil {
+			return fmt.Errorf("%w; flag:%v", err, name)
+		}
+		flags[name] = f
+	}
+	ff.mu.Lock()
+	ff.flags = flags
+	ff.mu.Unlock()
+	return nil
+}
+
+func decodeFeatureFlag(node *yaml.Node) (featureFlag, error) {
+	f := featureFlag{rollout: 100}
+	if node.Kind != yaml.MappingNode {
+		if err := node.Decode(&f.value); err != nil {
+			return f, fmt.Errorf("%w:%w", ErrBadFeatureFlag, err)
+		}
+		b, ok := f.value.(bool)
+		f.enabled = !ok || b
+		return f, nil
+	}
+	var spec featureFlagSpec
+	if err := node.Decode(&spec); err != nil {
+		return f, fmt.Errorf("%w:%w", ErrBadFeatureFlag, err)
+	}
+	f.enabled = spec.Enabled == nil || *spec.Enabled
+	f.value = spec.Value
+	if spec.Rollout != nil {
+		if *spec.Rollout < 0 || *spec.Rollout > 100 {
+			return f, fmt.Errorf("%w:rollout %v is not a percentage", ErrBadFeatureFlag, *spec.Rollout)
+		}
+		f.rollout = *spec.Rollout
+	}
+	f.overrides = spec.Overrides
+	return f, nil
+}
+
+// Update replaces the flags with those of next
+func (ff *FeatureFlags) Update(next *FeatureFlags) {
+	next.mu.RLock()
+	flags := next.flags
+	next.mu.RUnlock()
+	ff.mu.Lock()
+	ff.flags = flags
+	ff.mu.Unlock()
+}
+
+// Names lists the defined flags
+func (ff *FeatureFlags) Names() []string {
+	ff.mu.RLock()
+	defer ff.mu.RUnlock()
+	names := make([]string, 0, len(ff.flags))
+	for name := range ff.flags {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (ff *FeatureFlags) lookup(name string) (featureFlag, bool) {
+	if ff == nil {
+		return featureFlag{}, false
+	}
+	ff.mu.RLock()
+	defer ff.mu.RUnlock()
+	f, ok := ff.flags[name]
+	return f, ok
+}
+
+// Enabled reports whether the flag is on; undefined flags are off and partial rollouts are off without a stable ID
+func (ff *FeatureFlags) Enabled(name string) bool {
+	f, ok := ff.lookup(name)
+	return ok && f.enabled && f.rollout >= 100
+}
+
+// EnabledFor reports whether the flag is on for the stable ID; an override for id wins, otherwise id is hashed
+// together with the flag name into a bucket so the same ID keeps its decision as the rollout grows
+func (ff *FeatureFlags) EnabledFor(name string, id string) bool {
+	f, ok := ff.lookup(name)
+	if !ok {
+		return false
+	}
+	if on, ok := f.overrides[id]; ok {
+		return on
+	}
+	if !f.enabled {
+		return false
+	}
+	return rolloutBucket(name, id) < int(f.rollout*rolloutBuckets/100)
+}
+
+func rolloutBucket(name string, id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(id))
+	return int(h.Sum32() % rolloutBuckets)
+}
+
+// Bool returns the boolean value of the flag or def when the flag is undefined or not a boolean
+func (ff *FeatureFlags) Bool(name string, def bool) bool {
+	f, ok := ff.lookup(name)
+	if !ok {
+		return def
+	}
+	if b, ok := f.value.(bool); ok {
+		return b
+	}
+	return def
+}
+
+// String returns the value of the flag as a string or def when the flag is undefined or has no scalar value
+func (ff *FeatureFlags) String(name string, def string) string {
+	f, ok := ff.lookup(name)
+	if !ok {
+		return def
+	}
+	switch v := f.value.(type) {
+	case string:
+		return v
+	case bool, int, float64:
+		return fmt.Sprint(v)
+	}
+	return def
+}
+
+// Int returns the integer value of the flag or def when the flag is undefined or not a whole number
+func (ff *FeatureFlags) Int(name string, def int) int {
+	f, ok := ff.lookup(name)
+	if !ok {
+		return def
+	}
+	switch v := f.value.(type) {
+	case int:
+		return v
+	case float64:
+		if v == float64(int(v)) {
+			return int(v)
+		}
+	case string:
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
+	}
+	return def
+}
+
+// Float returns the numeric value of the flag or def when the flag is undefined or not a number
+func (ff *FeatureFlags) Float(name string, def float64) float64 {
+	f, ok := ff.lookup(name)
+	if !ok {
+		return def
+	}
+	switch v := f.value.(type) {
+	case int:
+		return float64(v)
+	case float64:
+		return v
+	case string:
+		if x, err := strconv.ParseFloat(v, 64); err == nil {
+			return x
+		}
+	}
+	return def
+}
+
+// Duration returns the value of the flag parsed as a duration (e.g. "30s") or def when it is undefined or malformed
+func (ff *FeatureFlags) Duration(name string, def time.Duration) time.Duration {
+	f, ok := ff.lookup(name)
+	if !ok {
+		return def
+	}
+	s, ok := f.value.(string)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// flagsKey is the top-level key of the flags section
+const flagsKey = "flags"
+
+type flagsSection struct {
+	Flags *FeatureFlags `yaml:"flags"`
+}
+
+func decodeFeatureFlags(content []byte) (*FeatureFlags, error) {
+	section := flagsSection{Flags: NewFeatureFlags()}
+	if err := yaml.Unmarshal(content, &section); err != nil {
+		return nil, err
+	}
+	return section.Flags, nil
+}
+
+// hasYAMLKey reports whether yaml.v3 decodes the key into a field of the struct t, including its inlined structs
+func hasYAMLKey(t reflect.Type, key string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if sf.Tag.Get("yaml") == "-" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		name, inline := yamlName(sf)
+		if inline {
+			if hasYAMLKey(sf.Type, key) {
+				return true
+			}
+			continue
+		}
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchFeatureFlags re-reads the flags section of the configuration file at path whenever its modification time
+// changes, updating ff in place until ctx is done; read and parse errors are passed to onErr and keep the current flags.
+// Remote configurations are reloaded with WatchRemote by calling Update with the Features of every new configuration
+func WatchFeatureFlags(ctx context.Context, path string, interval time.Duration, ff *FeatureFlags, onErr func(error)) {
+	// the first tick always reloads, so changes made before the watch started are not missed
+	var modified time.Time
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			onErr(err)
+			continue
+		}
+		if info.ModTime().Equal(modified) {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			onErr(err)
+			continue
+		}
+		next, err := decodeFeatureFlags(content)
+		if err != nil {
+			onErr(err)
+			continue
+		}
+		modified = info.ModTime()
+		ff.Update(next)
+	}
+}
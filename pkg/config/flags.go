@@ -0,0 +1,179 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnsupportedFlagType = errors.New("the field type can not be bound to a command line flag")
+
+// BindFlags registers a flag for every field of the configuration base tagged with `flag:"name,usage"`;
+// the current field values become the flag defaults, so a flag only overrides the file value when it is set explicitly.
+// Parsed values are written onto the configuration and recorded in Config.Flags
+func BindFlags[B any](fs *flag.FlagSet, c *Config[B]) error {
+	if c.Flags == nil {
+		c.Flags = make(map[string]any)
+	}
+	return bindStruct(fs, reflect.ValueOf(&c.Base).Elem(), c.Flags)
+}
+
+func bindStruct(fs *flag.FlagSet, sv reflect.Value, set map[string]any) error {
+	return walkTaggedFields(sv, "flag", func(name, usage string, sf reflect.StructField, field reflect.Value) error {
+		fv := &fieldValue{name: name, field: field, set: set}
+		if !fv.supported() {
+			return fmt.Errorf("%w; field:%s;type:%s", ErrUnsupportedFlagType, sf.Name, field.Type())
+		}
+		fs.Var(fv, name, usage)
+		return nil
+	})
+}
+
+// reapplyTagged writes the recorded flag or environment values onto a freshly loaded base, so a reload keeps the overrides
+func reapplyTagged(sv reflect.Value, tag string, set map[string]any) {
+	if len(set) == 0 {
+		return
+	}
+	walkTaggedFields(sv, tag, func(name, _ string, _ reflect.StructField, field reflect.Value) error {
+		if v, ok := set[name]; ok && reflect.TypeOf(v) == field.Type() {
+			field.Set(reflect.ValueOf(v))
+		}
+		return nil
+	})
+}
+
+// walkTaggedFields calls fn for every field tagged with tag, e.g. `flag:"name,usage"`, descending into untagged nested structs
+func walkTaggedFields(sv reflect.Value, tag string, fn func(name, usage string, sf reflect.StructField, field reflect.Value) error) error {
+	st := sv.Type()
+	for i := range sv.NumField() {
+		field := sv.Field(i)
+		sf := st.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		value, tagged := sf.Tag.Lookup(tag)
+		if !tagged {
+			if field.Kind() == reflect.Struct && !isFlagValue(field) {
+				if err := walkTaggedFields(field, tag, fn); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		name, usage, _ := strings.Cut(value, ",")
+		if len(name) == 0 || name == "-" {
+			continue
+		}
+		if err := fn(name, usage, sf, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isFlagValue(v reflect.Value) bool {
+	if !v.CanAddr() {
+		return false
+	}
+	switch v.Addr().Interface().(type) {
+	case flag.Value, encoding.TextUnmarshaler:
+		return true
+	}
+	return false
+}
+
+// fieldValue is a flag.Value writing straight into a configuration field
+type fieldValue struct {
+	name  string
+	field reflect.Value
+	set   map[string]any
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (fv *fieldValue) supported() bool {
+	if isFlagValue(fv.field) {
+		return true
+	}
+	switch fv.field.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func (fv *fieldValue) String() string {
+	if fv == nil || !fv.field.IsValid() {
+		return ""
+	}
+	if s, ok := fv.field.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(fv.field.Interface())
+}
+
+func (fv *fieldValue) IsBoolFlag() bool {
+	return fv.field.Kind() == reflect.Bool
+}
+
+func (fv *fieldValue) Set(s string) error {
+	if err := fv.assign(s); err != nil {
+		return err
+	}
+	fv.set[fv.name] = fv.field.Interface()
+	return nil
+}
+
+func (fv *fieldValue) assign(s string) error {
+	switch v := fv.field.Addr().Interface().(type) {
+	case flag.Value:
+		return v.Set(s)
+	case encoding.TextUnmarshaler:
+		return v.UnmarshalText([]byte(s))
+	}
+	if fv.field.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.field.SetInt(int64(d))
+		return nil
+	}
+	switch fv.field.Kind() {
+	case reflect.String:
+		fv.field.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, fv.field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, fv.field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, fv.field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.field.SetFloat(f)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+)
+
+const (
+	defaultSignatureHeader = "X-Config-Signature"
+	defaultRemotePoll      = time.Minute
+)
+
+var (
+	ErrMissingSignature = errors.New("the remote configuration is not signed")
+	ErrBadSignature     = errors.New("the remote configuration signature does not match its content")
+)
+
+// SignatureVerifier checks the signature sent alongside a remote configuration body
+type SignatureVerifier func(body []byte, signature string) error
+
+// Ed25519Verifier verifies base64 encoded ed25519 signatures of the configuration body
+func Ed25519Verifier(pub ed25519.PublicKey) SignatureVerifier {
+	return func(body []byte, signature string) error {
+		sig, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrBadSignature, err)
+		}
+		if !ed25519.Verify(pub, body, sig) {
+			return ErrBadSignature
+		}
+		return nil
+	}
+}
+
+type RemoteOpt func(*RemoteSource) error
+
+// WithSignatureVerifier rejects configurations whose signature header fails verification
+func WithSignatureVerifier(v SignatureVerifier) RemoteOpt {
+	return func(rs *RemoteSource) error {
+		rs.verify = v
+		return nil
+	}
+}
+
+// WithSignatureHeader changes the response header carrying the signature; defaults to X-Config-Signature
+func WithSignatureHeader(name string) RemoteOpt {
+	return func(rs *RemoteSource) error {
+		rs.sigHeader = name
+		return nil
+	}
+}
+
+// WithPollInterval sets how often WatchRemote checks the endpoint for changes; defaults to one minute
+func WithPollInterval(d time.Duration) RemoteOpt {
+	return func(rs *RemoteSource) error {
+		rs.interval = d
+		return nil
+	}
+}
+
+// WithRequestOptions adds request options (auth headers, query parameters) to every fetch
+func WithRequestOptions(opts ...netcom.RequestOption) RemoteOpt {
+	return func(rs *RemoteSource) error {
+		rs.reqOpts = append(rs.reqOpts, opts...)
+		return nil
+	}
+}
+
+// RemoteSource fetches a yaml configuration from an HTTP endpoint and tracks its ETag to detect changes
+type RemoteSource struct {
+	client    *netcom.Client
+	path      string
+	sigHeader string
+	verify    SignatureVerifier
+	interval  time.Duration
+	reqOpts   []netcom.RequestOption
+
+	mu   sync.Mutex
+	etag string
+}
+
+func NewRemoteSource(client *netcom.Client, path string, opts ...RemoteOpt) (*RemoteSource, error) {
+	rs := &RemoteSource{
+		client:    client,
+		path:      path,
+		sigHeader: defaultSignatureHeader,
+		interval:  defaultRemotePoll,
+	}
+	for _, opt := range opts {
+		if err := opt(rs); err != nil {
+			return nil, err
+		}
+	}
+	return rs, nil
+}
+
+// Fetch downloads the configuration; changed is false when the endpoint reports the last seen ETag as current
+func (rs *RemoteSource) Fetch(ctx context.Context) (body []byte, changed bool, err error) {
+	return rs.fetch(ctx, true)
+}
+
+// fetch downloads the configuration; conditional sends the last seen ETag so an unchanged configuration is not sent again
+func (rs *RemoteSource) fetch(ctx context.Context, conditional bool) (body []byte, changed bool, err error) {
+	rs.mu.Lock()
+	etag := rs.etag
+	rs.mu.Unlock()
+
+	opts := rs.reqOpts
+	if conditional && len(etag) != 0 {
+		opts = append([]netcom.RequestOption{netcom.WithSetHeader("If-None-Match", etag)}, opts...)
+	}
+	resp, err := rs.client.Get(ctx, rs.path, opts...)
+	if err != nil {
+		return nil, false, err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		return nil, false, nil
+	}
+	content, err := netcom.ReadResponseBody(resp)
+	if err != nil {
+		return nil, false, err
+	}
+	body = []byte(content)
+	if rs.verify != nil {
+		sig := resp.Header.Get(rs.sigHeader)
+		if len(sig) == 0 {
+			return nil, false, ErrMissingSignature
+		}
+		if err = rs.verify(body, sig); err != nil {
+			return nil, false, err
+		}
+	}
+	rs.mu.Lock()
+	rs.etag = resp.Header.Get("ETag")
+	rs.mu.Unlock()
+	return body, true, nil
+}
+
+// LoadRemote fetches and parses the remote configuration; the whole configuration is fetched even when the source
+// has seen its ETag before
+func LoadRemote[B any](ctx context.Context, rs *RemoteSource) (*Config[B], error) {
+	body, _, err := rs.fetch(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRemote[B](rs, body)
+}
+
+// WatchRemote polls the remote source until ctx is done and calls onChange with every new configuration or fetch error
+func WatchRemote[B any](ctx context.Context, rs *RemoteSource, onChange func(*Config[B], error)) {
+	ticker := time.NewTicker(rs.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		body, changed, err := rs.Fetch(ctx)
+		if err != nil {
+			onChange(nil, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		onChange(decodeRemote[B](rs, body))
+	}
+}
+
+func decodeRemote[B any](rs *RemoteSource, body []byte) (*Config[B], error) {
+	c, err := decodeConfig[B](bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	c.source, c.location = SourceRemote, rs.path
+	return c, nil
+}
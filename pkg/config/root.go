@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RootEnv is the default environment variable that overrides the project root lookup
+const RootEnv = "PROJECT_ROOT"
+
+var ErrRootNotFound = errors.New("no project root marker found")
+
+// DefaultRootMarkers are the files and directories that identify a project root
+var DefaultRootMarkers = []string{"go.mod", ".git"}
+
+type rootFinder struct {
+	start   string
+	env     string
+	markers []string
+}
+
+type RootOpt func(*rootFinder) error
+
+// WithRootMarkers replaces the default markers; a directory containing any of them is the root
+func WithRootMarkers(markers ...string) RootOpt {
+	return func(rf *rootFinder) error {
+		rf.markers = markers
+		return nil
+	}
+}
+
+// WithRootEnv changes the environment variable consulted before walking the directory tree; an empty name disables the override
+func WithRootEnv(name string) RootOpt {
+	return func(rf *rootFinder) error {
+		rf.env = name
+		return nil
+	}
+}
+
+// WithStartDir starts the lookup from dir instead of the working directory
+func WithStartDir(dir string) RootOpt {
+	return func(rf *rootFinder) error {
+		rf.start = dir
+		return nil
+	}
+}
+
+// FindRoot returns the absolute path of the project root: the directory named by the PROJECT_ROOT environment variable if set,
+// otherwise the closest directory at or above the working directory that contains one of the root markers
+func FindRoot(opts ...RootOpt) (string, error) {
+	rf := &rootFinder{env: RootEnv, markers: DefaultRootMarkers}
+	for _, opt := range opts {
+		if err := opt(rf); err != nil {
+			return "", err
+		}
+	}
+	if len(rf.env) != 0 {
+		if root, ok := os.LookupEnv(rf.env); ok && len(root) != 0 {
+			info, err := os.Stat(root)
+			if err != nil {
+				return "", fmt.Errorf("project root from %s:%w", rf.env, err)
+			}
+			if !info.IsDir() {
+				return "", fmt.Errorf("project root from %s is not a directory:%s", rf.env, root)
+			}
+			return filepath.Abs(root)
+		}
+	}
+	dir := rf.start
+	if len(dir) == 0 {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		dir = wd
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		for _, m := range rf.markers {
+			if _, err = os.Stat(filepath.Join(dir, m)); err == nil {
+				return dir, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%w; markers:%v", ErrRootNotFound, rf.markers)
+		}
+		dir = parent
+	}
+}
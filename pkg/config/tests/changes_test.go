@@ -0,0 +1,105 @@
+package config_test
+
+import (
+	"context"
+	"flag"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reloadBase struct {
+	Plant   string `yaml:"plant" flag:"plant,plant the service runs for"`
+	Logging struct {
+		Level string `yaml:"level"`
+	} `yaml:"logging"`
+	Netcom struct {
+		Timeout config.Duration `yaml:"timeout"`
+	} `yaml:"netcom"`
+	Intervals map[string]config.Duration `yaml:"intervals"`
+}
+
+func TestOnChange(t *testing.T) {
+	p := writeConfig(t, "plant: gradec\nlogging:\n  level: info\nnetcom:\n  timeout: 5s\nintervals:\n  sync: 1m\n")
+	c, err := config.NewConfig[reloadBase](p)
+	require.NoError(t, err)
+
+	var all, logging []config.Change
+	c.OnChange(func(path string, old, new any) { all = append(all, config.Change{Path: path, Old: old, New: new}) })
+	c.OnChange(func(path string, old, new any) {
+		logging = append(logging, config.Change{Path: path, Old: old, New: new})
+	}, "logging")
+	cancel := c.OnChange(func(path string, old, new any) { t.Fatal("cancelled subscriptions must not be called") })
+	cancel()
+
+	require.NoError(t, os.WriteFile(p, []byte("plant: gradec\nlogging:\n  level: debug\nnetcom:\n  timeout: 10s\nintervals:\n  sync: 1m\n  purge: 1h\n"), 0o644))
+	changes, err := c.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, changes, all)
+	assert.Equal(t, []config.Change{
+		{Path: "logging.level", Old: "info", New: "debug"},
+		{Path: "netcom.timeout", Old: config.Duration(5 * time.Second), New: config.Duration(10 * time.Second)},
+		{Path: "intervals.purge", Old: nil, New: config.Duration(time.Hour)},
+	}, changes)
+	assert.Equal(t, []config.Change{{Path: "logging.level", Old: "info", New: "debug"}}, logging)
+	assert.Equal(t, "debug", c.Snapshot().Logging.Level)
+
+	// an unchanged reload notifies nobody
+	changes, err = c.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestApplyKeepsFlagOverrides(t *testing.T) {
+	p := writeConfig(t, "plant: gradec\n")
+	c, err := config.NewConfig[reloadBase](p)
+	require.NoError(t, err)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	require.NoError(t, config.BindFlags(fs, c))
+	require.NoError(t, fs.Parse([]string{"-plant", "sofia"}))
+
+	require.NoError(t, os.WriteFile(p, []byte("plant: plovdiv\nlogging:\n  level: warn\n"), 0o644))
+	changes, err := c.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []config.Change{{Path: "logging.level", Old: "", New: "warn"}}, changes)
+	assert.Equal(t, "sofia", c.Base.Plant)
+}
+
+func TestWatchConfig(t *testing.T) {
+	p := writeConfig(t, "logging:\n  level: info\n")
+	c, err := config.NewConfig[reloadBase](p)
+	require.NoError(t, err)
+	var mu sync.Mutex
+	var level any
+	c.OnChange(func(path string, old, new any) {
+		mu.Lock()
+		defer mu.Unlock()
+		level = new
+	}, "logging.level")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	// the watcher must be gone before the temp dir is removed
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		config.WatchConfig(ctx, c, 5*time.Millisecond, func(err error) { t.Error(err) })
+	}()
+
+	require.NoError(t, os.WriteFile(p, []byte("logging:\n  level: error\n"), 0o644))
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(p, future, future))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return level == "error"
+	}, time.Second, 5*time.Millisecond)
+}
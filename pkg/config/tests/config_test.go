@@ -1,17 +1,75 @@
 package config_test
 
 import (
-	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ivanehh/go-boiler-lib/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+type testBase struct {
+	Name    string          `yaml:"name"`
+	Timeout config.Duration `yaml:"timeout"`
+	MaxSize config.ByteSize `yaml:"max_size"`
+	API     config.URL      `yaml:"api"`
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "cfg.yaml")
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	return p
+}
+
 func TestLoad(t *testing.T) {
-	err := config.Load("/home/terzivan/projects/MDOD/DCS/dcs-gradec/config/cfg.yaml")
-	if err != nil {
-		t.Fatal(err)
+	p := writeConfig(t, "name: gradec\ntimeout: 1m30s\nmax_size: 512MB\napi: https://mes.local/api\n")
+	c, err := config.NewConfig[testBase](p)
+	require.NoError(t, err)
+	assert.Equal(t, "gradec", c.Base.Name)
+	assert.Equal(t, 90*time.Second, c.Base.Timeout.D())
+	assert.Equal(t, int64(512_000_000), c.Base.MaxSize.Bytes())
+	assert.Equal(t, "mes.local", c.Base.API.Host)
+}
+
+func TestLoadRejectsBadScalars(t *testing.T) {
+	for name, content := range map[string]string{
+		"duration": "timeout: soon\n",
+		"size":     "max_size: 12 parsecs\n",
+		"url":      "api: /relative/path\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := config.NewConfig[testBase](writeConfig(t, content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	cases := map[string]config.ByteSize{
+		"1024":   1024,
+		"4KiB":   4 * config.KiB,
+		"1.5 GB": 1_500_000_000,
+		"1.1GB":  1_100_000_000,
+		"0.5KiB": 512,
+		"10mib":  10 * config.MiB,
+	}
+	for in, want := range cases {
+		var b config.ByteSize
+		require.NoError(t, b.Set(in), in)
+		assert.Equal(t, want, b, in)
 	}
-	c := config.Provide()
-	fmt.Printf("bc: %+v\n", c.Sources())
+	assert.Equal(t, "4KiB", (4 * config.KiB).String())
+	assert.ErrorIs(t, new(config.ByteSize).Set("MB"), config.ErrBadByteSize)
+	// 8EiB is exactly 2^63, one more than the largest size
+	assert.ErrorIs(t, new(config.ByteSize).Set("8388608TiB"), config.ErrBadByteSize)
+	assert.ErrorIs(t, new(config.ByteSize).Set("9223372036854775808"), config.ErrBadByteSize)
+	assert.NoError(t, new(config.ByteSize).Set("9223372036854775807"))
+	// sizes are whole bytes, fractions are not truncated away
+	assert.ErrorIs(t, new(config.ByteSize).Set("1.5B"), config.ErrBadByteSize)
+	assert.ErrorIs(t, new(config.ByteSize).Set("0.3KiB"), config.ErrBadByteSize)
+	assert.ErrorIs(t, new(config.ByteSize).Set("1.2.3MB"), config.ErrBadByteSize)
 }
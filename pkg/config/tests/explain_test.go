@@ -0,0 +1,126 @@
+package config_test
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/config"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type layeredBase struct {
+	Plant   string          `yaml:"plant"`
+	Workers int             `yaml:"workers" flag:"workers,number of ingestion workers"`
+	Timeout config.Duration `yaml:"timeout" env:"PIPELINE_TIMEOUT" flag:"timeout,upstream timeout"`
+	Retries int             `yaml:"retries" env:"PIPELINE_RETRIES"`
+	DB      struct {
+		Address string `yaml:"address"`
+		Pool    int    `yaml:"pool"`
+	} `yaml:"db"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+func sources(ps []config.Provenance) map[string]config.Source {
+	out := make(map[string]config.Source, len(ps))
+	for _, p := range ps {
+		out[p.Path] = p.Source
+	}
+	return out
+}
+
+func paths(ps []config.Provenance) []string {
+	out := make([]string, len(ps))
+	for i, p := range ps {
+		out[i] = p.Path
+	}
+	return out
+}
+
+func TestExplainLayers(t *testing.T) {
+	path := writeConfig(t, "plant: gradec\nworkers: 4\ntimeout: 30s\nretries: 3\ndb:\n  address: plantdb:1433\nlabels:\n  line: l1\n")
+	c, err := config.NewConfig[layeredBase](path)
+	require.NoError(t, err)
+
+	t.Setenv("PIPELINE_TIMEOUT", "45s")
+	t.Setenv("PIPELINE_RETRIES", "5")
+	require.NoError(t, config.ApplyEnv(c))
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	require.NoError(t, config.BindFlags(fs, c))
+	require.NoError(t, fs.Parse([]string{"-timeout", "1m"}))
+
+	assert.Equal(t, time.Minute, c.Base.Timeout.D())
+	assert.Equal(t, 5, c.Base.Retries)
+
+	ps := c.Explain()
+	assert.Equal(t, []string{"plant", "workers", "timeout", "retries", "db.address", "db.pool", "labels.line"}, paths(ps))
+	assert.Equal(t, map[string]config.Source{
+		"plant":       config.SourceFile,
+		"workers":     config.SourceFile,
+		"timeout":     config.SourceFlag,
+		"retries":     config.SourceEnv,
+		"db.address":  config.SourceFile,
+		"db.pool":     config.SourceDefault,
+		"labels.line": config.SourceFile,
+	}, sources(ps))
+	assert.Equal(t, "timeout=1m0s (flag -timeout)", ps[2].String())
+	assert.Equal(t, "retries=5 (env PIPELINE_RETRIES)", ps[3].String())
+	assert.Equal(t, "plant=gradec (file "+path+")", ps[0].String())
+	assert.Equal(t, "db.pool=0 (default)", ps[5].String())
+}
+
+func TestExplainSurvivesReload(t *testing.T) {
+	path := writeConfig(t, "plant: gradec\nretries: 3\n")
+	c, err := config.NewConfig[layeredBase](path)
+	require.NoError(t, err)
+	t.Setenv("PIPELINE_RETRIES", "5")
+	require.NoError(t, config.ApplyEnv(c))
+
+	require.NoError(t, os.WriteFile(path, []byte("plant: gradec\nretries: 1\ndb:\n  pool: 8\n"), 0o644))
+	_, err = c.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, 5, c.Base.Retries)
+	got := sources(c.Explain())
+	assert.Equal(t, config.SourceEnv, got["retries"])
+	assert.Equal(t, config.SourceFile, got["db.pool"])
+	assert.Equal(t, config.SourceDefault, got["db.address"])
+}
+
+func TestExplainRemote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plant: remote\nbase: &pool\n  pool: 4\ndb:\n  <<: *pool\n"))
+	}))
+	defer srv.Close()
+	client, err := netcom.NewClient(netcom.ClientConfig{BaseURL: srv.URL})
+	require.NoError(t, err)
+	rs, err := config.NewRemoteSource(client, "/config/gradec.yaml")
+	require.NoError(t, err)
+
+	c, err := config.LoadRemote[layeredBase](context.Background(), rs)
+	require.NoError(t, err)
+	ps := c.Explain()
+	got := sources(ps)
+	assert.Equal(t, config.SourceRemote, got["plant"])
+	assert.Equal(t, config.SourceRemote, got["db.pool"], "merged keys count as set")
+	assert.Equal(t, config.SourceDefault, got["timeout"])
+	assert.Equal(t, "plant=remote (remote /config/gradec.yaml)", ps[0].String())
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	c, err := config.NewConfig[layeredBase](writeConfig(t, "retries: 3\n"))
+	require.NoError(t, err)
+	t.Setenv("PIPELINE_RETRIES", "many")
+	assert.ErrorIs(t, config.ApplyEnv(c), config.ErrInvalidEnvValue)
+	assert.Equal(t, 3, c.Base.Retries)
+
+	bad := &config.Config[struct {
+		Hosts []string `env:"PIPELINE_HOSTS"`
+	}]{}
+	assert.ErrorIs(t, config.ApplyEnv(bad), config.ErrUnsupportedEnvType)
+}
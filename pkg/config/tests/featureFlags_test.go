@@ -0,0 +1,119 @@
+package config_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const flagsConfig = `name: gradec
+flags:
+  batch_size: 500
+  threshold: 0.75
+  flush_every: 30s
+  legacy_export: false
+  new_parser:
+    enabled: true
+    rollout: 25
+    overrides: {gradec: true, sofia: false}
+  dedupe:
+    enabled: false
+    overrides: {gradec: true}
+`
+
+func TestFeatureFlagsLookups(t *testing.T) {
+	c, err := config.NewConfig[testBase](writeConfig(t, flagsConfig))
+	require.NoError(t, err)
+	assert.Equal(t, "gradec", c.Base.Name)
+
+	ff := c.Features
+	assert.Equal(t, 500, ff.Int("batch_size", 100))
+	assert.Equal(t, 100, ff.Int("missing", 100))
+	assert.Equal(t, 0.75, ff.Float("threshold", 0))
+	assert.Equal(t, 30*time.Second, ff.Duration("flush_every", time.Minute))
+	assert.False(t, ff.Bool("legacy_export", true))
+	assert.False(t, ff.Enabled("legacy_export"))
+	assert.True(t, ff.Enabled("batch_size"))
+	assert.False(t, ff.Enabled("new_parser"), "a partial rollout is off without a stable ID")
+
+	assert.True(t, ff.EnabledFor("new_parser", "gradec"))
+	assert.False(t, ff.EnabledFor("new_parser", "sofia"))
+	assert.True(t, ff.EnabledFor("dedupe", "gradec"))
+	assert.False(t, ff.EnabledFor("dedupe", "plovdiv"))
+}
+
+func TestFeatureFlagsRolloutIsStable(t *testing.T) {
+	c, err := config.NewConfig[testBase](writeConfig(t, flagsConfig))
+	require.NoError(t, err)
+	on := 0
+	for i := range 1000 {
+		id := fmt.Sprintf("line-%d", i)
+		first := c.Features.EnabledFor("new_parser", id)
+		assert.Equal(t, first, c.Features.EnabledFor("new_parser", id))
+		if first {
+			on++
+		}
+	}
+	assert.InDelta(t, 250, on, 50)
+}
+
+func TestFeatureFlagsRejectBadRollout(t *testing.T) {
+	_, err := config.NewConfig[testBase](writeConfig(t, "flags:\n  new_parser: {rollout: 150}\n"))
+	assert.ErrorIs(t, err, config.ErrBadFeatureFlag)
+}
+
+func TestWatchFeatureFlags(t *testing.T) {
+	p := writeConfig(t, flagsConfig)
+	c, err := config.NewConfig[testBase](p)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go config.WatchFeatureFlags(ctx, p, 10*time.Millisecond, c.Features, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	require.NoError(t, os.WriteFile(p, []byte("flags:\n  batch_size: 1000\n"), 0o644))
+	// make the change visible on filesystems with coarse modification times
+	require.NoError(t, os.Chtimes(p, time.Now(), time.Now().Add(time.Second)))
+	require.Eventually(t, func() bool { return c.Features.Int("batch_size", 0) == 1000 }, time.Second, 10*time.Millisecond)
+	assert.False(t, c.Features.EnabledFor("new_parser", "gradec"))
+	assert.Empty(t, errs)
+}
+
+type cliBase struct {
+	Name  string   `yaml:"name"`
+	Flags []string `yaml:"flags"`
+}
+
+type inlinedCLIBase struct {
+	cliBase `yaml:",inline"`
+}
+
+func TestFeatureFlagsKeepOwnFlagsField(t *testing.T) {
+	// configurations predating feature flags may use the flags key for something else
+	p := writeConfig(t, "name: gradec\nflags: [--verbose, --dry-run]\n")
+
+	c, err := config.NewConfig[cliBase](p)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--verbose", "--dry-run"}, c.Base.Flags)
+	require.NotNil(t, c.Features)
+	assert.False(t, c.Features.Enabled("--verbose"))
+
+	inlined, err := config.NewConfig[inlinedCLIBase](p)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--verbose", "--dry-run"}, inlined.Base.Flags)
+
+	_, err = config.NewConfig[testBase](p)
+	assert.ErrorIs(t, err, config.ErrBadFeatureFlag)
+}
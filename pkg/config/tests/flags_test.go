@@ -0,0 +1,48 @@
+package config_test
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flagBase struct {
+	Plant   string          `yaml:"plant" flag:"plant,plant the service runs for"`
+	Workers int             `yaml:"workers" flag:"workers,number of ingestion workers"`
+	Verbose bool            `yaml:"verbose" flag:"verbose,enable debug logging"`
+	Timeout config.Duration `yaml:"timeout" flag:"timeout,upstream timeout"`
+	DB      struct {
+		Address string `yaml:"address" flag:"db-address,database address"`
+	} `yaml:"db"`
+}
+
+func TestBindFlagsPrecedence(t *testing.T) {
+	c, err := config.NewConfig[flagBase](writeConfig(t, "plant: gradec\nworkers: 4\ntimeout: 30s\ndb:\n  address: plantdb:1433\n"))
+	require.NoError(t, err)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	require.NoError(t, config.BindFlags(fs, c))
+	require.NoError(t, fs.Parse([]string{"-workers", "8", "-verbose", "-db-address", "localhost:1433"}))
+
+	// flags that were not set keep the file values
+	assert.Equal(t, "gradec", c.Base.Plant)
+	assert.Equal(t, 30*time.Second, c.Base.Timeout.D())
+	// flags that were set override them
+	assert.Equal(t, 8, c.Base.Workers)
+	assert.True(t, c.Base.Verbose)
+	assert.Equal(t, "localhost:1433", c.Base.DB.Address)
+	assert.Equal(t, map[string]any{"workers": 8, "verbose": true, "db-address": "localhost:1433"}, c.Flags)
+	assert.Equal(t, "gradec", fs.Lookup("plant").DefValue)
+}
+
+func TestBindFlagsUnsupported(t *testing.T) {
+	c := &config.Config[struct {
+		Hosts []string `flag:"hosts,hosts"`
+	}]{}
+	err := config.BindFlags(flag.NewFlagSet("test", flag.ContinueOnError), c)
+	assert.ErrorIs(t, err, config.ErrUnsupportedFlagType)
+}
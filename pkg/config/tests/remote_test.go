@@ -0,0 +1,95 @@
+package config_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/config"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteSourceETagAndSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	body := []byte("name: remote\ntimeout: 5s\n")
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-Config-Signature", base64.StdEncoding.EncodeToString(ed25519.Sign(priv, body)))
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	client, err := netcom.NewClient(netcom.ClientConfig{BaseURL: srv.URL})
+	require.NoError(t, err)
+	rs, err := config.NewRemoteSource(client, "/config/gradec.yaml", config.WithSignatureVerifier(config.Ed25519Verifier(pub)))
+	require.NoError(t, err)
+
+	c, err := config.LoadRemote[testBase](context.Background(), rs)
+	require.NoError(t, err)
+	assert.Equal(t, "remote", c.Base.Name)
+
+	_, changed, err := rs.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRemoteSourceRejectsBadSignature(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Config-Signature", base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize)))
+		w.Write([]byte("name: tampered\n"))
+	}))
+	defer srv.Close()
+
+	client, err := netcom.NewClient(netcom.ClientConfig{BaseURL: srv.URL})
+	require.NoError(t, err)
+	rs, err := config.NewRemoteSource(client, "/cfg", config.WithSignatureVerifier(config.Ed25519Verifier(pub)))
+	require.NoError(t, err)
+	_, err = config.LoadRemote[testBase](context.Background(), rs)
+	assert.ErrorIs(t, err, config.ErrBadSignature)
+}
+
+func TestLoadRemoteTwice(t *testing.T) {
+	conditional := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("name: remote\n"))
+	}))
+	defer srv.Close()
+
+	client, err := netcom.NewClient(netcom.ClientConfig{BaseURL: srv.URL})
+	require.NoError(t, err)
+	rs, err := config.NewRemoteSource(client, "/config/gradec.yaml")
+	require.NoError(t, err)
+
+	// the ETag seen by the first load must not turn the second one into an empty 304
+	for range 2 {
+		c, err := config.LoadRemote[testBase](context.Background(), rs)
+		require.NoError(t, err)
+		assert.Equal(t, "remote", c.Base.Name)
+	}
+	assert.Zero(t, conditional)
+
+	_, changed, err := rs.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
@@ -0,0 +1,38 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRoot(t *testing.T) {
+	t.Setenv(config.RootEnv, "")
+	root := t.TempDir()
+	deep := filepath.Join(root, "services", "gradec", "cmd")
+	require.NoError(t, os.MkdirAll(deep, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".dcsroot"), nil, 0o644))
+
+	found, err := config.FindRoot(config.WithStartDir(deep), config.WithRootMarkers(".dcsroot"))
+	require.NoError(t, err)
+	assert.Equal(t, root, found)
+
+	_, err = config.FindRoot(config.WithStartDir(deep), config.WithRootMarkers("no-such-marker"))
+	assert.ErrorIs(t, err, config.ErrRootNotFound)
+}
+
+func TestFindRootEnvOverride(t *testing.T) {
+	override := t.TempDir()
+	t.Setenv(config.RootEnv, override)
+	found, err := config.FindRoot(config.WithStartDir(t.TempDir()))
+	require.NoError(t, err)
+	assert.Equal(t, override, found)
+
+	t.Setenv(config.RootEnv, filepath.Join(override, "missing"))
+	_, err = config.FindRoot()
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateBase struct {
+	Azure struct {
+		Enabled     bool `yaml:"enabled"`
+		Credentials struct {
+			AccountName string `yaml:"account_name"`
+			AccountKey  string `yaml:"account_key"`
+		} `yaml:"credentials"`
+	} `yaml:"azure"`
+	Netcom struct {
+		Timeout  config.Duration `yaml:"timeout"`
+		Deadline config.Duration `yaml:"deadline"`
+	} `yaml:"netcom"`
+	Database *struct {
+		DSN  string `yaml:"dsn"`
+		File string `yaml:"file"`
+	} `yaml:"database"`
+}
+
+var validateRules = []config.Rule{
+	config.RequiredIf("azure.enabled", "azure.credentials.*"),
+	config.Less("netcom.timeout", "netcom.deadline"),
+	config.Exclusive("database.dsn", "database.file"),
+	config.Check("netcom.deadline", func(b validateBase) error {
+		if b.Netcom.Deadline.D() > time.Hour {
+			return errors.New("must not exceed an hour")
+		}
+		return nil
+	}),
+}
+
+func TestValidate(t *testing.T) {
+	p := writeConfig(t, "azure:\n  enabled: true\n  credentials:\n    account_name: plant7\nnetcom:\n  timeout: 30s\n  deadline: 10s\ndatabase:\n  dsn: sqlserver://mes\n  file: mes.db\n")
+	c, err := config.NewConfig[validateBase](p)
+	require.NoError(t, err)
+
+	err = c.Validate(validateRules...)
+	require.ErrorIs(t, err, config.ErrInvalidConfig)
+	var verr *config.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []config.Violation{
+		{Path: "azure.credentials.account_key", Message: "is required when azure.enabled is set"},
+		{Path: "netcom.timeout", Message: "must be < netcom.deadline (30s < 10s)"},
+		{Path: "database.file", Message: "must not be set together with database.dsn"},
+	}, verr.Violations)
+}
+
+func TestValidateConditionsNotMet(t *testing.T) {
+	var b validateBase
+	b.Netcom.Timeout = config.Duration(time.Second)
+	require.NoError(t, config.ValidateValue(b, validateRules...), "disabled azure, an unset deadline and a nil database pass")
+
+	b.Netcom.Deadline = config.Duration(2 * time.Hour)
+	assert.EqualError(t, config.ValidateValue(b, validateRules...), "invalid configuration; netcom.deadline: must not exceed an hour")
+}
+
+func TestValidateUnknownPath(t *testing.T) {
+	err := config.ValidateValue(validateBase{}, config.Required("azure.credential.account_key"))
+	var verr *config.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "unknown path azure.credential", verr.Violations[0].Message)
+}
+
+func TestValidateNil(t *testing.T) {
+	var b *validateBase
+	for _, v := range []any{nil, b} {
+		err := config.ValidateValue(v, config.Required("azure.enabled"), config.Check("netcom", func(b validateBase) error { return nil }))
+		var verr *config.ValidationError
+		require.ErrorAs(t, err, &verr)
+		assert.Equal(t, []config.Violation{
+			{Path: "azure.enabled", Message: "is required"},
+			{Path: "netcom", Message: "rule expects a config_test.validateBase configuration, got nil"},
+		}, verr.Violations)
+	}
+}
+
+func TestValidateMapKeys(t *testing.T) {
+	type lines struct {
+		Lines map[int]struct {
+			Plant string `yaml:"plant"`
+		} `yaml:"lines"`
+		Points map[[2]int]string `yaml:"points"`
+	}
+	var b lines
+	b.Lines = map[int]struct {
+		Plant string `yaml:"plant"`
+	}{1: {Plant: "plant7"}, 2: {}}
+
+	require.NoError(t, config.ValidateValue(b, config.Required("lines.1.plant")))
+	err := config.ValidateValue(b, config.Required("lines.*.plant"), config.Required("lines.one.plant"), config.Required("points.1"))
+	var verr *config.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []config.Violation{
+		{Path: "lines.2.plant", Message: "is required"},
+		{Path: "lines.one.plant", Message: "unknown path lines.one"},
+		{Path: "points.1", Message: "unknown path points.1"},
+	}, verr.Violations)
+}
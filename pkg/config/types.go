@@ -0,0 +1,186 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrBadDuration = errors.New("invalid duration")
+	ErrBadByteSize = errors.New("invalid byte size")
+	ErrBadURL      = errors.New("invalid url")
+)
+
+// Duration is a time.Duration that unmarshals from strings such as "10m" or "1h30m"
+type Duration time.Duration
+
+// D returns the value as a time.Duration
+func (d Duration) D() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d *Duration) Set(s string) error {
+	v, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("%w:%q", ErrBadDuration, s)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	return d.Set(value.Value)
+}
+
+func (d Duration) MarshalYAML() (any, error) {
+	return d.String(), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	return d.Set(string(text))
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// ByteSize is a number of bytes that unmarshals from strings such as "512MB" or "4KiB"; KB, MB, GB and TB are powers of 1000, KiB, MiB, GiB and TiB powers of 1024
+type ByteSize int64
+
+const (
+	B   ByteSize = 1
+	KB  ByteSize = 1000
+	MB           = 1000 * KB
+	GB           = 1000 * MB
+	TB           = 1000 * GB
+	KiB ByteSize = 1024
+	MiB          = 1024 * KiB
+	GiB          = 1024 * MiB
+	TiB          = 1024 * GiB
+)
+
+var byteUnits = map[string]ByteSize{
+	"":    B,
+	"b":   B,
+	"kb":  KB,
+	"mb":  MB,
+	"gb":  GB,
+	"tb":  TB,
+	"kib": KiB,
+	"mib": MiB,
+	"gib": GiB,
+	"tib": TiB,
+}
+
+// Bytes returns the value as an int64
+func (b ByteSize) Bytes() int64 {
+	return int64(b)
+}
+
+func (b ByteSize) String() string {
+	for _, u := range []struct {
+		size ByteSize
+		name string
+	}{{TiB, "TiB"}, {GiB, "GiB"}, {MiB, "MiB"}, {KiB, "KiB"}} {
+		if b >= u.size && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
+
+func (b *ByteSize) Set(s string) error {
+	s = strings.TrimSpace(s)
+	split := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	num, unit := s, ""
+	if split != -1 {
+		num, unit = s[:split], strings.TrimSpace(s[split:])
+	}
+	mult, ok := byteUnits[strings.ToLower(unit)]
+	if !ok || len(num) == 0 {
+		return fmt.Errorf("%w:%q", ErrBadByteSize, s)
+	}
+	// exact decimal arithmetic, so "1.1GB" is 1100000000 bytes and fractions of a byte are caught
+	size, ok := new(big.Rat).SetString(num)
+	if !ok {
+		return fmt.Errorf("%w:%q", ErrBadByteSize, s)
+	}
+	size.Mul(size, new(big.Rat).SetInt64(int64(mult)))
+	if !size.IsInt() {
+		return fmt.Errorf("%w:%q is not a whole number of bytes", ErrBadByteSize, s)
+	}
+	if !size.Num().IsInt64() {
+		return fmt.Errorf("%w:%q overflows", ErrBadByteSize, s)
+	}
+	*b = ByteSize(size.Num().Int64())
+	return nil
+}
+
+func (b *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	return b.Set(value.Value)
+}
+
+func (b ByteSize) MarshalYAML() (any, error) {
+	return b.String(), nil
+}
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	return b.Set(string(text))
+}
+
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// URL is an absolute url validated when the configuration is parsed
+type URL struct {
+	*url.URL
+}
+
+func (u URL) String() string {
+	if u.URL == nil {
+		return ""
+	}
+	return u.URL.String()
+}
+
+func (u *URL) Set(s string) error {
+	parsed, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrBadURL, err)
+	}
+	if !parsed.IsAbs() || len(parsed.Host) == 0 {
+		return fmt.Errorf("%w:%q must be absolute", ErrBadURL, s)
+	}
+	u.URL = parsed
+	return nil
+}
+
+func (u *URL) UnmarshalYAML(value *yaml.Node) error {
+	return u.Set(value.Value)
+}
+
+func (u URL) MarshalYAML() (any, error) {
+	return u.String(), nil
+}
+
+func (u *URL) UnmarshalText(text []byte) error {
+	return u.Set(string(text))
+}
+
+func (u URL) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
@@ -0,0 +1,354 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Violation is a broken rule at the dotted yaml path of the offending value
+type Violation struct {
+	Path    string
+	Message string
+}
+
+func (v Violation) Error() string {
+	return v.Path + ": " + v.Message
+}
+
+// ValidationError lists every violation found by Validate; it matches ErrInvalidConfig
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Error()
+	}
+	return fmt.Sprintf("%v; %s", ErrInvalidConfig, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// Rule is an invariant across the fields of a configuration, addressed by the dotted yaml paths used by OnChange;
+// a "*" segment stands for every field or key at that level, e.g. "azure.credentials.*"
+type Rule struct {
+	check func(root reflect.Value) []Violation
+}
+
+// Validate checks the base against the rules and returns a *ValidationError listing every violation
+func (c *Config[B]) Validate(rules ...Rule) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return ValidateValue(c.Base, rules...)
+}
+
+// ValidateValue checks any configuration struct against the rules, e.g. a remote configuration before it is applied
+func ValidateValue(v any, rules ...Rule) error {
+	root := reflect.ValueOf(v)
+	var violations []Violation
+	for _, r := range rules {
+		violations = append(violations, r.check(root)...)
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// Required requires the values at the paths to be set, i.e. not the zero value of their type
+func Required(paths ...string) Rule {
+	return required("is required", paths)
+}
+
+// RequiredIf requires the values at the paths when the value at cond is set, e.g. RequiredIf("azure.enabled", "azure.credentials.*")
+func RequiredIf(cond string, paths ...string) Rule {
+	return When(cond, required("is required when "+cond+" is set", paths))
+}
+
+func required(msg string, paths []string) Rule {
+	return Rule{check: func(root reflect.Value) []Violation {
+		var violations []Violation
+		for _, p := range paths {
+			values, err := resolvePath(root, p)
+			if err != nil {
+				violations = append(violations, Violation{Path: p, Message: err.Error()})
+				continue
+			}
+			for _, pv := range values {
+				if isZero(pv.value) {
+					violations = append(violations, Violation{Path: pv.path, Message: msg})
+				}
+			}
+		}
+		return violations
+	}}
+}
+
+// When applies the rules only when the value at cond is set
+func When(cond string, rules ...Rule) Rule {
+	return Rule{check: func(root reflect.Value) []Violation {
+		values, err := resolvePath(root, cond)
+		if err != nil {
+			return []Violation{{Path: cond, Message: err.Error()}}
+		}
+		if len(values) != 1 || isZero(values[0].value) {
+			return nil
+		}
+		var violations []Violation
+		for _, r := range rules {
+			violations = append(violations, r.check(root)...)
+		}
+		return violations
+	}}
+}
+
+// Less requires the value at a to be lower than the value at b, e.g. Less("netcom.timeout", "netcom.deadline");
+// numbers, durations and times can be compared, unset values are left to Required
+func Less(a, b string) Rule {
+	return compare(a, b, "<", func(c int) bool { return c < 0 })
+}
+
+// LessOrEqual requires the value at a not to exceed the value at b
+func LessOrEqual(a, b string) Rule {
+	return compare(a, b, "<=", func(c int) bool { return c <= 0 })
+}
+
+// Exclusive allows at most one of the values at the paths to be set
+func Exclusive(paths ...string) Rule {
+	return Rule{check: func(root reflect.Value) []Violation {
+		var set []string
+		for _, p := range paths {
+			values, err := resolvePath(root, p)
+			if err != nil {
+				return []Violation{{Path: p, Message: err.Error()}}
+			}
+			for _, pv := range values {
+				if !isZero(pv.value) {
+					set = append(set, pv.path)
+				}
+			}
+		}
+		if len(set) > 1 {
+			return []Violation{{Path: set[1], Message: "must not be set together with " + set[0]}}
+		}
+		return nil
+	}}
+}
+
+// Check adds a custom rule over the whole base; a non-nil error is reported at path, as is a nil base
+func Check[B any](path string, fn func(B) error) Rule {
+	return Rule{check: func(root reflect.Value) []Violation {
+		if !root.IsValid() || root.Kind() == reflect.Pointer && root.IsNil() {
+			return []Violation{{Path: path, Message: fmt.Sprintf("rule expects a %s configuration, got nil", reflect.TypeFor[B]())}}
+		}
+		b, ok := root.Interface().(B)
+		if !ok {
+			return []Violation{{Path: path, Message: fmt.Sprintf("rule expects a %T configuration", b)}}
+		}
+		if err := fn(b); err != nil {
+			return []Violation{{Path: path, Message: err.Error()}}
+		}
+		return nil
+	}}
+}
+
+func compare(a, b string, op string, ok func(int) bool) Rule {
+	return Rule{check: func(root reflect.Value) []Violation {
+		av, err := resolveOne(root, a)
+		if err != nil {
+			return []Violation{{Path: a, Message: err.Error()}}
+		}
+		bv, err := resolveOne(root, b)
+		if err != nil {
+			return []Violation{{Path: b, Message: err.Error()}}
+		}
+		if isZero(av) || isZero(bv) {
+			return nil
+		}
+		c, err := compareValues(av, bv)
+		if err != nil {
+			return []Violation{{Path: a, Message: err.Error()}}
+		}
+		if !ok(c) {
+			return []Violation{{Path: a, Message: fmt.Sprintf("must be %s %s (%v %s %v)", op, b, valueOf(av), op, valueOf(bv))}}
+		}
+		return nil
+	}}
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// compareValues orders two numbers, durations or times
+func compareValues(a, b reflect.Value) (int, error) {
+	sign := func(less, greater bool) int {
+		switch {
+		case less:
+			return -1
+		case greater:
+			return 1
+		}
+		return 0
+	}
+	switch {
+	case a.Type() == timeType && b.Type() == timeType:
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), nil
+	case a.CanInt() && b.CanInt():
+		return sign(a.Int() < b.Int(), a.Int() > b.Int()), nil
+	case a.CanUint() && b.CanUint():
+		return sign(a.Uint() < b.Uint(), a.Uint() > b.Uint()), nil
+	case (a.CanInt() || a.CanUint() || a.CanFloat()) && (b.CanInt() || b.CanUint() || b.CanFloat()):
+		af, bf := toFloat(a), toFloat(b)
+		return sign(af < bf, af > bf), nil
+	}
+	return 0, fmt.Errorf("can not compare %s with %s", a.Type(), b.Type())
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+func isZero(v reflect.Value) bool {
+	return !v.IsValid() || v.IsZero()
+}
+
+type pathValue struct {
+	path  string
+	value reflect.Value
+}
+
+// resolveOne resolves a path without wildcards
+func resolveOne(root reflect.Value, path string) (reflect.Value, error) {
+	values, err := resolvePath(root, path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if len(values) != 1 {
+		return reflect.Value{}, fmt.Errorf("path must name a single value")
+	}
+	return values[0].value, nil
+}
+
+// resolvePath returns the values at a dotted yaml path; a value below a nil pointer or a missing map key is invalid,
+// a path naming no field at all is an error
+func resolvePath(root reflect.Value, path string) ([]pathValue, error) {
+	current := []pathValue{{value: root}}
+	for _, seg := range strings.Split(path, ".") {
+		var next []pathValue
+		for _, pv := range current {
+			v := indirect(pv.value)
+			children, err := childValues(v, seg)
+			if err != nil {
+				return nil, fmt.Errorf("unknown path %s", joinPath(pv.path, seg))
+			}
+			for _, ch := range children {
+				next = append(next, pathValue{path: joinPath(pv.path, ch.path), value: ch.value})
+			}
+		}
+		current = next
+	}
+	return current, nil
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// childValues returns the field or key named seg of v, or all of them for "*"; their paths are relative to v
+func childValues(v reflect.Value, seg string) ([]pathValue, error) {
+	if !v.IsValid() {
+		// below a nil pointer every path is unset
+		return []pathValue{{path: seg}}, nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		var children []pathValue
+		for i := range v.NumField() {
+			sf := v.Type().Field(i)
+			name, inline := yamlName(sf)
+			if !sf.IsExported() || name == "-" {
+				continue
+			}
+			if inline {
+				if nested, err := childValues(indirect(v.Field(i)), seg); err == nil {
+					children = append(children, nested...)
+				}
+				continue
+			}
+			if seg == "*" || seg == name {
+				children = append(children, pathValue{path: name, value: v.Field(i)})
+			}
+		}
+		if len(children) == 0 && seg != "*" {
+			return nil, fmt.Errorf("unknown field %s", seg)
+		}
+		return children, nil
+	case reflect.Map:
+		if seg != "*" {
+			key, err := mapKey(v.Type().Key(), seg)
+			if err != nil {
+				return nil, err
+			}
+			return []pathValue{{path: seg, value: v.MapIndex(key)}}, nil
+		}
+		var children []pathValue
+		iter := v.MapRange()
+		for iter.Next() {
+			children = append(children, pathValue{path: fmt.Sprint(iter.Key().Interface()), value: iter.Value()})
+		}
+		slices.SortFunc(children, func(a, b pathValue) int { return strings.Compare(a.path, b.path) })
+		return children, nil
+	}
+	return nil, fmt.Errorf("%s has no fields", v.Type())
+}
+
+// mapKey parses a path segment into a map key; string, integer and bool keys can be addressed
+func mapKey(t reflect.Type, seg string) (reflect.Value, error) {
+	key := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		key.SetString(seg)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(seg, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s is not a %s key", seg, t)
+		}
+		key.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(seg, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s is not a %s key", seg, t)
+		}
+		key.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(seg)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s is not a %s key", seg, t)
+		}
+		key.SetBool(b)
+	default:
+		return reflect.Value{}, fmt.Errorf("%s keys can not be addressed by a path", t)
+	}
+	return key, nil
+}
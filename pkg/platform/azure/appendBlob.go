@@ -0,0 +1,172 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/appendblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/pageblob"
+)
+
+const (
+	// MaxAppendBlockBytes is the largest single block the service accepts on an append blob
+	MaxAppendBlockBytes = 4 * 1024 * 1024
+	// MaxAppendBlocks is the maximum number of committed blocks an append blob can hold
+	MaxAppendBlocks = 50000
+)
+
+var (
+	ErrAppendBlobFull  = errors.New("the append blob has reached the maximum number of committed blocks")
+	ErrPageMisaligned  = errors.New("page blob offsets and lengths must be multiples of 512 bytes")
+	ErrNothingToAppend = errors.New("attempted to append an empty payload")
+	// ErrEncryptionUnsupported is returned by the append and page blob writes of clients with client-side encryption,
+	// which seals whole blobs and can not encrypt blobs written in place
+	ErrEncryptionUnsupported = errors.New("client-side encryption is not supported on append and page blobs")
+)
+
+// AppendBlobState is the last known size information of an append blob written through the client
+type AppendBlobState struct {
+	Size   int64
+	Blocks int32
+}
+
+// appendTracker keeps the size information of the append blobs written by a client
+type appendTracker struct {
+	mu    sync.Mutex
+	blobs map[string]AppendBlobState
+}
+
+func (at *appendTracker) get(blob string) (AppendBlobState, bool) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	s, ok := at.blobs[blob]
+	return s, ok
+}
+
+func (at *appendTracker) set(blob string, s AppendBlobState) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	if at.blobs == nil {
+		at.blobs = make(map[string]AppendBlobState)
+	}
+	at.blobs[blob] = s
+}
+
+func (acc *AzureContainerClient) containerClient() *container.Client {
+	return acc.c.ServiceClient().NewContainerClient(acc.container)
+}
+
+// AppendLine appends data followed by a new line to the append blob; the blob is created if it does not exist.
+// Clients with client-side encryption fail with ErrEncryptionUnsupported
+func (acc *AzureContainerClient) AppendLine(ctx context.Context, blob string, data []byte) (AppendBlobState, error) {
+	line := make([]byte, 0, len(data)+1)
+	line = append(line, data...)
+	line = append(line, '\n')
+	return acc.Append(ctx, blob, line)
+}
+
+// Append appends data to the append blob; the blob is created if it does not exist and payloads larger than MaxAppendBlockBytes are split into several blocks.
+// Clients with client-side encryption fail with ErrEncryptionUnsupported
+func (acc *AzureContainerClient) Append(ctx context.Context, blob string, data []byte) (AppendBlobState, error) {
+	if acc.cipher != nil {
+		return AppendBlobState{}, fmt.Errorf("%w; blob:%s", ErrEncryptionUnsupported, blob)
+	}
+	if len(data) == 0 {
+		return AppendBlobState{}, ErrNothingToAppend
+	}
+	if known, ok := acc.appends.get(blob); ok && known.Blocks >= MaxAppendBlocks {
+		return known, fmt.Errorf("%w; blob:%s", ErrAppendBlobFull, blob)
+	}
+	abc := acc.containerClient().NewAppendBlobClient(blob)
+	var state AppendBlobState
+	for chunk := range slices.Chunk(data, MaxAppendBlockBytes) {
+		resp, err := abc.AppendBlock(ctx, streaming.NopCloser(bytes.NewReader(chunk)), nil)
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			if _, err = abc.Create(ctx, nil); err != nil && !bloberror.HasCode(err, bloberror.BlobAlreadyExists) {
+				return state, err
+			}
+			resp, err = abc.AppendBlock(ctx, streaming.NopCloser(bytes.NewReader(chunk)), nil)
+		}
+		if bloberror.HasCode(err, bloberror.BlockCountExceedsLimit) {
+			return state, fmt.Errorf("%w; blob:%s", ErrAppendBlobFull, blob)
+		}
+		if err != nil {
+			return state, err
+		}
+		state = appendStateFromResponse(resp, len(chunk))
+	}
+	acc.appends.set(blob, state)
+	return state, nil
+}
+
+// AppendState returns the size information of an append blob as recorded by the last successful append made through this client
+func (acc *AzureContainerClient) AppendState(blob string) (AppendBlobState, bool) {
+	return acc.appends.get(blob)
+}
+
+// RefreshAppendState reads the current size of the append blob from the service and records it
+func (acc *AzureContainerClient) RefreshAppendState(ctx context.Context, blob string) (AppendBlobState, error) {
+	props, err := acc.containerClient().NewAppendBlobClient(blob).GetProperties(ctx, nil)
+	if err != nil {
+		return AppendBlobState{}, err
+	}
+	var state AppendBlobState
+	if props.ContentLength != nil {
+		state.Size = *props.ContentLength
+	}
+	if props.BlobCommittedBlockCount != nil {
+		state.Blocks = *props.BlobCommittedBlockCount
+	}
+	acc.appends.set(blob, state)
+	return state, nil
+}
+
+// CreatePageBlob creates a zero-filled page blob of the given size; size must be a multiple of 512
+func (acc *AzureContainerClient) CreatePageBlob(ctx context.Context, blob string, size int64) error {
+	if size%pageblob.PageBytes != 0 {
+		return fmt.Errorf("%w; size:%d", ErrPageMisaligned, size)
+	}
+	_, err := acc.containerClient().NewPageBlobClient(blob).Create(ctx, size, nil)
+	return err
+}
+
+// WritePages writes data to the page blob at offset; both offset and len(data) must be multiples of 512.
+// Clients with client-side encryption fail with ErrEncryptionUnsupported
+func (acc *AzureContainerClient) WritePages(ctx context.Context, item string, offset int64, data []byte) error {
+	if acc.cipher != nil {
+		return fmt.Errorf("%w; blob:%s", ErrEncryptionUnsupported, item)
+	}
+	if offset%pageblob.PageBytes != 0 || int64(len(data))%pageblob.PageBytes != 0 {
+		return fmt.Errorf("%w; offset:%d;length:%d", ErrPageMisaligned, offset, len(data))
+	}
+	_, err := acc.containerClient().NewPageBlobClient(item).UploadPages(
+		ctx,
+		streaming.NopCloser(bytes.NewReader(data)),
+		blob.HTTPRange{Offset: offset, Count: int64(len(data))},
+		nil,
+	)
+	return err
+}
+
+func appendStateFromResponse(resp appendblob.AppendBlockResponse, written int) AppendBlobState {
+	var state AppendBlobState
+	if resp.BlobAppendOffset != nil {
+		offset, err := strconv.ParseInt(*resp.BlobAppendOffset, 10, 64)
+		if err == nil {
+			state.Size = offset + int64(written)
+		}
+	}
+	if resp.BlobCommittedBlockCount != nil {
+		state.Blocks = *resp.BlobCommittedBlockCount
+	}
+	return state
+}
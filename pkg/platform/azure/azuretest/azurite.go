@@ -0,0 +1,85 @@
+package azuretest
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure"
+)
+
+const (
+	// AzuriteAccount and AzuriteKey are the well-known development credentials of the emulator
+	AzuriteAccount = "devstoreaccount1"
+	AzuriteKey     = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+	// AzuriteBlobURL is the blob endpoint of an emulator with the default ports
+	AzuriteBlobURL = "http://127.0.0.1:10000/" + AzuriteAccount
+	// AzuriteURLEnv overrides AzuriteBlobURL, e.g. "http://azurite:10000/devstoreaccount1" for a CI service container
+	AzuriteURLEnv = "AZURITE_BLOB_URL"
+)
+
+// AzuriteURL returns the blob endpoint of the emulator, AzuriteURLEnv if set
+func AzuriteURL() string {
+	if u := os.Getenv(AzuriteURLEnv); len(u) != 0 {
+		return u
+	}
+	return AzuriteBlobURL
+}
+
+// AzuriteCredentials are the shared key credentials of the emulator
+func AzuriteCredentials() azure.AzSharedKeyCreds {
+	return azure.AzSharedKeyCreds{Account: AzuriteAccount, Key: AzuriteKey, Url: AzuriteURL()}
+}
+
+// AzuriteConfig is the client configuration of a container in the emulator
+func AzuriteConfig(container string) azure.AzureClientConfig {
+	return azure.AzureClientConfig{Container: container, Credentials: AzuriteCredentials()}
+}
+
+// AzuriteAvailable reports whether the emulator accepts connections
+func AzuriteAvailable() bool {
+	u, err := url.Parse(AzuriteURL())
+	if err != nil {
+		return false
+	}
+	conn, err := net.DialTimeout("tcp", u.Host, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// NewAzuriteClient creates container in the emulator and returns a client of it; the container is deleted when the test
+// ends. The test is skipped when the emulator is not running
+func NewAzuriteClient(t testing.TB, container string, opts ...azure.AzureClientOpt) *azure.AzureContainerClient {
+	t.Helper()
+	if !AzuriteAvailable() {
+		t.Skipf("azurite is not reachable at %s; start it or set %s", AzuriteURL(), AzuriteURLEnv)
+	}
+	cred, err := azblob.NewSharedKeyCredential(AzuriteAccount, AzuriteKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := azblob.NewClientWithSharedKeyCredential(AzuriteURL(), cred, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err = admin.CreateContainer(ctx, container, nil); err != nil && !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+		t.Fatalf("creating the container %s failed: %v", container, err)
+	}
+	t.Cleanup(func() {
+		admin.DeleteContainer(context.Background(), container, nil)
+	})
+	acc, err := azure.NewAzContainerClient(AzuriteConfig(container), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return acc
+}
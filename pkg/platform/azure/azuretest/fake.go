@@ -0,0 +1,169 @@
+// Package azuretest provides an in-memory azure.BlobStore and presets for the Azurite storage emulator, so pipeline
+// tests covering the upload and download paths run without real storage credentials
+package azuretest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure"
+)
+
+// Operations passed to FakeBlobStore.Fail
+const (
+	OpUpload    = "upload"
+	OpEnumerate = "enumerate"
+	OpPull      = "pull"
+	OpDelete    = "delete"
+)
+
+// FakeBlobStore keeps blobs in memory and behaves like AzureContainerClient: uploads overwrite, files are stored
+// under azure.BlobName and missing blobs fail with the BlobNotFound service error, so bloberror.HasCode works
+type FakeBlobStore struct {
+	// Fail, when set, is consulted before every operation; a non-nil error fails the operation, e.g. to simulate an outage
+	Fail func(op string, blob string) error
+
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+var _ azure.BlobStore = (*FakeBlobStore)(nil)
+
+// NewFakeBlobStore returns an empty store
+func NewFakeBlobStore() *FakeBlobStore {
+	return &FakeBlobStore{blobs: make(map[string][]byte)}
+}
+
+// Put stores a blob directly, e.g. to seed a download test
+func (f *FakeBlobStore) Put(blob string, content []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blobs[blob] = slices.Clone(content)
+}
+
+// Blob returns the content of a blob and whether it exists
+func (f *FakeBlobStore) Blob(blob string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	content, ok := f.blobs[blob]
+	return slices.Clone(content), ok
+}
+
+// Blobs returns a copy of every stored blob
+func (f *FakeBlobStore) Blobs() map[string][]byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	blobs := make(map[string][]byte, len(f.blobs))
+	for name, content := range f.blobs {
+		blobs[name] = slices.Clone(content)
+	}
+	return blobs
+}
+
+func (f *FakeBlobStore) UploadBuffer(ctx context.Context, blob string, content bytes.Buffer) error {
+	if err := f.check(ctx, OpUpload, blob); err != nil {
+		return err
+	}
+	f.Put(blob, content.Bytes())
+	return nil
+}
+
+func (f *FakeBlobStore) UploadFile(ctx context.Context, content *os.File, blobdir string) error {
+	blob := azure.UploadedBlobName(content.Name(), blobdir)
+	if err := f.check(ctx, OpUpload, blob); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	f.Put(blob, data)
+	return nil
+}
+
+// Enumerate lists the blob names in lexical order, as the service does
+func (f *FakeBlobStore) Enumerate(ctx context.Context) ([]string, error) {
+	if err := f.check(ctx, OpEnumerate, ""); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Sorted(maps.Keys(f.blobs)), nil
+}
+
+// PullBuffer copies the blob into destination and shortens it to the blob size
+func (f *FakeBlobStore) PullBuffer(ctx context.Context, item string, destination *[]byte) error {
+	content, err := f.pull(ctx, item)
+	if err != nil {
+		return err
+	}
+	if len(content) > len(*destination) {
+		return fmt.Errorf("%w; blob:%s;size:%d", azure.ErrDestinationTooSmall, item, len(content))
+	}
+	*destination = (*destination)[:copy(*destination, content)]
+	return nil
+}
+
+func (f *FakeBlobStore) PullFile(ctx context.Context, item string, destination *os.File) error {
+	content, err := f.pull(ctx, item)
+	if err != nil {
+		return err
+	}
+	_, err = destination.Write(content)
+	return err
+}
+
+func (f *FakeBlobStore) DeleteBlob(ctx context.Context, item string) error {
+	if err := f.check(ctx, OpDelete, item); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.blobs[item]; !ok {
+		return notFound(item)
+	}
+	delete(f.blobs, item)
+	return nil
+}
+
+func (f *FakeBlobStore) pull(ctx context.Context, item string) ([]byte, error) {
+	if err := f.check(ctx, OpPull, item); err != nil {
+		return nil, err
+	}
+	content, ok := f.Blob(item)
+	if !ok {
+		return nil, notFound(item)
+	}
+	return content, nil
+}
+
+func (f *FakeBlobStore) check(ctx context.Context, op string, blob string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if f.Fail != nil {
+		return f.Fail(op, blob)
+	}
+	return nil
+}
+
+// notFound is the error the service returns for a missing blob
+func notFound(blob string) error {
+	return runtime.NewResponseError(&http.Response{
+		StatusCode: http.StatusNotFound,
+		Status:     "404 The specified blob does not exist.",
+		Header:     http.Header{"X-Ms-Error-Code": {string(bloberror.BlobNotFound)}},
+		Body:       http.NoBody,
+		Request:    &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "memory", Host: "fakeblobstore", Path: "/" + blob}},
+	})
+}
@@ -0,0 +1,349 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azqueue"
+)
+
+const (
+	EventTypeBlobCreated = "Microsoft.Storage.BlobCreated"
+	EventTypeBlobDeleted = "Microsoft.Storage.BlobDeleted"
+
+	eventTypeSubscriptionValidation = "Microsoft.EventGrid.SubscriptionValidationEvent"
+	defaultEventPollInterval        = 10 * time.Second
+	// the storage queue service does not hand out more than 32 messages per request
+	maxDequeueBatch = 32
+	// Event Grid delivers batches of at most 1 MB to webhooks
+	maxWebhookBatchBytes = 1 << 20
+)
+
+var (
+	ErrBadBlobEvent   = errors.New("the message is not a valid blob storage event")
+	ErrPoisonedEvent  = errors.New("the event exceeded the maximum number of deliveries and was discarded")
+	ErrNoEventHandler = errors.New("a blob event handler must be provided")
+	ErrNoEventQueue   = errors.New("the listener was created without a queue; it can only serve webhook deliveries")
+)
+
+// BlobEvent is the blob reference carried by a storage event
+type BlobEvent struct {
+	ID            string
+	Type          string
+	Time          time.Time
+	Container     string
+	Blob          string
+	URL           string
+	ContentType   string
+	ContentLength int64
+	ETag          string
+}
+
+// BlobEventHandler processes a single blob event; returning an error leaves the event in the queue for redelivery
+type BlobEventHandler func(ctx context.Context, ev BlobEvent) error
+
+// eventGridEvent is the Event Grid schema of a blob storage event
+type eventGridEvent struct {
+	ID        string    `json:"id"`
+	EventType string    `json:"eventType"`
+	Subject   string    `json:"subject"`
+	EventTime time.Time `json:"eventTime"`
+	Data      struct {
+		URL            string `json:"url"`
+		ContentType    string `json:"contentType"`
+		ContentLength  int64  `json:"contentLength"`
+		ETag           string `json:"eTag"`
+		ValidationCode string `json:"validationCode"`
+	} `json:"data"`
+}
+
+func (e eventGridEvent) blobEvent() (BlobEvent, error) {
+	// subject format: /blobServices/default/containers/{container}/blobs/{blob}
+	_, rest, ok := strings.Cut(e.Subject, "/containers/")
+	if !ok {
+		return BlobEvent{}, fmt.Errorf("%w; subject:%s", ErrBadBlobEvent, e.Subject)
+	}
+	container, blob, ok := strings.Cut(rest, "/blobs/")
+	if !ok {
+		return BlobEvent{}, fmt.Errorf("%w; subject:%s", ErrBadBlobEvent, e.Subject)
+	}
+	return BlobEvent{
+		ID:            e.ID,
+		Type:          e.EventType,
+		Time:          e.EventTime,
+		Container:     container,
+		Blob:          blob,
+		URL:           e.Data.URL,
+		ContentType:   e.Data.ContentType,
+		ContentLength: e.Data.ContentLength,
+		ETag:          e.Data.ETag,
+	}, nil
+}
+
+// decodeQueueEvent parses a storage queue message; Event Grid base64 encodes the events it delivers to queues
+func decodeQueueEvent(text string) (eventGridEvent, error) {
+	var ev eventGridEvent
+	raw := []byte(text)
+	if decoded, err := base64.StdEncoding.DecodeString(text); err == nil {
+		raw = decoded
+	}
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return ev, fmt.Errorf("%w:%w", ErrBadBlobEvent, err)
+	}
+	return ev, nil
+}
+
+// EventListenerConfig configures a listener consuming blob events from a storage queue
+type EventListenerConfig struct {
+	QueueURL     string           `yaml:"queue_url" json:"queue_url"`
+	Credentials  AzSharedKeyCreds `yaml:"credentials" json:"credentials"`
+	PollInterval time.Duration    `yaml:"poll_interval" json:"poll_interval"`
+	BatchSize    int32            `yaml:"batch_size" json:"batch_size"`
+	// VisibilityTimeout is how long a dequeued event stays hidden while the handler processes it
+	VisibilityTimeout time.Duration `yaml:"visibility_timeout" json:"visibility_timeout"`
+	// MaxDeliveries discards events that failed this many times; 0 retries forever
+	MaxDeliveries int64 `yaml:"max_deliveries" json:"max_deliveries"`
+}
+
+type EventListenerOpt func(*BlobEventListener) error
+
+// WithEventTypes limits the dispatched events to the provided event types; defaults to EventTypeBlobCreated
+func WithEventTypes(types ...string) EventListenerOpt {
+	return func(l *BlobEventListener) error {
+		l.types = types
+		return nil
+	}
+}
+
+// WithBlobPrefix only dispatches events for blobs whose name starts with prefix; other events are acknowledged and dropped
+func WithBlobPrefix(prefix string) EventListenerOpt {
+	return func(l *BlobEventListener) error {
+		l.prefix = prefix
+		return nil
+	}
+}
+
+// WithEventErrorHandler receives the errors returned by the handler and the malformed or poisoned events
+func WithEventErrorHandler(f func(error)) EventListenerOpt {
+	return func(l *BlobEventListener) error {
+		l.onError = f
+		return nil
+	}
+}
+
+// messageQueue is the part of the storage queue client the listener depends on
+type messageQueue interface {
+	DequeueMessages(ctx context.Context, o *azqueue.DequeueMessagesOptions) (azqueue.DequeueMessagesResponse, error)
+	DeleteMessage(ctx context.Context, messageID string, popReceipt string, o *azqueue.DeleteMessageOptions) (azqueue.DeleteMessageResponse, error)
+}
+
+// BlobEventListener consumes blob storage events and dispatches them to a handler
+type BlobEventListener struct {
+	queue   messageQueue
+	config  EventListenerConfig
+	handler BlobEventHandler
+	types   []string
+	prefix  string
+	onError func(error)
+}
+
+// NewBlobEventListener creates a listener over the storage queue an Event Grid subscription delivers blob events to
+func NewBlobEventListener(config EventListenerConfig, handler BlobEventHandler, opts ...EventListenerOpt) (*BlobEventListener, error) {
+	cred, err := azqueue.NewSharedKeyCredential(config.Credentials.Account, config.Credentials.Key)
+	if err != nil {
+		return nil, err
+	}
+	qc, err := azqueue.NewQueueClientWithSharedKeyCredential(config.QueueURL, cred, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newBlobEventListener(qc, config, handler, opts...)
+}
+
+// NewBlobEventWebhook creates a listener that only receives events pushed by an Event Grid webhook subscription through ServeHTTP
+func NewBlobEventWebhook(handler BlobEventHandler, opts ...EventListenerOpt) (*BlobEventListener, error) {
+	return newBlobEventListener(nil, EventListenerConfig{}, handler, opts...)
+}
+
+func newBlobEventListener(q messageQueue, config EventListenerConfig, handler BlobEventHandler, opts ...EventListenerOpt) (*BlobEventListener, error) {
+	if handler == nil {
+		return nil, ErrNoEventHandler
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultEventPollInterval
+	}
+	if config.BatchSize <= 0 || config.BatchSize > maxDequeueBatch {
+		config.BatchSize = maxDequeueBatch
+	}
+	l := &BlobEventListener{
+		queue:   q,
+		config:  config,
+		handler: handler,
+		types:   []string{EventTypeBlobCreated},
+		onError: func(error) {},
+	}
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Run polls the queue until ctx is cancelled; queue errors are reported to the error handler and retried on the next tick
+func (l *BlobEventListener) Run(ctx context.Context) error {
+	if l.queue == nil {
+		return ErrNoEventQueue
+	}
+	ticker := time.NewTicker(l.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		// drain the queue before waiting for the next tick
+		for {
+			n, err := l.poll(ctx)
+			if err != nil {
+				l.onError(err)
+			}
+			if err != nil || n < int(l.config.BatchSize) {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll dequeues one batch of events and dispatches them; returns the number of messages received
+func (l *BlobEventListener) poll(ctx context.Context) (int, error) {
+	opts := &azqueue.DequeueMessagesOptions{NumberOfMessages: &l.config.BatchSize}
+	if l.config.VisibilityTimeout > 0 {
+		vt := int32(l.config.VisibilityTimeout.Seconds())
+		opts.VisibilityTimeout = &vt
+	}
+	resp, err := l.queue.DequeueMessages(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+	for _, msg := range resp.Messages {
+		if msg == nil || msg.MessageID == nil || msg.PopReceipt == nil {
+			continue
+		}
+		if l.process(ctx, msg) {
+			if _, err = l.queue.DeleteMessage(ctx, *msg.MessageID, *msg.PopReceipt, nil); err != nil {
+				l.onError(err)
+			}
+		}
+	}
+	return len(resp.Messages), nil
+}
+
+// process dispatches a single message; returns true when the message should be removed from the queue
+func (l *BlobEventListener) process(ctx context.Context, msg *azqueue.DequeuedMessage) bool {
+	var text string
+	if msg.MessageText != nil {
+		text = *msg.MessageText
+	}
+	ege, err := decodeQueueEvent(text)
+	if err != nil {
+		l.onError(fmt.Errorf("%w; message:%s", err, *msg.MessageID))
+		return true
+	}
+	ev, ok, err := l.accept(ege)
+	if err != nil {
+		l.onError(fmt.Errorf("%w; message:%s", err, *msg.MessageID))
+		return true
+	}
+	if !ok {
+		return true
+	}
+	if err = l.handler(ctx, ev); err != nil {
+		if l.config.MaxDeliveries > 0 && msg.DequeueCount != nil && *msg.DequeueCount >= l.config.MaxDeliveries {
+			l.onError(fmt.Errorf("%w; blob:%s;cause:%w", ErrPoisonedEvent, ev.Blob, err))
+			return true
+		}
+		l.onError(err)
+		return false
+	}
+	return true
+}
+
+// accept converts the event and reports whether it passes the listener filters
+func (l *BlobEventListener) accept(ege eventGridEvent) (BlobEvent, bool, error) {
+	if !containsFold(l.types, ege.EventType) {
+		return BlobEvent{}, false, nil
+	}
+	ev, err := ege.blobEvent()
+	if err != nil {
+		return ev, false, err
+	}
+	return ev, strings.HasPrefix(ev.Blob, l.prefix), nil
+}
+
+// ServeHTTP handles Event Grid webhook deliveries, including the subscription validation handshake; bodies larger than
+// an Event Grid batch are rejected and the errors are reported to the error handler only
+func (l *BlobEventListener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBatchBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			statusError(w, http.StatusRequestEntityTooLarge)
+			return
+		}
+		statusError(w, http.StatusBadRequest)
+		return
+	}
+	var events []eventGridEvent
+	if err = json.Unmarshal(body, &events); err != nil {
+		l.onError(fmt.Errorf("%w:%w", ErrBadBlobEvent, err))
+		statusError(w, http.StatusBadRequest)
+		return
+	}
+	for _, ege := range events {
+		if ege.EventType == eventTypeSubscriptionValidation {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"validationResponse": ege.Data.ValidationCode})
+			return
+		}
+	}
+	for _, ege := range events {
+		ev, ok, err := l.accept(ege)
+		if err != nil {
+			l.onError(err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err = l.handler(r.Context(), ev); err != nil {
+			// a non-2xx status makes Event Grid redeliver the batch
+			l.onError(err)
+			statusError(w, http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// statusError responds with the status text only, so handler errors do not leak to the caller
+func statusError(w http.ResponseWriter, status int) {
+	http.Error(w, http.StatusText(status), status)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
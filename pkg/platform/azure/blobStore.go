@@ -0,0 +1,35 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// BlobStore is the blob storage behind the upload and download paths of a pipeline; AzureContainerClient implements it
+// and azuretest.FakeBlobStore stands in for it in tests
+type BlobStore interface {
+	UploadBuffer(ctx context.Context, blob string, content bytes.Buffer) error
+	UploadFile(ctx context.Context, content *os.File, blobdir string) error
+	Enumerate(ctx context.Context) ([]string, error)
+	PullBuffer(ctx context.Context, item string, destination *[]byte) error
+	PullFile(ctx context.Context, item string, destination *os.File) error
+	DeleteBlob(ctx context.Context, item string) error
+}
+
+var _ BlobStore = (*AzureContainerClient)(nil)
+
+// BlobName is the name UploadFile stores a file under: inner extensions are dropped, "report.2025.csv" becomes "report.csv"
+func BlobName(file string) string {
+	s := strings.Split(file, ".")
+	return strings.Join([]string{s[0], s[len(s)-1]}, ".")
+}
+
+// UploadedBlobName is the blob UploadFile stores file under in blobdir: the directories of file are dropped
+// and its base name goes through BlobName, so "lines/report.2025.csv" becomes blobdir+"/report.csv"
+func UploadedBlobName(file string, blobdir string) string {
+	return path.Join(blobdir, BlobName(filepath.Base(file)))
+}
@@ -0,0 +1,99 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+)
+
+// MaxBlobBlocks is the maximum number of blocks a block blob can be committed with
+const MaxBlobBlocks = 50000
+
+var (
+	ErrStreamingEncrypted = errors.New("client-side encryption seals whole blobs and cannot stream blocks")
+	ErrTooManyBlocks      = errors.New("the block blob has reached the maximum number of blocks")
+	ErrSinkDone           = errors.New("the block blob upload was already committed or aborted")
+)
+
+// BlockBlobSink uploads a blob in parts: every chunk is staged as a block and Commit assembles the staged
+// blocks into the blob, so the blob only becomes visible once complete; it implements datamanagement.ChunkSink.
+// The chunks are hashed as they are staged and the blob is committed with the checksum of the client
+type BlockBlobSink struct {
+	bbc      *blockblob.Client
+	blob     string
+	checksum ChecksumAlgorithm
+
+	mu   sync.Mutex
+	ids  []string
+	hash hash.Hash
+	done bool
+}
+
+// NewBlockBlobSink starts a block upload of blob; clients with client-side encryption cannot stream uploads
+func (acc *AzureContainerClient) NewBlockBlobSink(blob string) (*BlockBlobSink, error) {
+	if acc.cipher != nil {
+		return nil, fmt.Errorf("%w; blob:%s", ErrStreamingEncrypted, blob)
+	}
+	return &BlockBlobSink{
+		bbc:      acc.containerClient().NewBlockBlobClient(blob),
+		blob:     blob,
+		checksum: acc.checksum,
+		hash:     contentHash(acc.checksum),
+	}, nil
+}
+
+// WriteChunk stages the chunk as the next block of the blob
+func (s *BlockBlobSink) WriteChunk(ctx context.Context, chunk []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return fmt.Errorf("%w; blob:%s", ErrSinkDone, s.blob)
+	}
+	if len(s.ids) >= MaxBlobBlocks {
+		return fmt.Errorf("%w; blob:%s", ErrTooManyBlocks, s.blob)
+	}
+	// block ids of a blob must all have the same length
+	id := base64.StdEncoding.EncodeToString(fmt.Appendf(nil, "block-%06d", len(s.ids)))
+	opts := &blockblob.StageBlockOptions{TransactionalValidation: transferValidation(s.checksum)}
+	if _, err := s.bbc.StageBlock(ctx, id, streaming.NopCloser(bytes.NewReader(chunk)), opts); err != nil {
+		return fmt.Errorf("%w; blob:%s", err, s.blob)
+	}
+	s.ids = append(s.ids, id)
+	if s.hash != nil {
+		s.hash.Write(chunk)
+	}
+	return nil
+}
+
+// Commit assembles the staged blocks into the blob, replacing any previous content
+func (s *BlockBlobSink) Commit(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return fmt.Errorf("%w; blob:%s", ErrSinkDone, s.blob)
+	}
+	opts := &blockblob.CommitBlockListOptions{}
+	if s.hash != nil {
+		opts.HTTPHeaders, opts.Metadata = checksumProperties(s.checksum, s.hash, nil)
+	}
+	if _, err := s.bbc.CommitBlockList(ctx, s.ids, opts); err != nil {
+		return fmt.Errorf("%w; blob:%s", err, s.blob)
+	}
+	s.done = true
+	return nil
+}
+
+// Abort gives up the upload; the service discards uncommitted blocks after a week
+func (s *BlockBlobSink) Abort(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	return nil
+}
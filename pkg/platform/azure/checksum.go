@@ -0,0 +1,209 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+)
+
+// ChecksumAlgorithm selects the checksum uploads, block blob sinks included, are stored with; clients with a checksum
+// also verify every download against the checksums the blob carries, whichever algorithm it was uploaded with.
+// Verified downloads are hashed as they stream to the destination, which is emptied again when they do not match
+type ChecksumAlgorithm string
+
+const (
+	// ChecksumNone uploads without a checksum; only the downloads of encrypted blobs, which are read whole anyway, are verified
+	ChecksumNone ChecksumAlgorithm = ""
+	// ChecksumMD5 stores the MD5 of the content as the Content-MD5 property of the blob, which other tools verify as well
+	ChecksumMD5 ChecksumAlgorithm = "md5"
+	// ChecksumCRC64 validates every upload request with the storage CRC64 and stores the CRC64 of the content in the metadata
+	ChecksumCRC64 ChecksumAlgorithm = "crc64"
+)
+
+// metaContentCRC64 is the blob metadata key holding the base64 encoded CRC64 of the stored content
+const metaContentCRC64 = "contentcrc64"
+
+// crc64Table is the polynomial of the storage service, the one of transactional CRC64 validation
+var crc64Table = crc64.MakeTable(0x9A6C9329AC4BC9B5)
+
+var (
+	ErrUnknownChecksum  = errors.New("unknown checksum algorithm")
+	ErrChecksumMismatch = errors.New("the downloaded blob content does not match its checksum")
+)
+
+// IntegrityError reports a download whose content does not match the checksum stored with the blob; it matches
+// ErrChecksumMismatch
+type IntegrityError struct {
+	Blob      string
+	Algorithm ChecksumAlgorithm
+	// Expected and Actual are the base64 encoded checksums of the blob and of the received content
+	Expected string
+	Actual   string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("%s; blob:%s;algorithm:%s;expected:%s;actual:%s", ErrChecksumMismatch, e.Blob, e.Algorithm, e.Expected, e.Actual)
+}
+
+func (e *IntegrityError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+// WithChecksum stores uploads with a checksum of the algorithm; overrides the Checksum configuration
+func WithChecksum(alg ChecksumAlgorithm) AzureClientOpt {
+	return func(acc *AzureContainerClient) error {
+		acc.checksum = alg
+		return nil
+	}
+}
+
+func validateChecksum(alg ChecksumAlgorithm) error {
+	switch alg {
+	case ChecksumNone, ChecksumMD5, ChecksumCRC64:
+		return nil
+	default:
+		return fmt.Errorf("%w; algorithm:%s", ErrUnknownChecksum, alg)
+	}
+}
+
+// contentHash returns the hash of the checksum algorithm, nil for ChecksumNone
+func contentHash(alg ChecksumAlgorithm) hash.Hash {
+	switch alg {
+	case ChecksumMD5:
+		return md5.New()
+	case ChecksumCRC64:
+		return crc64.New(crc64Table)
+	default:
+		return nil
+	}
+}
+
+// checksumProperties returns the headers and metadata storing the sum of h, the contentHash of alg; meta is extended
+// with the checksum metadata
+func checksumProperties(alg ChecksumAlgorithm, h hash.Hash, meta map[string]*string) (*blob.HTTPHeaders, map[string]*string) {
+	switch alg {
+	case ChecksumMD5:
+		return &blob.HTTPHeaders{BlobContentMD5: h.Sum(nil)}, meta
+	case ChecksumCRC64:
+		if meta == nil {
+			meta = make(map[string]*string, 1)
+		}
+		// little endian like the x-ms-content-crc64 header of the service
+		sum := base64.StdEncoding.EncodeToString(binary.LittleEndian.AppendUint64(nil, h.(hash.Hash64).Sum64()))
+		meta[metaContentCRC64] = &sum
+	}
+	return nil, meta
+}
+
+// transferValidation returns the validation of the upload requests of alg: the service rejects a request whose body does
+// not match its CRC64, so a corrupted upload fails instead of being stored
+func transferValidation(alg ChecksumAlgorithm) blob.TransferValidationType {
+	if alg == ChecksumCRC64 {
+		return blob.TransferValidationTypeComputeCRC64()
+	}
+	return nil
+}
+
+// uploadOptions returns the upload options carrying the checksum of content, which is read from the start;
+// meta is extended with the checksum metadata
+func (acc *AzureContainerClient) uploadOptions(content io.ReaderAt, size int64, meta map[string]*string) (*azblob.UploadBufferOptions, error) {
+	opts := &azblob.UploadBufferOptions{Metadata: meta}
+	h := contentHash(acc.checksum)
+	if h == nil {
+		return opts, nil
+	}
+	if _, err := io.Copy(h, io.NewSectionReader(content, 0, size)); err != nil {
+		return nil, err
+	}
+	opts.HTTPHeaders, opts.Metadata = checksumProperties(acc.checksum, h, meta)
+	opts.TransactionalValidation = transferValidation(acc.checksum)
+	return opts, nil
+}
+
+// verifiedBody is a downloaded blob checked against the CRC64 metadata and the Content-MD5 property of the blob as it
+// is read: the end of a body not matching them is reported by an *IntegrityError instead of io.EOF. Blobs without
+// either are not verified
+type verifiedBody struct {
+	rc   io.ReadCloser
+	item string
+	size int64
+	meta map[string]*string
+
+	contentMD5 []byte
+	contentCRC string
+	md5, crc64 hash.Hash
+	err        error
+}
+
+// pullVerified starts the download of the blob, verified as it is read
+func (acc *AzureContainerClient) pullVerified(ctx context.Context, item string) (*verifiedBody, error) {
+	resp, err := acc.c.DownloadStream(ctx, acc.container, item, nil)
+	if err != nil {
+		return nil, err
+	}
+	b := &verifiedBody{rc: resp.Body, item: item, size: -1, meta: resp.Metadata, contentMD5: resp.ContentMD5}
+	if resp.ContentLength != nil {
+		b.size = *resp.ContentLength
+	}
+	if encoded, ok := lookupMeta(resp.Metadata, metaContentCRC64); ok {
+		b.contentCRC, b.crc64 = encoded, crc64.New(crc64Table)
+	}
+	if len(resp.ContentMD5) != 0 {
+		b.md5 = md5.New()
+	}
+	return b, nil
+}
+
+func (b *verifiedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	n, err := b.rc.Read(p)
+	for _, h := range []hash.Hash{b.crc64, b.md5} {
+		if h != nil {
+			h.Write(p[:n])
+		}
+	}
+	if err == io.EOF {
+		if verr := b.verify(); verr != nil {
+			err = verr
+		}
+	}
+	b.err = err
+	return n, err
+}
+
+func (b *verifiedBody) Close() error {
+	return b.rc.Close()
+}
+
+// verify checks the content read so far, which is the whole blob
+func (b *verifiedBody) verify() error {
+	if b.crc64 != nil {
+		sum := binary.LittleEndian.AppendUint64(nil, b.crc64.(hash.Hash64).Sum64())
+		if actual := base64.StdEncoding.EncodeToString(sum); actual != b.contentCRC {
+			return &IntegrityError{Blob: b.item, Algorithm: ChecksumCRC64, Expected: b.contentCRC, Actual: actual}
+		}
+	}
+	if b.md5 != nil {
+		if sum := b.md5.Sum(nil); !bytes.Equal(sum, b.contentMD5) {
+			return &IntegrityError{
+				Blob:      b.item,
+				Algorithm: ChecksumMD5,
+				Expected:  base64.StdEncoding.EncodeToString(b.contentMD5),
+				Actual:    base64.StdEncoding.EncodeToString(sum),
+			}
+		}
+	}
+	return nil
+}
@@ -4,10 +4,9 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
-	"path"
-	"path/filepath"
-	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 )
@@ -22,18 +21,52 @@ type AzureContainerClient struct {
 	c         *azblob.Client
 	creds     AzSharedKeyCreds
 	container string
+	appends   appendTracker
+	cipher    *blobCipher
+	checksum  ChecksumAlgorithm
 }
 
 type AzureClientConfig struct {
 	Container   string           `yaml:"container" json:"container"`
 	Credentials AzSharedKeyCreds `yaml:"credentials" json:"credentials"`
+	// Encryption enables client-side encryption of the uploaded blobs when set
+	Encryption *EncryptionConfig `yaml:"encryption" json:"encryption"`
+	// Checksum stores uploads with a checksum and verifies downloads against it; "md5", "crc64" or empty for none
+	Checksum ChecksumAlgorithm `yaml:"checksum" json:"checksum"`
+}
+
+type AzureClientOpt func(*AzureContainerClient) error
+
+// WithKeyProvider enables client-side encryption with keys from the provided KeyProvider; overrides the Encryption configuration.
+// Only whole blob uploads are encrypted: block streaming, append and page blob writes fail instead of storing plaintext
+func WithKeyProvider(kp KeyProvider) AzureClientOpt {
+	return func(acc *AzureContainerClient) error {
+		acc.cipher = &blobCipher{keys: kp}
+		return nil
+	}
 }
 
 // NewAzContainerClient creates a new container client with the provided configuration; the client is immutable
-func NewAzContainerClient(config AzureClientConfig) (*AzureContainerClient, error) {
+func NewAzContainerClient(config AzureClientConfig, opts ...AzureClientOpt) (*AzureContainerClient, error) {
 	client := new(AzureContainerClient)
 	client.creds = config.Credentials
 	client.container = config.Container
+	client.checksum = config.Checksum
+	if config.Encryption != nil {
+		kp, err := NewStaticKeyProvider(*config.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		client.cipher = &blobCipher{keys: kp}
+	}
+	for _, opt := range opts {
+		if err := opt(client); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateChecksum(client.checksum); err != nil {
+		return nil, err
+	}
 
 	cred, err := azblob.NewSharedKeyCredential(client.creds.Account, client.creds.Key)
 	if err != nil {
@@ -46,13 +79,15 @@ func NewAzContainerClient(config AzureClientConfig) (*AzureContainerClient, erro
 	return client, nil
 }
 
-func (acc *AzureContainerClient) sanitizeName(n string) string {
-	s := strings.Split(n, ".")
-	return strings.Join([]string{s[0], s[len(s)-1]}, ".")
-}
-
 func (acc *AzureContainerClient) UploadBuffer(ctx context.Context, blob string, content bytes.Buffer) error {
-	_, err := acc.c.UploadBuffer(ctx, acc.container, blob, content.Bytes(), nil)
+	if acc.cipher != nil {
+		return acc.uploadEncrypted(ctx, blob, content.Bytes())
+	}
+	opts, err := acc.uploadOptions(bytes.NewReader(content.Bytes()), int64(content.Len()), nil)
+	if err != nil {
+		return err
+	}
+	_, err = acc.c.UploadBuffer(ctx, acc.container, blob, content.Bytes(), opts)
 	if err != nil {
 		return err
 	}
@@ -60,9 +95,24 @@ func (acc *AzureContainerClient) UploadBuffer(ctx context.Context, blob string,
 }
 
 func (acc *AzureContainerClient) UploadFile(ctx context.Context, content *os.File, blobdir string) error {
-	fname := filepath.Base(content.Name())
-	blob := acc.sanitizeName(fname)
-	_, err := acc.c.UploadFile(ctx, acc.container, path.Join(blobdir, blob), content, nil)
+	blob := UploadedBlobName(content.Name(), blobdir)
+	if acc.cipher != nil {
+		// INFO: AES-GCM is not a streaming cipher; the file is encrypted in memory
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return err
+		}
+		return acc.uploadEncrypted(ctx, blob, data)
+	}
+	info, err := content.Stat()
+	if err != nil {
+		return err
+	}
+	opts, err := acc.uploadOptions(content, info.Size(), nil)
+	if err != nil {
+		return err
+	}
+	_, err = acc.c.UploadFile(ctx, acc.container, blob, content, opts)
 	if err != nil {
 		return err
 	}
@@ -86,8 +136,40 @@ func (acc *AzureContainerClient) Enumerate(ctx context.Context) ([]string, error
 
 var ErrDestinationTooSmall = errors.New("the provided destination can not fit the content of the blob")
 
+// PullBuffer downloads the blob into destination and shortens it to the blob size
 func (acc *AzureContainerClient) PullBuffer(ctx context.Context, item string, destination *[]byte) error {
-	_, err := acc.c.DownloadBuffer(
+	if acc.cipher != nil {
+		plaintext, err := acc.pullDecrypted(ctx, item)
+		if err != nil {
+			return err
+		}
+		if len(plaintext) > len(*destination) {
+			return fmt.Errorf("%w; blob:%s;size:%d", ErrDestinationTooSmall, item, len(plaintext))
+		}
+		*destination = (*destination)[:copy(*destination, plaintext)]
+		return nil
+	}
+	if acc.checksum != ChecksumNone {
+		body, err := acc.pullVerified(ctx, item)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		if body.size > int64(len(*destination)) {
+			return fmt.Errorf("%w; blob:%s;size:%d", ErrDestinationTooSmall, item, body.size)
+		}
+		n, err := io.ReadFull(body, (*destination)[:max(body.size, 0)])
+		if err == nil {
+			// the checksums are verified at the end of the body
+			_, err = io.Copy(io.Discard, body)
+		}
+		if err != nil {
+			return err
+		}
+		*destination = (*destination)[:n]
+		return nil
+	}
+	n, err := acc.c.DownloadBuffer(
 		ctx,
 		acc.container,
 		item,
@@ -97,10 +179,20 @@ func (acc *AzureContainerClient) PullBuffer(ctx context.Context, item string, de
 	if err != nil {
 		return err
 	}
+	*destination = (*destination)[:n]
 	return nil
 }
 
+// PullFile downloads the blob into destination, replacing its content. A verified download not matching its
+// checksums leaves destination empty
 func (acc *AzureContainerClient) PullFile(ctx context.Context, item string, destination *os.File) error {
+	if acc.cipher != nil || acc.checksum != ChecksumNone {
+		err := acc.pullVerifiedFile(ctx, item, destination)
+		if errors.Is(err, ErrChecksumMismatch) {
+			destination.Truncate(0)
+		}
+		return err
+	}
 	_, err := acc.c.DownloadFile(
 		ctx,
 		acc.container,
@@ -114,6 +206,60 @@ func (acc *AzureContainerClient) PullFile(ctx context.Context, item string, dest
 	return nil
 }
 
+// pullVerifiedFile writes the verified, and decrypted, blob to destination from its start like DownloadFile does
+func (acc *AzureContainerClient) pullVerifiedFile(ctx context.Context, item string, destination *os.File) error {
+	if err := destination.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := destination.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if acc.cipher != nil {
+		plaintext, err := acc.pullDecrypted(ctx, item)
+		if err != nil {
+			return err
+		}
+		_, err = destination.Write(plaintext)
+		return err
+	}
+	body, err := acc.pullVerified(ctx, item)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = io.Copy(destination, body)
+	return err
+}
+
+func (acc *AzureContainerClient) uploadEncrypted(ctx context.Context, blob string, plaintext []byte) error {
+	ciphertext, meta, err := acc.cipher.seal(ctx, plaintext)
+	if err != nil {
+		return err
+	}
+	opts, err := acc.uploadOptions(bytes.NewReader(ciphertext), int64(len(ciphertext)), meta)
+	if err != nil {
+		return err
+	}
+	_, err = acc.c.UploadBuffer(ctx, acc.container, blob, ciphertext, opts)
+	return err
+}
+
+// pullDecrypted downloads and verifies the blob and decrypts it if it carries encryption metadata; unencrypted blobs
+// are returned as-is
+func (acc *AzureContainerClient) pullDecrypted(ctx context.Context, item string) ([]byte, error) {
+	body, err := acc.pullVerified(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	// INFO: AES-GCM is not a streaming cipher; the blob is read whole to be decrypted
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return acc.cipher.open(ctx, content, body.meta)
+}
+
 func (acc *AzureContainerClient) DeleteBlob(ctx context.Context, item string) error {
 	_, err := acc.c.DeleteBlob(ctx, acc.container, item, nil)
 	return err
@@ -0,0 +1,239 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azdatalake"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azdatalake/directory"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azdatalake/file"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azdatalake/filesystem"
+)
+
+var ErrACLNotApplied = errors.New("the access control list could not be applied to every path")
+
+type DataLakeConfig struct {
+	Filesystem  string           `yaml:"filesystem" json:"filesystem"`
+	Credentials AzSharedKeyCreds `yaml:"credentials" json:"credentials"`
+}
+
+// DataLakeClient works on a filesystem of an account with the hierarchical namespace enabled; unlike the flat blob API
+// directories are real paths, so renames and deletes of a directory are single atomic operations
+type DataLakeClient struct {
+	fs         *filesystem.Client
+	filesystem string
+}
+
+// PathItem describes a file or directory of the filesystem
+type PathItem struct {
+	Name        string
+	Directory   bool
+	Size        int64
+	Modified    time.Time
+	Owner       string
+	Group       string
+	Permissions string
+}
+
+// AccessControl is the POSIX ownership and access control of a path; ACL uses the short form, e.g. "user::rwx,group::r-x,other::---,user:<oid>:r-x"
+type AccessControl struct {
+	Owner       string
+	Group       string
+	Permissions string
+	ACL         string
+}
+
+// ACLResult counts the paths a recursive access control change was applied to
+type ACLResult struct {
+	Directories int
+	Files       int
+	// Failed holds the paths the change failed on
+	Failed []string
+}
+
+// NewDataLakeClient creates a client for the filesystem; the credentials url may be the blob or the dfs endpoint of the account
+func NewDataLakeClient(config DataLakeConfig) (*DataLakeClient, error) {
+	cred, err := azdatalake.NewSharedKeyCredential(config.Credentials.Account, config.Credentials.Key)
+	if err != nil {
+		return nil, err
+	}
+	url := strings.TrimSuffix(strings.Replace(config.Credentials.Url, ".blob.", ".dfs.", 1), "/") + "/" + config.Filesystem
+	fs, err := filesystem.NewClientWithSharedKeyCredential(url, cred, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DataLakeClient{fs: fs, filesystem: config.Filesystem}, nil
+}
+
+// CreateDirectory creates dir together with its missing parents; creating an existing directory succeeds
+func (dlc *DataLakeClient) CreateDirectory(ctx context.Context, dir string) error {
+	_, err := dlc.fs.NewDirectoryClient(cleanPath(dir)).Create(ctx, nil)
+	return err
+}
+
+// RenameDirectory moves dir and everything under it to dst; it fails when dst exists
+func (dlc *DataLakeClient) RenameDirectory(ctx context.Context, dir string, dst string) error {
+	_, err := dlc.fs.NewDirectoryClient(cleanPath(dir)).Rename(ctx, cleanPath(dst), &directory.RenameOptions{AccessConditions: noOverwrite()})
+	return err
+}
+
+// RenameFile moves the file to dst; it fails when dst exists
+func (dlc *DataLakeClient) RenameFile(ctx context.Context, name string, dst string) error {
+	_, err := dlc.fs.NewFileClient(cleanPath(name)).Rename(ctx, cleanPath(dst), &file.RenameOptions{AccessConditions: noOverwrite()})
+	return err
+}
+
+// DeleteDirectory deletes dir and everything under it
+func (dlc *DataLakeClient) DeleteDirectory(ctx context.Context, dir string) error {
+	_, err := dlc.fs.NewDirectoryClient(cleanPath(dir)).Delete(ctx, nil)
+	return err
+}
+
+// DeleteFile deletes a single file
+func (dlc *DataLakeClient) DeleteFile(ctx context.Context, name string) error {
+	_, err := dlc.fs.NewFileClient(cleanPath(name)).Delete(ctx, nil)
+	return err
+}
+
+// Exists reports whether a file or directory exists at p
+func (dlc *DataLakeClient) Exists(ctx context.Context, p string) (bool, error) {
+	_, err := dlc.fs.NewDirectoryClient(cleanPath(p)).GetProperties(ctx, nil)
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ListPaths lists the paths under dir, the root of the filesystem when dir is empty; recursive also lists the content of the subdirectories
+func (dlc *DataLakeClient) ListPaths(ctx context.Context, dir string, recursive bool) ([]PathItem, error) {
+	opts := new(filesystem.ListPathsOptions)
+	if dir = cleanPath(dir); len(dir) != 0 {
+		opts.Prefix = &dir
+	}
+	items := make([]PathItem, 0)
+	pager := dlc.fs.NewListPathsPager(recursive, opts)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page.Paths {
+			if p == nil || p.Name == nil {
+				continue
+			}
+			item := PathItem{
+				Name:        *p.Name,
+				Directory:   p.IsDirectory != nil && *p.IsDirectory,
+				Owner:       deref(p.Owner),
+				Group:       deref(p.Group),
+				Permissions: deref(p.Permissions),
+			}
+			if p.ContentLength != nil {
+				item.Size = *p.ContentLength
+			}
+			if p.LastModified != nil {
+				if t, err := time.Parse(time.RFC1123, *p.LastModified); err == nil {
+					item.Modified = t
+				}
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// GetAccessControl returns the ownership, permissions and ACL of the file or directory at p
+func (dlc *DataLakeClient) GetAccessControl(ctx context.Context, p string) (AccessControl, error) {
+	resp, err := dlc.fs.NewDirectoryClient(cleanPath(p)).GetAccessControl(ctx, nil)
+	if err != nil {
+		return AccessControl{}, err
+	}
+	return AccessControl{
+		Owner:       deref(resp.Owner),
+		Group:       deref(resp.Group),
+		Permissions: deref(resp.Permissions),
+		ACL:         deref(resp.ACL),
+	}, nil
+}
+
+// SetAccessControl sets the access control of the single path p; empty fields are left unchanged and ACL and Permissions are mutually exclusive
+func (dlc *DataLakeClient) SetAccessControl(ctx context.Context, p string, ac AccessControl) error {
+	opts := &directory.SetAccessControlOptions{
+		Owner:       optional(ac.Owner),
+		Group:       optional(ac.Group),
+		Permissions: optional(ac.Permissions),
+		ACL:         optional(ac.ACL),
+	}
+	_, err := dlc.fs.NewDirectoryClient(cleanPath(p)).SetAccessControl(ctx, opts)
+	return err
+}
+
+// SetACLRecursive replaces the ACL of dir and every path under it
+func (dlc *DataLakeClient) SetACLRecursive(ctx context.Context, dir string, acl string) (ACLResult, error) {
+	resp, err := dlc.fs.NewDirectoryClient(cleanPath(dir)).SetAccessControlRecursive(ctx, acl, &directory.SetAccessControlRecursiveOptions{ContinueOnFailure: to.Ptr(true)})
+	return aclResult(dir, resp, err)
+}
+
+// UpdateACLRecursive adds or modifies the given entries on dir and every path under it, keeping the other entries
+func (dlc *DataLakeClient) UpdateACLRecursive(ctx context.Context, dir string, acl string) (ACLResult, error) {
+	resp, err := dlc.fs.NewDirectoryClient(cleanPath(dir)).UpdateAccessControlRecursive(ctx, acl, &directory.UpdateAccessControlRecursiveOptions{ContinueOnFailure: to.Ptr(true)})
+	return aclResult(dir, resp, err)
+}
+
+// RemoveACLRecursive removes the given entries, e.g. "user:<oid>", from dir and every path under it
+func (dlc *DataLakeClient) RemoveACLRecursive(ctx context.Context, dir string, acl string) (ACLResult, error) {
+	resp, err := dlc.fs.NewDirectoryClient(cleanPath(dir)).RemoveAccessControlRecursive(ctx, acl, &directory.RemoveAccessControlRecursiveOptions{ContinueOnFailure: to.Ptr(true)})
+	return aclResult(dir, resp, err)
+}
+
+func aclResult(dir string, resp directory.SetAccessControlRecursiveResponse, err error) (ACLResult, error) {
+	var res ACLResult
+	if resp.DirectoriesSuccessful != nil {
+		res.Directories = int(*resp.DirectoriesSuccessful)
+	}
+	if resp.FilesSuccessful != nil {
+		res.Files = int(*resp.FilesSuccessful)
+	}
+	for _, e := range resp.FailedEntries {
+		if e != nil && e.Name != nil {
+			res.Failed = append(res.Failed, *e.Name)
+		}
+	}
+	if err != nil {
+		return res, err
+	}
+	if len(res.Failed) != 0 || (resp.FailureCount != nil && *resp.FailureCount > 0) {
+		return res, fmt.Errorf("%w; directory:%s;failed:%v", ErrACLNotApplied, dir, res.Failed)
+	}
+	return res, nil
+}
+
+// noOverwrite makes a rename fail when the destination path exists
+func noOverwrite() *directory.AccessConditions {
+	return &directory.AccessConditions{ModifiedAccessConditions: &directory.ModifiedAccessConditions{IfNoneMatch: to.Ptr(azcore.ETagAny)}}
+}
+
+func cleanPath(p string) string {
+	return strings.Trim(strings.ReplaceAll(p, "\\", "/"), "/")
+}
+
+func optional(s string) *string {
+	if len(s) == 0 {
+		return nil
+	}
+	return &s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,158 @@
+package azure
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// metaEncryptionKeyID is the blob metadata key holding the id of the key the blob was encrypted with
+	metaEncryptionKeyID = "encryptionkeyid"
+	// metaEncryptionAlg is the blob metadata key holding the algorithm the blob was encrypted with
+	metaEncryptionAlg   = "encryptionalg"
+	encryptionAlgAESGCM = "AES-GCM"
+)
+
+var (
+	ErrUnknownEncryptionKey = errors.New("the blob was encrypted with a key that is not available")
+	ErrBadEncryptionKey     = errors.New("encryption keys must be 16, 24 or 32 bytes long")
+	ErrCiphertextCorrupted  = errors.New("the encrypted blob content is corrupted or was tampered with")
+)
+
+// KeyProvider supplies the keys used for client-side encryption; implementations can be backed by the configuration or a secret store
+type KeyProvider interface {
+	// CurrentKey returns the key new uploads are encrypted with
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+	// Key returns the key with the provided id; used on download
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// EncryptionConfig configures client-side encryption from the yaml configuration; keys are base64 encoded
+type EncryptionConfig struct {
+	KeyID string            `yaml:"key_id" json:"key_id"`
+	Keys  map[string]string `yaml:"keys" json:"keys"`
+}
+
+// StaticKeyProvider is a KeyProvider over a fixed set of keys
+type StaticKeyProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyProvider creates a KeyProvider from the encryption configuration
+func NewStaticKeyProvider(c EncryptionConfig) (*StaticKeyProvider, error) {
+	kp := &StaticKeyProvider{current: c.KeyID, keys: make(map[string][]byte)}
+	for id, encoded := range c.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decoding encryption key %s failed:%w", id, err)
+		}
+		if err = validateKey(key); err != nil {
+			return nil, fmt.Errorf("%w; key:%s", err, id)
+		}
+		kp.keys[id] = key
+	}
+	if _, ok := kp.keys[kp.current]; !ok {
+		return nil, fmt.Errorf("%w; key:%s", ErrUnknownEncryptionKey, kp.current)
+	}
+	return kp, nil
+}
+
+func (kp *StaticKeyProvider) CurrentKey(ctx context.Context) (string, []byte, error) {
+	return kp.current, kp.keys[kp.current], nil
+}
+
+func (kp *StaticKeyProvider) Key(ctx context.Context, id string) ([]byte, error) {
+	key, ok := kp.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w; key:%s", ErrUnknownEncryptionKey, id)
+	}
+	return key, nil
+}
+
+func validateKey(key []byte) error {
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	default:
+		return ErrBadEncryptionKey
+	}
+}
+
+// blobCipher encrypts and decrypts blob payloads; the nonce is stored in front of the ciphertext
+type blobCipher struct {
+	keys KeyProvider
+}
+
+func (bc *blobCipher) seal(ctx context.Context, plaintext []byte) ([]byte, map[string]*string, error) {
+	id, key, err := bc.keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+	alg := encryptionAlgAESGCM
+	meta := map[string]*string{
+		metaEncryptionKeyID: &id,
+		metaEncryptionAlg:   &alg,
+	}
+	return aead.Seal(nonce, nonce, plaintext, []byte(id)), meta, nil
+}
+
+func (bc *blobCipher) open(ctx context.Context, ciphertext []byte, meta map[string]*string) ([]byte, error) {
+	id, encrypted := lookupMeta(meta, metaEncryptionKeyID)
+	if !encrypted {
+		return ciphertext, nil
+	}
+	key, err := bc.keys.Key(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrCiphertextCorrupted
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrCiphertextCorrupted, err)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// lookupMeta finds a metadata value; the service does not preserve the case of metadata keys
+func lookupMeta(meta map[string]*string, key string) (string, bool) {
+	for k, v := range meta {
+		if strings.EqualFold(k, key) && v != nil {
+			return *v, true
+		}
+	}
+	return "", false
+}
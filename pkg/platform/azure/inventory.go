@@ -0,0 +1,156 @@
+package azure
+
+import (
+	"context"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+)
+
+// InventoryItem describes a blob of the container
+type InventoryItem struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+	Tags     map[string]string
+	// Tier is the access tier, inferred from the account default when not set on the blob
+	Tier AccessTier
+}
+
+// InventoryItems lists the blobs under prefix together with their size, modification time and index tags
+func (acc *AzureContainerClient) InventoryItems(ctx context.Context, prefix string) ([]InventoryItem, error) {
+	opts := &azblob.ListBlobsFlatOptions{Include: container.ListBlobsInclude{Tags: true}}
+	if len(prefix) != 0 {
+		opts.Prefix = &prefix
+	}
+	items := make([]InventoryItem, 0)
+	pager := acc.c.NewListBlobsFlatPager(acc.container, opts)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, blob := range page.Segment.BlobItems {
+			item := InventoryItem{Name: *blob.Name, Tags: make(map[string]string)}
+			if p := blob.Properties; p != nil {
+				if p.ContentLength != nil {
+					item.Size = *p.ContentLength
+				}
+				if p.LastModified != nil {
+					item.Modified = *p.LastModified
+				}
+				if p.AccessTier != nil {
+					item.Tier = AccessTier(*p.AccessTier)
+				}
+			}
+			if blob.BlobTags != nil {
+				for _, t := range blob.BlobTags.BlobTagSet {
+					if t != nil && t.Key != nil && t.Value != nil {
+						item.Tags[*t.Key] = *t.Value
+					}
+				}
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// Inventory produces a dataframe of the blobs under prefix with the columns name, size, modified, age and tags;
+// age is in whole seconds and tags are formatted as "key=value" pairs separated by ';'
+func (acc *AzureContainerClient) Inventory(ctx context.Context, prefix string) (*datamanagement.Dataframe, error) {
+	items, err := acc.InventoryItems(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return InventoryFrame(items, time.Now())
+}
+
+// InventoryFrame lays out the items like Inventory, computing the ages relative to now
+func InventoryFrame(items []InventoryItem, now time.Time) (*datamanagement.Dataframe, error) {
+	records := make([]datamanagement.Record, 0, len(items)+1)
+	records = append(records, datamanagement.Record{"name", "size", "modified", "age", "tags"})
+	for _, item := range items {
+		tags := make([]string, 0, len(item.Tags))
+		for _, k := range slices.Sorted(maps.Keys(item.Tags)) {
+			tags = append(tags, k+"="+item.Tags[k])
+		}
+		records = append(records, datamanagement.Record{
+			item.Name,
+			strconv.FormatInt(item.Size, 10),
+			item.Modified.UTC().Format(time.RFC3339),
+			strconv.FormatInt(int64(now.Sub(item.Modified)/time.Second), 10),
+			strings.Join(tags, ";"),
+		})
+	}
+	return datamanagement.NewDataframeFromRecords(records, nil, datamanagement.WithInterpretedColumns())
+}
+
+// Manifest is the local record of the blobs a container should hold: blob name to expected size; a negative size is not checked
+type Manifest map[string]int64
+
+// ManifestFromDir builds the manifest of the files under root as if each was uploaded under blobdir with UploadFile,
+// named by UploadedBlobName; files of different directories sharing a blob name collide like their uploads do.
+// Client-side encrypted blobs are larger than their files, pass withSizes false for such containers
+func ManifestFromDir(root string, blobdir string, withSizes bool) (Manifest, error) {
+	m := make(Manifest)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size := int64(-1)
+		if withSizes {
+			size = info.Size()
+		}
+		m[UploadedBlobName(p, blobdir)] = size
+		return nil
+	})
+	return m, err
+}
+
+// Reconciliation is the result of comparing an inventory with a manifest
+type Reconciliation struct {
+	// Missing blobs are in the manifest but not in the container
+	Missing []string
+	// Orphaned blobs are in the container but not in the manifest
+	Orphaned []string
+	// SizeMismatch blobs exist on both sides with different sizes
+	SizeMismatch []string
+}
+
+// Reconcile compares the inventory with the manifest; the result lists are sorted
+func Reconcile(items []InventoryItem, m Manifest) Reconciliation {
+	var r Reconciliation
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		seen[item.Name] = true
+		size, ok := m[item.Name]
+		switch {
+		case !ok:
+			r.Orphaned = append(r.Orphaned, item.Name)
+		case size >= 0 && size != item.Size:
+			r.SizeMismatch = append(r.SizeMismatch, item.Name)
+		}
+	}
+	for name := range m {
+		if !seen[name] {
+			r.Missing = append(r.Missing, name)
+		}
+	}
+	slices.Sort(r.Missing)
+	slices.Sort(r.Orphaned)
+	slices.Sort(r.SizeMismatch)
+	return r
+}
@@ -0,0 +1,90 @@
+package azure_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure"
+)
+
+func TestAppendCreatesBlob(t *testing.T) {
+	bs, acc := newBlobServer(t)
+	ctx := context.Background()
+
+	state, err := acc.AppendLine(ctx, "logs/line1.log", []byte("started"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != (azure.AppendBlobState{Size: 8, Blocks: 1}) {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	if state, err = acc.AppendLine(ctx, "logs/line1.log", []byte("stopped")); err != nil {
+		t.Fatal(err)
+	}
+	if state != (azure.AppendBlobState{Size: 16, Blocks: 2}) {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	b, ok := bs.blob("logs/line1.log")
+	if !ok || b.kind != "AppendBlob" || string(b.content) != "started\nstopped\n" {
+		t.Fatalf("unexpected blob %+v", b)
+	}
+	if tracked, ok := acc.AppendState("logs/line1.log"); !ok || tracked != state {
+		t.Fatalf("expected the tracked state %+v, got %+v", state, tracked)
+	}
+	if _, err = acc.Append(ctx, "logs/line1.log", nil); !errors.Is(err, azure.ErrNothingToAppend) {
+		t.Fatalf("expected ErrNothingToAppend, got %v", err)
+	}
+}
+
+func TestAppendChunksLargePayloads(t *testing.T) {
+	bs, acc := newBlobServer(t)
+	data := bytes.Repeat([]byte{'x'}, 2*azure.MaxAppendBlockBytes+10)
+
+	state, err := acc.Append(context.Background(), "dumps/dump.bin", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != (azure.AppendBlobState{Size: int64(len(data)), Blocks: 3}) {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	// the first block is refused with BlobNotFound and sent again after the blob is created
+	if bs.appends != 4 {
+		t.Fatalf("expected 4 append requests, got %d", bs.appends)
+	}
+	if b, _ := bs.blob("dumps/dump.bin"); !bytes.Equal(b.content, data) {
+		t.Fatal("the stored blob differs from the appended data")
+	}
+}
+
+func TestAppendBlobFull(t *testing.T) {
+	bs, acc := newBlobServer(t)
+	bs.maxBlocks = 1
+	ctx := context.Background()
+
+	if _, err := acc.AppendLine(ctx, "logs/line1.log", []byte("started")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acc.AppendLine(ctx, "logs/line1.log", []byte("stopped")); !errors.Is(err, azure.ErrAppendBlobFull) {
+		t.Fatalf("expected ErrAppendBlobFull, got %v", err)
+	}
+
+	// a blob known to be full is refused without a request
+	b, _ := bs.blob("logs/line1.log")
+	b.blocks = azure.MaxAppendBlocks
+	state, err := acc.RefreshAppendState(ctx, "logs/line1.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != (azure.AppendBlobState{Size: 8, Blocks: azure.MaxAppendBlocks}) {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	before := bs.appends
+	if _, err = acc.AppendLine(ctx, "logs/line1.log", []byte("stopped")); !errors.Is(err, azure.ErrAppendBlobFull) {
+		t.Fatalf("expected ErrAppendBlobFull, got %v", err)
+	}
+	if bs.appends != before {
+		t.Fatal("the append was sent to the service")
+	}
+}
@@ -1,32 +1,96 @@
 package azure_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
-	"github.com/ivanehh/go-boiler-lib/pkg/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure"
-	"github.com/ivanehh/go-boiler-lib/pkg/platform/logging"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure/azuretest"
 )
 
-// TODO: The test are broken; Rewrite them
-func TestNewAzClient(t *testing.T) {
-	err := config.Load("/home/terzivan/projects/MDOD/DCS/dcs-gradec/config/cfg.yaml")
-	if err != nil {
+// testBlobStore runs the upload and download paths against a store
+func testBlobStore(t *testing.T, store azure.BlobStore) {
+	ctx := context.Background()
+	if err := store.UploadBuffer(ctx, "lines/line1.csv", *bytes.NewBufferString("wo,qty\n1,2\n")); err != nil {
 		t.Fatal(err)
 	}
-	logging.NewDCSlogger("test", config.Provide().LogConfig())
-	acc, err := azure.NewAzContainerClient()
-	if err != nil {
+	src := filepath.Join(t.TempDir(), "report.2025.csv")
+	if err := os.WriteFile(src, []byte("plant,state\ngradec,ok\n"), 0o644); err != nil {
 		t.Fatal(err)
 	}
-	f, err := os.Open("/home/terzivan/tmp/Pipfile")
+	f, err := os.Open(src)
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer f.Close()
-	err = acc.Upload(f, "")
+	if err = store.UploadFile(ctx, f, "reports"); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := store.Enumerate(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if !slices.Equal(items, []string{"lines/line1.csv", "reports/report.csv"}) {
+		t.Fatalf("unexpected blobs %v", items)
+	}
+
+	buf := make([]byte, 64)
+	if err = store.PullBuffer(ctx, "lines/line1.csv", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:11]) != "wo,qty\n1,2\n" {
+		t.Fatalf("unexpected content %q", buf)
+	}
+	dst, err := os.Create(filepath.Join(t.TempDir(), "report.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	if err = store.PullFile(ctx, "reports/report.csv", dst); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(dst.Name()); string(got) != "plant,state\ngradec,ok\n" {
+		t.Fatalf("unexpected file content %q", got)
+	}
+
+	if err = store.DeleteBlob(ctx, "lines/line1.csv"); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.PullBuffer(ctx, "lines/line1.csv", &buf); !bloberror.HasCode(err, bloberror.BlobNotFound) {
+		t.Fatalf("expected BlobNotFound, got %v", err)
+	}
+}
+
+func TestFakeBlobStore(t *testing.T) {
+	store := azuretest.NewFakeBlobStore()
+	testBlobStore(t, store)
+
+	small := make([]byte, 4)
+	if err := store.PullBuffer(context.Background(), "reports/report.csv", &small); !errors.Is(err, azure.ErrDestinationTooSmall) {
+		t.Fatalf("expected ErrDestinationTooSmall, got %v", err)
+	}
+	outage := errors.New("storage unavailable")
+	store.Fail = func(op string, blob string) error {
+		if op == azuretest.OpUpload {
+			return outage
+		}
+		return nil
+	}
+	if err := store.UploadBuffer(context.Background(), "lines/line2.csv", bytes.Buffer{}); !errors.Is(err, outage) {
+		t.Fatalf("expected the injected failure, got %v", err)
+	}
+	if _, ok := store.Blob("lines/line2.csv"); ok {
+		t.Fatal("a failed upload must not store the blob")
+	}
+}
+
+func TestAzuriteBlobStore(t *testing.T) {
+	testBlobStore(t, azuretest.NewAzuriteClient(t, "pipeline-test"))
 }
@@ -0,0 +1,239 @@
+package azure_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure/azuretest"
+)
+
+// queuedMessage is a message held by queueServer
+type queuedMessage struct {
+	ID           string `xml:"MessageId"`
+	PopReceipt   string `xml:"PopReceipt"`
+	DequeueCount int64  `xml:"DequeueCount"`
+	Text         string `xml:"MessageText"`
+}
+
+// queueServer is a minimal storage queue service: every dequeue hands out all the messages not deleted yet
+type queueServer struct {
+	mu       sync.Mutex
+	messages []*queuedMessage
+}
+
+func (qs *queueServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/messages"):
+		for _, m := range qs.messages {
+			m.DequeueCount++
+			m.PopReceipt = fmt.Sprintf("%s-%d", m.ID, m.DequeueCount)
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		xml.NewEncoder(w).Encode(struct {
+			XMLName  xml.Name         `xml:"QueueMessagesList"`
+			Messages []*queuedMessage `xml:"QueueMessage"`
+		}{Messages: qs.messages})
+	case r.Method == http.MethodDelete:
+		id := r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+		i := slices.IndexFunc(qs.messages, func(m *queuedMessage) bool {
+			return m.ID == id && m.PopReceipt == r.URL.Query().Get("popreceipt")
+		})
+		if i < 0 {
+			w.Header().Set("x-ms-error-code", "MessageNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		qs.messages = slices.Delete(qs.messages, i, i+1)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (qs *queueServer) push(id string, text string) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qs.messages = append(qs.messages, &queuedMessage{ID: id, Text: text})
+}
+
+func (qs *queueServer) len() int {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	return len(qs.messages)
+}
+
+func blobEventJSON(t *testing.T, eventType string, blob string) string {
+	t.Helper()
+	ev, err := json.Marshal([]map[string]any{{
+		"id":        blob,
+		"eventType": eventType,
+		"subject":   "/blobServices/default/containers/raw/blobs/" + blob,
+		"eventTime": "2025-10-13T10:00:00Z",
+		"data":      map[string]any{"url": "https://account.blob.core.windows.net/raw/" + blob, "contentLength": 11},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(ev)
+}
+
+func TestBlobEventListener(t *testing.T) {
+	qs := &queueServer{}
+	srv := httptest.NewServer(qs)
+	defer srv.Close()
+	event := func(eventType string, blob string) string {
+		ev := blobEventJSON(t, eventType, blob)
+		// a queue message carries a single event, base64 encoded by Event Grid
+		return base64.StdEncoding.EncodeToString([]byte(ev[1 : len(ev)-1]))
+	}
+	qs.push("created", event(azure.EventTypeBlobCreated, "lines/line1.csv"))
+	qs.push("plain", strings.Trim(blobEventJSON(t, azure.EventTypeBlobCreated, "lines/line2.csv"), "[]"))
+	qs.push("deleted", event(azure.EventTypeBlobDeleted, "lines/line3.csv"))
+	qs.push("other-prefix", event(azure.EventTypeBlobCreated, "reports/report.csv"))
+	qs.push("poisoned", event(azure.EventTypeBlobCreated, "lines/bad.csv"))
+	qs.push("malformed", "not an event")
+	qs.push("bad-subject", base64.StdEncoding.EncodeToString([]byte(`{"eventType":"Microsoft.Storage.BlobCreated","subject":"/blobServices/default"}`)))
+
+	var mu sync.Mutex
+	var handled []azure.BlobEvent
+	var errs []error
+	handlerErr := errors.New("parsing failed")
+	l, err := azure.NewBlobEventListener(azure.EventListenerConfig{
+		QueueURL:      srv.URL + "/" + azuretest.AzuriteAccount + "/events",
+		Credentials:   azuretest.AzuriteCredentials(),
+		PollInterval:  10 * time.Millisecond,
+		MaxDeliveries: 2,
+	}, func(ctx context.Context, ev azure.BlobEvent) error {
+		if ev.Blob == "lines/bad.csv" {
+			return handlerErr
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, ev)
+		return nil
+	}, azure.WithBlobPrefix("lines/"), azure.WithEventErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- l.Run(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for qs.len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err = <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the listener to stop with the context, got %v", err)
+	}
+	if n := qs.len(); n != 0 {
+		t.Fatalf("expected every message to be removed, %d left", n)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var blobs []string
+	for _, ev := range handled {
+		blobs = append(blobs, ev.Blob)
+	}
+	if !slices.Equal(blobs, []string{"lines/line1.csv", "lines/line2.csv"}) {
+		t.Fatalf("unexpected handled blobs %v", blobs)
+	}
+	if ev := handled[0]; ev.Container != "raw" || ev.Type != azure.EventTypeBlobCreated || ev.ContentLength != 11 || ev.URL == "" {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+	var bad, poisoned, failed int
+	for _, err := range errs {
+		switch {
+		case errors.Is(err, azure.ErrBadBlobEvent):
+			bad++
+		case errors.Is(err, azure.ErrPoisonedEvent):
+			if !errors.Is(err, handlerErr) {
+				t.Fatalf("the poisoned event error misses its cause: %v", err)
+			}
+			poisoned++
+		case errors.Is(err, handlerErr):
+			failed++
+		}
+	}
+	// the failing event is redelivered once, then discarded on its second delivery
+	if bad != 2 || poisoned != 1 || failed != 1 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
+
+func TestBlobEventWebhook(t *testing.T) {
+	var handled []string
+	l, err := azure.NewBlobEventWebhook(func(ctx context.Context, ev azure.BlobEvent) error {
+		if ev.Blob == "lines/bad.csv" {
+			return errors.New("parsing failed: secret connection string")
+		}
+		handled = append(handled, ev.Blob)
+		return nil
+	}, azure.WithEventTypes(azure.EventTypeBlobCreated, azure.EventTypeBlobDeleted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(l)
+	defer srv.Close()
+	post := func(payload string) (*http.Response, string) {
+		resp, err := http.Post(srv.URL, "application/json", strings.NewReader(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, string(body)
+	}
+
+	resp, body := post(`[{"id":"1","eventType":"Microsoft.EventGrid.SubscriptionValidationEvent","data":{"validationCode":"512d38b6"}}]`)
+	var validation map[string]string
+	if err = json.Unmarshal([]byte(body), &validation); err != nil || resp.StatusCode != http.StatusOK || validation["validationResponse"] != "512d38b6" {
+		t.Fatalf("unexpected validation response %d %q", resp.StatusCode, body)
+	}
+
+	created := blobEventJSON(t, azure.EventTypeBlobCreated, "lines/line1.csv")
+	deleted := blobEventJSON(t, azure.EventTypeBlobDeleted, "lines/line2.csv")
+	if resp, _ = post(created[:len(created)-1] + "," + deleted[1:]); resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	if !slices.Equal(handled, []string{"lines/line1.csv", "lines/line2.csv"}) {
+		t.Fatalf("unexpected handled blobs %v", handled)
+	}
+
+	resp, body = post(blobEventJSON(t, azure.EventTypeBlobCreated, "lines/bad.csv"))
+	if resp.StatusCode != http.StatusInternalServerError || strings.Contains(body, "secret") {
+		t.Fatalf("unexpected response %d %q", resp.StatusCode, body)
+	}
+	if resp, _ = post("not an event"); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	large := `[{"id":"` + string(bytes.Repeat([]byte{'x'}, 2<<20)) + `"}]`
+	if resp, _ = post(large); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+}
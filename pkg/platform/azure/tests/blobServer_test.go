@@ -0,0 +1,226 @@
+package azure_test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure/azuretest"
+)
+
+// storedBlob is a blob held by blobServer
+type storedBlob struct {
+	kind     string
+	content  []byte
+	meta     map[string]string
+	blocks   int32
+	tier     string
+	modified time.Time
+	// md5 is the base64 encoded Content-MD5 property
+	md5 string
+}
+
+// blobServer is a minimal blob service covering the requests the client makes for uploads, block uploads, downloads,
+// listings, tiers and append blobs; maxBlocks, when set, fails appends beyond it like the service does at MaxAppendBlocks
+type blobServer struct {
+	maxBlocks int32
+
+	mu    sync.Mutex
+	blobs map[string]*storedBlob
+	// staged holds the uncommitted blocks by blob and block id
+	staged map[string]map[string][]byte
+	// appends counts the append block requests
+	appends int
+	// prefixes records the prefix of every listing
+	prefixes []string
+}
+
+// newBlobServer starts a blob service and returns a client of its container
+func newBlobServer(t *testing.T, opts ...azure.AzureClientOpt) (*blobServer, *azure.AzureContainerClient) {
+	t.Helper()
+	bs := &blobServer{blobs: make(map[string]*storedBlob), staged: make(map[string]map[string][]byte)}
+	srv := httptest.NewServer(bs)
+	t.Cleanup(srv.Close)
+	config := azuretest.AzuriteConfig("server-test")
+	// an IP host makes the client address the account in the path, like on the emulator
+	config.Credentials.Url = srv.URL + "/" + azuretest.AzuriteAccount
+	acc, err := azure.NewAzContainerClient(config, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bs, acc
+}
+
+func (bs *blobServer) blob(name string) (*storedBlob, bool) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b, ok := bs.blobs[name]
+	return b, ok
+}
+
+func (bs *blobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// /{account}/{container}/{blob}
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	if len(parts) == 2 && r.URL.Query().Get("comp") == "list" {
+		bs.list(w, r.URL.Query().Get("prefix"))
+		return
+	}
+	if len(parts) < 3 {
+		serviceError(w, http.StatusBadRequest, "InvalidUri")
+		return
+	}
+	name := parts[2]
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		serviceError(w, http.StatusBadRequest, "InvalidInput")
+		return
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	w.Header().Set("ETag", `"0x1"`)
+	w.Header().Set("Last-Modified", "Mon, 13 Oct 2025 10:00:00 GMT")
+	b, exists := bs.blobs[name]
+	switch {
+	case r.Method == http.MethodPut && r.URL.Query().Get("comp") == "appendblock":
+		bs.appends++
+		if !exists {
+			serviceError(w, http.StatusNotFound, bloberror.BlobNotFound)
+			return
+		}
+		if bs.maxBlocks > 0 && b.blocks >= bs.maxBlocks {
+			serviceError(w, http.StatusConflict, bloberror.BlockCountExceedsLimit)
+			return
+		}
+		w.Header().Set("x-ms-blob-append-offset", strconv.Itoa(len(b.content)))
+		b.content = append(b.content, body...)
+		b.blocks++
+		w.Header().Set("x-ms-blob-committed-block-count", strconv.Itoa(int(b.blocks)))
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && r.URL.Query().Get("comp") == "tier":
+		if !exists {
+			serviceError(w, http.StatusNotFound, bloberror.BlobNotFound)
+			return
+		}
+		b.tier = r.Header.Get("x-ms-access-tier")
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPut && r.URL.Query().Get("comp") == "block":
+		if bs.staged[name] == nil {
+			bs.staged[name] = make(map[string][]byte)
+		}
+		bs.staged[name][r.URL.Query().Get("blockid")] = body
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && r.URL.Query().Get("comp") == "blocklist":
+		var list struct {
+			Latest []string `xml:"Latest"`
+		}
+		if err := xml.Unmarshal(body, &list); err != nil {
+			serviceError(w, http.StatusBadRequest, "InvalidXmlDocument")
+			return
+		}
+		b = newStoredBlob("BlockBlob", nil, r.Header)
+		for _, id := range list.Latest {
+			block, ok := bs.staged[name][id]
+			if !ok {
+				serviceError(w, http.StatusBadRequest, bloberror.InvalidBlockList)
+				return
+			}
+			b.content = append(b.content, block...)
+		}
+		delete(bs.staged, name)
+		bs.blobs[name] = b
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && r.URL.Query().Get("comp") == "":
+		bs.blobs[name] = newStoredBlob(r.Header.Get("x-ms-blob-type"), body, r.Header)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		if !exists {
+			w.Header().Set("x-ms-error-code", string(bloberror.BlobNotFound))
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for key, value := range b.meta {
+			w.Header().Set("x-ms-meta-"+key, value)
+		}
+		w.Header().Set("x-ms-blob-type", b.kind)
+		w.Header().Set("x-ms-blob-committed-block-count", strconv.Itoa(int(b.blocks)))
+		content, status := b.content, http.StatusOK
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("x-ms-range"), "bytes=%d-%d", &start, &end); err == nil {
+			end = min(end+1, len(content))
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(content)))
+			content, status = content[start:end], http.StatusPartialContent
+		} else if b.md5 != "" {
+			w.Header().Set("Content-MD5", b.md5)
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			w.Write(content)
+		}
+	default:
+		// a 4xx status, the client retries 5xx ones
+		serviceError(w, http.StatusBadRequest, "UnsupportedHttpVerb")
+	}
+}
+
+// newStoredBlob returns a blob with the properties and metadata of the headers of its upload
+func newStoredBlob(kind string, content []byte, header http.Header) *storedBlob {
+	b := &storedBlob{kind: kind, content: content, meta: make(map[string]string), md5: header.Get("x-ms-blob-content-md5")}
+	for key, values := range header {
+		if meta, ok := strings.CutPrefix(strings.ToLower(key), "x-ms-meta-"); ok {
+			b.meta[meta] = values[0]
+		}
+	}
+	return b
+}
+
+// list writes a single page listing the blobs under prefix
+func (bs *blobServer) list(w http.ResponseWriter, prefix string) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.prefixes = append(bs.prefixes, prefix)
+	type properties struct {
+		LastModified  string `xml:"Last-Modified"`
+		ContentLength int    `xml:"Content-Length"`
+		AccessTier    string `xml:"AccessTier,omitempty"`
+	}
+	type item struct {
+		Name       string     `xml:"Name"`
+		Properties properties `xml:"Properties"`
+	}
+	var items []item
+	for _, name := range slices.Sorted(maps.Keys(bs.blobs)) {
+		if b := bs.blobs[name]; strings.HasPrefix(name, prefix) {
+			items = append(items, item{Name: name, Properties: properties{
+				LastModified:  b.modified.UTC().Format(http.TimeFormat),
+				ContentLength: len(b.content),
+				AccessTier:    b.tier,
+			}})
+		}
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"EnumerationResults"`
+		Blobs   []item   `xml:"Blobs>Blob"`
+	}{Blobs: items})
+}
+
+// serviceError writes an error response the client parses into a ResponseError with the error code
+func serviceError(w http.ResponseWriter, status int, code bloberror.Code) {
+	w.Header().Set("x-ms-error-code", string(code))
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"utf-8\"?><Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
@@ -0,0 +1,147 @@
+package azure_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure/azuretest"
+)
+
+func TestUnknownChecksum(t *testing.T) {
+	config := azuretest.AzuriteConfig("checksum-test")
+	config.Checksum = "sha1"
+	if _, err := azure.NewAzContainerClient(config); !errors.Is(err, azure.ErrUnknownChecksum) {
+		t.Fatalf("expected ErrUnknownChecksum, got %v", err)
+	}
+}
+
+func TestAzuriteChecksums(t *testing.T) {
+	for _, alg := range []azure.ChecksumAlgorithm{azure.ChecksumMD5, azure.ChecksumCRC64} {
+		t.Run(string(alg), func(t *testing.T) {
+			container := "checksum-" + string(alg)
+			store := azuretest.NewAzuriteClient(t, container, azure.WithChecksum(alg))
+			testBlobStore(t, store)
+
+			ctx := context.Background()
+			if err := store.UploadBuffer(ctx, "lines/line2.csv", *bytes.NewBufferString("wo,qty\n3,4\n")); err != nil {
+				t.Fatal(err)
+			}
+			// replace the stored checksum, as if the content had been corrupted
+			cred, err := azblob.NewSharedKeyCredential(azuretest.AzuriteAccount, azuretest.AzuriteKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			admin, err := azblob.NewClientWithSharedKeyCredential(azuretest.AzuriteURL(), cred, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bc := admin.ServiceClient().NewContainerClient(container).NewBlobClient("lines/line2.csv")
+			if alg == azure.ChecksumMD5 {
+				_, err = bc.SetHTTPHeaders(ctx, blob.HTTPHeaders{BlobContentMD5: make([]byte, 16)}, nil)
+			} else {
+				bogus := "AAAAAAAAAAA="
+				_, err = bc.SetMetadata(ctx, map[string]*string{"contentcrc64": &bogus}, nil)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			buf := make([]byte, 64)
+			err = store.PullBuffer(ctx, "lines/line2.csv", &buf)
+			var integrity *azure.IntegrityError
+			if !errors.Is(err, azure.ErrChecksumMismatch) || !errors.As(err, &integrity) || integrity.Algorithm != alg {
+				t.Fatalf("expected a %s IntegrityError, got %v", alg, err)
+			}
+		})
+	}
+}
+
+func TestBlockBlobSinkChecksums(t *testing.T) {
+	for _, alg := range []azure.ChecksumAlgorithm{azure.ChecksumMD5, azure.ChecksumCRC64} {
+		t.Run(string(alg), func(t *testing.T) {
+			bs, acc := newBlobServer(t, azure.WithChecksum(alg))
+			ctx := context.Background()
+			sink, err := acc.NewBlockBlobSink("exports/lines.csv")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, chunk := range []string{"wo,qty\n", "1,2\n", "3,4\n"} {
+				if err = sink.WriteChunk(ctx, []byte(chunk)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err = sink.Commit(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			// the committed blob carries the checksum of all its chunks
+			b, _ := bs.blob("exports/lines.csv")
+			if string(b.content) != "wo,qty\n1,2\n3,4\n" {
+				t.Fatalf("unexpected content %q", b.content)
+			}
+			if (alg == azure.ChecksumMD5) == (b.md5 == "") || (alg == azure.ChecksumCRC64) == (b.meta["contentcrc64"] == "") {
+				t.Fatalf("expected a %s checksum, got md5 %q and metadata %v", alg, b.md5, b.meta)
+			}
+			buf := make([]byte, 64)
+			if err = acc.PullBuffer(ctx, "exports/lines.csv", &buf); err != nil || string(buf) != "wo,qty\n1,2\n3,4\n" {
+				t.Fatalf("unexpected content %q: %v", buf, err)
+			}
+			b.content[0] = 'W'
+			if err = acc.PullBuffer(ctx, "exports/lines.csv", &buf); !errors.Is(err, azure.ErrChecksumMismatch) {
+				t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPullFileVerified(t *testing.T) {
+	kp := newKeyProvider(t, "k1", map[string]string{"k1": testKey1})
+	for name, opt := range map[string]azure.AzureClientOpt{
+		"md5":       azure.WithChecksum(azure.ChecksumMD5),
+		"crc64":     azure.WithChecksum(azure.ChecksumCRC64),
+		"encrypted": azure.WithKeyProvider(kp),
+	} {
+		t.Run(name, func(t *testing.T) {
+			bs, acc := newBlobServer(t, opt)
+			ctx := context.Background()
+			if err := acc.UploadBuffer(ctx, "lines/line1.csv", *bytes.NewBufferString("wo,qty\n1,2\n")); err != nil {
+				t.Fatal(err)
+			}
+			// a longer file read to its end: the download replaces the whole content like an unverified one
+			f, err := os.Create(filepath.Join(t.TempDir(), "line1.csv"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if _, err = f.WriteString("previous content of the file\n"); err != nil {
+				t.Fatal(err)
+			}
+			if err = acc.PullFile(ctx, "lines/line1.csv", f); err != nil {
+				t.Fatal(err)
+			}
+			if content, _ := os.ReadFile(f.Name()); string(content) != "wo,qty\n1,2\n" {
+				t.Fatalf("unexpected file content %q", content)
+			}
+			if name == "encrypted" {
+				return
+			}
+
+			b, _ := bs.blob("lines/line1.csv")
+			b.content[0] = 'W'
+			if err = acc.PullFile(ctx, "lines/line1.csv", f); !errors.Is(err, azure.ErrChecksumMismatch) {
+				t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+			}
+			// the content not matching its checksum is not left behind
+			if info, _ := f.Stat(); info.Size() != 0 {
+				t.Fatalf("expected an empty file, got %d bytes", info.Size())
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package azure_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure/azuretest"
+)
+
+// dataLakeServer answers the path requests of the Data Lake client: HEAD finds the paths in dirs and recursive
+// access control changes respond with the pages in acl, keyed by path
+type dataLakeServer struct {
+	dirs []string
+	acl  map[string][]map[string]any
+
+	mu    sync.Mutex
+	paths []string
+}
+
+func (ds *dataLakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// /{account}/{filesystem}/{path}
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	p := ""
+	if len(parts) == 3 {
+		p = parts[2]
+	}
+	ds.mu.Lock()
+	ds.paths = append(ds.paths, p)
+	ds.mu.Unlock()
+	switch {
+	case r.Method == http.MethodHead:
+		if !slices.Contains(ds.dirs, p) {
+			w.Header().Set("x-ms-error-code", "PathNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("x-ms-resource-type", "directory")
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPatch && r.URL.Query().Get("action") == "setAccessControlRecursive":
+		pages := ds.acl[p]
+		page := 0
+		if c := r.URL.Query().Get("continuation"); c != "" {
+			page = len(pages) - 1
+		}
+		if page < len(pages)-1 {
+			w.Header().Set("x-ms-continuation", "next")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pages[page])
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newDataLakeServer(t *testing.T, ds *dataLakeServer) *azure.DataLakeClient {
+	t.Helper()
+	srv := httptest.NewServer(ds)
+	t.Cleanup(srv.Close)
+	creds := azuretest.AzuriteCredentials()
+	creds.Url = srv.URL + "/" + azuretest.AzuriteAccount
+	dlc, err := azure.NewDataLakeClient(azure.DataLakeConfig{Filesystem: "raw", Credentials: creds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dlc
+}
+
+func TestDataLakePaths(t *testing.T) {
+	ds := &dataLakeServer{dirs: []string{"lines/2025"}}
+	dlc := newDataLakeServer(t, ds)
+	ctx := context.Background()
+
+	// windows separators and surrounding slashes are cleaned up
+	for _, p := range []string{"lines/2025", "/lines/2025/", `lines\2025`} {
+		ok, err := dlc.Exists(ctx, p)
+		if err != nil || !ok {
+			t.Fatalf("%s: expected the directory to exist: %v", p, err)
+		}
+	}
+	if ok, err := dlc.Exists(ctx, "lines/2024"); err != nil || ok {
+		t.Fatalf("expected the directory to be missing: %v", err)
+	}
+	if !slices.Equal(ds.paths, []string{"lines/2025", "lines/2025", "lines/2025", "lines/2024"}) {
+		t.Fatalf("unexpected request paths %v", ds.paths)
+	}
+}
+
+func TestDataLakeACLResult(t *testing.T) {
+	dlc := newDataLakeServer(t, &dataLakeServer{acl: map[string][]map[string]any{
+		"lines": {
+			{"directoriesSuccessful": 1, "filesSuccessful": 2, "failureCount": 0},
+			{"directoriesSuccessful": 2, "filesSuccessful": 3, "failureCount": 0},
+		},
+		"locked": {{
+			"directoriesSuccessful": 1, "filesSuccessful": 1, "failureCount": 1,
+			"failedEntries": []map[string]string{{"name": "locked/line1.csv", "type": "FILE", "errorMessage": "AuthorizationPermissionMismatch"}},
+		}},
+		"counted": {{"directoriesSuccessful": 0, "filesSuccessful": 4, "failureCount": 2}},
+	}})
+	ctx := context.Background()
+
+	res, err := dlc.SetACLRecursive(ctx, "/lines/", "user::rwx,group::r-x,other::---")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the batches of a recursive change add up
+	if res.Directories != 3 || res.Files != 5 || len(res.Failed) != 0 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	res, err = dlc.UpdateACLRecursive(ctx, "locked", "user:reader:r-x")
+	if !errors.Is(err, azure.ErrACLNotApplied) {
+		t.Fatalf("expected ErrACLNotApplied, got %v", err)
+	}
+	if res.Directories != 1 || res.Files != 1 || !slices.Equal(res.Failed, []string{"locked/line1.csv"}) {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	// the service may report failures without listing them
+	res, err = dlc.RemoveACLRecursive(ctx, "counted", "user:reader")
+	if !errors.Is(err, azure.ErrACLNotApplied) {
+		t.Fatalf("expected ErrACLNotApplied, got %v", err)
+	}
+	if res.Files != 4 || len(res.Failed) != 0 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+// TestDataLakeAccount needs an account with the hierarchical namespace enabled, which the emulator does not offer;
+// it runs when DATALAKE_URL, DATALAKE_ACCOUNT and DATALAKE_KEY are set
+func TestDataLakeAccount(t *testing.T) {
+	creds := azure.AzSharedKeyCreds{Url: os.Getenv("DATALAKE_URL"), Account: os.Getenv("DATALAKE_ACCOUNT"), Key: os.Getenv("DATALAKE_KEY")}
+	if creds.Url == "" || creds.Account == "" || creds.Key == "" {
+		t.Skip("set DATALAKE_URL, DATALAKE_ACCOUNT and DATALAKE_KEY to run against a Data Lake account")
+	}
+	filesystem := os.Getenv("DATALAKE_FILESYSTEM")
+	if filesystem == "" {
+		filesystem = "datalake-test"
+	}
+	dlc, err := azure.NewDataLakeClient(azure.DataLakeConfig{Filesystem: filesystem, Credentials: creds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err = dlc.CreateDirectory(ctx, "go-boiler-lib/lines/2025"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dlc.DeleteDirectory(context.Background(), "go-boiler-lib") })
+
+	if err = dlc.RenameDirectory(ctx, "go-boiler-lib/lines", "go-boiler-lib/archive"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := dlc.Exists(ctx, "go-boiler-lib/archive/2025"); err != nil || !ok {
+		t.Fatalf("expected the renamed directory to exist: %v", err)
+	}
+	items, err := dlc.ListPaths(ctx, "go-boiler-lib", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || !items[0].Directory {
+		t.Fatalf("unexpected paths %+v", items)
+	}
+	res, err := dlc.UpdateACLRecursive(ctx, "go-boiler-lib", "other::r-x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Directories != 3 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
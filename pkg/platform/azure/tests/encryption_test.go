@@ -0,0 +1,153 @@
+package azure_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure"
+)
+
+var (
+	testKey1 = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	testKey2 = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 16))
+)
+
+func newKeyProvider(t *testing.T, current string, keys map[string]string) *azure.StaticKeyProvider {
+	t.Helper()
+	kp, err := azure.NewStaticKeyProvider(azure.EncryptionConfig{KeyID: current, Keys: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kp
+}
+
+func TestStaticKeyProvider(t *testing.T) {
+	if _, err := azure.NewStaticKeyProvider(azure.EncryptionConfig{KeyID: "k3", Keys: map[string]string{"k1": testKey1}}); !errors.Is(err, azure.ErrUnknownEncryptionKey) {
+		t.Fatalf("expected ErrUnknownEncryptionKey, got %v", err)
+	}
+	short := base64.StdEncoding.EncodeToString([]byte("short"))
+	if _, err := azure.NewStaticKeyProvider(azure.EncryptionConfig{KeyID: "k1", Keys: map[string]string{"k1": short}}); !errors.Is(err, azure.ErrBadEncryptionKey) {
+		t.Fatalf("expected ErrBadEncryptionKey, got %v", err)
+	}
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	bs, acc := newBlobServer(t, azure.WithKeyProvider(newKeyProvider(t, "k1", map[string]string{"k1": testKey1})))
+	ctx := context.Background()
+	plaintext := "wo,qty\n1,2\n"
+	if err := acc.UploadBuffer(ctx, "lines/line1.csv", *bytes.NewBufferString(plaintext)); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := bs.blob("lines/line1.csv")
+	if bytes.Contains(b.content, []byte(plaintext)) {
+		t.Fatal("the blob was stored in plaintext")
+	}
+	if b.meta["encryptionkeyid"] != "k1" || b.meta["encryptionalg"] != "AES-GCM" {
+		t.Fatalf("unexpected encryption metadata %v", b.meta)
+	}
+	buf := make([]byte, 64)
+	if err := acc.PullBuffer(ctx, "lines/line1.csv", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != plaintext {
+		t.Fatalf("unexpected content %q", buf)
+	}
+
+	// the blob is still readable once the current key is rotated
+	rotated := newKeyProvider(t, "k2", map[string]string{"k1": testKey1, "k2": testKey2})
+	if err := azure.WithKeyProvider(rotated)(acc); err != nil {
+		t.Fatal(err)
+	}
+	buf = make([]byte, 64)
+	if err := acc.PullBuffer(ctx, "lines/line1.csv", &buf); err != nil || string(buf) != plaintext {
+		t.Fatalf("unexpected content %q: %v", buf, err)
+	}
+}
+
+func TestEncryptionFailures(t *testing.T) {
+	// k2 is the same key as k1 under another id, so only the additional data differs
+	kp := newKeyProvider(t, "k1", map[string]string{"k1": testKey1, "k2": testKey1, "k3": testKey2})
+	ctx := context.Background()
+	upload := func(t *testing.T) (*blobServer, *azure.AzureContainerClient, *storedBlob) {
+		bs, acc := newBlobServer(t, azure.WithKeyProvider(kp))
+		if err := acc.UploadBuffer(ctx, "lines/line1.csv", *bytes.NewBufferString("wo,qty\n1,2\n")); err != nil {
+			t.Fatal(err)
+		}
+		b, _ := bs.blob("lines/line1.csv")
+		return bs, acc, b
+	}
+
+	for name, tc := range map[string]struct {
+		tamper func(b *storedBlob)
+		want   error
+	}{
+		"tampered ciphertext":   {func(b *storedBlob) { b.content[len(b.content)-1] ^= 0xff }, azure.ErrCiphertextCorrupted},
+		"truncated ciphertext":  {func(b *storedBlob) { b.content = b.content[:4] }, azure.ErrCiphertextCorrupted},
+		"wrong additional data": {func(b *storedBlob) { b.meta["encryptionkeyid"] = "k2" }, azure.ErrCiphertextCorrupted},
+		"wrong key":             {func(b *storedBlob) { b.meta["encryptionkeyid"] = "k3" }, azure.ErrCiphertextCorrupted},
+		"unknown key id":        {func(b *storedBlob) { b.meta["encryptionkeyid"] = "k9" }, azure.ErrUnknownEncryptionKey},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, acc, b := upload(t)
+			tc.tamper(b)
+			buf := make([]byte, 64)
+			if err := acc.PullBuffer(ctx, "lines/line1.csv", &buf); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestEncryptionReadsUnencryptedBlobs(t *testing.T) {
+	bs, acc := newBlobServer(t, azure.WithKeyProvider(newKeyProvider(t, "k1", map[string]string{"k1": testKey1})))
+	bs.blobs["lines/line1.csv"] = &storedBlob{kind: "BlockBlob", content: []byte("wo,qty\n1,2\n")}
+
+	buf := make([]byte, 64)
+	if err := acc.PullBuffer(context.Background(), "lines/line1.csv", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "wo,qty\n1,2\n" {
+		t.Fatalf("unexpected content %q", buf)
+	}
+}
+
+func TestPullBufferLength(t *testing.T) {
+	bs, plain := newBlobServer(t)
+	bs.blobs["lines/line1.csv"] = &storedBlob{kind: "BlockBlob", content: []byte("wo,qty\n1,2\n")}
+	_, verified := newBlobServer(t, azure.WithChecksum(azure.ChecksumCRC64))
+	if err := verified.UploadBuffer(context.Background(), "lines/line1.csv", *bytes.NewBufferString("wo,qty\n1,2\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// both download paths shorten the destination to the blob size
+	for name, acc := range map[string]*azure.AzureContainerClient{"plain": plain, "verified": verified} {
+		buf := make([]byte, 64)
+		if err := acc.PullBuffer(context.Background(), "lines/line1.csv", &buf); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if string(buf) != "wo,qty\n1,2\n" {
+			t.Fatalf("%s: unexpected content %q", name, buf)
+		}
+	}
+}
+
+func TestEncryptionUnsupportedWrites(t *testing.T) {
+	bs, acc := newBlobServer(t, azure.WithKeyProvider(newKeyProvider(t, "k1", map[string]string{"k1": testKey1})))
+	ctx := context.Background()
+	if _, err := acc.AppendLine(ctx, "logs/line1.log", []byte("started")); !errors.Is(err, azure.ErrEncryptionUnsupported) {
+		t.Fatalf("expected ErrEncryptionUnsupported, got %v", err)
+	}
+	if err := acc.WritePages(ctx, "disks/disk1.vhd", 0, make([]byte, 512)); !errors.Is(err, azure.ErrEncryptionUnsupported) {
+		t.Fatalf("expected ErrEncryptionUnsupported, got %v", err)
+	}
+	if _, err := acc.NewBlockBlobSink("dumps/dump.bin"); !errors.Is(err, azure.ErrStreamingEncrypted) {
+		t.Fatalf("expected ErrStreamingEncrypted, got %v", err)
+	}
+	if len(bs.blobs) != 0 {
+		t.Fatalf("expected no blobs, got %d", len(bs.blobs))
+	}
+}
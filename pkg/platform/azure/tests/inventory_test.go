@@ -0,0 +1,120 @@
+package azure_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure"
+)
+
+func TestInventoryFrame(t *testing.T) {
+	now := time.Date(2025, 10, 13, 10, 0, 0, 0, time.UTC)
+	df, err := azure.InventoryFrame([]azure.InventoryItem{
+		{Name: "lines/line1.csv", Size: 11, Modified: now.Add(-90 * time.Minute), Tags: map[string]string{"plant": "gradec", "line": "1"}},
+		{Name: "lines/line2.csv", Size: 0, Modified: now.Add(-1500 * time.Millisecond), Tags: map[string]string{}},
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header := df.Header(); !slices.Equal(header, []string{"name", "size", "modified", "age", "tags"}) {
+		t.Fatalf("unexpected header %v", header)
+	}
+	want := [][]string{
+		// ages are in whole seconds and the tags are sorted by key
+		{"lines/line1.csv", "11", "2025-10-13T08:30:00Z", "5400", "line=1;plant=gradec"},
+		{"lines/line2.csv", "0", "2025-10-13T09:59:58Z", "1", ""},
+	}
+	if len(df.Rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), len(df.Rows))
+	}
+	for i, row := range df.Rows {
+		if !slices.Equal([]string(row), want[i]) {
+			t.Fatalf("row %d: expected %v, got %v", i, want[i], row)
+		}
+	}
+}
+
+func TestManifestFromDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "lines"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "lines", "line1.2025.csv"), []byte("wo,qty\n1,2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "report.csv"), []byte("plant\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the keys are the blobs UploadFile stores: directories and inner extensions are dropped
+	m, err := azure.ManifestFromDir(root, "uploads", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (azure.Manifest{"uploads/line1.csv": 11, "uploads/report.csv": 6}); !reflect.DeepEqual(m, want) {
+		t.Fatalf("expected %v, got %v", want, m)
+	}
+	if m, err = azure.ManifestFromDir(root, "", false); err != nil {
+		t.Fatal(err)
+	}
+	if want := (azure.Manifest{"line1.csv": -1, "report.csv": -1}); !reflect.DeepEqual(m, want) {
+		t.Fatalf("expected %v, got %v", want, m)
+	}
+
+	// a manifest of uploaded files reconciles cleanly with the container
+	bs, acc := newBlobServer(t)
+	for _, name := range []string{filepath.Join("lines", "line1.2025.csv"), "report.csv"} {
+		f, err := os.Open(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err = acc.UploadFile(context.Background(), f, "uploads"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var items []azure.InventoryItem
+	for name, b := range bs.blobs {
+		items = append(items, azure.InventoryItem{Name: name, Size: int64(len(b.content))})
+	}
+	m, _ = azure.ManifestFromDir(root, "uploads", true)
+	if r := azure.Reconcile(items, m); !reflect.DeepEqual(r, azure.Reconciliation{}) {
+		t.Fatalf("expected a clean reconciliation, got %+v", r)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	items := []azure.InventoryItem{
+		{Name: "lines/line1.csv", Size: 11},
+		{Name: "lines/line2.csv", Size: 40},
+		{Name: "lines/line3.csv", Size: 7},
+		{Name: "tmp/upload.part", Size: 3},
+		{Name: "lines/line0.csv", Size: 1},
+	}
+	m := azure.Manifest{
+		"lines/line1.csv": 11,
+		"lines/line2.csv": 12,
+		// a negative size is not checked
+		"lines/line3.csv":  -1,
+		"lines/line9.csv":  5,
+		"lines/line10.csv": -1,
+	}
+
+	r := azure.Reconcile(items, m)
+	want := azure.Reconciliation{
+		Missing:      []string{"lines/line10.csv", "lines/line9.csv"},
+		Orphaned:     []string{"lines/line0.csv", "tmp/upload.part"},
+		SizeMismatch: []string{"lines/line2.csv"},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Fatalf("expected %+v, got %+v", want, r)
+	}
+	if r = azure.Reconcile(items[:1], azure.Manifest{"lines/line1.csv": 11}); !reflect.DeepEqual(r, azure.Reconciliation{}) {
+		t.Fatalf("expected a clean reconciliation, got %+v", r)
+	}
+}
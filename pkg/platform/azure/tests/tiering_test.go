@@ -0,0 +1,143 @@
+package azure_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/azure"
+)
+
+func TestPlanTiering(t *testing.T) {
+	now := time.Date(2025, 10, 13, 10, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+	rules := []azure.TierRule{
+		{Prefix: "raw/", OlderThanDays: 30, Tier: azure.TierCool},
+		{Prefix: "raw/", OlderThanDays: 180, Tier: azure.TierArchive},
+		{Prefix: "raw/lines/", OlderThanDays: 90, Tier: azure.TierCold},
+	}
+	cases := map[string]struct {
+		item azure.InventoryItem
+		want []azure.TierChange
+	}{
+		"too recent": {
+			item: azure.InventoryItem{Name: "raw/line1.csv", Tier: azure.TierHot, Modified: daysAgo(29)},
+		},
+		"other prefix": {
+			item: azure.InventoryItem{Name: "exports/line1.csv", Tier: azure.TierHot, Modified: daysAgo(400)},
+		},
+		"single rule": {
+			item: azure.InventoryItem{Name: "raw/line1.csv", Tier: azure.TierHot, Modified: daysAgo(30)},
+			want: []azure.TierChange{{Blob: "raw/line1.csv", From: azure.TierHot, To: azure.TierCool}},
+		},
+		"coldest matching rule wins": {
+			item: azure.InventoryItem{Name: "raw/lines/line1.csv", Tier: azure.TierHot, Modified: daysAgo(200)},
+			want: []azure.TierChange{{Blob: "raw/lines/line1.csv", From: azure.TierHot, To: azure.TierArchive}},
+		},
+		"coldest rule of the prefix wins": {
+			item: azure.InventoryItem{Name: "raw/lines/line1.csv", Tier: azure.TierCool, Modified: daysAgo(100)},
+			want: []azure.TierChange{{Blob: "raw/lines/line1.csv", From: azure.TierCool, To: azure.TierCold}},
+		},
+		"never warmer": {
+			item: azure.InventoryItem{Name: "raw/line1.csv", Tier: azure.TierCold, Modified: daysAgo(40)},
+		},
+		"already there": {
+			item: azure.InventoryItem{Name: "raw/line1.csv", Tier: azure.TierArchive, Modified: daysAgo(400)},
+		},
+		"without a tier": {
+			item: azure.InventoryItem{Name: "raw/disk1.vhd", Modified: daysAgo(400)},
+		},
+		"premium tier": {
+			item: azure.InventoryItem{Name: "raw/disk1.vhd", Tier: "P10", Modified: daysAgo(400)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := azure.PlanTiering([]azure.InventoryItem{tc.item}, rules, now); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestApplyTiering(t *testing.T) {
+	now := time.Now()
+	cases := map[string]struct {
+		rules  []azure.TierRule
+		prefix string
+	}{
+		"shared prefix": {
+			rules:  []azure.TierRule{{Prefix: "raw/lines/", Tier: azure.TierCool}, {Prefix: "raw/logs/", Tier: azure.TierCool}},
+			prefix: "raw/l",
+		},
+		"nested prefix": {
+			rules:  []azure.TierRule{{Prefix: "raw/lines/", Tier: azure.TierCool}, {Prefix: "raw/", Tier: azure.TierCool}},
+			prefix: "raw/",
+		},
+		"disjoint prefixes": {
+			rules:  []azure.TierRule{{Prefix: "raw/lines/", Tier: azure.TierCool}, {Prefix: "exports/", Tier: azure.TierCool}},
+			prefix: "",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			bs, acc := newBlobServer(t)
+			bs.blobs["raw/lines/line1.csv"] = &storedBlob{kind: "BlockBlob", tier: "Hot", modified: now}
+			res, err := acc.ApplyTiering(context.Background(), azure.TieringPolicy{Rules: tc.rules, DryRun: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// a single listing covers the prefixes of every rule
+			if !slices.Equal(bs.prefixes, []string{tc.prefix}) {
+				t.Fatalf("expected one listing of %q, got %q", tc.prefix, bs.prefixes)
+			}
+			if len(res.Changes) != 1 || bs.blobs["raw/lines/line1.csv"].tier != "Hot" {
+				t.Fatalf("expected a single planned change and no move, got %+v", res)
+			}
+		})
+	}
+
+	bs, acc := newBlobServer(t)
+	bs.blobs["raw/line1.csv"] = &storedBlob{kind: "BlockBlob", tier: "Hot", modified: now.Add(-48 * time.Hour)}
+	bs.blobs["raw/line2.csv"] = &storedBlob{kind: "BlockBlob", tier: "Hot", modified: now}
+	policy := azure.TieringPolicy{Rules: []azure.TierRule{{Prefix: "raw/", OlderThanDays: 1, Tier: azure.TierCool}}}
+	res, err := acc.ApplyTiering(context.Background(), policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []azure.TierChange{{Blob: "raw/line1.csv", From: azure.TierHot, To: azure.TierCool}}; !reflect.DeepEqual(res.Changes, want) {
+		t.Fatalf("expected %v, got %v", want, res.Changes)
+	}
+	if bs.blobs["raw/line1.csv"].tier != "Cool" || bs.blobs["raw/line2.csv"].tier != "Hot" {
+		t.Fatal("unexpected tiers after the run")
+	}
+	if _, err = acc.ApplyTiering(context.Background(), azure.TieringPolicy{Rules: []azure.TierRule{{Tier: "Frozen"}}}); !errors.Is(err, azure.ErrBadTier) {
+		t.Fatalf("expected ErrBadTier, got %v", err)
+	}
+}
+
+func TestRunTieringDefaultInterval(t *testing.T) {
+	_, acc := newBlobServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// a zero interval falls back to the default instead of panicking
+		acc.RunTiering(ctx, azure.TieringPolicy{}, 0, func(res azure.TieringResult, err error) {
+			runs++
+			cancel()
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunTiering did not stop with the context")
+	}
+	if runs != 1 {
+		t.Fatalf("expected a single run, got %d", runs)
+	}
+}
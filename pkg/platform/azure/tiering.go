@@ -0,0 +1,192 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+)
+
+// defaultTieringInterval is the interval of RunTiering when none is given; the rules count ages in days
+const defaultTieringInterval = 24 * time.Hour
+
+var (
+	ErrBadTier        = errors.New("unknown access tier")
+	ErrTierNotApplied = errors.New("the access tier could not be set on every blob")
+)
+
+// AccessTier is the storage tier of a blob; the colder the tier the cheaper the storage and the dearer the reads
+type AccessTier string
+
+const (
+	TierHot     AccessTier = "Hot"
+	TierCool    AccessTier = "Cool"
+	TierCold    AccessTier = "Cold"
+	TierArchive AccessTier = "Archive"
+)
+
+// tierOrder lists the tiers from the warmest to the coldest
+var tierOrder = []AccessTier{TierHot, TierCool, TierCold, TierArchive}
+
+// ParseAccessTier accepts the tier names case insensitively
+func ParseAccessTier(s string) (AccessTier, error) {
+	for _, t := range tierOrder {
+		if strings.EqualFold(s, string(t)) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("%w; tier:%s", ErrBadTier, s)
+}
+
+func (t AccessTier) rank() int {
+	return slices.Index(tierOrder, t)
+}
+
+// SetBlobTier moves the blob to the tier; moving a blob out of Archive starts a rehydration that takes hours
+func (acc *AzureContainerClient) SetBlobTier(ctx context.Context, blobName string, tier AccessTier) error {
+	if tier.rank() < 0 {
+		return fmt.Errorf("%w; tier:%s", ErrBadTier, tier)
+	}
+	bc := acc.containerClient().NewBlobClient(blobName)
+	if _, err := bc.SetTier(ctx, blob.AccessTier(tier), nil); err != nil {
+		return fmt.Errorf("%w; blob:%s;tier:%s", err, blobName, tier)
+	}
+	return nil
+}
+
+// TierRule moves the blobs under Prefix that were last modified more than OlderThanDays days ago to Tier
+type TierRule struct {
+	Prefix        string     `yaml:"prefix" json:"prefix"`
+	OlderThanDays int        `yaml:"older_than_days" json:"older_than_days"`
+	Tier          AccessTier `yaml:"tier" json:"tier"`
+}
+
+// TieringPolicy is the set of tier rules of a container, e.g. raw/ to Cool after 30 days and to Archive after 180;
+// of the rules a blob qualifies for the coldest tier wins and blobs are never moved to a warmer tier
+type TieringPolicy struct {
+	Rules []TierRule `yaml:"rules" json:"rules"`
+	// DryRun plans the moves without applying them
+	DryRun bool `yaml:"dry_run" json:"dry_run"`
+}
+
+// Validate checks the tiers and ages of the rules
+func (p TieringPolicy) Validate() error {
+	for i, r := range p.Rules {
+		if r.Tier.rank() < 0 {
+			return fmt.Errorf("%w; rule:%d;tier:%s", ErrBadTier, i, r.Tier)
+		}
+		if r.OlderThanDays < 0 {
+			return fmt.Errorf("the age of a tier rule must not be negative; rule:%d;days:%d", i, r.OlderThanDays)
+		}
+	}
+	return nil
+}
+
+// TierChange is a planned or applied move of a blob
+type TierChange struct {
+	Blob string
+	From AccessTier
+	To   AccessTier
+}
+
+// TieringResult reports a run of a tiering policy
+type TieringResult struct {
+	Changes []TierChange
+	// Failed maps the blobs that could not be moved to the error
+	Failed map[string]error
+}
+
+// PlanTiering returns the moves the rules call for at now; blobs without a standard tier, e.g. premium page blobs, are left alone
+func PlanTiering(items []InventoryItem, rules []TierRule, now time.Time) []TierChange {
+	var changes []TierChange
+	for _, item := range items {
+		current := item.Tier
+		if current.rank() < 0 {
+			continue
+		}
+		target := current
+		for _, r := range rules {
+			if !strings.HasPrefix(item.Name, r.Prefix) || now.Sub(item.Modified) < time.Duration(r.OlderThanDays)*24*time.Hour {
+				continue
+			}
+			if r.Tier.rank() > target.rank() {
+				target = r.Tier
+			}
+		}
+		if target != current {
+			changes = append(changes, TierChange{Blob: item.Name, From: current, To: target})
+		}
+	}
+	return changes
+}
+
+// ApplyTiering lists the blobs under the prefixes of the policy and moves those the rules call for;
+// the result lists every move and ErrTierNotApplied is returned when some blobs could not be moved
+func (acc *AzureContainerClient) ApplyTiering(ctx context.Context, policy TieringPolicy) (TieringResult, error) {
+	result := TieringResult{Failed: make(map[string]error)}
+	if err := policy.Validate(); err != nil || len(policy.Rules) == 0 {
+		return result, err
+	}
+	items, err := acc.InventoryItems(ctx, commonPrefix(policy.Rules))
+	if err != nil {
+		return result, err
+	}
+	result.Changes = PlanTiering(items, policy.Rules, time.Now())
+	if policy.DryRun {
+		return result, nil
+	}
+	for _, ch := range result.Changes {
+		if err := acc.SetBlobTier(ctx, ch.Blob, ch.To); err != nil {
+			result.Failed[ch.Blob] = err
+		}
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+	}
+	if len(result.Failed) > 0 {
+		return result, fmt.Errorf("%w; failed:%d;planned:%d", ErrTierNotApplied, len(result.Failed), len(result.Changes))
+	}
+	return result, nil
+}
+
+// RunTiering applies the policy every interval until ctx is done, starting right away; report receives every run.
+// An interval of zero or less runs the policy daily
+func (acc *AzureContainerClient) RunTiering(ctx context.Context, policy TieringPolicy, interval time.Duration, report func(TieringResult, error)) {
+	if interval <= 0 {
+		interval = defaultTieringInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		res, err := acc.ApplyTiering(ctx, policy)
+		if ctx.Err() != nil {
+			return
+		}
+		if report != nil {
+			report(res, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// commonPrefix returns the longest prefix shared by the prefixes of the rules, so a single listing covers them
+func commonPrefix(rules []TierRule) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	prefix := rules[0].Prefix
+	for _, r := range rules[1:] {
+		for !strings.HasPrefix(r.Prefix, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
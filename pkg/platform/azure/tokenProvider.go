@@ -0,0 +1,22 @@
+package azure
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+)
+
+// TokenProvider adapts an Azure AD credential (e.g. one of azidentity) to the bearer auth of a netcom client,
+// caching its tokens until refreshBefore their expiry; zero uses netcom.DefaultTokenRefreshBefore
+func TokenProvider(cred azcore.TokenCredential, refreshBefore time.Duration, scopes ...string) *netcom.CachingTokenProvider {
+	return netcom.NewCachingTokenProvider(func(ctx context.Context) (string, time.Time, error) {
+		tok, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: scopes})
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		return tok.Token, tok.ExpiresOn, nil
+	}, refreshBefore)
+}
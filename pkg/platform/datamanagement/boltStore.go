@@ -0,0 +1,263 @@
+package datamanagement
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Store is the API shared by SimpleStore and the persistent store backends
+type Store[K comparable, T any] interface {
+	Add(k K, i T) error
+	Get(k K) (T, error)
+	Update(k K, i T) error
+	Delete(k K) error
+}
+
+var (
+	_ Store[string, any] = SimpleStore[string, any]{}
+	_ Store[string, any] = (*BoltStore[string, any])(nil)
+)
+
+type BoltOpt func(*boltConfig) error
+
+type boltConfig struct {
+	gob     bool
+	timeout time.Duration
+}
+
+// WithGobEncoding stores values gob encoded instead of JSON encoded
+func WithGobEncoding() BoltOpt {
+	return func(bc *boltConfig) error {
+		bc.gob = true
+		return nil
+	}
+}
+
+// WithOpenTimeout bounds the wait for the file lock held by another process; defaults to 1 second
+func WithOpenTimeout(d time.Duration) BoltOpt {
+	return func(bc *boltConfig) error {
+		bc.timeout = d
+		return nil
+	}
+}
+
+// BoltStore is a durable Store backed by a bbolt database file; keys are ordered, so integer keys scan in numeric order
+type BoltStore[K comparable, T any] struct {
+	db     *bolt.DB
+	bucket []byte
+	gob    bool
+}
+
+// NewBoltStore opens (or creates) the database file at path and the bucket inside it
+func NewBoltStore[K comparable, T any](path string, bucket string, opts ...BoltOpt) (*BoltStore[K, T], error) {
+	bc := &boltConfig{timeout: time.Second}
+	for _, opt := range opts {
+		if err := opt(bc); err != nil {
+			return nil, err
+		}
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: bc.timeout})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStore[K, T]{db: db, bucket: []byte(bucket), gob: bc.gob}, nil
+}
+
+// Close releases the database file
+func (bs *BoltStore[K, T]) Close() error {
+	return bs.db.Close()
+}
+
+func (bs *BoltStore[K, T]) Add(k K, i T) error {
+	return bs.Tx(func(tx *BoltTx[K, T]) error { return tx.Add(k, i) })
+}
+
+func (bs *BoltStore[K, T]) Get(k K) (T, error) {
+	var i T
+	err := bs.View(func(tx *BoltTx[K, T]) error {
+		var err error
+		i, err = tx.Get(k)
+		return err
+	})
+	return i, err
+}
+
+// Update replaces the t value at k; errors if no key not found
+func (bs *BoltStore[K, T]) Update(k K, i T) error {
+	return bs.Tx(func(tx *BoltTx[K, T]) error { return tx.Update(k, i) })
+}
+
+// Put sets the value at k whether or not it exists
+func (bs *BoltStore[K, T]) Put(k K, i T) error {
+	return bs.Tx(func(tx *BoltTx[K, T]) error { return tx.Put(k, i) })
+}
+
+// Delete deletes the entry at k, including the key; returns error if key not found
+func (bs *BoltStore[K, T]) Delete(k K) error {
+	return bs.Tx(func(tx *BoltTx[K, T]) error { return tx.Delete(k) })
+}
+
+// ScanPrefix calls fn for every entry whose encoded key starts with prefix, in key order; returning an error from fn stops the scan
+func (bs *BoltStore[K, T]) ScanPrefix(prefix string, fn func(k K, i T) error) error {
+	return bs.View(func(tx *BoltTx[K, T]) error { return tx.ScanPrefix(prefix, fn) })
+}
+
+// Tx runs fn in a read-write transaction; all changes are rolled back if fn returns an error
+func (bs *BoltStore[K, T]) Tx(fn func(tx *BoltTx[K, T]) error) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return fn(&BoltTx[K, T]{b: tx.Bucket(bs.bucket), gob: bs.gob})
+	})
+}
+
+// View runs fn in a read-only transaction
+func (bs *BoltStore[K, T]) View(fn func(tx *BoltTx[K, T]) error) error {
+	return bs.db.View(func(tx *bolt.Tx) error {
+		return fn(&BoltTx[K, T]{b: tx.Bucket(bs.bucket), gob: bs.gob})
+	})
+}
+
+// BoltTx exposes the Store API inside a transaction
+type BoltTx[K comparable, T any] struct {
+	b   *bolt.Bucket
+	gob bool
+}
+
+func (tx *BoltTx[K, T]) Add(k K, i T) error {
+	key, err := encodeKey(k)
+	if err != nil {
+		return err
+	}
+	if tx.b.Get(key) != nil {
+		return fmt.Errorf("%w; key:%v", ErrNoOverwrite, k)
+	}
+	return tx.put(key, i)
+}
+
+func (tx *BoltTx[K, T]) Get(k K) (T, error) {
+	var i T
+	key, err := encodeKey(k)
+	if err != nil {
+		return i, err
+	}
+	raw := tx.b.Get(key)
+	if raw == nil {
+		return i, fmt.Errorf("%w; key:%v", ErrNoOrderFound, k)
+	}
+	return i, tx.decode(raw, &i)
+}
+
+func (tx *BoltTx[K, T]) Update(k K, i T) error {
+	key, err := encodeKey(k)
+	if err != nil {
+		return err
+	}
+	if tx.b.Get(key) == nil {
+		return fmt.Errorf("%w; key:%v", ErrNoOrderFound, k)
+	}
+	return tx.put(key, i)
+}
+
+func (tx *BoltTx[K, T]) Put(k K, i T) error {
+	key, err := encodeKey(k)
+	if err != nil {
+		return err
+	}
+	return tx.put(key, i)
+}
+
+func (tx *BoltTx[K, T]) Delete(k K) error {
+	key, err := encodeKey(k)
+	if err != nil {
+		return err
+	}
+	if tx.b.Get(key) == nil {
+		return fmt.Errorf("%w; key:%v", ErrNoOrderFound, k)
+	}
+	return tx.b.Delete(key)
+}
+
+func (tx *BoltTx[K, T]) ScanPrefix(prefix string, fn func(k K, i T) error) error {
+	c := tx.b.Cursor()
+	p := []byte(prefix)
+	for key, raw := c.Seek(p); key != nil && bytes.HasPrefix(key, p); key, raw = c.Next() {
+		k, err := decodeKey[K](key)
+		if err != nil {
+			return err
+		}
+		var i T
+		if err = tx.decode(raw, &i); err != nil {
+			return err
+		}
+		if err = fn(k, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *BoltTx[K, T]) put(key []byte, i T) error {
+	var buf bytes.Buffer
+	var err error
+	if tx.gob {
+		err = gob.NewEncoder(&buf).Encode(i)
+	} else {
+		err = json.NewEncoder(&buf).Encode(i)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.b.Put(key, buf.Bytes())
+}
+
+func (tx *BoltTx[K, T]) decode(raw []byte, i *T) error {
+	if tx.gob {
+		return gob.NewDecoder(bytes.NewReader(raw)).Decode(i)
+	}
+	return json.Unmarshal(raw, i)
+}
+
+// encodeKey turns a key into its ordered byte form: strings as-is, integers big-endian with the sign bit flipped, anything else as JSON
+func encodeKey[K comparable](k K) ([]byte, error) {
+	rv := reflect.ValueOf(k)
+	switch rv.Kind() {
+	case reflect.String:
+		return []byte(rv.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.BigEndian.AppendUint64(nil, uint64(rv.Int())^(1<<63)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return binary.BigEndian.AppendUint64(nil, rv.Uint()), nil
+	default:
+		return json.Marshal(k)
+	}
+}
+
+func decodeKey[K comparable](key []byte) (K, error) {
+	var k K
+	rv := reflect.ValueOf(&k).Elem()
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(string(key))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		rv.SetInt(int64(binary.BigEndian.Uint64(key) ^ (1 << 63)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		rv.SetUint(binary.BigEndian.Uint64(key))
+	default:
+		return k, json.Unmarshal(key, &k)
+	}
+	return k, nil
+}
@@ -0,0 +1,263 @@
+package datamanagement
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrCacheMiss = errors.New("item not cached or expired")
+
+// Cache is the API shared by the cache backends so one cache policy can be used wherever results are cached;
+// a ttl of zero never expires
+type Cache[K comparable, V any] interface {
+	Get(k K) (V, error)
+	Set(k K, v V, ttl time.Duration) error
+	Delete(k K) error
+	// TTL returns the time left before k expires; zero for items that never expire
+	TTL(k K) (time.Duration, error)
+}
+
+var (
+	_ Cache[string, any] = (*SimpleCache[string, any])(nil)
+	_ Cache[string, any] = (*LRUCache[string, any])(nil)
+	_ Cache[string, any] = (*BoltCache[string, any])(nil)
+)
+
+// CacheEntry is a cached value with its expiry; the zero Expires never expires
+type CacheEntry[V any] struct {
+	Value   V
+	Expires time.Time
+}
+
+func newCacheEntry[V any](v V, ttl time.Duration) CacheEntry[V] {
+	e := CacheEntry[V]{Value: v}
+	if ttl > 0 {
+		e.Expires = time.Now().Add(ttl)
+	}
+	return e
+}
+
+func (e CacheEntry[V]) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+func (e CacheEntry[V]) ttl(now time.Time) time.Duration {
+	if e.Expires.IsZero() {
+		return 0
+	}
+	return e.Expires.Sub(now)
+}
+
+// SimpleCache is a Cache over a SimpleStore; expired items are dropped when they are read
+type SimpleCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	store SimpleStore[K, CacheEntry[V]]
+}
+
+func NewSimpleCache[K comparable, V any]() *SimpleCache[K, V] {
+	return &SimpleCache[K, V]{store: NewSimpleStore[K, CacheEntry[V]]()}
+}
+
+func (c *SimpleCache[K, V]) Get(k K) (V, error) {
+	e, err := c.entry(k)
+	return e.Value, err
+}
+
+func (c *SimpleCache[K, V]) Set(k K, v V, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store[k] = newCacheEntry(v, ttl)
+	return nil
+}
+
+func (c *SimpleCache[K, V]) Delete(k K) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.store, k)
+	return nil
+}
+
+func (c *SimpleCache[K, V]) TTL(k K) (time.Duration, error) {
+	e, err := c.entry(k)
+	if err != nil {
+		return 0, err
+	}
+	return e.ttl(time.Now()), nil
+}
+
+func (c *SimpleCache[K, V]) entry(k K) (CacheEntry[V], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, err := c.store.Get(k)
+	if err != nil {
+		return CacheEntry[V]{}, fmt.Errorf("%w; key:%v", ErrCacheMiss, k)
+	}
+	if e.expired(time.Now()) {
+		delete(c.store, k)
+		return CacheEntry[V]{}, fmt.Errorf("%w; key:%v", ErrCacheMiss, k)
+	}
+	return e, nil
+}
+
+// LRUCache is a Cache holding at most a fixed number of items; the least recently used item is evicted first
+type LRUCache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[K]*list.Element
+}
+
+type lruItem[K comparable, V any] struct {
+	key   K
+	entry CacheEntry[V]
+}
+
+func NewLRUCache[K comparable, V any](capacity int) (*LRUCache[K, V], error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("invalid cache capacity; capacity:%v", capacity)
+	}
+	return &LRUCache[K, V]{capacity: capacity, order: list.New(), items: make(map[K]*list.Element)}, nil
+}
+
+func (c *LRUCache[K, V]) Get(k K) (V, error) {
+	e, err := c.entry(k)
+	return e.Value, err
+}
+
+func (c *LRUCache[K, V]) Set(k K, v V, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item := lruItem[K, V]{key: k, entry: newCacheEntry(v, ttl)}
+	if el, ok := c.items[k]; ok {
+		el.Value = item
+		c.order.MoveToFront(el)
+		return nil
+	}
+	c.items[k] = c.order.PushFront(item)
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(lruItem[K, V]).key)
+	}
+	return nil
+}
+
+func (c *LRUCache[K, V]) Delete(k K) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[k]; ok {
+		c.order.Remove(el)
+		delete(c.items, k)
+	}
+	return nil
+}
+
+func (c *LRUCache[K, V]) TTL(k K) (time.Duration, error) {
+	e, err := c.entry(k)
+	if err != nil {
+		return 0, err
+	}
+	return e.ttl(time.Now()), nil
+}
+
+// Len returns the number of cached items, including expired items that were not read since they expired
+func (c *LRUCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRUCache[K, V]) entry(k K) (CacheEntry[V], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[k]
+	if !ok {
+		return CacheEntry[V]{}, fmt.Errorf("%w; key:%v", ErrCacheMiss, k)
+	}
+	item := el.Value.(lruItem[K, V])
+	if item.entry.expired(time.Now()) {
+		c.order.Remove(el)
+		delete(c.items, k)
+		return CacheEntry[V]{}, fmt.Errorf("%w; key:%v", ErrCacheMiss, k)
+	}
+	c.order.MoveToFront(el)
+	return item.entry, nil
+}
+
+// BoltCache is a Cache persisted in a BoltStore, so cached items survive restarts
+type BoltCache[K comparable, V any] struct {
+	store *BoltStore[K, CacheEntry[V]]
+}
+
+// NewBoltCache opens (or creates) the cache bucket in the database file at path
+func NewBoltCache[K comparable, V any](path string, bucket string, opts ...BoltOpt) (*BoltCache[K, V], error) {
+	store, err := NewBoltStore[K, CacheEntry[V]](path, bucket, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &BoltCache[K, V]{store: store}, nil
+}
+
+// Close releases the database file
+func (c *BoltCache[K, V]) Close() error {
+	return c.store.Close()
+}
+
+func (c *BoltCache[K, V]) Get(k K) (V, error) {
+	e, err := c.entry(k)
+	return e.Value, err
+}
+
+func (c *BoltCache[K, V]) Set(k K, v V, ttl time.Duration) error {
+	return c.store.Put(k, newCacheEntry(v, ttl))
+}
+
+func (c *BoltCache[K, V]) Delete(k K) error {
+	err := c.store.Delete(k)
+	if errors.Is(err, ErrNoOrderFound) {
+		return nil
+	}
+	return err
+}
+
+func (c *BoltCache[K, V]) TTL(k K) (time.Duration, error) {
+	e, err := c.entry(k)
+	if err != nil {
+		return 0, err
+	}
+	return e.ttl(time.Now()), nil
+}
+
+// Purge deletes the expired items
+func (c *BoltCache[K, V]) Purge() error {
+	now := time.Now()
+	return c.store.Tx(func(tx *BoltTx[K, CacheEntry[V]]) error {
+		var expired []K
+		err := tx.ScanPrefix("", func(k K, e CacheEntry[V]) error {
+			if e.expired(now) {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err = tx.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *BoltCache[K, V]) entry(k K) (CacheEntry[V], error) {
+	e, err := c.store.Get(k)
+	if errors.Is(err, ErrNoOrderFound) || (err == nil && e.expired(time.Now())) {
+		return CacheEntry[V]{}, fmt.Errorf("%w; key:%v", ErrCacheMiss, k)
+	}
+	return e, err
+}
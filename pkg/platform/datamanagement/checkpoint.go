@@ -0,0 +1,121 @@
+package datamanagement
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrCheckpointRegression = errors.New("the new checkpoint is older than the recorded one")
+
+// Mark is the position a source was processed up to
+type Mark struct {
+	// ID is the last processed identifier (row id, file name, offset...)
+	ID string `json:"id"`
+	// Time is the timestamp of the last processed item
+	Time time.Time `json:"time"`
+	// Updated is when the mark was recorded
+	Updated time.Time `json:"updated"`
+}
+
+// Checkpoint tracks the high-water mark of every ingestion source on top of a Store backend
+type Checkpoint struct {
+	store Store[string, Mark]
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewCheckpoint creates a checkpoint tracker; pass a BoltStore to keep marks across restarts
+func NewCheckpoint(store Store[string, Mark]) *Checkpoint {
+	return &Checkpoint{store: store, locks: make(map[string]*sync.Mutex)}
+}
+
+func (c *Checkpoint) lock(source string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.locks[source]
+	if !ok {
+		l = new(sync.Mutex)
+		c.locks[source] = l
+	}
+	return l
+}
+
+// Last returns the recorded mark of the source; ok is false when the source was never processed
+func (c *Checkpoint) Last(source string) (m Mark, ok bool, err error) {
+	m, err = c.store.Get(source)
+	if errors.Is(err, ErrNoOrderFound) {
+		return Mark{}, false, nil
+	}
+	if err != nil {
+		return Mark{}, false, err
+	}
+	return m, true, nil
+}
+
+// Advance records m as the new mark of the source; marks older than the current one are rejected
+func (c *Checkpoint) Advance(source string, m Mark) error {
+	l := c.lock(source)
+	l.Lock()
+	defer l.Unlock()
+	last, ok, err := c.Last(source)
+	if err != nil {
+		return err
+	}
+	return c.advance(source, last, ok, m)
+}
+
+// Process calls fn with the last mark of the source and records the mark it returns;
+// if fn fails nothing is recorded, so the next run starts again from the same position.
+// Calls for the same source are serialized
+func (c *Checkpoint) Process(source string, fn func(last Mark) (Mark, error)) error {
+	l := c.lock(source)
+	l.Lock()
+	defer l.Unlock()
+	last, ok, err := c.Last(source)
+	if err != nil {
+		return err
+	}
+	next, err := fn(last)
+	if err != nil {
+		return err
+	}
+	return c.advance(source, last, ok, next)
+}
+
+// Rewind sets the mark of the source back to m regardless of the recorded one, for reprocessing
+func (c *Checkpoint) Rewind(source string, m Mark) error {
+	l := c.lock(source)
+	l.Lock()
+	defer l.Unlock()
+	m.Updated = time.Now()
+	return c.put(source, m)
+}
+
+// Reset forgets the source so it is processed from the beginning
+func (c *Checkpoint) Reset(source string) error {
+	l := c.lock(source)
+	l.Lock()
+	defer l.Unlock()
+	if err := c.store.Delete(source); err != nil && !errors.Is(err, ErrNoOrderFound) {
+		return err
+	}
+	return nil
+}
+
+func (c *Checkpoint) advance(source string, last Mark, exists bool, next Mark) error {
+	if exists && !next.Time.IsZero() && next.Time.Before(last.Time) {
+		return fmt.Errorf("%w; source:%s;recorded:%s;new:%s", ErrCheckpointRegression, source, last.Time, next.Time)
+	}
+	next.Updated = time.Now()
+	return c.put(source, next)
+}
+
+func (c *Checkpoint) put(source string, m Mark) error {
+	err := c.store.Update(source, m)
+	if errors.Is(err, ErrNoOrderFound) {
+		return c.store.Add(source, m)
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package datamanagement
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ColumnStats summarises the cells of a column
+type ColumnStats struct {
+	Column string
+	// Count is the number of rows, Empty the number of empty (or blank) cells among them
+	Count int
+	Empty int
+	// Distinct counts the different non-empty values, compared as written
+	Distinct int
+	// Numeric reports whether every non-empty cell is a plain number (see NormalizeNumbers); Min and Max are then
+	// compared as numbers and held in MinNumber and MaxNumber too, otherwise they are compared as text
+	Numeric   bool
+	Min       string
+	Max       string
+	MinNumber float64
+	MaxNumber float64
+}
+
+// ColumnStats returns the statistics of the column, matched like Get matches columns. They are computed once and
+// cached until the dataframe is changed by Drop, SetRecord, Append, NormalizeNumbers or Mask; after editing Rows
+// directly call InvalidateStats. ColumnStats is not safe for concurrent use
+func (d *Dataframe) ColumnStats(name string) (ColumnStats, error) {
+	idx, ok := d.columnIdx(name)
+	if !ok {
+		return ColumnStats{}, &ColumnsNotFoundErr{Available: d.Header(), Required: []string{name}}
+	}
+	if s, ok := d.stats[idx]; ok {
+		return s, nil
+	}
+	s := d.columnStats(idx)
+	if d.stats == nil {
+		d.stats = make(map[int]ColumnStats)
+	}
+	d.stats[idx] = s
+	return s, nil
+}
+
+// InvalidateStats drops the cached column statistics
+func (d *Dataframe) InvalidateStats() {
+	d.stats = nil
+}
+
+func (d *Dataframe) columnStats(idx int) ColumnStats {
+	s := ColumnStats{Count: len(d.Rows), Numeric: true}
+	for _, c := range d.Columns {
+		if c.idx == idx {
+			s.Column = c.name
+		}
+	}
+	distinct := make(map[string]struct{})
+	var values []string
+	for _, r := range d.Rows {
+		if idx >= len(r) || len(strings.TrimSpace(r[idx])) == 0 {
+			s.Empty++
+			continue
+		}
+		v := r[idx]
+		if _, seen := distinct[v]; !seen {
+			distinct[v] = struct{}{}
+			values = append(values, v)
+		}
+	}
+	s.Distinct = len(distinct)
+	if len(values) == 0 {
+		s.Numeric = false
+		return s
+	}
+
+	numbers := make([]float64, len(values))
+	for i, v := range values {
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			s.Numeric = false
+			break
+		}
+		numbers[i] = f
+	}
+	s.Min, s.Max = values[0], values[0]
+	if s.Numeric {
+		s.MinNumber, s.MaxNumber = numbers[0], numbers[0]
+		for i, f := range numbers {
+			if f < s.MinNumber {
+				s.MinNumber, s.Min = f, values[i]
+			}
+			if f > s.MaxNumber {
+				s.MaxNumber, s.Max = f, values[i]
+			}
+		}
+		return s
+	}
+	for _, v := range values {
+		s.Min = min(s.Min, v)
+		s.Max = max(s.Max, v)
+	}
+	return s
+}
@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"reflect"
 	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/fsops"
 	"github.com/pbnjay/grate"
 	_ "github.com/pbnjay/grate/simple"
 	_ "github.com/pbnjay/grate/xls"
@@ -30,6 +34,8 @@ type Dataframe struct {
 	Rows        []Record
 	CleanerFunc func(Record) Record
 	cleaned     bool
+	// stats caches ColumnStats by column index
+	stats map[int]ColumnStats
 }
 
 // DfRowsAsStructList the dataframe as a []sType representation; sType must have 'df' tags
@@ -120,14 +126,17 @@ func withRecordsFromData(b []byte, newLine string, valueSep string) DataframeOpt
 	}
 }
 
-func recordsFromFiles(filePaths []string) DataframeOpt {
+// recordsFromFiles loads the rows of the files; a nil repair keeps malformed rows as they are and fails on a header mismatch
+func recordsFromFiles(filePaths []string, repair *loadRepair) DataframeOpt {
 	return func(d *Dataframe) error {
 		var head []string
+	files:
 		for idx, fp := range filePaths {
-			source, err := grate.Open(fp)
+			source, err := openSource(fp)
 			if err != nil {
 				return err
 			}
+			defer source.Close()
 			sheets, err := source.List()
 			if err != nil {
 				return err
@@ -155,6 +164,9 @@ func recordsFromFiles(filePaths []string) DataframeOpt {
 						if strings.Contains(r[0], ",") {
 							if record = d.CleanerFunc(strings.Split(r[0], ",")); len(record) > 0 {
 								if slices.Compare(head, record) != 0 {
+									if repair.skipFile(fp, head, record) {
+										continue files
+									}
 									return &HeaderMismatchErr{
 										Original: head,
 										Mismatch: record,
@@ -164,6 +176,9 @@ func recordsFromFiles(filePaths []string) DataframeOpt {
 						} else { // this part is for excel files
 							if record = d.CleanerFunc(r); len(record) > 0 {
 								if slices.Compare(head, record) != 0 {
+									if repair.skipFile(fp, head, record) {
+										continue files
+									}
 									return &HeaderMismatchErr{
 										Original: head,
 										Mismatch: record,
@@ -175,6 +190,7 @@ func recordsFromFiles(filePaths []string) DataframeOpt {
 					break
 				}
 			}
+			start := len(d.Rows)
 			for data.Next() {
 				r := data.Strings()
 				var cr Record
@@ -194,11 +210,61 @@ func recordsFromFiles(filePaths []string) DataframeOpt {
 					head = d.CleanerFunc(cr)
 				}
 			}
+			d.Rows = repair.repairRows(fp, d.Rows, start)
 		}
 		return nil
 	}
 }
 
+// tempSource removes the decompressed copy of a compressed file once the source is closed
+type tempSource struct {
+	grate.Source
+	dir string
+}
+
+func (ts tempSource) Close() error {
+	err := ts.Source.Close()
+	os.RemoveAll(ts.dir)
+	return err
+}
+
+// openSource opens a data file with grate; compressed files, e.g. orders.csv.gz, are decompressed to a temporary copy first
+func openSource(path string) (grate.Source, error) {
+	rc, compression, err := fsops.OpenMaybeCompressed(path)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	if compression == fsops.CompressionNone {
+		return grate.Open(path)
+	}
+	dir, err := os.MkdirTemp("", "dataframe-")
+	if err != nil {
+		return nil, err
+	}
+	source, err := decompressTo(filepath.Join(dir, fsops.UncompressedName(filepath.Base(path))), rc)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("%w; file:%s", err, path)
+	}
+	return tempSource{Source: source, dir: dir}, nil
+}
+
+func decompressTo(path string, r io.Reader) (grate.Source, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return grate.Open(path)
+}
+
 // func cleanRecord(r []string) Record {
 // 	newR := make(Record, 0)
 // 	for idx := range r {
@@ -218,10 +284,11 @@ func WithProvidedColumns(h []string) DataframeOpt {
 
 		for idx, str := range h {
 			d.Columns = append(d.Columns, Column{
-				name: strings.ToLower(strings.ReplaceAll(str, " ", "")),
+				name: columnName(str),
 				idx:  idx,
 			})
 		}
+		uniqueColumnNames(d.Columns)
 		return nil
 	}
 }
@@ -231,10 +298,11 @@ func WithInterpretedColumns() DataframeOpt {
 	return func(d *Dataframe) error {
 		for idx, str := range d.Rows[0] {
 			d.Columns = append(d.Columns, Column{
-				name: strings.ToLower(strings.ReplaceAll(str, " ", "")),
+				name: columnName(str),
 				idx:  idx,
 			})
 		}
+		uniqueColumnNames(d.Columns)
 		d.Rows = d.Rows[1:]
 		return nil
 	}
@@ -242,6 +310,7 @@ func WithInterpretedColumns() DataframeOpt {
 
 // Drop a range of rows from the dataframe
 func (d *Dataframe) Drop(i ...int) {
+	d.InvalidateStats()
 	slices.Sort(i)
 	d.Rows = slices.Delete(d.Rows, i[0], i[len(i)-1])
 	newRows := make([]Record, 0)
@@ -298,6 +367,7 @@ func (d *Dataframe) SetRecord(row int, record Record) error {
 		return fmt.Errorf("%w:record length:%d does not match dataframe header length:%d", ErrBadRow, len(record), len(d.Header()))
 	}
 	d.Rows[row] = record
+	d.InvalidateStats()
 	return nil
 }
 
@@ -322,6 +392,7 @@ func (d *Dataframe) Append(candidate *Dataframe) (*Dataframe, error) {
 		}
 		return d, fmt.Errorf("%w: mismatch at idx:%d", ErrIncompatibleDataframes, v)
 	}
+	d.InvalidateStats()
 	for _, rec := range candidate.Rows {
 		if cleanRec := d.CleanerFunc(rec); len(cleanRec) != 0 {
 			d.Rows = append(d.Rows, cleanRec)
@@ -331,6 +402,10 @@ func (d *Dataframe) Append(candidate *Dataframe) (*Dataframe, error) {
 }
 
 func NewDataframeFromFiles(filesPaths []string, cleaner func(Record) Record, opts ...DataframeOpt) (*Dataframe, error) {
+	return newDataframeFromFiles(filesPaths, cleaner, nil, opts)
+}
+
+func newDataframeFromFiles(filesPaths []string, cleaner func(Record) Record, repair *loadRepair, opts []DataframeOpt) (*Dataframe, error) {
 	df := new(Dataframe)
 	// INFO: A hacky solution to avoid a nil cleanerfunc
 	df.CleanerFunc = func(r Record) Record {
@@ -344,7 +419,7 @@ func NewDataframeFromFiles(filesPaths []string, cleaner func(Record) Record, opt
 	if cleaner != nil {
 		df.CleanerFunc = cleaner
 	}
-	opts = append(opts, recordsFromFiles(filesPaths))
+	opts = append(slices.Clone(opts), recordsFromFiles(filesPaths, repair))
 	slices.Reverse(opts)
 
 	for _, opt := range opts {
@@ -388,3 +463,32 @@ func NewDataframeFromData(b ByteDefinition, cleaner func(Record) Record, opts ..
 
 	return df, nil
 }
+
+func withRecords(records []Record) DataframeOpt {
+	return func(d *Dataframe) error {
+		for _, r := range records {
+			if d.CleanerFunc != nil {
+				r = d.CleanerFunc(r)
+			}
+			d.Rows = append(d.Rows, r)
+		}
+		return nil
+	}
+}
+
+// NewDataframeFromRecords builds a dataframe from already split records; like the other constructors the columns come from the provided opts
+func NewDataframeFromRecords(records []Record, cleaner func(Record) Record, opts ...DataframeOpt) (*Dataframe, error) {
+	df := new(Dataframe)
+	if cleaner != nil {
+		df.CleanerFunc = cleaner
+	}
+	opts = append(slices.Clone(opts), withRecords(records))
+	slices.Reverse(opts)
+
+	for _, opt := range opts {
+		if err := opt(df); err != nil {
+			return nil, err
+		}
+	}
+	return df, nil
+}
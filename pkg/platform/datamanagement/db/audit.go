@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+type actorKey struct{}
+
+// ContextWithActor returns a context carrying the actor (user, service or job name) recorded in the audit columns
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with ContextWithActor
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok && len(actor) != 0
+}
+
+// AuditColumns names the audit columns of a table; an empty name leaves that column out
+type AuditColumns struct {
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	Actor     string `json:"actor"`
+	// Now returns the recorded time; defaults to time.Now in UTC
+	Now func() time.Time `json:"-"`
+}
+
+// DefaultAuditColumns is the created_at/updated_at/updated_by convention
+var DefaultAuditColumns = AuditColumns{CreatedAt: "created_at", UpdatedAt: "updated_at", Actor: "updated_by"}
+
+// Insert appends the audit columns of an inserted row and their values to the columns and values of the statement;
+// columns the caller already provides are kept as they are and the actor is NULL when ctx carries none
+func (a AuditColumns) Insert(ctx context.Context, columns []string, values []any) ([]string, []any) {
+	now := a.now()
+	columns, values = appendAudit(columns, values, a.CreatedAt, now)
+	columns, values = appendAudit(columns, values, a.UpdatedAt, now)
+	return appendAudit(columns, values, a.Actor, actorValue(ctx))
+}
+
+// Update appends the audit columns of an updated row, i.e. without the creation time, to the SET columns and values of the statement
+func (a AuditColumns) Update(ctx context.Context, columns []string, values []any) ([]string, []any) {
+	columns, values = appendAudit(columns, values, a.UpdatedAt, a.now())
+	return appendAudit(columns, values, a.Actor, actorValue(ctx))
+}
+
+func (a AuditColumns) now() time.Time {
+	if a.Now != nil {
+		return a.Now()
+	}
+	return time.Now().UTC()
+}
+
+func appendAudit(columns []string, values []any, column string, value any) ([]string, []any) {
+	if len(column) == 0 || slices.ContainsFunc(columns, func(c string) bool { return strings.EqualFold(c, column) }) {
+		return columns, values
+	}
+	return append(columns, column), append(values, value)
+}
+
+func actorValue(ctx context.Context) any {
+	if actor, ok := ActorFromContext(ctx); ok {
+		return actor
+	}
+	return nil
+}
@@ -0,0 +1,406 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrUnsupportedParam = errors.New("the statement parameter cannot be stored in the offline buffer")
+	ErrReplayFailed     = errors.New("a buffered statement was rejected by the remote database and moved to the dead letter table")
+)
+
+const bufferSchema = `
+CREATE TABLE IF NOT EXISTS outbox (
+	seq     INTEGER PRIMARY KEY AUTOINCREMENT,
+	dedup   TEXT UNIQUE,
+	query   TEXT NOT NULL,
+	params  TEXT NOT NULL,
+	created INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS outbox_dead (
+	seq     INTEGER PRIMARY KEY,
+	dedup   TEXT,
+	query   TEXT NOT NULL,
+	params  TEXT NOT NULL,
+	created INTEGER NOT NULL,
+	reason  TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS delivered (
+	dedup     TEXT PRIMARY KEY,
+	delivered INTEGER NOT NULL
+);`
+
+type BufferOpt func(*Buffer) error
+
+// WithRetryInterval sets how often Run checks the remote database and replays the queue; defaults to 30 seconds
+func WithRetryInterval(d time.Duration) BufferOpt {
+	return func(b *Buffer) error {
+		if d <= 0 {
+			return fmt.Errorf("%w; retry interval:%s", ErrBadConfig, d)
+		}
+		b.interval = d
+		return nil
+	}
+}
+
+// WithUnreachableFunc replaces the check deciding whether a remote error means the database cannot be reached
+// (the statement is buffered) or that the statement itself failed (the error is returned)
+func WithUnreachableFunc(fn func(error) bool) BufferOpt {
+	return func(b *Buffer) error {
+		b.unreachable = fn
+		return nil
+	}
+}
+
+// WithDedupRetention sets how long the keys of delivered statements are remembered; defaults to 24 hours
+func WithDedupRetention(d time.Duration) BufferOpt {
+	return func(b *Buffer) error {
+		b.retention = d
+		return nil
+	}
+}
+
+// WithReplayErrorHandler is called when a buffered statement is rejected during replay
+func WithReplayErrorHandler(fn func(error)) BufferOpt {
+	return func(b *Buffer) error {
+		b.onReplayErr = fn
+		return nil
+	}
+}
+
+// Buffer forwards statements to a remote Database and keeps them in a local sqlite queue while the remote is unreachable;
+// queued statements are replayed in insertion order once connectivity returns
+type Buffer struct {
+	remote      *Database
+	local       *Database
+	mu          sync.Mutex
+	interval    time.Duration
+	retention   time.Duration
+	unreachable func(error) bool
+	onReplayErr func(error)
+}
+
+// NewBuffer opens (or creates) the sqlite queue at path in front of the remote database
+func NewBuffer(remote *Database, path string, opts ...BufferOpt) (*Buffer, error) {
+	b := &Buffer{
+		remote:      remote,
+		interval:    30 * time.Second,
+		retention:   24 * time.Hour,
+		unreachable: IsUnreachable,
+		onReplayErr: func(error) {},
+	}
+	for _, opt := range opts {
+		if err := opt(b); err != nil {
+			return nil, err
+		}
+	}
+	local, err := NewDatabase(DatabaseConfig{Driver: DriverSQLite, Name: "outbox", Address: path}, "outbox")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = local.Exec(bufferSchema); err != nil {
+		local.Close()
+		return nil, err
+	}
+	b.local = local
+	return b, nil
+}
+
+// Close releases the local queue; the remote database is left open
+func (b *Buffer) Close() error {
+	return b.local.Close()
+}
+
+// Insert executes the statement against the remote database, or queues it when the remote is unreachable or older statements are still queued.
+// A non-empty key deduplicates the statement: it is dropped if a statement with the same key is queued or was delivered within the retention period
+func (b *Buffer) Insert(key string, qc QueryConstructor, params ...any) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(key) != 0 {
+		seen, err := b.seen(key)
+		if err != nil || seen {
+			return err
+		}
+	}
+	pending, err := b.pending()
+	if err != nil {
+		return err
+	}
+	if pending == 0 && b.remote.open {
+		_, err = b.remote.ExecuteConstructor(qc, params...)
+		if err == nil {
+			return b.delivered(key)
+		}
+		if !b.unreachable(err) {
+			return err
+		}
+	}
+	return b.enqueue(key, qc.Construct(), params)
+}
+
+// Pending returns the number of queued statements
+func (b *Buffer) Pending() (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pending()
+}
+
+// Flush replays the queued statements in order; it stops at the first statement the remote cannot be reached for
+// and returns the number of statements delivered
+func (b *Buffer) Flush() (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.remote.open {
+		return 0, nil
+	}
+	if _, err := b.local.Exec("DELETE FROM delivered WHERE delivered < ?", time.Now().Add(-b.retention).UnixNano()); err != nil {
+		return 0, err
+	}
+	rows, err := b.local.Query("SELECT seq, dedup, query, params FROM outbox ORDER BY seq")
+	if err != nil {
+		return 0, err
+	}
+	var queued []bufferedStmt
+	for rows.Next() {
+		var s bufferedStmt
+		if err = rows.Scan(&s.seq, &s.dedup, &s.query, &s.params); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		queued = append(queued, s)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, s := range queued {
+		params, err := decodeParams(s.params)
+		if err == nil {
+			_, err = b.remote.Exec(s.query, params...)
+		}
+		if err != nil && b.unreachable(err) {
+			return sent, nil
+		}
+		if err != nil {
+			if derr := b.bury(s, err); derr != nil {
+				return sent, derr
+			}
+			b.onReplayErr(fmt.Errorf("%w; seq:%d;reason:%w", ErrReplayFailed, s.seq, err))
+			continue
+		}
+		if err = b.ack(s); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// Run pings the remote database every retry interval and flushes the queue while it is reachable; it returns when ctx is done
+func (b *Buffer) Run(ctx context.Context) error {
+	t := time.NewTicker(b.interval)
+	defer t.Stop()
+	for {
+		if b.remote.open {
+			if err := b.remote.PingContext(ctx); err == nil {
+				if _, err = b.Flush(); err != nil {
+					b.onReplayErr(err)
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// IsUnreachable reports whether err is a connectivity failure rather than a rejected statement
+func IsUnreachable(err error) bool {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return true
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return true
+	case errors.As(err, &netErr):
+		return true
+	}
+	return false
+}
+
+type bufferedStmt struct {
+	seq    int64
+	dedup  sql.NullString
+	query  string
+	params string
+}
+
+func (b *Buffer) pending() (int, error) {
+	var n int
+	err := b.local.QueryRow("SELECT COUNT(*) FROM outbox").Scan(&n)
+	return n, err
+}
+
+func (b *Buffer) seen(key string) (bool, error) {
+	var n int
+	err := b.local.QueryRow("SELECT (SELECT COUNT(*) FROM outbox WHERE dedup = ?) + (SELECT COUNT(*) FROM delivered WHERE dedup = ? AND delivered >= ?)",
+		key, key, time.Now().Add(-b.retention).UnixNano()).Scan(&n)
+	return n > 0, err
+}
+
+func (b *Buffer) enqueue(key string, query string, params []any) error {
+	encoded, err := encodeParams(params)
+	if err != nil {
+		return err
+	}
+	_, err = b.local.Exec("INSERT OR IGNORE INTO outbox (dedup, query, params, created) VALUES (?, ?, ?, ?)",
+		nullKey(key), query, encoded, time.Now().UnixNano())
+	return err
+}
+
+func (b *Buffer) delivered(key string) error {
+	if len(key) == 0 {
+		return nil
+	}
+	_, err := b.local.Exec("INSERT OR REPLACE INTO delivered (dedup, delivered) VALUES (?, ?)", key, time.Now().UnixNano())
+	return err
+}
+
+func (b *Buffer) ack(s bufferedStmt) error {
+	tx, err := b.local.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err = tx.Exec("DELETE FROM outbox WHERE seq = ?", s.seq); err != nil {
+		return err
+	}
+	if s.dedup.Valid {
+		if _, err = tx.Exec("INSERT OR REPLACE INTO delivered (dedup, delivered) VALUES (?, ?)", s.dedup.String, time.Now().UnixNano()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (b *Buffer) bury(s bufferedStmt, reason error) error {
+	tx, err := b.local.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec("INSERT INTO outbox_dead (seq, dedup, query, params, created, reason) SELECT seq, dedup, query, params, created, ? FROM outbox WHERE seq = ?",
+		reason.Error(), s.seq)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM outbox WHERE seq = ?", s.seq); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func nullKey(key string) sql.NullString {
+	return sql.NullString{String: key, Valid: len(key) != 0}
+}
+
+// storedParam keeps the kind of a statement parameter next to its value so replay passes the same types to the driver
+type storedParam struct {
+	Kind  string `json:"k"`
+	Value string `json:"v,omitempty"`
+}
+
+func encodeParams(params []any) (string, error) {
+	stored := make([]storedParam, len(params))
+	for i, p := range params {
+		if v, ok := p.(driver.Valuer); ok {
+			var err error
+			if p, err = v.Value(); err != nil {
+				return "", err
+			}
+		}
+		sp, err := encodeParam(p)
+		if err != nil {
+			return "", fmt.Errorf("%w; index:%d;type:%T", err, i, p)
+		}
+		stored[i] = sp
+	}
+	raw, err := json.Marshal(stored)
+	return string(raw), err
+}
+
+func encodeParam(p any) (storedParam, error) {
+	switch v := p.(type) {
+	case nil:
+		return storedParam{Kind: "nil"}, nil
+	case []byte:
+		return storedParam{Kind: "bytes", Value: base64.StdEncoding.EncodeToString(v)}, nil
+	case time.Time:
+		return storedParam{Kind: "time", Value: v.Format(time.RFC3339Nano)}, nil
+	}
+	rv := reflect.ValueOf(p)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return storedParam{Kind: "int", Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return storedParam{Kind: "uint", Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return storedParam{Kind: "float", Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	case reflect.Bool:
+		return storedParam{Kind: "bool", Value: strconv.FormatBool(rv.Bool())}, nil
+	case reflect.String:
+		return storedParam{Kind: "string", Value: rv.String()}, nil
+	}
+	return storedParam{}, ErrUnsupportedParam
+}
+
+func decodeParams(raw string) ([]any, error) {
+	var stored []storedParam
+	if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+		return nil, err
+	}
+	params := make([]any, len(stored))
+	for i, sp := range stored {
+		var err error
+		switch sp.Kind {
+		case "nil":
+		case "bytes":
+			params[i], err = base64.StdEncoding.DecodeString(sp.Value)
+		case "time":
+			params[i], err = time.Parse(time.RFC3339Nano, sp.Value)
+		case "int":
+			params[i], err = strconv.ParseInt(sp.Value, 10, 64)
+		case "uint":
+			params[i], err = strconv.ParseUint(sp.Value, 10, 64)
+		case "float":
+			params[i], err = strconv.ParseFloat(sp.Value, 64)
+		case "bool":
+			params[i], err = strconv.ParseBool(sp.Value)
+		case "string":
+			params[i] = sp.Value
+		default:
+			err = fmt.Errorf("%w; kind:%s", ErrUnsupportedParam, sp.Kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
@@ -11,8 +11,6 @@ import (
 
 var ErrBadConfig = errors.New("the configuration provided is missing fields or has bad values in the provided fields")
 
-type dbMode int
-
 type QueryConstructor interface {
 	Construct() string
 }
@@ -31,12 +29,8 @@ type Query interface {
 	QueryUnwrapper
 }
 
-const (
-	stage dbMode = iota
-	prod  dbMode = iota
-)
-
-// DatabaseConfig provides the necessary configuration for Database initiailization; All fields must be filled
+// DatabaseConfig provides the necessary configuration for Database initiailization; All fields must be filled,
+// except for the sqlite driver which only needs the Address (file path or ":memory:")
 type DatabaseConfig struct {
 	Driver      string `json:"driver"`
 	Name        string `json:"name"`
@@ -47,6 +41,11 @@ type DatabaseConfig struct {
 	} `json:"credentials"`
 	/* 	 ConnectionStringTemplate example:"sqlserver://{{.Credentials.Name}}:{{.Credentials.Password}}@{{.Address}}/?database={{.Name}}" */
 	ConnectionStringTemplate *template.Template
+	// Mode is the environment the database serves; it selects the Session statements, defaults to Stage
+	Mode DBMode `json:"mode"`
+	// Session holds the statements run on every new connection of the pool per mode, e.g. "SET LOCK_TIMEOUT 5000",
+	// "SET application_name = 'mes-sync'" or "SET TRANSACTION ISOLATION LEVEL READ COMMITTED"
+	Session map[DBMode][]string `json:"session"`
 }
 
 type Database struct {
@@ -58,6 +57,15 @@ type Database struct {
 }
 
 func ValidateConfig(c DatabaseConfig) error {
+	if c.Mode != Stage && c.Mode != Prod {
+		return fmt.Errorf("%w; mode:%d", ErrBadConfig, c.Mode)
+	}
+	if isSQLite(c.Driver) {
+		if len(c.Address) == 0 {
+			return ErrBadConfig
+		}
+		return nil
+	}
 	valid := len(c.Address) != 0 && len(c.Driver) != 0 && c.ConnectionStringTemplate != nil && len(c.Credentials.Name) != 0 && len(c.Credentials.Password) != 0
 	if !valid {
 		return ErrBadConfig
@@ -69,22 +77,50 @@ func NewDatabase(c DatabaseConfig, name string) (*Database, error) {
 	if err := ValidateConfig(c); err != nil {
 		return nil, err
 	}
-	connectionString := bytes.NewBuffer([]byte{})
 	db := new(Database)
 	db.Config = c
-	err := db.Config.ConnectionStringTemplate.Execute(connectionString, db.Config)
-	if err != nil {
+	if isSQLite(c.Driver) && c.ConnectionStringTemplate == nil {
+		db.connString = sqliteDSN(c)
+	} else {
+		connectionString := bytes.NewBuffer([]byte{})
+		err := db.Config.ConnectionStringTemplate.Execute(connectionString, db.Config)
+		if err != nil {
+			return nil, err
+		}
+		db.connString = connectionString.String()
+	}
+	if err := db.Open(); err != nil {
 		return nil, err
 	}
-	db.connString = connectionString.String()
+	return db, nil
+}
 
-	db.DB, err = sql.Open(db.Config.Driver, db.connString)
+// Open (re)opens the connection pool; it is a no-op on an open database
+func (pdb *Database) Open() error {
+	if pdb.open {
+		return nil
+	}
+	var err error
+	driver := pdb.Config.Driver
+	if isSQLite(driver) {
+		driver = DriverSQLite
+	}
+	if session := pdb.Config.Session[pdb.Config.Mode]; len(session) != 0 {
+		pdb.DB, err = openWithSession(driver, pdb.connString, session)
+	} else {
+		pdb.DB, err = sql.Open(driver, pdb.connString)
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
-	db.open = true
-	db.prepStmts = make(map[string]*sql.Stmt)
-	return db, nil
+	if isSQLiteMemory(pdb.Config) {
+		// INFO: the shared memory database lives as long as one connection is open; keep one around
+		pdb.DB.SetMaxIdleConns(1)
+		pdb.DB.SetConnMaxIdleTime(0)
+	}
+	pdb.open = true
+	pdb.prepStmts = make(map[string]*sql.Stmt)
+	return nil
 }
 
 func (pdb *Database) Close() error {
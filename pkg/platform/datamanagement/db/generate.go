@@ -0,0 +1,420 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+var ErrUnknownTable = errors.New("the table does not exist in the database")
+
+// GenerateConfig selects the tables Generate emits accessors for
+type GenerateConfig struct {
+	// Package is the package clause of the generated file
+	Package string
+	// Tables are the tables to introspect, optionally qualified with their schema ("dbo.orders")
+	Tables []string
+	// TypeNames overrides the struct name derived from a table name (e.g. "orders" becomes Order)
+	TypeNames map[string]string
+	// InsertSkip lists "table.column" entries left out of the generated inserts, e.g. identity columns or
+	// columns filled by a default
+	InsertSkip []string
+}
+
+// generatedColumn is a column of a table as the generator sees it
+type generatedColumn struct {
+	Name     string
+	Field    string
+	GoType   string
+	Quoted   string
+	Nullable bool
+	Key      bool
+	// NoInsert leaves the column out of the insert
+	NoInsert bool
+}
+
+type generatedTable struct {
+	Table   string
+	Quoted  string
+	Type    string
+	Plural  string
+	Columns []generatedColumn
+}
+
+// Generate introspects the configured tables and writes a Go file with a struct per table, tagged with `db` and `df`
+// column names, a Query implementation selecting its rows for QueryWrappedValues, and Insert/List helpers bound to
+// a Database; tables with a primary key also get Get, Update and Delete helpers. The SQL of the helpers uses the
+// quoting and placeholders of the database's driver. Regenerate after a migration, e.g. from a go:generate program,
+// so the structs can not drift from the tables
+func (pdb *Database) Generate(ctx context.Context, w io.Writer, cfg GenerateConfig) error {
+	if len(cfg.Package) == 0 || len(cfg.Tables) == 0 {
+		return fmt.Errorf("%w; generate needs a package and tables", ErrBadConfig)
+	}
+	var tables []generatedTable
+	usesTime := false
+	for _, name := range cfg.Tables {
+		t, err := pdb.generatedTable(ctx, name, cfg)
+		if err != nil {
+			return err
+		}
+		for _, c := range t.Columns {
+			usesTime = usesTime || strings.Contains(c.GoType, "time.Time")
+		}
+		tables = append(tables, t)
+	}
+
+	var src bytes.Buffer
+	err := generateTemplate.Execute(&src, map[string]any{
+		"Package":  cfg.Package,
+		"Driver":   pdb.Config.Driver,
+		"Tables":   tables,
+		"UsesTime": usesTime,
+	})
+	if err != nil {
+		return err
+	}
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting the generated source failed: %w", err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func (pdb *Database) generatedTable(ctx context.Context, name string, cfg GenerateConfig) (generatedTable, error) {
+	quoted, err := pdb.QuoteIdent(name)
+	if err != nil {
+		return generatedTable{}, err
+	}
+	cols, err := pdb.tableColumns(ctx, name)
+	if err != nil {
+		return generatedTable{}, err
+	}
+	if len(cols) == 0 {
+		return generatedTable{}, fmt.Errorf("%w; table:%s", ErrUnknownTable, name)
+	}
+	base := name[strings.LastIndexByte(name, '.')+1:]
+	t := generatedTable{Table: name, Quoted: quoted, Type: cfg.TypeNames[name]}
+	if len(t.Type) == 0 {
+		t.Type = singular(goName(base))
+	}
+	t.Plural = goName(base)
+	if t.Plural == t.Type {
+		t.Plural += "Rows"
+	}
+	fields := make(map[string]bool)
+	for _, c := range cols {
+		if c.Quoted, err = pdb.QuoteIdent(c.Name); err != nil {
+			return generatedTable{}, err
+		}
+		field := goName(c.Name)
+		for n := 2; fields[field]; n++ {
+			field = goName(c.Name) + strconv.Itoa(n)
+		}
+		fields[field] = true
+		c.Field = field
+		c.GoType = goType(c.GoType, c.Nullable)
+		c.NoInsert = slices.Contains(cfg.InsertSkip, name+"."+c.Name)
+		t.Columns = append(t.Columns, c)
+	}
+	return t, nil
+}
+
+// tableColumns lists the columns of the table in ordinal order; GoType holds the base database type
+func (pdb *Database) tableColumns(ctx context.Context, name string) ([]generatedColumn, error) {
+	schemaName, table := "", name
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		schemaName, table = name[:i], name[i+1:]
+	}
+	if isSQLite(pdb.Config.Driver) {
+		rows, err := pdb.QueryContext(ctx, `SELECT name, type, "notnull", pk FROM pragma_table_info(?) ORDER BY cid`, table)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		var cols []generatedColumn
+		for rows.Next() {
+			var c generatedColumn
+			var notNull, pk int
+			if err = rows.Scan(&c.Name, &c.GoType, &notNull, &pk); err != nil {
+				return nil, err
+			}
+			c.Nullable, c.Key = notNull == 0 && pk == 0, pk > 0
+			cols = append(cols, c)
+		}
+		return cols, rows.Err()
+	}
+
+	p := func(n int) string { return placeholder(pdb.Config.Driver, n) }
+	query := `SELECT c.COLUMN_NAME, c.DATA_TYPE, c.IS_NULLABLE,
+		CASE WHEN EXISTS (SELECT 1 FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+			JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE k ON k.CONSTRAINT_NAME = tc.CONSTRAINT_NAME AND k.TABLE_SCHEMA = tc.TABLE_SCHEMA AND k.TABLE_NAME = tc.TABLE_NAME
+			WHERE tc.CONSTRAINT_TYPE = 'PRIMARY KEY' AND tc.TABLE_SCHEMA = c.TABLE_SCHEMA AND tc.TABLE_NAME = c.TABLE_NAME AND k.COLUMN_NAME = c.COLUMN_NAME)
+		THEN 1 ELSE 0 END
+		FROM INFORMATION_SCHEMA.COLUMNS c WHERE c.TABLE_NAME = ` + p(1)
+	params := []any{table}
+	if len(schemaName) != 0 {
+		query += " AND c.TABLE_SCHEMA = " + p(2)
+		params = append(params, schemaName)
+	}
+	rows, err := pdb.QueryContext(ctx, query+" ORDER BY c.ORDINAL_POSITION", params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []generatedColumn
+	for rows.Next() {
+		var c generatedColumn
+		var nullable string
+		var pk int
+		if err = rows.Scan(&c.Name, &c.GoType, &nullable, &pk); err != nil {
+			return nil, err
+		}
+		c.Nullable, c.Key = strings.EqualFold(nullable, "YES"), pk == 1
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+// placeholder is the n-th query parameter in the driver's dialect
+func placeholder(driver string, n int) string {
+	switch strings.ToLower(driver) {
+	case "sqlserver", "mssql", "azuresql":
+		return "@p" + strconv.Itoa(n)
+	case "postgres", "pgx", "postgresql":
+		return "$" + strconv.Itoa(n)
+	default:
+		return "?"
+	}
+}
+
+// goType maps a database type to the Go type of the struct field; nullable columns use the sql.Null types
+func goType(dbType string, nullable bool) string {
+	typ, null := "string", "sql.NullString"
+	switch baseType(dbType) {
+	case "int", "smallint", "tinyint", "mediumint", "bigint", "int2", "serial", "bigserial":
+		typ, null = "int64", "sql.NullInt64"
+	case "bool", "bit":
+		typ, null = "bool", "sql.NullBool"
+	case "real", "float", "float4", "double", "numeric", "decimal", "money":
+		typ, null = "float64", "sql.NullFloat64"
+	case "date", "time", "datetime", "datetime2", "smalldatetime", "datetimeoffset", "timestamp", "timestamptz", "timestamp with time zone":
+		typ, null = "time.Time", "sql.NullTime"
+	case "blob", "bytea", "binary", "varbinary", "image":
+		return "[]byte"
+	}
+	if nullable {
+		return null
+	}
+	return typ
+}
+
+// goName turns a table or column name into an exported Go identifier: "work_order_id" becomes WorkOrderID
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var b strings.Builder
+	for _, p := range parts {
+		if strings.EqualFold(p, "id") {
+			b.WriteString("ID")
+			continue
+		}
+		r := []rune(p)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	s := b.String()
+	if len(s) == 0 || unicode.IsDigit([]rune(s)[0]) {
+		s = "C" + s
+	}
+	return s
+}
+
+// singular drops the plural ending of a type name derived from a table name: Orders becomes Order, Batches Batch;
+// names that only look plural such as Status are kept
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(name, "ss"), strings.HasSuffix(name, "us"), strings.HasSuffix(name, "is"), !strings.HasSuffix(name, "s"), len(name) == 1:
+		return name
+	}
+	return name[:len(name)-1]
+}
+
+var generateTemplate = template.Must(template.New("accessors").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+	"keys": func(cols []generatedColumn) []generatedColumn {
+		return slices.DeleteFunc(slices.Clone(cols), func(c generatedColumn) bool { return !c.Key })
+	},
+	"values": func(cols []generatedColumn) []generatedColumn {
+		return slices.DeleteFunc(slices.Clone(cols), func(c generatedColumn) bool { return c.Key })
+	},
+	"inserted": func(cols []generatedColumn) []generatedColumn {
+		return slices.DeleteFunc(slices.Clone(cols), func(c generatedColumn) bool { return c.NoInsert })
+	},
+	"list": func(cols []generatedColumn) string {
+		names := make([]string, len(cols))
+		for i, c := range cols {
+			names[i] = c.Quoted
+		}
+		return strings.Join(names, ", ")
+	},
+	"placeholders": func(driver string, from int, cols []generatedColumn) string {
+		ps := make([]string, len(cols))
+		for i := range cols {
+			ps[i] = placeholder(driver, from+i)
+		}
+		return strings.Join(ps, ", ")
+	},
+	"assignments": func(driver string, from int, cols []generatedColumn, sep string) string {
+		as := make([]string, len(cols))
+		for i, c := range cols {
+			as[i] = c.Quoted + " = " + placeholder(driver, from+i)
+		}
+		return strings.Join(as, sep)
+	},
+	"fields": func(prefix string, cols []generatedColumn) string {
+		fs := make([]string, len(cols))
+		for i, c := range cols {
+			fs[i] = prefix + c.Field
+		}
+		return strings.Join(fs, ", ")
+	},
+	"params": func(cols []generatedColumn) string {
+		ps := make([]string, len(cols))
+		for i, c := range cols {
+			ps[i] = unexported(c.Field) + " " + c.GoType
+		}
+		return strings.Join(ps, ", ")
+	},
+	"args": func(cols []generatedColumn) string {
+		as := make([]string, len(cols))
+		for i, c := range cols {
+			as[i] = unexported(c.Field)
+		}
+		return strings.Join(as, ", ")
+	},
+	"after": func(cols []generatedColumn) int { return len(cols) + 1 },
+}).Parse(`// Code generated by db.Generate; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"database/sql"
+{{- if .UsesTime}}
+	"time"
+{{- end}}
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement/db"
+)
+{{$driver := .Driver}}
+{{- range .Tables}}
+{{- $all := .Columns}}
+{{- $keys := keys .Columns}}
+{{- $values := values .Columns}}
+{{- $insert := inserted .Columns}}
+// {{.Type}} is a row of the {{.Table}} table
+type {{.Type}} struct {
+{{- range $all}}
+	{{.Field}} {{.GoType}} ` + "`" + `db:"{{.Name}}" df:"{{.Name}}"` + "`" + `
+{{- end}}
+}
+
+// {{.Type}}Query selects {{.Table}} rows for Database.QueryWrappedValues; Where, if set, is the condition after WHERE
+type {{.Type}}Query struct {
+	Where string
+	Rows  []{{.Type}}
+	Err   error
+}
+
+func (q *{{.Type}}Query) Construct() string {
+	query := {{quote (print "SELECT " (list $all) " FROM " .Quoted)}}
+	if len(q.Where) != 0 {
+		query += " WHERE " + q.Where
+	}
+	return query
+}
+
+func (q *{{.Type}}Query) Wrap(rows *sql.Rows) {
+	defer rows.Close()
+	for rows.Next() {
+		var r {{.Type}}
+		if q.Err = rows.Scan({{fields "&r." $all}}); q.Err != nil {
+			return
+		}
+		q.Rows = append(q.Rows, r)
+	}
+	q.Err = rows.Err()
+}
+
+func (q *{{.Type}}Query) Unwrap() any {
+	return q.Rows
+}
+
+// Insert{{.Type}} inserts r into {{.Table}}
+func Insert{{.Type}}(ctx context.Context, d *db.Database, r {{.Type}}) (sql.Result, error) {
+	return d.ExecContext(ctx, {{quote (print "INSERT INTO " .Quoted " (" (list $insert) ") VALUES (" (placeholders $driver 1 $insert) ")")}}, {{fields "r." $insert}})
+}
+
+// List{{.Plural}} selects the {{.Table}} rows matching where, all of them when where is empty
+func List{{.Plural}}(ctx context.Context, d *db.Database, where string, params ...any) ([]{{.Type}}, error) {
+	q := &{{.Type}}Query{Where: where}
+	rows, err := d.QueryContext(ctx, q.Construct(), params...)
+	if err != nil {
+		return nil, err
+	}
+	q.Wrap(rows)
+	return q.Rows, q.Err
+}
+{{- if $keys}}
+
+// Get{{.Type}} selects the {{.Table}} row by its primary key; the error is sql.ErrNoRows when there is none
+func Get{{.Type}}(ctx context.Context, d *db.Database, {{params $keys}}) ({{.Type}}, error) {
+	var r {{.Type}}
+	err := d.QueryRowContext(ctx, {{quote (print "SELECT " (list $all) " FROM " .Quoted " WHERE " (assignments $driver 1 $keys " AND "))}}, {{args $keys}}).Scan({{fields "&r." $all}})
+	return r, err
+}
+{{- if $values}}
+
+// Update{{.Type}} writes r to the {{.Table}} row with its primary key
+func Update{{.Type}}(ctx context.Context, d *db.Database, r {{.Type}}) (sql.Result, error) {
+	return d.ExecContext(ctx, {{quote (print "UPDATE " .Quoted " SET " (assignments $driver 1 $values ", ") " WHERE " (assignments $driver (after $values) $keys " AND "))}}, {{fields "r." $values}}, {{fields "r." $keys}})
+}
+{{- end}}
+
+// Delete{{.Type}} deletes the {{.Table}} row by its primary key
+func Delete{{.Type}}(ctx context.Context, d *db.Database, {{params $keys}}) (sql.Result, error) {
+	return d.ExecContext(ctx, {{quote (print "DELETE FROM " .Quoted " WHERE " (assignments $driver 1 $keys " AND "))}}, {{args $keys}})
+}
+{{- end}}
+{{end}}`))
+
+// unexported is the parameter name of a key column: WorkOrderID becomes workOrderID, ID becomes id;
+// names taken by keywords or the other parameters get a trailing underscore
+func unexported(field string) string {
+	r := []rune(field)
+	for i := 0; i < len(r) && unicode.IsUpper(r[i]); i++ {
+		// the last capital of an initialism followed by a word starts that word: IDNumber becomes idNumber
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	s := string(r)
+	if token.IsKeyword(s) || s == "ctx" || s == "d" {
+		s += "_"
+	}
+	return s
+}
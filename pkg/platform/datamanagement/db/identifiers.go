@@ -0,0 +1,105 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrUnknownDialect = errors.New("no identifier quoting is known for the driver")
+	ErrBadIdentifier  = errors.New("the identifier is not a safe table or column name")
+)
+
+// maxIdentLen is the shortest identifier limit of the supported databases (postgres allows 63 bytes)
+const maxIdentLen = 63
+
+// reservedIdents are keywords that are reserved in every supported dialect and usually point at a mistake when they
+// show up as a configured table or column name
+var reservedIdents = map[string]bool{
+	"select": true, "insert": true, "update": true, "delete": true, "drop": true, "create": true, "alter": true,
+	"table": true, "from": true, "where": true, "join": true, "union": true, "grant": true, "exec": true, "execute": true,
+}
+
+type quoting struct {
+	open  string
+	close string
+}
+
+func dialectQuoting(driver string) (quoting, error) {
+	switch strings.ToLower(driver) {
+	case "sqlserver", "mssql", "azuresql":
+		return quoting{"[", "]"}, nil
+	case "mysql":
+		return quoting{"`", "`"}, nil
+	case "postgres", "pgx", "postgresql", DriverSQLite:
+		return quoting{`"`, `"`}, nil
+	default:
+		return quoting{}, fmt.Errorf("%w; driver:%s", ErrUnknownDialect, driver)
+	}
+}
+
+// QuoteIdent validates name with ValidateIdent and quotes it for the driver's SQL dialect; a qualified name such as
+// "plant01.orders" is quoted part by part. Values must still be passed as query parameters, this is only for names
+// that can not be parameters, such as a table name taken from the configuration
+func QuoteIdent(driver string, name string) (string, error) {
+	q, err := dialectQuoting(driver)
+	if err != nil {
+		return "", err
+	}
+	if err = ValidateIdent(name); err != nil {
+		return "", err
+	}
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = q.open + strings.ReplaceAll(p, q.close, q.close+q.close) + q.close
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// QuoteIdent quotes name for the dialect of the database's driver
+func (pdb *Database) QuoteIdent(name string) (string, error) {
+	return QuoteIdent(pdb.Config.Driver, name)
+}
+
+// ValidateIdent checks a dynamically provided table or column name, optionally qualified with schema names ("dbo.orders");
+// every part must start with a letter or underscore and contain only letters, digits and underscores, be at most 63 bytes long
+// and not be one of the statement keywords. The error names the offending part and why it was rejected
+func ValidateIdent(name string) error {
+	if len(name) == 0 {
+		return fmt.Errorf("%w; empty name", ErrBadIdentifier)
+	}
+	parts := strings.Split(name, ".")
+	if len(parts) > 3 {
+		return fmt.Errorf("%w; name:%q;reason:more than three name parts", ErrBadIdentifier, name)
+	}
+	for _, p := range parts {
+		if reason := identProblem(p); len(reason) != 0 {
+			return fmt.Errorf("%w; name:%q;part:%q;reason:%s", ErrBadIdentifier, name, p, reason)
+		}
+	}
+	return nil
+}
+
+func identProblem(part string) string {
+	switch {
+	case len(part) == 0:
+		return "empty name part"
+	case len(part) > maxIdentLen:
+		return fmt.Sprintf("longer than %d bytes", maxIdentLen)
+	case reservedIdents[strings.ToLower(part)]:
+		return "reserved keyword"
+	}
+	for i, r := range part {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case unicode.IsDigit(r) && i > 0:
+		case unicode.IsDigit(r):
+			return "starts with a digit"
+		default:
+			return fmt.Sprintf("contains %q", r)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,223 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+)
+
+const defaultLookupTTL = 10 * time.Minute
+
+var ErrLookupColumns = errors.New("the lookup query must select a key column followed by the value columns")
+
+type LookupOpt func(*lookupConfig) error
+
+type lookupConfig struct {
+	ttl       time.Duration
+	params    []any
+	onRefresh func(error)
+	cache     any
+}
+
+// WithLookupTTL sets how long a loaded table is served before it is reloaded; defaults to 10 minutes
+func WithLookupTTL(d time.Duration) LookupOpt {
+	return func(c *lookupConfig) error {
+		if d <= 0 {
+			return fmt.Errorf("%w; lookup ttl:%s", ErrBadConfig, d)
+		}
+		c.ttl = d
+		return nil
+	}
+}
+
+// WithLookupParams passes parameters to the lookup query
+func WithLookupParams(params ...any) LookupOpt {
+	return func(c *lookupConfig) error {
+		c.params = params
+		return nil
+	}
+}
+
+// WithRefreshErrorHandler is called when a reload fails; the previously loaded table keeps being served
+func WithRefreshErrorHandler(fn func(error)) LookupOpt {
+	return func(c *lookupConfig) error {
+		c.onRefresh = fn
+		return nil
+	}
+}
+
+// WithLookupCache keeps the loaded table in c instead of a private SimpleCache, e.g. a BoltCache so the tables
+// survive restarts or a cache shared by several lookups; T must match the type the lookup is loaded with
+func WithLookupCache[T any](c datamanagement.Cache[string, map[string]T]) LookupOpt {
+	return func(lc *lookupConfig) error {
+		lc.cache = c
+		return nil
+	}
+}
+
+// Lookup is a small reference table (status codes, reason codes, units) loaded into memory and reloaded once its TTL passed
+type Lookup[T any] struct {
+	pdb    *Database
+	query  string
+	key    string
+	config lookupConfig
+	cache  datamanagement.Cache[string, map[string]T]
+
+	mu      sync.RWMutex
+	last    map[string]T
+	refresh sync.Mutex
+}
+
+// LoadLookup loads the rows of query into a Lookup: the first column is the key and the remaining columns are the value;
+// struct values take the columns by their `db` tag or, case insensitively, their field name, any other T takes the second column.
+// A table still fresh in the lookup cache is used without querying
+func LoadLookup[T any](ctx context.Context, pdb *Database, query string, opts ...LookupOpt) (*Lookup[T], error) {
+	l := &Lookup[T]{pdb: pdb, query: query, config: lookupConfig{ttl: defaultLookupTTL}}
+	for _, opt := range opts {
+		if err := opt(&l.config); err != nil {
+			return nil, err
+		}
+	}
+	l.key = fmt.Sprintf("lookup:%s:%v", query, l.config.params)
+	switch c := l.config.cache.(type) {
+	case nil:
+		l.cache = datamanagement.NewSimpleCache[string, map[string]T]()
+	case datamanagement.Cache[string, map[string]T]:
+		l.cache = c
+	default:
+		return nil, fmt.Errorf("%w; lookup cache:%T", ErrBadConfig, c)
+	}
+	if entries, err := l.cache.Get(l.key); err == nil {
+		l.last = entries
+		return l, nil
+	}
+	if err := l.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Get returns the value under key, reloading the table first when its TTL passed
+func (l *Lookup[T]) Get(ctx context.Context, key string) (T, bool) {
+	v, ok := l.current(ctx)[key]
+	return v, ok
+}
+
+// All returns a copy of the table, reloading it first when its TTL passed
+func (l *Lookup[T]) All(ctx context.Context) map[string]T {
+	return maps.Clone(l.current(ctx))
+}
+
+// Refresh reloads the table regardless of its TTL
+func (l *Lookup[T]) Refresh(ctx context.Context) error {
+	l.refresh.Lock()
+	defer l.refresh.Unlock()
+	return l.load(ctx)
+}
+
+func (l *Lookup[T]) current(ctx context.Context) map[string]T {
+	if entries, err := l.cache.Get(l.key); err == nil {
+		return entries
+	}
+	l.refresh.Lock()
+	defer l.refresh.Unlock()
+	// another caller may have reloaded while we waited
+	if entries, err := l.cache.Get(l.key); err == nil {
+		return entries
+	}
+	if err := l.load(ctx); err != nil && l.config.onRefresh != nil {
+		l.config.onRefresh(err)
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.last
+}
+
+func (l *Lookup[T]) load(ctx context.Context) error {
+	rows, err := l.pdb.QueryContext(ctx, l.query, l.config.params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	scan, err := lookupScanner[T](columns)
+	if err != nil {
+		return err
+	}
+	entries := make(map[string]T)
+	for rows.Next() {
+		var key sql.NullString
+		var v T
+		if err = rows.Scan(append([]any{&key}, scan(&v)...)...); err != nil {
+			return err
+		}
+		entries[key.String] = v
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.last = entries
+	l.mu.Unlock()
+	return l.cache.Set(l.key, entries, l.config.ttl)
+}
+
+// lookupScanner returns the scan destinations of the value columns for a *T
+func lookupScanner[T any](columns []string) (func(*T) []any, error) {
+	if len(columns) < 2 {
+		return nil, fmt.Errorf("%w; columns:%v", ErrLookupColumns, columns)
+	}
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct || t.Implements(scannerType) || reflect.PointerTo(t).Implements(scannerType) || t == timeType {
+		if len(columns) != 2 {
+			return nil, fmt.Errorf("%w; columns:%v", ErrLookupColumns, columns)
+		}
+		return func(v *T) []any { return []any{v} }, nil
+	}
+	fields := make([]int, len(columns)-1)
+	for i, c := range columns[1:] {
+		fields[i] = -1
+		for j := range t.NumField() {
+			f := t.Field(j)
+			name := f.Tag.Get("db")
+			if name == "-" || !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			if strings.EqualFold(name, c) {
+				fields[i] = j
+				break
+			}
+		}
+	}
+	return func(v *T) []any {
+		rv := reflect.ValueOf(v).Elem()
+		dest := make([]any, len(fields))
+		for i, j := range fields {
+			if j < 0 {
+				dest[i] = new(any)
+				continue
+			}
+			dest[i] = rv.Field(j).Addr().Interface()
+		}
+		return dest
+	}, nil
+}
+
+var (
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	timeType    = reflect.TypeFor[time.Time]()
+)
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrBadParams     = errors.New("query parameters must be a struct with param tagged fields")
+	ErrUnboundParams = errors.New("the query does not use every named parameter")
+)
+
+// namedPlaceholder matches the @name, :name and $name placeholders of a query; @@ROWCOUNT and ::int casts are skipped
+var namedPlaceholder = regexp.MustCompile(`(^|[^@:$\w])[@:$]([A-Za-z_]\w*)`)
+
+// BindParams extracts the query parameters from the fields of params (a struct or a pointer to one) tagged with
+// `param:"name"`; fields of embedded structs are included and `param:"-"` skips a field.
+// When the query of qc refers to the names as @name, :name or $name placeholders the parameters are bound by name
+// with sql.Named and every tagged field must be used by the query; otherwise they are positional, in the order
+// the fields are declared in
+func BindParams(qc QueryConstructor, params any) ([]any, error) {
+	rv := reflect.ValueOf(params)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w; type:%T", ErrBadParams, params)
+	}
+	var names []string
+	var values []any
+	collectParams(rv, &names, &values)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w; type:%T", ErrBadParams, params)
+	}
+
+	used := make(map[string]bool)
+	for _, m := range namedPlaceholder.FindAllStringSubmatch(qc.Construct(), -1) {
+		used[strings.ToLower(m[2])] = true
+	}
+	var unbound []string
+	for _, name := range names {
+		if !used[strings.ToLower(name)] {
+			unbound = append(unbound, name)
+		}
+	}
+	switch len(unbound) {
+	case len(names):
+		return values, nil
+	case 0:
+		named := make([]any, len(values))
+		for i, v := range values {
+			named[i] = sql.Named(names[i], v)
+		}
+		return named, nil
+	default:
+		return nil, fmt.Errorf("%w; params:%v", ErrUnboundParams, unbound)
+	}
+}
+
+// collectParams appends the tagged fields of the struct in declaration order
+func collectParams(rv reflect.Value, names *[]string, values *[]any) {
+	t := rv.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name := f.Tag.Get("param")
+		if name == "-" {
+			continue
+		}
+		if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+			collectParams(rv.Field(i), names, values)
+			continue
+		}
+		if name == "" || !f.IsExported() {
+			continue
+		}
+		*names = append(*names, name)
+		*values = append(*values, rv.Field(i).Interface())
+	}
+}
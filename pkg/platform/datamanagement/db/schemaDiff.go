@@ -0,0 +1,558 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var ErrUnsupportedDDL = errors.New("the migration file contains DDL the schema diff cannot interpret")
+
+// ColumnRef names a column of a table
+type ColumnRef struct {
+	Table  string
+	Column string
+}
+
+// TypeDrift is a column whose live type differs from the migrations
+type TypeDrift struct {
+	ColumnRef
+	Want string
+	Got  string
+}
+
+// SchemaDrift lists the differences between the live database and the schema described by the migrations;
+// names are lowercase
+type SchemaDrift struct {
+	MissingTables  []string
+	ExtraTables    []string
+	MissingColumns []ColumnRef
+	ExtraColumns   []ColumnRef
+	TypeMismatches []TypeDrift
+	MissingIndexes []string
+	ExtraIndexes   []string
+}
+
+// Empty reports whether the live schema matches the migrations
+func (sd *SchemaDrift) Empty() bool {
+	return len(sd.MissingTables)+len(sd.ExtraTables)+len(sd.MissingColumns)+len(sd.ExtraColumns)+
+		len(sd.TypeMismatches)+len(sd.MissingIndexes)+len(sd.ExtraIndexes) == 0
+}
+
+func (sd *SchemaDrift) String() string {
+	if sd.Empty() {
+		return "no schema drift"
+	}
+	var lines []string
+	for _, t := range sd.MissingTables {
+		lines = append(lines, "missing table "+t)
+	}
+	for _, t := range sd.ExtraTables {
+		lines = append(lines, "extra table "+t)
+	}
+	for _, c := range sd.MissingColumns {
+		lines = append(lines, fmt.Sprintf("missing column %s.%s", c.Table, c.Column))
+	}
+	for _, c := range sd.ExtraColumns {
+		lines = append(lines, fmt.Sprintf("extra column %s.%s", c.Table, c.Column))
+	}
+	for _, c := range sd.TypeMismatches {
+		lines = append(lines, fmt.Sprintf("column %s.%s is %s, migrations declare %s", c.Table, c.Column, c.Got, c.Want))
+	}
+	for _, i := range sd.MissingIndexes {
+		lines = append(lines, "missing index "+i)
+	}
+	for _, i := range sd.ExtraIndexes {
+		lines = append(lines, "extra index "+i)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type SchemaDiffOpt func(*schemaDiffConfig)
+
+type schemaDiffConfig struct {
+	ignored map[string]bool
+}
+
+// WithIgnoredTables leaves tables out of the comparison (e.g. the bookkeeping table of the migration tool)
+func WithIgnoredTables(tables ...string) SchemaDiffOpt {
+	return func(c *schemaDiffConfig) {
+		for _, t := range tables {
+			c.ignored[strings.ToLower(t)] = true
+		}
+	}
+}
+
+// schema is a set of tables and indexes; column types are normalized base type names
+type schema struct {
+	tables  map[string]map[string]string
+	indexes map[string]string // index name -> table; nil when the driver cannot list indexes
+}
+
+// SchemaDiff replays the *.sql files of desiredDDL in lexical path order (files ending in .down.sql are skipped)
+// and compares the schema they describe with the live database: tables, columns, base column types and indexes.
+// The DDL understood is CREATE/DROP TABLE, ALTER TABLE ADD/DROP COLUMN and CREATE/DROP INDEX; other statements are ignored.
+// Indexes are compared on sqlite, sqlserver and postgres
+func (pdb *Database) SchemaDiff(ctx context.Context, desiredDDL fs.FS, opts ...SchemaDiffOpt) (*SchemaDrift, error) {
+	cfg := &schemaDiffConfig{ignored: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	desired, err := schemaFromDDL(desiredDDL)
+	if err != nil {
+		return nil, err
+	}
+	live, err := pdb.liveSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for t := range cfg.ignored {
+		delete(desired.tables, t)
+		delete(live.tables, t)
+	}
+	return diffSchemas(desired, live, cfg), nil
+}
+
+func diffSchemas(desired, live *schema, cfg *schemaDiffConfig) *SchemaDrift {
+	sd := new(SchemaDrift)
+	for _, t := range slices.Sorted(maps.Keys(desired.tables)) {
+		liveCols, ok := live.tables[t]
+		if !ok {
+			sd.MissingTables = append(sd.MissingTables, t)
+			continue
+		}
+		wantCols := desired.tables[t]
+		for _, c := range slices.Sorted(maps.Keys(wantCols)) {
+			got, ok := liveCols[c]
+			switch {
+			case !ok:
+				sd.MissingColumns = append(sd.MissingColumns, ColumnRef{Table: t, Column: c})
+			case wantCols[c] != "" && got != "" && wantCols[c] != got:
+				sd.TypeMismatches = append(sd.TypeMismatches, TypeDrift{ColumnRef: ColumnRef{Table: t, Column: c}, Want: wantCols[c], Got: got})
+			}
+		}
+		for _, c := range slices.Sorted(maps.Keys(liveCols)) {
+			if _, ok := wantCols[c]; !ok {
+				sd.ExtraColumns = append(sd.ExtraColumns, ColumnRef{Table: t, Column: c})
+			}
+		}
+	}
+	for _, t := range slices.Sorted(maps.Keys(live.tables)) {
+		if _, ok := desired.tables[t]; !ok {
+			sd.ExtraTables = append(sd.ExtraTables, t)
+		}
+	}
+	if live.indexes == nil {
+		return sd
+	}
+	for _, i := range slices.Sorted(maps.Keys(desired.indexes)) {
+		if _, ok := live.indexes[i]; !ok && !cfg.ignored[desired.indexes[i]] {
+			sd.MissingIndexes = append(sd.MissingIndexes, i)
+		}
+	}
+	for _, i := range slices.Sorted(maps.Keys(live.indexes)) {
+		if _, ok := desired.indexes[i]; !ok && !cfg.ignored[live.indexes[i]] {
+			sd.ExtraIndexes = append(sd.ExtraIndexes, i)
+		}
+	}
+	return sd
+}
+
+func (pdb *Database) liveSchema(ctx context.Context) (*schema, error) {
+	s := &schema{tables: make(map[string]map[string]string)}
+	driver := strings.ToLower(pdb.Config.Driver)
+	if isSQLite(driver) {
+		return s, pdb.liveSQLiteSchema(ctx, s)
+	}
+	rows, err := pdb.QueryContext(ctx, `SELECT c.TABLE_NAME, c.COLUMN_NAME, c.DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS c
+		JOIN INFORMATION_SCHEMA.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+		WHERE t.TABLE_TYPE = 'BASE TABLE' AND c.TABLE_SCHEMA NOT IN ('pg_catalog', 'information_schema', 'sys', 'mysql', 'performance_schema')`)
+	if err != nil {
+		return nil, err
+	}
+	if err = scanColumns(rows, s); err != nil {
+		return nil, err
+	}
+	var indexQuery string
+	switch driver {
+	case "sqlserver", "mssql", "azuresql":
+		indexQuery = `SELECT i.name, t.name FROM sys.indexes i JOIN sys.tables t ON t.object_id = i.object_id
+			WHERE i.name IS NOT NULL AND i.is_primary_key = 0 AND i.is_unique_constraint = 0`
+	case "postgres", "pgx", "postgresql":
+		indexQuery = `SELECT i.indexname, i.tablename FROM pg_indexes i
+			WHERE i.schemaname NOT IN ('pg_catalog', 'information_schema')
+			AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conname = i.indexname)`
+	default:
+		return s, nil
+	}
+	s.indexes = make(map[string]string)
+	return s, pdb.scanIndexes(ctx, indexQuery, s)
+}
+
+func (pdb *Database) liveSQLiteSchema(ctx context.Context, s *schema) error {
+	rows, err := pdb.QueryContext(ctx, `SELECT m.name, p.name, p.type FROM sqlite_master m JOIN pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		return err
+	}
+	if err = scanColumns(rows, s); err != nil {
+		return err
+	}
+	s.indexes = make(map[string]string)
+	// automatic indexes back PRIMARY KEY and UNIQUE constraints and are not declared by migrations
+	return pdb.scanIndexes(ctx, `SELECT name, tbl_name FROM sqlite_master WHERE type = 'index' AND name NOT LIKE 'sqlite_autoindex%'`, s)
+}
+
+func scanColumns(rows *sql.Rows, s *schema) error {
+	defer rows.Close()
+	for rows.Next() {
+		var table, column, typ string
+		if err := rows.Scan(&table, &column, &typ); err != nil {
+			return err
+		}
+		table = strings.ToLower(table)
+		if s.tables[table] == nil {
+			s.tables[table] = make(map[string]string)
+		}
+		s.tables[table][strings.ToLower(column)] = baseType(typ)
+	}
+	return rows.Err()
+}
+
+func (pdb *Database) scanIndexes(ctx context.Context, query string, s *schema) error {
+	rows, err := pdb.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var index, table string
+		if err = rows.Scan(&index, &table); err != nil {
+			return err
+		}
+		s.indexes[strings.ToLower(index)] = strings.ToLower(table)
+	}
+	return rows.Err()
+}
+
+// typeAliases folds the spellings of the same type reported by different databases
+var typeAliases = map[string]string{
+	"integer":                     "int",
+	"int4":                        "int",
+	"int8":                        "bigint",
+	"boolean":                     "bool",
+	"character varying":           "varchar",
+	"character":                   "char",
+	"double precision":            "double",
+	"float8":                      "double",
+	"timestamp without time zone": "timestamp",
+}
+
+// baseType lowercases a column type and drops its length/precision: "VARCHAR(50)" is "varchar"
+func baseType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if i := strings.IndexByte(t, '('); i >= 0 {
+		t = strings.TrimSpace(t[:i])
+	}
+	t = strings.Join(strings.Fields(t), " ")
+	if alias, ok := typeAliases[t]; ok {
+		return alias
+	}
+	return t
+}
+
+func schemaFromDDL(fsys fs.FS) (*schema, error) {
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := strings.ToLower(d.Name())
+		if !d.IsDir() && strings.HasSuffix(name, ".sql") && !strings.HasSuffix(name, ".down.sql") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+	s := &schema{tables: make(map[string]map[string]string), indexes: make(map[string]string)}
+	for _, f := range files {
+		b, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return nil, err
+		}
+		for _, stmt := range splitStatements(string(b)) {
+			if err = s.apply(stmt); err != nil {
+				return nil, fmt.Errorf("%w; file:%s;statement:%q", err, f, stmt)
+			}
+		}
+	}
+	return s, nil
+}
+
+// splitStatements strips comments and splits a script on semicolons and GO batch separators outside quotes
+func splitStatements(script string) []string {
+	var stmts []string
+	var cur strings.Builder
+	flush := func() {
+		if st := strings.TrimSpace(cur.String()); st != "" {
+			stmts = append(stmts, st)
+		}
+		cur.Reset()
+	}
+	var quote byte
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != 0:
+			cur.WriteByte(c)
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			cur.WriteByte(c)
+		case c == '[':
+			quote = ']'
+			cur.WriteByte(c)
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			cur.WriteByte('\n')
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 3
+			}
+			cur.WriteByte(' ')
+		case c == ';':
+			flush()
+		case c == '\n':
+			cur.WriteByte(c)
+			if line, _, _ := strings.Cut(script[i+1:], "\n"); strings.EqualFold(strings.TrimSpace(line), "go") {
+				flush()
+				i += len(line) + 1
+			}
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return stmts
+}
+
+func (s *schema) apply(stmt string) error {
+	tokens := tokenize(stmt)
+	upper := func(i int) string {
+		if i < len(tokens) {
+			return strings.ToUpper(tokens[i])
+		}
+		return ""
+	}
+	next := 0
+	skip := func(words ...string) {
+		for _, w := range words {
+			if upper(next) == w {
+				next++
+			}
+		}
+	}
+	switch {
+	case upper(0) == "CREATE" && upper(1) == "TABLE":
+		next = 2
+		skip("IF", "NOT", "EXISTS")
+		if next >= len(tokens) {
+			return ErrUnsupportedDDL
+		}
+		table := identifier(tokens[next])
+		start := strings.IndexByte(stmt, '(')
+		end := strings.LastIndexByte(stmt, ')')
+		if start < 0 || end < start {
+			return ErrUnsupportedDDL
+		}
+		cols := make(map[string]string)
+		for _, def := range splitTopLevel(stmt[start+1 : end]) {
+			if name, typ, ok := columnDefinition(def); ok {
+				cols[name] = typ
+			}
+		}
+		s.tables[table] = cols
+	case upper(0) == "CREATE" && slices.Contains([]string{upper(1), upper(2), upper(3)}, "INDEX"):
+		next = 1
+		skip("UNIQUE", "CLUSTERED", "NONCLUSTERED", "INDEX", "IF", "NOT", "EXISTS")
+		if next+2 >= len(tokens) || upper(next+1) != "ON" {
+			return ErrUnsupportedDDL
+		}
+		s.indexes[identifier(tokens[next])] = identifier(tokens[next+2])
+	case upper(0) == "DROP" && upper(1) == "TABLE":
+		next = 2
+		skip("IF", "EXISTS")
+		if next < len(tokens) {
+			table := identifier(tokens[next])
+			delete(s.tables, table)
+			maps.DeleteFunc(s.indexes, func(_, t string) bool { return t == table })
+		}
+	case upper(0) == "DROP" && upper(1) == "INDEX":
+		next = 2
+		skip("IF", "EXISTS")
+		if next < len(tokens) {
+			delete(s.indexes, identifier(tokens[next]))
+		}
+	case upper(0) == "ALTER" && upper(1) == "TABLE" && len(tokens) > 3:
+		table := identifier(tokens[2])
+		cols, ok := s.tables[table]
+		if !ok {
+			return nil
+		}
+		switch upper(3) {
+		case "ADD":
+			next = 4
+			skip("COLUMN")
+			if upper(next) == "CONSTRAINT" || upper(next) == "PRIMARY" || upper(next) == "FOREIGN" || upper(next) == "UNIQUE" || upper(next) == "CHECK" {
+				return nil
+			}
+			if name, typ, ok := columnDefinition(strings.Join(tokens[next:], " ")); ok {
+				cols[name] = typ
+			}
+		case "DROP":
+			next = 4
+			skip("COLUMN", "IF", "EXISTS")
+			if next < len(tokens) && upper(4) != "CONSTRAINT" {
+				delete(cols, identifier(tokens[next]))
+			}
+		case "RENAME":
+			next = 4
+			if upper(next) == "TO" && next+1 < len(tokens) {
+				delete(s.tables, table)
+				s.tables[identifier(tokens[next+1])] = cols
+			}
+		}
+	}
+	return nil
+}
+
+// columnDefinition reads the name and base type of a column definition; constraints yield ok false
+func columnDefinition(def string) (name string, typ string, ok bool) {
+	tokens := tokenize(def)
+	if len(tokens) == 0 {
+		return "", "", false
+	}
+	switch strings.ToUpper(tokens[0]) {
+	case "CONSTRAINT", "PRIMARY", "FOREIGN", "UNIQUE", "CHECK", "INDEX", "KEY":
+		return "", "", false
+	}
+	name = identifier(tokens[0])
+	var parts []string
+	for _, t := range tokens[1:] {
+		if !isTypeWord(t) {
+			break
+		}
+		parts = append(parts, t)
+	}
+	return name, baseType(strings.Join(parts, " ")), true
+}
+
+// isTypeWord reports whether a token still belongs to the type of a column definition ("double precision", "varchar(20)")
+func isTypeWord(t string) bool {
+	switch strings.ToUpper(t) {
+	case "NOT", "NULL", "PRIMARY", "DEFAULT", "REFERENCES", "UNIQUE", "CHECK", "CONSTRAINT", "IDENTITY", "COLLATE",
+		"AUTOINCREMENT", "AUTO_INCREMENT", "GENERATED", "AS", "ON":
+		return false
+	}
+	return t != "" && (t[0] == '(' || t[0] >= 'a' && t[0] <= 'z' || t[0] >= 'A' && t[0] <= 'Z')
+}
+
+// tokenize splits on whitespace keeping quoted identifiers and parenthesized groups attached to the previous word
+func tokenize(s string) []string {
+	var tokens []string
+	var cur strings.Builder
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			cur.WriteByte(c)
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`' || c == '\'':
+			quote = c
+			cur.WriteByte(c)
+		case c == '[':
+			quote = ']'
+			cur.WriteByte(c)
+		case c == '(':
+			depth++
+			if depth == 1 && cur.Len() == 0 && len(tokens) > 0 {
+				// "varchar (20)" belongs to the previous token
+				cur.WriteString(tokens[len(tokens)-1])
+				tokens = tokens[:len(tokens)-1]
+			}
+			cur.WriteByte(c)
+		case c == ')':
+			depth--
+			cur.WriteByte(c)
+		case depth == 0 && (c == ' ' || c == '\t' || c == '\n' || c == '\r'):
+			if cur.Len() > 0 {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens
+}
+
+// splitTopLevel splits a column definition list on the commas outside parentheses and quotes
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`' || c == '\'':
+			quote = c
+		case c == '[':
+			quote = ']'
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// identifier unquotes a possibly schema qualified name and keeps the object name: "[dbo].[Orders]" is "orders"
+func identifier(t string) string {
+	if i := strings.IndexByte(t, '('); i > 0 {
+		t = t[:i]
+	}
+	parts := strings.Split(t, ".")
+	name := parts[len(parts)-1]
+	name = strings.Trim(name, "[]\"`")
+	return strings.ToLower(name)
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DBMode is the environment a database serves
+type DBMode int
+
+const (
+	Stage DBMode = iota
+	Prod
+)
+
+func (m DBMode) String() string {
+	switch m {
+	case Stage:
+		return "stage"
+	case Prod:
+		return "prod"
+	}
+	return fmt.Sprintf("DBMode(%d)", int(m))
+}
+
+// MarshalText writes the mode as "stage" or "prod", also as the key of the DatabaseConfig.Session map
+func (m DBMode) MarshalText() ([]byte, error) {
+	if m != Stage && m != Prod {
+		return nil, fmt.Errorf("%w; mode:%d", ErrBadConfig, int(m))
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText reads "stage" or "prod", in any case
+func (m *DBMode) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "stage":
+		*m = Stage
+	case "prod":
+		*m = Prod
+	default:
+		return fmt.Errorf("%w; mode:%s", ErrBadConfig, text)
+	}
+	return nil
+}
+
+// openWithSession opens a pool whose connections run the session statements before they are handed out;
+// a failing statement fails the connection attempt
+func openWithSession(driverName string, dsn string, statements []string) (*sql.DB, error) {
+	// INFO: sql.Open does not connect, it only resolves the registered driver
+	probe, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	d := probe.Driver()
+	probe.Close()
+	var base driver.Connector = dsnConnector{dsn: dsn, driver: d}
+	if dc, ok := d.(driver.DriverContext); ok {
+		if base, err = dc.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(sessionConnector{Connector: base, statements: statements}), nil
+}
+
+// dsnConnector is the connector of drivers without driver.DriverContext, as database/sql builds it
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type sessionConnector struct {
+	driver.Connector
+	statements []string
+}
+
+func (c sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range c.statements {
+		if err = execSession(ctx, conn, s); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w; session statement:%s", err, s)
+		}
+	}
+	return conn, nil
+}
+
+// Close releases the wrapped connector when it holds resources, as sql.DB.Close does for connectors
+func (c sessionConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func execSession(ctx context.Context, conn driver.Conn, statement string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, statement, nil)
+		if err != driver.ErrSkip {
+			return err
+		}
+	}
+	stmt, err := conn.Prepare(statement)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	if sc, ok := stmt.(driver.StmtExecContext); ok {
+		_, err = sc.ExecContext(ctx, nil)
+		return err
+	}
+	// drivers without StmtExecContext
+	_, err = stmt.Exec(nil)
+	return err
+}
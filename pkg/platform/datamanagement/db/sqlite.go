@@ -0,0 +1,45 @@
+package db
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	// registers the "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// DriverSQLite selects the embedded sqlite driver; Address is the database file path or ":memory:"
+	DriverSQLite = "sqlite"
+	// SQLiteMemory is the Address of an in-memory sqlite database
+	SQLiteMemory = ":memory:"
+)
+
+func isSQLite(driver string) bool {
+	return strings.EqualFold(driver, DriverSQLite)
+}
+
+func isSQLiteMemory(c DatabaseConfig) bool {
+	return isSQLite(c.Driver) && c.Address == SQLiteMemory
+}
+
+// sqliteDSN builds the connection string of a sqlite database when no ConnectionStringTemplate is provided;
+// file databases use WAL and a busy timeout so a local buffer tolerates concurrent writers,
+// memory databases are shared between the connections of the pool under the database Name
+func sqliteDSN(c DatabaseConfig) string {
+	q := url.Values{}
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "foreign_keys(1)")
+	if isSQLiteMemory(c) {
+		name := c.Name
+		if len(name) == 0 {
+			name = "memdb"
+		}
+		q.Set("mode", "memory")
+		q.Set("cache", "shared")
+		return fmt.Sprintf("file:%s?%s", name, q.Encode())
+	}
+	q.Add("_pragma", "journal_mode(WAL)")
+	return fmt.Sprintf("file:%s?%s", c.Address, q.Encode())
+}
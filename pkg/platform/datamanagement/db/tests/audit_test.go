@@ -0,0 +1,39 @@
+package db_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement/db"
+)
+
+func TestAuditColumns(t *testing.T) {
+	now := time.Date(2025, 5, 1, 6, 0, 0, 0, time.UTC)
+	audit := db.DefaultAuditColumns
+	audit.Now = func() time.Time { return now }
+	ctx := db.ContextWithActor(context.Background(), "mes-sync")
+
+	columns, values := audit.Insert(ctx, []string{"order_id", "qty"}, []any{"A1", 3})
+	if want := []string{"order_id", "qty", "created_at", "updated_at", "updated_by"}; !slices.Equal(columns, want) {
+		t.Fatalf("expected columns %v, got %v", want, columns)
+	}
+	if want := []any{"A1", 3, now, now, "mes-sync"}; !slices.Equal(values, want) {
+		t.Fatalf("expected values %v, got %v", want, values)
+	}
+
+	// a creation time provided by the caller is kept, a missing actor is recorded as NULL
+	columns, values = audit.Insert(context.Background(), []string{"order_id", "CREATED_AT"}, []any{"A2", now.Add(-time.Hour)})
+	if want := []string{"order_id", "CREATED_AT", "updated_at", "updated_by"}; !slices.Equal(columns, want) {
+		t.Fatalf("expected columns %v, got %v", want, columns)
+	}
+	if values[3] != nil {
+		t.Fatalf("expected a NULL actor, got %v", values[3])
+	}
+
+	columns, _ = audit.Update(ctx, []string{"qty"}, []any{4})
+	if want := []string{"qty", "updated_at", "updated_by"}; !slices.Equal(columns, want) {
+		t.Fatalf("expected columns %v, got %v", want, columns)
+	}
+}
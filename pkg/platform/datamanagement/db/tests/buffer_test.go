@@ -0,0 +1,142 @@
+package db_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement/db"
+)
+
+type insertReading struct{}
+
+func (insertReading) Construct() string {
+	return "INSERT INTO readings (sensor, value, taken) VALUES (?, ?, ?)"
+}
+
+type rejectedInsert struct{}
+
+func (rejectedInsert) Construct() string {
+	return "INSERT INTO missing (sensor) VALUES (?)"
+}
+
+func newRemote(t *testing.T) *db.Database {
+	t.Helper()
+	remote, err := db.NewDatabase(db.DatabaseConfig{Driver: db.DriverSQLite, Address: filepath.Join(t.TempDir(), "remote.db")}, "remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { remote.Close() })
+	if _, err = remote.Exec("CREATE TABLE readings (id INTEGER PRIMARY KEY AUTOINCREMENT, sensor TEXT, value REAL, taken TIMESTAMP)"); err != nil {
+		t.Fatal(err)
+	}
+	return remote
+}
+
+func readings(t *testing.T, remote *db.Database) []string {
+	t.Helper()
+	rows, err := remote.Query("SELECT sensor FROM readings ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var sensors []string
+	for rows.Next() {
+		var s string
+		if err = rows.Scan(&s); err != nil {
+			t.Fatal(err)
+		}
+		sensors = append(sensors, s)
+	}
+	return sensors
+}
+
+func TestBufferStoreAndForward(t *testing.T) {
+	remote := newRemote(t)
+	buf, err := db.NewBuffer(remote, filepath.Join(t.TempDir(), "outbox.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Close()
+	now := time.Now()
+	if err = buf.Insert("r-1", insertReading{}, "s1", 1.5, now); err != nil {
+		t.Fatal(err)
+	}
+	// the network drops
+	if err = remote.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"r-2", "r-3", "r-3", "r-1"} {
+		if err = buf.Insert(key, insertReading{}, "s"+key[2:], 2.5, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := buf.Pending(); n != 2 {
+		t.Fatalf("expected 2 queued statements, got %d", n)
+	}
+	if err = remote.Open(); err != nil {
+		t.Fatal(err)
+	}
+	// a new insert keeps its place behind the queued ones
+	if err = buf.Insert("r-4", insertReading{}, "s4", 3.5, now); err != nil {
+		t.Fatal(err)
+	}
+	sent, err := buf.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 3 {
+		t.Fatalf("expected 3 replayed statements, got %d", sent)
+	}
+	got := readings(t, remote)
+	want := []string{"s1", "s2", "s3", "s4"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	if err = buf.Insert("r-2", insertReading{}, "s2", 2.5, now); err != nil {
+		t.Fatal(err)
+	}
+	if got = readings(t, remote); len(got) != 4 {
+		t.Fatalf("a delivered key was inserted again: %v", got)
+	}
+}
+
+func TestBufferDeadLetter(t *testing.T) {
+	remote := newRemote(t)
+	var replayErr error
+	buf, err := db.NewBuffer(remote, filepath.Join(t.TempDir(), "outbox.db"), db.WithReplayErrorHandler(func(err error) { replayErr = err }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Close()
+	if err = buf.Insert("", rejectedInsert{}, "s1"); err == nil {
+		t.Fatal("expected a rejected statement to fail while the remote is reachable")
+	}
+	remote.Close()
+	if err = buf.Insert("", rejectedInsert{}, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	if err = buf.Insert("", insertReading{}, "s2", 1.0, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err = buf.Insert("", insertReading{}, struct{}{}, 1.0, time.Now()); !errors.Is(err, db.ErrUnsupportedParam) {
+		t.Fatalf("expected ErrUnsupportedParam, got %v", err)
+	}
+	remote.Open()
+	sent, err := buf.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 || !errors.Is(replayErr, db.ErrReplayFailed) {
+		t.Fatalf("expected the rejected statement to be dead lettered; sent:%d;err:%v", sent, replayErr)
+	}
+	if n, _ := buf.Pending(); n != 0 {
+		t.Fatalf("expected an empty queue, got %d", n)
+	}
+}
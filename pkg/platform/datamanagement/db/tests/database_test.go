@@ -1,62 +1,106 @@
 package db_test
 
 import (
-	"fmt"
+	"database/sql"
+	"path/filepath"
 	"testing"
 
-	"github.com/ivanehh/go-boiler-lib/pkg/config"
 	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement/db"
 )
 
-// TODO: The test are broken; Rewrite them
+func memoryConfig(name string) db.DatabaseConfig {
+	return db.DatabaseConfig{Driver: db.DriverSQLite, Name: name, Address: db.SQLiteMemory}
+}
 
-func TestNewDb(t *testing.T) {
-	err := config.Load("/home/terzivan/projects/MDOD/DCS/dcs-gradec/config/cfg.yaml")
-	if err != nil {
-		t.Fatal(fmt.Errorf("failed to load config:%w", err))
+type createOrders struct{}
+
+func (createOrders) Construct() string {
+	return "CREATE TABLE IF NOT EXISTS orders (wo INTEGER PRIMARY KEY, plant TEXT NOT NULL)"
+}
+
+type insertOrder struct{}
+
+func (insertOrder) Construct() string {
+	return "INSERT INTO orders (wo, plant) VALUES (?, ?)"
+}
+
+type order struct {
+	Wonum int
+	Plant string
+}
+
+type getOrder struct {
+	result []order
+	err    error
+}
+
+func (q *getOrder) Construct() string {
+	return "SELECT wo, plant FROM orders WHERE wo = ?"
+}
+
+func (q *getOrder) Wrap(rows *sql.Rows) {
+	defer rows.Close()
+	for rows.Next() {
+		var o order
+		if q.err = rows.Scan(&o.Wonum, &o.Plant); q.err != nil {
+			return
+		}
+		q.result = append(q.result, o)
 	}
-	c := config.Provide()
-	_, err = db.NewDatabase(c, "plant")
+}
+
+func (q *getOrder) Unwrap() any {
+	return q.result
+}
+
+func TestNewDb(t *testing.T) {
+	_, err := db.NewDatabase(memoryConfig("newdb"), "plant")
 	if err != nil {
 		t.Fatal(err)
 	}
+	if _, err = db.NewDatabase(db.DatabaseConfig{Driver: db.DriverSQLite}, "plant"); err == nil {
+		t.Fatal("expected a sqlite config without address to be rejected")
+	}
 }
 
 func TestOpenCloseDB(t *testing.T) {
-	err := config.Load("/home/terzivan/projects/MDOD/DCS/dcs-gradec/config/cfg.yaml")
-	if err != nil {
-		t.Fatal(fmt.Errorf("failed to load config:%w", err))
-	}
-	c := config.Provide()
+	c := db.DatabaseConfig{Driver: db.DriverSQLite, Address: filepath.Join(t.TempDir(), "buffer.db")}
 	pdb, err := db.NewDatabase(c, "plant")
 	if err != nil {
 		t.Fatal(err)
 	}
+	if err = pdb.Close(); err != nil {
+		t.Fatal(err)
+	}
 	if err = pdb.Open(); err != nil {
 		t.Fatal(err)
 	}
+	if err = pdb.Ping(); err != nil {
+		t.Fatal(err)
+	}
 	if err = pdb.Close(); err != nil {
 		t.Fatal(err)
 	}
 }
 
 func TestDbExecute(t *testing.T) {
-	err := config.Load("/home/terzivan/projects/MDOD/DCS/dcs-gradec/config/cfg.yaml")
+	database, err := db.NewDatabase(memoryConfig("execute"), "plant")
 	if err != nil {
-		t.Fatal(fmt.Errorf("failed to load config:%w", err))
+		t.Fatal(err)
 	}
-	c := config.Provide()
-	database, err := db.NewDatabase(c, "plant")
-	if err != nil {
+	defer database.Close()
+	if _, err = database.ExecuteConstructor(createOrders{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = database.ExecuteConstructor(insertOrder{}, 900923406, "gradec"); err != nil {
 		t.Fatal(err)
 	}
-	q, err := database.Query(q.NewQuery[q.GetWorkorderMeta](), 900923406)
+	q, err := database.QueryWrappedValues(new(getOrder), 900923406)
 	if err != nil {
 		t.Fatal(err)
 	}
-	// if do, ok := q.Unwrap().(model.DigestedOrder); !ok {
-	// 	t.Fatal("failed to unwrap query")
-	// } else {
-	// 	fmt.Printf("do: %+v\n", do)
-	// }
+	orders, ok := q.Unwrap().([]order)
+	if !ok || len(orders) != 1 || orders[0].Plant != "gradec" {
+		t.Fatalf("unexpected query result: %+v", q.Unwrap())
+	}
 }
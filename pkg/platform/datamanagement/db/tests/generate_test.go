@@ -0,0 +1,65 @@
+package db_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement/db"
+)
+
+func TestGenerate(t *testing.T) {
+	pdb, err := db.NewDatabase(memoryConfig("generate"), "plant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = pdb.Exec(`CREATE TABLE work_orders (work_order_id INTEGER PRIMARY KEY, plant TEXT NOT NULL, qty REAL NOT NULL,
+		started DATETIME NOT NULL, note VARCHAR(200), created_at DATETIME DEFAULT CURRENT_TIMESTAMP)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = pdb.Exec(`CREATE TABLE plant_status (plant TEXT, line TEXT, state TEXT NOT NULL, PRIMARY KEY (plant, line))`); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = pdb.Generate(context.Background(), &out, db.GenerateConfig{
+		Package:    "plant",
+		Tables:     []string{"work_orders", "plant_status"},
+		InsertSkip: []string{"work_orders.created_at"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := out.String()
+	if _, err = parser.ParseFile(token.NewFileSet(), "accessors.go", src, 0); err != nil {
+		t.Fatalf("the generated source does not parse: %v\n%s", err, src)
+	}
+	for _, want := range []string{
+		"package plant",
+		"\t\"time\"\n",
+		"type WorkOrder struct {",
+		"WorkOrderID int64          `db:\"work_order_id\" df:\"work_order_id\"`",
+		"Note        sql.NullString `db:\"note\" df:\"note\"`",
+		"Started     time.Time      `db:\"started\" df:\"started\"`",
+		`INSERT INTO \"work_orders\" (\"work_order_id\", \"plant\", \"qty\", \"started\", \"note\") VALUES (?, ?, ?, ?, ?)`,
+		"func GetWorkOrder(ctx context.Context, d *db.Database, workOrderID int64) (WorkOrder, error) {",
+		"func ListWorkOrders(ctx context.Context, d *db.Database, where string, params ...any) ([]WorkOrder, error) {",
+		`UPDATE \"plant_status\" SET \"state\" = ? WHERE \"plant\" = ? AND \"line\" = ?", r.State, r.Plant, r.Line)`,
+		"func DeletePlantStatus(ctx context.Context, d *db.Database, plant string, line string) (sql.Result, error) {",
+		"func (q *PlantStatusQuery) Wrap(rows *sql.Rows) {",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("the generated source lacks %q:\n%s", want, src)
+		}
+	}
+
+	err = pdb.Generate(context.Background(), &out, db.GenerateConfig{Package: "plant", Tables: []string{"missing"}})
+	if !errors.Is(err, db.ErrUnknownTable) {
+		t.Fatalf("expected ErrUnknownTable, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package db_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement/db"
+)
+
+func TestQuoteIdent(t *testing.T) {
+	for _, c := range []struct {
+		driver string
+		name   string
+		want   string
+	}{
+		{"sqlserver", "dbo.orders", "[dbo].[orders]"},
+		{"postgres", "plant01.Orders", `"plant01"."Orders"`},
+		{"mysql", "line_4", "`line_4`"},
+		{"sqlite", "status", `"status"`},
+	} {
+		got, err := db.QuoteIdent(c.driver, c.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.want {
+			t.Fatalf("expected %s for %s on %s, got %s", c.want, c.name, c.driver, got)
+		}
+	}
+	if _, err := db.QuoteIdent("oracle", "orders"); !errors.Is(err, db.ErrUnknownDialect) {
+		t.Fatalf("expected ErrUnknownDialect, got %v", err)
+	}
+}
+
+func TestValidateIdentRejectsInjection(t *testing.T) {
+	for _, name := range []string{
+		"",
+		"orders; DROP TABLE orders",
+		"orders]--",
+		`orders" OR "1"="1`,
+		"1orders",
+		"dbo..orders",
+		"a.b.c.d",
+		"drop",
+		"orders\x00",
+	} {
+		if err := db.ValidateIdent(name); !errors.Is(err, db.ErrBadIdentifier) {
+			t.Fatalf("expected %q to be rejected, got %v", name, err)
+		}
+	}
+	if err := db.ValidateIdent("plant_gradec.work_orders"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQuotedIdentOnDatabase(t *testing.T) {
+	pdb := statusDatabase(t, "quoteident")
+	table, err := pdb.QuoteIdent("status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err = pdb.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 rows, got %d", n)
+	}
+}
@@ -0,0 +1,129 @@
+package db_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement/db"
+)
+
+type statusCode struct {
+	Label  string `db:"label"`
+	Closed bool
+}
+
+func statusDatabase(t *testing.T, name string) *db.Database {
+	t.Helper()
+	pdb, err := db.NewDatabase(memoryConfig(name), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pdb.Close() })
+	for _, stmt := range []string{
+		"CREATE TABLE status (code INTEGER PRIMARY KEY, label TEXT NOT NULL, closed INTEGER NOT NULL, sort INTEGER)",
+		"INSERT INTO status VALUES (10, 'released', 0, 1), (20, 'in progress', 0, 2), (90, 'closed', 1, 3)",
+	} {
+		if _, err = pdb.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return pdb
+}
+
+func TestLoadLookupStruct(t *testing.T) {
+	pdb := statusDatabase(t, "lookupstruct")
+	ctx := context.Background()
+	statuses, err := db.LoadLookup[statusCode](ctx, pdb, "SELECT code, label, closed, sort FROM status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := statuses.Get(ctx, "90"); !ok || s.Label != "closed" || !s.Closed {
+		t.Fatalf("unexpected status 90: %+v %v", s, ok)
+	}
+	if _, ok := statuses.Get(ctx, "30"); ok {
+		t.Fatal("expected no status 30")
+	}
+	if all := statuses.All(ctx); len(all) != 3 {
+		t.Fatalf("expected 3 statuses, got %v", all)
+	}
+}
+
+func TestLoadLookupRefreshesAfterTTL(t *testing.T) {
+	pdb := statusDatabase(t, "lookupttl")
+	ctx := context.Background()
+	var refreshErr error
+	labels, err := db.LoadLookup[string](ctx, pdb, "SELECT code, label FROM status WHERE code < ?",
+		db.WithLookupParams(50),
+		db.WithLookupTTL(20*time.Millisecond),
+		db.WithRefreshErrorHandler(func(err error) { refreshErr = err }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = pdb.Exec("UPDATE status SET label = 'started' WHERE code = 20"); err != nil {
+		t.Fatal(err)
+	}
+	if l, _ := labels.Get(ctx, "20"); l != "in progress" {
+		t.Fatalf("expected the cached label, got %q", l)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if l, _ := labels.Get(ctx, "20"); l != "started" {
+		t.Fatalf("expected the reloaded label, got %q", l)
+	}
+
+	// a failing reload keeps serving the last table
+	if _, err = pdb.Exec("DROP TABLE status"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if l, ok := labels.Get(ctx, "10"); !ok || l != "released" {
+		t.Fatalf("expected the stale label, got %q", l)
+	}
+	if refreshErr == nil {
+		t.Fatal("expected the refresh error to be reported")
+	}
+}
+
+func TestLoadLookupRejectsBadQueries(t *testing.T) {
+	pdb := statusDatabase(t, "lookupbad")
+	ctx := context.Background()
+	if _, err := db.LoadLookup[string](ctx, pdb, "SELECT code FROM status"); err == nil {
+		t.Fatal("expected a single column query to be rejected")
+	}
+	if _, err := db.LoadLookup[string](ctx, pdb, "SELECT code, label, sort FROM status"); err == nil {
+		t.Fatal("expected a scalar lookup with several value columns to be rejected")
+	}
+}
+
+func TestLoadLookupSharesCache(t *testing.T) {
+	pdb := statusDatabase(t, "lookupcache")
+	ctx := context.Background()
+	cache, err := datamanagement.NewBoltCache[string, map[string]statusCode](filepath.Join(t.TempDir(), "lookups.db"), "lookups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	const query = "SELECT code, label, closed FROM status"
+	if _, err = db.LoadLookup[statusCode](ctx, pdb, query, db.WithLookupCache(cache)); err != nil {
+		t.Fatal(err)
+	}
+
+	// a later load, e.g. after a restart, is served from the cache while the table is fresh
+	if _, err = pdb.Exec("DROP TABLE status"); err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := db.LoadLookup[statusCode](ctx, pdb, query, db.WithLookupCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := statuses.Get(ctx, "20"); !ok || s.Label != "in progress" {
+		t.Fatalf("unexpected cached status 20: %+v %v", s, ok)
+	}
+
+	if _, err = db.LoadLookup[string](ctx, pdb, query, db.WithLookupCache(cache)); err == nil {
+		t.Fatal("expected a cache of another value type to be rejected")
+	}
+}
@@ -0,0 +1,81 @@
+package db_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement/db"
+)
+
+type orderParams struct {
+	Wonum int    `param:"wo"`
+	Plant string `param:"plant"`
+	Note  string
+}
+
+type insertOrderNamed struct{}
+
+func (insertOrderNamed) Construct() string {
+	return "INSERT INTO orders (plant, wo) VALUES (:plant, @wo)"
+}
+
+type auditedOrderParams struct {
+	orderParams
+	Skipped string `param:"-"`
+}
+
+func TestBindParamsPositional(t *testing.T) {
+	args, err := db.BindParams(insertOrder{}, &auditedOrderParams{orderParams: orderParams{Wonum: 900923406, Plant: "gradec"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 2 || args[0] != 900923406 || args[1] != "gradec" {
+		t.Fatalf("unexpected positional parameters %v", args)
+	}
+}
+
+func TestBindParamsNamed(t *testing.T) {
+	database, err := db.NewDatabase(memoryConfig("bindparams"), "plant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if _, err = database.ExecuteConstructor(createOrders{}); err != nil {
+		t.Fatal(err)
+	}
+	args, err := db.BindParams(insertOrderNamed{}, orderParams{Wonum: 900923407, Plant: "kranj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if named, ok := args[0].(sql.NamedArg); !ok || named.Name != "wo" {
+		t.Fatalf("expected named parameters in declaration order, got %v", args)
+	}
+	if _, err = database.ExecuteConstructor(insertOrderNamed{}, args...); err != nil {
+		t.Fatal(err)
+	}
+	q, err := database.QueryWrappedValues(new(getOrder), 900923407)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orders := q.Unwrap().([]order); len(orders) != 1 || orders[0].Plant != "kranj" {
+		t.Fatalf("unexpected query result: %+v", orders)
+	}
+}
+
+type partialNamedQuery struct{}
+
+func (partialNamedQuery) Construct() string {
+	return "SELECT wo FROM orders WHERE plant = @plant"
+}
+
+func TestBindParamsErrors(t *testing.T) {
+	if _, err := db.BindParams(partialNamedQuery{}, orderParams{}); !errors.Is(err, db.ErrUnboundParams) {
+		t.Fatalf("expected ErrUnboundParams, got %v", err)
+	}
+	for _, params := range []any{42, struct{ Plant string }{}, nil} {
+		if _, err := db.BindParams(insertOrder{}, params); !errors.Is(err, db.ErrBadParams) {
+			t.Fatalf("expected ErrBadParams for %#v, got %v", params, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement/db"
+)
+
+var migrations = fstest.MapFS{
+	"001_orders.sql": {Data: []byte(`
+-- orders placed by the MES
+CREATE TABLE IF NOT EXISTS orders (
+	wo      INTEGER PRIMARY KEY,
+	plant   VARCHAR(20) NOT NULL DEFAULT 'gradec',
+	created TIMESTAMP,
+	CONSTRAINT plant_known CHECK (plant <> '')
+);
+CREATE INDEX orders_plant ON orders (plant);`)},
+	"002_readings.sql": {Data: []byte(`
+CREATE TABLE readings (id INTEGER PRIMARY KEY, wo INTEGER REFERENCES orders(wo), value REAL);
+ALTER TABLE orders ADD COLUMN line TEXT;
+/* the old audit table is gone */
+CREATE TABLE audit (id INTEGER);
+DROP TABLE audit;`)},
+	"002_readings.down.sql": {Data: []byte(`DROP TABLE readings;`)},
+}
+
+func applyMigrations(t *testing.T, database *db.Database) {
+	t.Helper()
+	for _, name := range []string{"001_orders.sql", "002_readings.sql"} {
+		if _, err := database.Exec(string(migrations[name].Data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSchemaDiffClean(t *testing.T) {
+	database, err := db.NewDatabase(memoryConfig("schema-clean"), "plant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	applyMigrations(t, database)
+	drift, err := database.SchemaDiff(context.Background(), migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !drift.Empty() {
+		t.Fatalf("expected no drift, got:\n%s", drift)
+	}
+}
+
+func TestSchemaDiffHotfix(t *testing.T) {
+	database, err := db.NewDatabase(memoryConfig("schema-hotfix"), "plant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	applyMigrations(t, database)
+	// manual hotfixes applied directly on the plant database
+	for _, stmt := range []string{
+		"ALTER TABLE orders ADD COLUMN operator TEXT",
+		"DROP INDEX orders_plant",
+		"CREATE TABLE tmp_fix (id INTEGER)",
+		"CREATE TABLE schema_migrations (version INTEGER)",
+		"ALTER TABLE readings DROP COLUMN value",
+		"ALTER TABLE readings ADD COLUMN value TEXT",
+		"CREATE INDEX readings_value ON readings (value)",
+	} {
+		if _, err = database.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	drift, err := database.SchemaDiff(context.Background(), migrations, db.WithIgnoredTables("schema_migrations"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `extra table tmp_fix
+extra column orders.operator
+column readings.value is text, migrations declare real
+missing index orders_plant
+extra index readings_value`
+	if got := drift.String(); got != want {
+		t.Fatalf("unexpected drift:\n%s\nwant:\n%s", got, want)
+	}
+}
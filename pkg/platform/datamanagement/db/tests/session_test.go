@@ -0,0 +1,72 @@
+package db_test
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement/db"
+)
+
+func TestSessionPerMode(t *testing.T) {
+	var c db.DatabaseConfig
+	err := json.Unmarshal([]byte(`{
+		"driver": "sqlite",
+		"session": {"prod": ["PRAGMA query_only = 1"], "stage": ["PRAGMA busy_timeout = 100"]}
+	}`), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Address = filepath.Join(t.TempDir(), "plant.db")
+
+	stage, err := db.NewDatabase(c, "plant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stage.Close()
+	if _, err = stage.Exec("CREATE TABLE orders (wo INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+	var timeout int
+	if err = stage.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil || timeout != 100 {
+		t.Fatalf("expected the stage session busy timeout, got %d: %v", timeout, err)
+	}
+
+	c.Mode = db.Prod
+	prod, err := db.NewDatabase(c, "plant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prod.Close()
+	if _, err = prod.Exec("INSERT INTO orders (wo) VALUES (1)"); err == nil {
+		t.Fatal("expected the prod session to be read-only")
+	}
+	var n int
+	if err = prod.QueryRow("SELECT count(*) FROM orders").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessionErrors(t *testing.T) {
+	c := memoryConfig("session")
+	c.Mode = db.Prod
+	c.Session = map[db.DBMode][]string{db.Prod: {"SET LOCK_TIMEOUT 5000"}}
+	database, err := db.NewDatabase(c, "plant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if err = database.Ping(); err == nil {
+		t.Fatal("expected the unsupported session statement to fail the connection")
+	}
+
+	c.Mode = db.DBMode(7)
+	if _, err = db.NewDatabase(c, "plant"); !errors.Is(err, db.ErrBadConfig) {
+		t.Fatalf("expected ErrBadConfig for an unknown mode, got %v", err)
+	}
+	var mode db.DBMode
+	if err = json.Unmarshal([]byte(`"production"`), &mode); !errors.Is(err, db.ErrBadConfig) {
+		t.Fatalf("expected ErrBadConfig for an unknown mode name, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package datamanagement
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"slices"
+)
+
+type fingerprintConfig struct {
+	unordered bool
+}
+
+type FingerprintOpt func(*fingerprintConfig)
+
+// IgnoreRowOrder makes the fingerprint independent of the row order, e.g. for exports the source system sorts differently between runs;
+// duplicated rows still count
+func IgnoreRowOrder() FingerprintOpt {
+	return func(c *fingerprintConfig) {
+		c.unordered = true
+	}
+}
+
+// Fingerprint returns the hex sha256 digest of the header and the rows; equal dataframes, such as a file and its parsed upload,
+// have equal fingerprints. Values are length prefixed, so moving a separator between values changes the fingerprint
+func (d *Dataframe) Fingerprint(opts ...FingerprintOpt) string {
+	c := new(fingerprintConfig)
+	for _, opt := range opts {
+		opt(c)
+	}
+	h := sha256.New()
+	h.Write([]byte("dataframe-v1"))
+	writeRecord(h, d.Header())
+	if !c.unordered {
+		for _, r := range d.Rows {
+			writeRecord(h, r)
+		}
+		return hex.EncodeToString(h.Sum(nil))
+	}
+	digests := make([][]byte, len(d.Rows))
+	for i, r := range d.Rows {
+		rh := sha256.New()
+		writeRecord(rh, r)
+		digests[i] = rh.Sum(nil)
+	}
+	slices.SortFunc(digests, bytes.Compare)
+	for _, digest := range digests {
+		h.Write(digest)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeRecord writes the number of values followed by every value with its length
+func writeRecord(h hash.Hash, values []string) {
+	var n [binary.MaxVarintLen64]byte
+	h.Write(n[:binary.PutUvarint(n[:], uint64(len(values)))])
+	for _, v := range values {
+		h.Write(n[:binary.PutUvarint(n[:], uint64(len(v)))])
+		h.Write([]byte(v))
+	}
+}
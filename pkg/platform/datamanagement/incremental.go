@@ -0,0 +1,265 @@
+package datamanagement
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrNoNewRows = errors.New("no new rows since the last checkpoint")
+
+// fingerprintWindow is how much of the start and of the end of the processed part of a file is hashed
+// to tell an appended file from a rewritten one
+const fingerprintWindow = 4 << 10
+
+// FileCheckpointSource returns the checkpoint source under which the progress of a file is recorded
+func FileCheckpointSource(path string) string {
+	return "file:" + filepath.Clean(path)
+}
+
+type fileMark struct {
+	source string
+	mark   Mark
+	// rewind is set when the file was rewritten and is processed from the start again
+	rewind bool
+}
+
+// NewDataframeFromFilesSince builds a dataframe from the rows the files gained since the checkpoint recorded them:
+// unchanged files are skipped, rows appended to a csv since the last run are parsed without the rows before them,
+// and rewritten csv files as well as changed spreadsheets are parsed whole. As with NewDataframeFromFiles the header
+// of the first file is the first row, so the columns come from the provided opts, e.g. WithInterpretedColumns.
+// The returned commit records the new progress of the files; call it once the rows were stored so a failed run
+// is repeated. ErrNoNewRows is returned together with commit when no file has new rows
+func NewDataframeFromFilesSince(files []string, checkpoint *Checkpoint, cleaner func(Record) Record, opts ...DataframeOpt) (*Dataframe, func() error, error) {
+	var header Record
+	var records []Record
+	var marks []fileMark
+	for _, f := range files {
+		fileHeader, rows, fm, err := rowsSince(f, checkpoint)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w; file:%s", err, f)
+		}
+		if fm == nil {
+			continue
+		}
+		marks = append(marks, *fm)
+		if len(rows) == 0 {
+			continue
+		}
+		if header == nil {
+			header = fileHeader
+			records = append(records, header)
+		} else if !slices.Equal(header, fileHeader) {
+			return nil, nil, &HeaderMismatchErr{Original: header, Mismatch: fileHeader}
+		}
+		records = append(records, rows...)
+	}
+
+	commit := func() error {
+		for _, fm := range marks {
+			var err error
+			if fm.rewind {
+				err = checkpoint.Rewind(fm.source, fm.mark)
+			} else {
+				err = checkpoint.Advance(fm.source, fm.mark)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(records) == 0 {
+		return nil, commit, ErrNoNewRows
+	}
+	if cleaner == nil {
+		cleaner = func(r Record) Record { return r }
+	}
+	df, err := NewDataframeFromRecords(records, cleaner, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return df, commit, nil
+}
+
+// rowsSince returns the header and the new rows of a file with the mark to record for them; a nil mark means the file is unchanged
+func rowsSince(path string, checkpoint *Checkpoint) (Record, []Record, *fileMark, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	source := FileCheckpointSource(path)
+	last, recorded, err := checkpoint.Last(source)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	offset, fingerprint, valid := parseFileMark(last)
+	valid = recorded && valid
+	if valid && offset == info.Size() && last.Time.Equal(info.ModTime()) {
+		return nil, nil, nil, nil
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		rows, err := sheetRows(path)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		fm := &fileMark{source: source, mark: newFileMark(info.Size(), "", info), rewind: true}
+		if len(rows) == 0 {
+			return nil, nil, fm, nil
+		}
+		return rows[0], rows[1:], fm, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer f.Close()
+
+	var start int64
+	if valid && offset <= info.Size() {
+		fp, err := prefixFingerprint(f, offset)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if fp == fingerprint {
+			start = offset
+		}
+	}
+
+	// only complete lines are taken; a line still being written is picked up by the next run
+	content, err := io.ReadAll(io.NewSectionReader(f, start, info.Size()-start))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	content = content[:bytes.LastIndexByte(content, '\n')+1]
+	end := start + int64(len(content))
+
+	rows, err := csvRows(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var header Record
+	if start == 0 {
+		if len(rows) > 0 {
+			header, rows = rows[0], rows[1:]
+		}
+	} else {
+		if header, err = firstCSVRow(io.NewSectionReader(f, 0, start)); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	fp, err := prefixFingerprint(f, end)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return header, rows, &fileMark{source: source, mark: newFileMark(end, fp, info), rewind: start == 0}, nil
+}
+
+func newFileMark(offset int64, fingerprint string, info os.FileInfo) Mark {
+	return Mark{ID: strconv.FormatInt(offset, 10) + ":" + fingerprint, Time: info.ModTime()}
+}
+
+// parseFileMark splits the ID of a file mark into the processed offset and the fingerprint of the processed part
+func parseFileMark(m Mark) (int64, string, bool) {
+	off, fp, ok := strings.Cut(m.ID, ":")
+	if !ok {
+		return 0, "", false
+	}
+	offset, err := strconv.ParseInt(off, 10, 64)
+	return offset, fp, err == nil
+}
+
+// prefixFingerprint hashes the start and the end of the first n bytes of r
+func prefixFingerprint(r io.ReaderAt, n int64) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n", n)
+	head := min(n, fingerprintWindow)
+	if _, err := io.Copy(h, io.NewSectionReader(r, 0, head)); err != nil {
+		return "", err
+	}
+	tail := max(head, n-fingerprintWindow)
+	if _, err := io.Copy(h, io.NewSectionReader(r, tail, n-tail)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// csvRows parses csv content, skipping the rows without any value
+func csvRows(r io.Reader) ([]Record, error) {
+	cr := newCSVReader(r)
+	var rows []Record
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hasValue(row) {
+			rows = append(rows, row)
+		}
+	}
+}
+
+// firstCSVRow returns the first row with a value, i.e. the header
+func firstCSVRow(r io.Reader) (Record, error) {
+	cr := newCSVReader(r)
+	for {
+		row, err := cr.Read()
+		if err != nil {
+			return nil, err
+		}
+		if hasValue(row) {
+			return row, nil
+		}
+	}
+}
+
+func newCSVReader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	return cr
+}
+
+func hasValue(row []string) bool {
+	return slices.ContainsFunc(row, func(v string) bool { return len(v) > 0 })
+}
+
+// sheetRows reads the rows of the first sheet of a spreadsheet or of a compressed file, skipping the rows without any value
+func sheetRows(path string) ([]Record, error) {
+	source, err := openSource(path)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+	sheets, err := source.List()
+	if err != nil || len(sheets) == 0 {
+		return nil, err
+	}
+	data, err := source.Get(sheets[0])
+	if err != nil {
+		return nil, err
+	}
+	var rows []Record
+	for data.Next() {
+		row := data.Strings()
+		if hasValue(row) {
+			rows = append(rows, slices.Clone(row))
+		}
+	}
+	return rows, nil
+}
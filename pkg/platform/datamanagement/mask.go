@@ -0,0 +1,67 @@
+package datamanagement
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Masker replaces a single value of a masked column
+type Masker func(v string) string
+
+// HashMask replaces values with their hex sha256 digest; equal values stay equal so masked columns can still be joined on.
+// Prefer SaltedHashMask for low-entropy values such as names, which an unsalted digest does not protect against lookups
+func HashMask(v string) string {
+	if len(v) == 0 {
+		return v
+	}
+	sum := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(sum[:])
+}
+
+// SaltedHashMask is HashMask keyed with a secret salt (HMAC-SHA256)
+func SaltedHashMask(salt []byte) Masker {
+	return func(v string) string {
+		if len(v) == 0 {
+			return v
+		}
+		mac := hmac.New(sha256.New, salt)
+		mac.Write([]byte(v))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+}
+
+// PartialMask keeps the first keepStart and the last keepEnd characters and replaces the rest with '*';
+// values too short to hide anything are masked completely
+func PartialMask(keepStart, keepEnd int) Masker {
+	return func(v string) string {
+		r := []rune(v)
+		if keepStart+keepEnd >= len(r) {
+			return strings.Repeat("*", len(r))
+		}
+		return string(r[:keepStart]) + strings.Repeat("*", len(r)-keepStart-keepEnd) + string(r[len(r)-keepEnd:])
+	}
+}
+
+// ConstantMask replaces every value with c
+func ConstantMask(c string) Masker {
+	return func(string) string {
+		return c
+	}
+}
+
+// Mask applies m to every value of the column in place
+func (d *Dataframe) Mask(column string, m Masker) error {
+	idx, ok := d.columnIdx(column)
+	if !ok {
+		return &ColumnsNotFoundErr{Available: d.Header(), Required: []string{column}}
+	}
+	d.InvalidateStats()
+	for _, r := range d.Rows {
+		if idx < len(r) {
+			r[idx] = m(r[idx])
+		}
+	}
+	return nil
+}
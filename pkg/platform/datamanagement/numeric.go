@@ -0,0 +1,116 @@
+package datamanagement
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var ErrBadNumber = errors.New("the value is not a number in the provided format")
+
+// NumberFormat describes the separators of formatted numbers
+type NumberFormat struct {
+	Decimal   rune
+	Thousands rune
+}
+
+var (
+	// EuropeanFormat reads "1.234,56"
+	EuropeanFormat = NumberFormat{Decimal: ',', Thousands: '.'}
+	// EnglishFormat reads "1,234.56"
+	EnglishFormat = NumberFormat{Decimal: '.', Thousands: ','}
+)
+
+// ParseNumber reads a formatted number with an optional unit suffix ("1.234,56", "85%", "12 kg", "-3,5 °C");
+// the unit is returned as written, the value is not scaled (85% is 85). Spaces, non-breaking spaces and apostrophes
+// are accepted as thousands separators in every format
+func ParseNumber(s string, f NumberFormat) (value float64, unit string, err error) {
+	s = strings.TrimSpace(s)
+	// the unit is everything after the last digit
+	end := strings.LastIndexFunc(s, unicode.IsDigit)
+	if end < 0 {
+		return 0, "", fmt.Errorf("%w; value:%q", ErrBadNumber, s)
+	}
+	num, unit := s[:end+1], strings.TrimSpace(s[end+1:])
+	var b strings.Builder
+	decimals := 0
+	for i, r := range num {
+		switch {
+		case unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == f.Decimal:
+			decimals++
+			b.WriteByte('.')
+		case r == f.Thousands, r == ' ', r == '\u00a0', r == '\u202f', r == '\'':
+		case (r == '-' || r == '+') && i == 0:
+			b.WriteRune(r)
+		default:
+			return 0, "", fmt.Errorf("%w; value:%q", ErrBadNumber, s)
+		}
+	}
+	if decimals > 1 {
+		return 0, "", fmt.Errorf("%w; value:%q", ErrBadNumber, s)
+	}
+	value, err = strconv.ParseFloat(b.String(), 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("%w; value:%q", ErrBadNumber, s)
+	}
+	return value, unit, nil
+}
+
+// NormalizeNumbers rewrites the column in plain strconv form ("1234.56") so DfRowsAsStructList can parse it;
+// when unitColumn is not empty the units are moved to that column, which is added if needed.
+// Empty cells are left empty; the dataframe is unchanged if any cell fails to parse
+func (d *Dataframe) NormalizeNumbers(column string, f NumberFormat, unitColumn string) error {
+	idx, ok := d.columnIdx(column)
+	if !ok {
+		return &ColumnsNotFoundErr{Available: d.Header(), Required: []string{column}}
+	}
+	values := make([]string, len(d.Rows))
+	units := make([]string, len(d.Rows))
+	for i, r := range d.Rows {
+		if idx >= len(r) || len(strings.TrimSpace(r[idx])) == 0 {
+			continue
+		}
+		v, u, err := ParseNumber(r[idx], f)
+		if err != nil {
+			return fmt.Errorf("%w;column:%s;row:%d", err, column, i)
+		}
+		values[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		units[i] = u
+	}
+	d.InvalidateStats()
+	uidx := -1
+	if len(unitColumn) != 0 {
+		if uidx, ok = d.columnIdx(unitColumn); !ok {
+			uidx = len(d.Columns)
+			d.Columns = append(d.Columns, Column{name: strings.ToLower(strings.ReplaceAll(unitColumn, " ", "")), idx: uidx})
+		}
+	}
+	for i, r := range d.Rows {
+		if idx < len(r) {
+			r[idx] = values[i]
+		}
+		if uidx >= 0 {
+			for len(r) <= uidx {
+				r = append(r, "")
+			}
+			r[uidx] = units[i]
+			d.Rows[i] = r
+		}
+	}
+	return nil
+}
+
+// columnIdx finds a column by name, ignoring case and spaces like the column interpretation does
+func (d *Dataframe) columnIdx(name string) (int, bool) {
+	name = strings.ReplaceAll(name, " ", "")
+	for _, c := range d.Columns {
+		if strings.EqualFold(c.name, name) {
+			return c.idx, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,166 @@
+package datamanagement
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RowRepair is what happens to a row with another number of cells than the header
+type RowRepair int
+
+const (
+	// RowKeep loads the row as it is
+	RowKeep RowRepair = iota
+	// RowFix pads a short row with empty cells and truncates a long row to the width of the header
+	RowFix
+	// RowReject leaves the row out and lists it in the load report
+	RowReject
+)
+
+// Repair selects how NewDataframeFromFilesWithRepair deals with malformed files instead of failing the whole load
+type Repair struct {
+	// ShortRows is applied to rows with fewer cells than the header
+	ShortRows RowRepair
+	// LongRows is applied to rows with more cells than the header
+	LongRows RowRepair
+	// SkipMismatchedFiles leaves out the files whose header differs from the header of the first file
+	SkipMismatchedFiles bool
+}
+
+// RejectedRow is a row left out of the dataframe
+type RejectedRow struct {
+	// Row is the position of the row among the rows read from the file, the header being row 1
+	Row    int
+	Cells  Record
+	Reason string
+}
+
+// FileReport lists the repairs made to a file
+type FileReport struct {
+	Padded    int
+	Truncated int
+	Rejected  []RejectedRow
+	// Skipped is set when the whole file was left out
+	Skipped error
+}
+
+// LoadReport maps the files that needed repairs to their report
+type LoadReport map[string]*FileReport
+
+// Clean reports whether no file needed a repair
+func (lr LoadReport) Clean() bool {
+	return len(lr) == 0
+}
+
+// Files returns the files that needed repairs in order
+func (lr LoadReport) Files() []string {
+	return slices.Sorted(maps.Keys(lr))
+}
+
+type loadRepair struct {
+	Repair
+	report LoadReport
+}
+
+func (lr *loadRepair) file(path string) *FileReport {
+	fr, ok := lr.report[path]
+	if !ok {
+		fr = new(FileReport)
+		lr.report[path] = fr
+	}
+	return fr
+}
+
+// skipFile records a file with a mismatched header and reports whether it is to be left out
+func (lr *loadRepair) skipFile(path string, head, found Record) bool {
+	if lr == nil || !lr.SkipMismatchedFiles {
+		return false
+	}
+	lr.file(path).Skipped = &HeaderMismatchErr{Original: head, Mismatch: found}
+	return true
+}
+
+// repairRows repairs the rows read from the file, rows[start:], against the width of the first row, i.e. the header
+func (lr *loadRepair) repairRows(path string, rows []Record, start int) []Record {
+	if lr == nil || len(rows) == 0 {
+		return rows
+	}
+	width := len(rows[0])
+	kept := rows[:start]
+	// the header of the first file is among its rows, the header of the other files was consumed already
+	first := 2
+	for i, row := range rows[start:] {
+		if start == 0 && i == 0 {
+			kept = append(kept, row)
+			first = 1
+			continue
+		}
+		strategy, reason := RowKeep, ""
+		switch {
+		case len(row) < width:
+			strategy, reason = lr.ShortRows, fmt.Sprintf("%d of %d cells", len(row), width)
+		case len(row) > width:
+			strategy, reason = lr.LongRows, fmt.Sprintf("%d of %d cells", len(row), width)
+		}
+		switch strategy {
+		case RowFix:
+			if len(row) < width {
+				row = append(slices.Clone(row), make(Record, width-len(row))...)
+				lr.file(path).Padded++
+			} else {
+				row = row[:width]
+				lr.file(path).Truncated++
+			}
+		case RowReject:
+			fr := lr.file(path)
+			fr.Rejected = append(fr.Rejected, RejectedRow{Row: i + first, Cells: row, Reason: reason})
+			continue
+		}
+		kept = append(kept, row)
+	}
+	return kept
+}
+
+// NewDataframeFromFilesWithRepair loads the files as NewDataframeFromFiles does, repairing malformed rows and files
+// according to repair; the report lists what was repaired per file
+func NewDataframeFromFilesWithRepair(filesPaths []string, cleaner func(Record) Record, repair Repair, opts ...DataframeOpt) (*Dataframe, LoadReport, error) {
+	lr := &loadRepair{Repair: repair, report: make(LoadReport)}
+	df, err := newDataframeFromFiles(filesPaths, cleaner, lr, opts)
+	if err != nil {
+		return nil, lr.report, err
+	}
+	return df, lr.report, nil
+}
+
+// uniqueColumnNames gives repeated column names a deterministic suffix: the second "qty" becomes "qty_2", the third "qty_3",
+// skipping suffixes that are already taken by another column
+func uniqueColumnNames(columns []Column) {
+	taken := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		taken[c.name] = true
+	}
+	seen := make(map[string]int, len(columns))
+	for i, c := range columns {
+		seen[c.name]++
+		if seen[c.name] == 1 {
+			continue
+		}
+		n := seen[c.name]
+		name := c.name + "_" + strconv.Itoa(n)
+		for taken[name] {
+			n++
+			name = c.name + "_" + strconv.Itoa(n)
+		}
+		seen[c.name] = n
+		taken[name] = true
+		columns[i].name = name
+	}
+}
+
+// columnName normalizes a header cell into a column name
+func columnName(cell string) string {
+	return strings.ToLower(strings.ReplaceAll(cell, " ", ""))
+}
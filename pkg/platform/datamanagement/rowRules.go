@@ -0,0 +1,221 @@
+package datamanagement
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrBadRule = errors.New("the row rule can not be compiled")
+
+// RowRule is a constraint across the cells of a row, e.g. "end_time after start_time"
+type RowRule struct {
+	Name    string
+	columns []string
+	check   func(cells map[string]string) error
+}
+
+// Columns returns the columns the rule reads
+func (r RowRule) Columns() []string {
+	return slices.Clone(r.columns)
+}
+
+// FuncRule checks the cells of the columns, keyed by the names given, with a Go function; a returned error is a violation
+func FuncRule(name string, columns []string, check func(cells map[string]string) error) RowRule {
+	return RowRule{Name: name, columns: slices.Clone(columns), check: check}
+}
+
+// RowRuleSpec is the declarative form of a rule; Check and When are expressions over the columns of a row such as
+// "qty_good + qty_scrap == qty_total" or "end_time after start_time".
+// Expressions combine columns, numbers, 'quoted text', true and false with + - * /, == != < <= > >=,
+// after and before for times, and and or not; column names with other characters are quoted in backticks.
+// Cells are compared as numbers when both sides are numbers, as times (RuleTimeLayouts) when both are times and as
+// text otherwise; an empty cell is a violation unless it is compared with a quoted text, such as the empty text
+type RowRuleSpec struct {
+	Name  string `yaml:"name"`
+	Check string `yaml:"check"`
+	// When restricts the rule to the rows the condition holds for
+	When string `yaml:"when"`
+	// Message replaces the default violation message, the failed check
+	Message string `yaml:"message"`
+}
+
+// CompileRowRule parses the expressions of a rule specification
+func CompileRowRule(spec RowRuleSpec) (RowRule, error) {
+	check, columns, err := parseRuleExpr(spec.Check)
+	if err != nil {
+		return RowRule{}, fmt.Errorf("%w; rule:%s", err, spec.Name)
+	}
+	var when exprNode
+	if len(spec.When) != 0 {
+		var whenColumns []string
+		when, whenColumns, err = parseRuleExpr(spec.When)
+		if err != nil {
+			return RowRule{}, fmt.Errorf("%w; rule:%s", err, spec.Name)
+		}
+		for _, c := range whenColumns {
+			if !slices.Contains(columns, c) {
+				columns = append(columns, c)
+			}
+		}
+	}
+	name := spec.Name
+	if len(name) == 0 {
+		name = spec.Check
+	}
+	return RowRule{Name: name, columns: columns, check: func(cells map[string]string) error {
+		if when != nil {
+			applies, err := when.eval(cells)
+			if err != nil {
+				return fmt.Errorf("when %s: %w", spec.When, err)
+			}
+			if !applies.b {
+				return nil
+			}
+		}
+		ok, err := check.eval(cells)
+		switch {
+		case err != nil && len(spec.Message) != 0:
+			return fmt.Errorf("%s: %w", spec.Message, err)
+		case err != nil:
+			return err
+		case ok.b:
+			return nil
+		case len(spec.Message) != 0:
+			return errors.New(spec.Message)
+		}
+		return fmt.Errorf("failed %s", spec.Check)
+	}}, nil
+}
+
+// MustCompileRowRule is CompileRowRule for rules known to be valid; it panics on invalid expressions
+func MustCompileRowRule(spec RowRuleSpec) RowRule {
+	rule, err := CompileRowRule(spec)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// ReadRowRules compiles the rules of a yaml rule file with a top level "rules" list of RowRuleSpec
+func ReadRowRules(r io.Reader) ([]RowRule, error) {
+	var file struct {
+		Rules []RowRuleSpec `yaml:"rules"`
+	}
+	if err := yaml.NewDecoder(r).Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w; %w", ErrBadRule, err)
+	}
+	rules := make([]RowRule, 0, len(file.Rules))
+	for _, spec := range file.Rules {
+		rule, err := CompileRowRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// LoadRowRules reads the rule file at path, see ReadRowRules
+func LoadRowRules(path string) ([]RowRule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadRowRules(f)
+}
+
+// RowViolation is a rule broken by a row
+type RowViolation struct {
+	Rule    string
+	Message string
+	// Cells holds the cells of the columns the rule reads
+	Cells map[string]string
+}
+
+// RowReport lists the rules broken by a row
+type RowReport struct {
+	// Row is the index of the row in Dataframe.Rows
+	Row        int
+	Violations []RowViolation
+}
+
+// ValidationReport lists the rows that break at least one rule in row order
+type ValidationReport struct {
+	Checked int
+	Rows    []RowReport
+}
+
+// Clean reports whether every row passed every rule
+func (vr ValidationReport) Clean() bool {
+	return len(vr.Rows) == 0
+}
+
+// ByRule counts the violations of every rule
+func (vr ValidationReport) ByRule() map[string]int {
+	counts := make(map[string]int)
+	for _, row := range vr.Rows {
+		for _, v := range row.Violations {
+			counts[v.Rule]++
+		}
+	}
+	return counts
+}
+
+// Invalid returns the indexes of the rows that break at least one rule
+func (vr ValidationReport) Invalid() []int {
+	rows := make([]int, len(vr.Rows))
+	for i, r := range vr.Rows {
+		rows[i] = r.Row
+	}
+	return rows
+}
+
+// ValidateRows checks every row against the rules; columns are matched like Get matches them and a rule reading
+// a column the dataframe does not have fails the whole validation with a *ColumnsNotFoundErr
+func (d *Dataframe) ValidateRows(rules ...RowRule) (ValidationReport, error) {
+	idx := make(map[string]int)
+	var missing []string
+	for _, rule := range rules {
+		for _, c := range rule.columns {
+			i, ok := d.columnIdx(c)
+			if !ok {
+				if !slices.Contains(missing, c) {
+					missing = append(missing, c)
+				}
+				continue
+			}
+			idx[c] = i
+		}
+	}
+	if len(missing) != 0 {
+		return ValidationReport{}, &ColumnsNotFoundErr{Available: d.Header(), Required: missing}
+	}
+
+	report := ValidationReport{Checked: len(d.Rows)}
+	for row, record := range d.Rows {
+		var violations []RowViolation
+		for _, rule := range rules {
+			cells := make(map[string]string, len(rule.columns))
+			for _, c := range rule.columns {
+				cells[c] = ""
+				if i := idx[c]; i < len(record) {
+					cells[c] = record[i]
+				}
+			}
+			if err := rule.check(maps.Clone(cells)); err != nil {
+				violations = append(violations, RowViolation{Rule: rule.Name, Message: err.Error(), Cells: cells})
+			}
+		}
+		if len(violations) != 0 {
+			report.Rows = append(report.Rows, RowReport{Row: row, Violations: violations})
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,218 @@
+package datamanagement
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// DefaultChunkBytes is the amount of encoded rows buffered before a chunk is flushed
+const DefaultChunkBytes = 4 << 20
+
+var (
+	ErrRowWidth     = errors.New("the row does not have as many values as the header")
+	ErrWriterClosed = errors.New("the row writer is closed")
+)
+
+// RowWriter receives rows incrementally; Close flushes the remaining rows and completes the output
+type RowWriter interface {
+	WriteRow(r Record) error
+	Flush() error
+	Close() error
+}
+
+// ChunkSink receives the chunks of a streamed file in order; Commit completes the file and Abort discards it.
+// A chunk is only valid during the WriteChunk call. azure.BlockBlobSink stages the chunks as the blocks of a block blob
+type ChunkSink interface {
+	WriteChunk(ctx context.Context, chunk []byte) error
+	Commit(ctx context.Context) error
+	Abort(ctx context.Context) error
+}
+
+type CSVWriterOpt func(*CSVWriter) error
+
+// WithChunkBytes sets the amount of encoded rows buffered before a chunk is flushed; defaults to DefaultChunkBytes
+func WithChunkBytes(n int) CSVWriterOpt {
+	return func(w *CSVWriter) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid chunk size; size:%v", n)
+		}
+		w.chunkBytes = n
+		return nil
+	}
+}
+
+// WithValueSeparator changes the value separator; defaults to ','
+func WithValueSeparator(sep rune) CSVWriterOpt {
+	return func(w *CSVWriter) error {
+		w.csv.Comma = sep
+		return nil
+	}
+}
+
+// WithCRLF terminates the rows with \r\n instead of \n
+func WithCRLF() CSVWriterOpt {
+	return func(w *CSVWriter) error {
+		w.csv.UseCRLF = true
+		return nil
+	}
+}
+
+// CSVWriter encodes rows as CSV and hands them to its target in chunks, so only a chunk of the output is held in memory
+type CSVWriter struct {
+	header     []string
+	csv        *csv.Writer
+	buf        bytes.Buffer
+	chunkBytes int
+	rows       int
+	closed     bool
+
+	emit   func(chunk []byte) error
+	finish func(ok bool) error
+}
+
+func newCSVWriter(header []string, emit func([]byte) error, finish func(bool) error, opts []CSVWriterOpt) (*CSVWriter, error) {
+	w := &CSVWriter{header: header, chunkBytes: DefaultChunkBytes, emit: emit, finish: finish}
+	w.csv = csv.NewWriter(&w.buf)
+	for _, opt := range opts {
+		if err := opt(w); err != nil {
+			return nil, err
+		}
+	}
+	if len(header) != 0 {
+		if err := w.csv.Write(header); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// NewCSVFileWriter streams the rows into the file at path; the file is written under a temporary name
+// and only appears at path once the writer is closed successfully
+func NewCSVFileWriter(path string, header []string, opts ...CSVWriterOpt) (*CSVWriter, error) {
+	part := path + ".part"
+	f, err := os.Create(part)
+	if err != nil {
+		return nil, err
+	}
+	emit := func(chunk []byte) error {
+		_, err := f.Write(chunk)
+		return err
+	}
+	finish := func(ok bool) error {
+		if !ok {
+			f.Close()
+			return os.Remove(part)
+		}
+		if err := f.Close(); err != nil {
+			os.Remove(part)
+			return err
+		}
+		return os.Rename(part, path)
+	}
+	w, err := newCSVWriter(header, emit, finish, opts)
+	if err != nil {
+		finish(false)
+		return nil, err
+	}
+	return w, nil
+}
+
+// NewCSVChunkWriter streams the rows into the sink; the sink is committed on Close and aborted on Abort
+func NewCSVChunkWriter(ctx context.Context, sink ChunkSink, header []string, opts ...CSVWriterOpt) (*CSVWriter, error) {
+	emit := func(chunk []byte) error {
+		return sink.WriteChunk(ctx, chunk)
+	}
+	finish := func(ok bool) error {
+		if !ok {
+			return sink.Abort(ctx)
+		}
+		return sink.Commit(ctx)
+	}
+	return newCSVWriter(header, emit, finish, opts)
+}
+
+// WriteRow buffers the row and flushes a chunk once enough rows are buffered
+func (w *CSVWriter) WriteRow(r Record) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	if len(w.header) != 0 && len(r) != len(w.header) {
+		return fmt.Errorf("%w; row:%v; expected:%v; got:%v", ErrRowWidth, w.rows, len(w.header), len(r))
+	}
+	if err := w.csv.Write(r); err != nil {
+		return err
+	}
+	w.rows++
+	if w.buf.Len() < w.chunkBytes {
+		return nil
+	}
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	return w.flushChunks(false)
+}
+
+// Rows returns the number of rows written, without the header
+func (w *CSVWriter) Rows() int {
+	return w.rows
+}
+
+// Flush hands every buffered row to the target, even if they do not fill a chunk
+func (w *CSVWriter) Flush() error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	return w.flushChunks(true)
+}
+
+// flushChunks emits the buffer in chunks of chunkBytes; the remainder is kept unless all is set
+func (w *CSVWriter) flushChunks(all bool) error {
+	for w.buf.Len() >= w.chunkBytes || (all && w.buf.Len() != 0) {
+		n := min(w.buf.Len(), w.chunkBytes)
+		if err := w.emit(w.buf.Next(n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes the remaining rows and completes the output; the output is discarded if the flush fails
+func (w *CSVWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		w.closed = true
+		return errors.Join(err, w.finish(false))
+	}
+	w.closed = true
+	return w.finish(true)
+}
+
+// Abort discards the output
+func (w *CSVWriter) Abort() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.finish(false)
+}
+
+// WriteDataframe writes the rows of the dataframe to w
+func WriteDataframe(w RowWriter, d *Dataframe) error {
+	for _, r := range d.Rows {
+		if err := w.WriteRow(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
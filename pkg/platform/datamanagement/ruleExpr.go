@@ -0,0 +1,533 @@
+package datamanagement
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RuleTimeLayouts are tried in order to read the cells compared as times
+var RuleTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var ruleOps = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "+", "-", "*", "/", "(", ")"}
+
+var ruleKeywords = []string{"and", "or", "not", "after", "before", "true", "false"}
+
+// lexRule splits a rule expression; column names are bare identifiers or quoted in backticks
+func lexRule(src string) ([]token, error) {
+	var tokens []token
+	isIdent := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' }
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '\'' || r == '"' || r == '`':
+			end := strings.IndexRune(src[i+1:], r)
+			if end < 0 {
+				return nil, fmt.Errorf("%w; unterminated quote at %d", ErrBadRule, i)
+			}
+			kind := tokenString
+			if r == '`' {
+				kind = tokenIdent
+			}
+			tokens = append(tokens, token{kind: kind, text: src[i+1 : i+1+end], pos: i})
+			i += end + 2
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[start:i], pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if !isIdent(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[start:i], pos: start})
+		default:
+			op := ""
+			for _, o := range ruleOps {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("%w; unexpected %q at %d", ErrBadRule, r, i)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+type operandKind int
+
+const (
+	operandCell operandKind = iota
+	operandNumber
+	operandText
+	operandBool
+)
+
+// operand is a value during evaluation; cells keep their text until an operator decides how to read them
+type operand struct {
+	kind   operandKind
+	text   string
+	num    float64
+	b      bool
+	column string
+}
+
+func (o operand) empty() bool {
+	return o.kind == operandCell && len(strings.TrimSpace(o.text)) == 0
+}
+
+func (o operand) number() (float64, error) {
+	switch o.kind {
+	case operandNumber:
+		return o.num, nil
+	case operandCell:
+		if o.empty() {
+			return 0, fmt.Errorf("%s is empty", o.column)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(o.text), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %q is not a number", o.column, o.text)
+		}
+		return v, nil
+	}
+	return 0, fmt.Errorf("%s is not a number", o)
+}
+
+func (o operand) time() (time.Time, error) {
+	if o.kind != operandCell && o.kind != operandText {
+		return time.Time{}, fmt.Errorf("%s is not a time", o)
+	}
+	if o.empty() {
+		return time.Time{}, fmt.Errorf("%s is empty", o.column)
+	}
+	s := strings.TrimSpace(o.text)
+	for _, layout := range RuleTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	if o.kind == operandCell {
+		return time.Time{}, fmt.Errorf("%s: %q is not a time", o.column, o.text)
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time", o.text)
+}
+
+func (o operand) String() string {
+	switch o.kind {
+	case operandCell:
+		return o.column
+	case operandNumber:
+		return strconv.FormatFloat(o.num, 'g', -1, 64)
+	case operandBool:
+		return strconv.FormatBool(o.b)
+	}
+	return strconv.Quote(o.text)
+}
+
+// exprNode is a node of a parsed rule expression evaluated against the cells of a row
+type exprNode interface {
+	eval(cells map[string]string) (operand, error)
+}
+
+type literalNode struct{ v operand }
+
+func (n literalNode) eval(map[string]string) (operand, error) { return n.v, nil }
+
+type columnNode struct{ name string }
+
+func (n columnNode) eval(cells map[string]string) (operand, error) {
+	return operand{kind: operandCell, text: cells[n.name], column: n.name}, nil
+}
+
+type negNode struct{ x exprNode }
+
+func (n negNode) eval(cells map[string]string) (operand, error) {
+	v, err := n.x.eval(cells)
+	if err != nil {
+		return operand{}, err
+	}
+	f, err := v.number()
+	return operand{kind: operandNumber, num: -f}, err
+}
+
+type notNode struct{ x exprNode }
+
+func (n notNode) eval(cells map[string]string) (operand, error) {
+	v, err := condition(n.x, cells)
+	return operand{kind: operandBool, b: !v.b}, err
+}
+
+type arithNode struct {
+	op   string
+	l, r exprNode
+}
+
+func (n arithNode) eval(cells map[string]string) (operand, error) {
+	l, r, err := evalPair(n.l, n.r, cells)
+	if err != nil {
+		return operand{}, err
+	}
+	a, err := l.number()
+	if err != nil {
+		return operand{}, err
+	}
+	b, err := r.number()
+	if err != nil {
+		return operand{}, err
+	}
+	switch n.op {
+	case "+":
+		return operand{kind: operandNumber, num: a + b}, nil
+	case "-":
+		return operand{kind: operandNumber, num: a - b}, nil
+	case "*":
+		return operand{kind: operandNumber, num: a * b}, nil
+	}
+	if b == 0 {
+		return operand{}, fmt.Errorf("division by zero: %s is 0", r)
+	}
+	return operand{kind: operandNumber, num: a / b}, nil
+}
+
+type logicNode struct {
+	and  bool
+	l, r exprNode
+}
+
+func (n logicNode) eval(cells map[string]string) (operand, error) {
+	l, err := condition(n.l, cells)
+	if err != nil || l.b != n.and {
+		return l, err
+	}
+	return condition(n.r, cells)
+}
+
+// condition evaluates an operand of a logical operator, which must be true or false
+func condition(n exprNode, cells map[string]string) (operand, error) {
+	v, err := n.eval(cells)
+	if err == nil && v.kind != operandBool {
+		err = fmt.Errorf("%s is not a condition", v)
+	}
+	return v, err
+}
+
+type compareNode struct {
+	op   string
+	l, r exprNode
+}
+
+func (n compareNode) eval(cells map[string]string) (operand, error) {
+	l, r, err := evalPair(n.l, n.r, cells)
+	if err != nil {
+		return operand{}, err
+	}
+	c, err := compareOperands(n.op, l, r)
+	if err != nil {
+		return operand{}, err
+	}
+	var b bool
+	switch n.op {
+	case "==":
+		b = c == 0
+	case "!=":
+		b = c != 0
+	case "<", "before":
+		b = c < 0
+	case "<=":
+		b = c <= 0
+	case ">", "after":
+		b = c > 0
+	case ">=":
+		b = c >= 0
+	}
+	return operand{kind: operandBool, b: b}, nil
+}
+
+func evalPair(l, r exprNode, cells map[string]string) (operand, operand, error) {
+	a, err := l.eval(cells)
+	if err != nil {
+		return operand{}, operand{}, err
+	}
+	b, err := r.eval(cells)
+	return a, b, err
+}
+
+// compareOperands compares texts when one side is a quoted text, times for after and before and otherwise
+// numbers, then times, then the cell texts; numbers equal within a relative 1e-9 compare as equal
+func compareOperands(op string, l, r operand) (int, error) {
+	if l.kind == operandBool || r.kind == operandBool {
+		if l.kind != r.kind || (op != "==" && op != "!=") {
+			return 0, fmt.Errorf("can not compare %s and %s", l, r)
+		}
+		if l.b == r.b {
+			return 0, nil
+		}
+		return 1, nil
+	}
+	if op == "after" || op == "before" {
+		a, err := l.time()
+		if err != nil {
+			return 0, err
+		}
+		b, err := r.time()
+		if err != nil {
+			return 0, err
+		}
+		return a.Compare(b), nil
+	}
+	if l.kind == operandText || r.kind == operandText {
+		return strings.Compare(strings.TrimSpace(l.text), strings.TrimSpace(r.text)), nil
+	}
+	for _, o := range []operand{l, r} {
+		if o.empty() {
+			return 0, fmt.Errorf("%s is empty", o.column)
+		}
+	}
+	if a, err := l.number(); err == nil {
+		if b, err := r.number(); err == nil {
+			if math.Abs(a-b) <= 1e-9*max(1, math.Abs(a), math.Abs(b)) {
+				return 0, nil
+			}
+			if a < b {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	if a, err := l.time(); err == nil {
+		if b, err := r.time(); err == nil {
+			return a.Compare(b), nil
+		}
+	}
+	if l.kind == operandCell && r.kind == operandCell {
+		return strings.Compare(l.text, r.text), nil
+	}
+	return 0, fmt.Errorf("can not compare %s and %s", l, r)
+}
+
+// ruleParser is a recursive descent parser of rule expressions:
+//
+//	or      = and { ("||" | "or") and }
+//	and     = not { ("&&" | "and") not }
+//	not     = ("!" | "not") not | compare
+//	compare = sum [ ("==" | "!=" | "<" | "<=" | ">" | ">=" | "after" | "before") sum ]
+//	sum     = product { ("+" | "-") product }
+//	product = unary { ("*" | "/") unary }
+//	unary   = "-" unary | number | 'text' | true | false | column | "(" or ")"
+type ruleParser struct {
+	tokens  []token
+	pos     int
+	columns []string
+}
+
+// parseRuleExpr parses a boolean rule expression and returns it with the columns it reads
+func parseRuleExpr(src string) (exprNode, []string, error) {
+	tokens, err := lexRule(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &ruleParser{tokens: tokens}
+	n, err := p.or()
+	if err != nil {
+		return nil, nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, nil, fmt.Errorf("%w; unexpected %q at %d", ErrBadRule, t.text, t.pos)
+	}
+	switch n.(type) {
+	case compareNode, logicNode, notNode:
+	default:
+		if l, ok := n.(literalNode); !ok || l.v.kind != operandBool {
+			return nil, nil, fmt.Errorf("%w; %q is not a condition", ErrBadRule, src)
+		}
+	}
+	return n, p.columns, nil
+}
+
+func (p *ruleParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// accept consumes the next token if it is one of the operators or keywords
+func (p *ruleParser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	text := t.text
+	if t.kind == tokenIdent {
+		text = strings.ToLower(text)
+		if !slices.Contains(ruleKeywords, text) {
+			return "", false
+		}
+	} else if t.kind != tokenOp {
+		return "", false
+	}
+	if !slices.Contains(ops, text) {
+		return "", false
+	}
+	p.pos++
+	return text, true
+}
+
+func (p *ruleParser) or() (exprNode, error) {
+	l, err := p.and()
+	for err == nil {
+		if _, ok := p.accept("||", "or"); !ok {
+			return l, nil
+		}
+		var r exprNode
+		if r, err = p.and(); err == nil {
+			l = logicNode{and: false, l: l, r: r}
+		}
+	}
+	return nil, err
+}
+
+func (p *ruleParser) and() (exprNode, error) {
+	l, err := p.not()
+	for err == nil {
+		if _, ok := p.accept("&&", "and"); !ok {
+			return l, nil
+		}
+		var r exprNode
+		if r, err = p.not(); err == nil {
+			l = logicNode{and: true, l: l, r: r}
+		}
+	}
+	return nil, err
+}
+
+func (p *ruleParser) not() (exprNode, error) {
+	if _, ok := p.accept("!", "not"); ok {
+		x, err := p.not()
+		return notNode{x: x}, err
+	}
+	return p.compare()
+}
+
+func (p *ruleParser) compare() (exprNode, error) {
+	l, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<", "<=", ">", ">=", "after", "before")
+	if !ok {
+		return l, nil
+	}
+	r, err := p.sum()
+	return compareNode{op: op, l: l, r: r}, err
+}
+
+func (p *ruleParser) sum() (exprNode, error) {
+	l, err := p.product()
+	for err == nil {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return l, nil
+		}
+		var r exprNode
+		if r, err = p.product(); err == nil {
+			l = arithNode{op: op, l: l, r: r}
+		}
+	}
+	return nil, err
+}
+
+func (p *ruleParser) product() (exprNode, error) {
+	l, err := p.unary()
+	for err == nil {
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return l, nil
+		}
+		var r exprNode
+		if r, err = p.unary(); err == nil {
+			l = arithNode{op: op, l: l, r: r}
+		}
+	}
+	return nil, err
+}
+
+func (p *ruleParser) unary() (exprNode, error) {
+	if _, ok := p.accept("-"); ok {
+		x, err := p.unary()
+		return negNode{x: x}, err
+	}
+	if _, ok := p.accept("("); ok {
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			t := p.peek()
+			return nil, fmt.Errorf("%w; expected ')' at %d", ErrBadRule, t.pos)
+		}
+		return n, nil
+	}
+	if b, ok := p.accept("true", "false"); ok {
+		return literalNode{v: operand{kind: operandBool, b: b == "true"}}, nil
+	}
+	t := p.peek()
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w; bad number %q at %d", ErrBadRule, t.text, t.pos)
+		}
+		p.pos++
+		return literalNode{v: operand{kind: operandNumber, num: v}}, nil
+	case tokenString:
+		p.pos++
+		return literalNode{v: operand{kind: operandText, text: t.text}}, nil
+	case tokenIdent:
+		if slices.Contains(ruleKeywords, strings.ToLower(t.text)) {
+			break
+		}
+		p.pos++
+		if !slices.Contains(p.columns, t.text) {
+			p.columns = append(p.columns, t.text)
+		}
+		return columnNode{name: t.text}, nil
+	case tokenEOF:
+		return nil, fmt.Errorf("%w; unexpected end of the expression", ErrBadRule)
+	}
+	return nil, fmt.Errorf("%w; unexpected %q at %d", ErrBadRule, t.text, t.pos)
+}
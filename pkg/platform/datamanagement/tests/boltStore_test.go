@@ -0,0 +1,83 @@
+package datamanagement_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	dm "github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cursor struct {
+	File   string
+	Offset int64
+}
+
+func TestBoltStoreAPI(t *testing.T) {
+	for name, opts := range map[string][]dm.BoltOpt{"json": nil, "gob": {dm.WithGobEncoding()}} {
+		t.Run(name, func(t *testing.T) {
+			s, err := dm.NewBoltStore[string, cursor](filepath.Join(t.TempDir(), "state.db"), "cursors", opts...)
+			require.NoError(t, err)
+			defer s.Close()
+
+			require.NoError(t, s.Add("plant/a", cursor{File: "a.csv", Offset: 10}))
+			assert.ErrorIs(t, s.Add("plant/a", cursor{}), dm.ErrNoOverwrite)
+			assert.ErrorIs(t, s.Update("plant/b", cursor{}), dm.ErrNoOrderFound)
+			require.NoError(t, s.Update("plant/a", cursor{File: "a.csv", Offset: 20}))
+
+			c, err := s.Get("plant/a")
+			require.NoError(t, err)
+			assert.Equal(t, int64(20), c.Offset)
+
+			require.NoError(t, s.Delete("plant/a"))
+			_, err = s.Get("plant/a")
+			assert.ErrorIs(t, err, dm.ErrNoOrderFound)
+		})
+	}
+}
+
+func TestBoltStorePersistsAndScans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	s, err := dm.NewBoltStore[string, int](path, "hwm")
+	require.NoError(t, err)
+	for k, v := range map[string]int{"gradec/line1": 1, "gradec/line2": 2, "sofia/line1": 3} {
+		require.NoError(t, s.Add(k, v))
+	}
+	require.NoError(t, s.Close())
+
+	s, err = dm.NewBoltStore[string, int](path, "hwm")
+	require.NoError(t, err)
+	defer s.Close()
+	var keys []string
+	require.NoError(t, s.ScanPrefix("gradec/", func(k string, v int) error {
+		keys = append(keys, k)
+		return nil
+	}))
+	assert.Equal(t, []string{"gradec/line1", "gradec/line2"}, keys)
+}
+
+func TestBoltStoreTxRollback(t *testing.T) {
+	s, err := dm.NewBoltStore[int64, string](filepath.Join(t.TempDir(), "state.db"), "orders")
+	require.NoError(t, err)
+	defer s.Close()
+	require.NoError(t, s.Add(-5, "neg"))
+	require.NoError(t, s.Add(7, "pos"))
+
+	boom := errors.New("boom")
+	err = s.Tx(func(tx *dm.BoltTx[int64, string]) error {
+		require.NoError(t, tx.Put(1, "one"))
+		return boom
+	})
+	assert.ErrorIs(t, err, boom)
+	_, err = s.Get(1)
+	assert.ErrorIs(t, err, dm.ErrNoOrderFound)
+
+	var order []int64
+	require.NoError(t, s.ScanPrefix("", func(k int64, v string) error {
+		order = append(order, k)
+		return nil
+	}))
+	assert.Equal(t, []int64{-5, 7}, order)
+}
@@ -0,0 +1,84 @@
+package datamanagement_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	dm "github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cacheBackends(t *testing.T) map[string]dm.Cache[string, int] {
+	t.Helper()
+	lru, err := dm.NewLRUCache[string, int](16)
+	require.NoError(t, err)
+	bolt, err := dm.NewBoltCache[string, int](filepath.Join(t.TempDir(), "cache.db"), "cache")
+	require.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+	return map[string]dm.Cache[string, int]{
+		"simple": dm.NewSimpleCache[string, int](),
+		"lru":    lru,
+		"bolt":   bolt,
+	}
+}
+
+func TestCacheBackends(t *testing.T) {
+	for name, c := range cacheBackends(t) {
+		require.NoError(t, c.Set("wo", 42, 0), name)
+		require.NoError(t, c.Set("shift", 3, 20*time.Millisecond), name)
+		v, err := c.Get("wo")
+		require.NoError(t, err, name)
+		assert.Equal(t, 42, v, name)
+		ttl, err := c.TTL("wo")
+		require.NoError(t, err, name)
+		assert.Zero(t, ttl, name)
+		ttl, err = c.TTL("shift")
+		require.NoError(t, err, name)
+		assert.Greater(t, ttl, time.Duration(0), name)
+
+		time.Sleep(30 * time.Millisecond)
+		_, err = c.Get("shift")
+		assert.ErrorIs(t, err, dm.ErrCacheMiss, name)
+
+		require.NoError(t, c.Delete("wo"), name)
+		require.NoError(t, c.Delete("wo"), name)
+		_, err = c.TTL("wo")
+		assert.ErrorIs(t, err, dm.ErrCacheMiss, name)
+	}
+}
+
+func TestLRUCacheEvicts(t *testing.T) {
+	c, err := dm.NewLRUCache[int, string](2)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(1, "a", 0))
+	require.NoError(t, c.Set(2, "b", 0))
+	_, err = c.Get(1)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(3, "c", 0))
+
+	_, err = c.Get(2)
+	assert.ErrorIs(t, err, dm.ErrCacheMiss, "the least recently used item must be evicted")
+	_, err = c.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, c.Len())
+
+	_, err = dm.NewLRUCache[int, string](0)
+	assert.Error(t, err)
+}
+
+func TestBoltCachePurge(t *testing.T) {
+	c, err := dm.NewBoltCache[string, string](filepath.Join(t.TempDir(), "cache.db"), "cache")
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.Set("a", "kept", time.Hour))
+	require.NoError(t, c.Set("b", "expired", time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, c.Purge())
+	v, err := c.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "kept", v)
+	_, err = c.Get("b")
+	assert.ErrorIs(t, err, dm.ErrCacheMiss)
+}
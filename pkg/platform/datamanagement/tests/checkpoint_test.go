@@ -0,0 +1,45 @@
+package datamanagement_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	dm "github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointProcess(t *testing.T) {
+	cp := dm.NewCheckpoint(dm.NewSimpleStore[string, dm.Mark]())
+	t0 := time.Date(2025, 5, 1, 6, 0, 0, 0, time.UTC)
+
+	_, ok, err := cp.Last("mes")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, cp.Process("mes", func(last dm.Mark) (dm.Mark, error) {
+		assert.True(t, last.Time.IsZero())
+		return dm.Mark{ID: "100", Time: t0}, nil
+	}))
+
+	// a failing run leaves the mark untouched
+	failed := errors.New("upload failed")
+	err = cp.Process("mes", func(last dm.Mark) (dm.Mark, error) {
+		return dm.Mark{ID: "200", Time: t0.Add(time.Hour)}, failed
+	})
+	assert.ErrorIs(t, err, failed)
+	m, ok, err := cp.Last("mes")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "100", m.ID)
+
+	assert.ErrorIs(t, cp.Advance("mes", dm.Mark{ID: "50", Time: t0.Add(-time.Minute)}), dm.ErrCheckpointRegression)
+	require.NoError(t, cp.Rewind("mes", dm.Mark{ID: "50", Time: t0.Add(-time.Minute)}))
+	m, _, _ = cp.Last("mes")
+	assert.Equal(t, "50", m.ID)
+
+	require.NoError(t, cp.Reset("mes"))
+	_, ok, _ = cp.Last("mes")
+	assert.False(t, ok)
+}
@@ -0,0 +1,63 @@
+package datamanagement_test
+
+import (
+	"strings"
+	"testing"
+
+	dm "github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnStats(t *testing.T) {
+	df := productionFrame(t)
+
+	qty, err := df.ColumnStats("qty_total")
+	require.NoError(t, err)
+	assert.Equal(t, dm.ColumnStats{
+		Column: "qty_total", Count: 3, Distinct: 2, Numeric: true,
+		Min: "10", Max: "100", MinNumber: 10, MaxNumber: 100,
+	}, qty)
+
+	end, err := df.ColumnStats("END_TIME")
+	require.NoError(t, err)
+	assert.False(t, end.Numeric)
+	assert.Equal(t, 1, end.Empty)
+	assert.Equal(t, "2024-05-01 13:00:00", end.Min)
+	assert.Equal(t, "2024-05-01 14:00:00", end.Max)
+
+	good, err := df.ColumnStats("qty_good")
+	require.NoError(t, err)
+	assert.False(t, good.Numeric, "abc is not a number")
+
+	_, err = df.ColumnStats("line")
+	var notFound *dm.ColumnsNotFoundErr
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestColumnStatsInvalidation(t *testing.T) {
+	df := productionFrame(t)
+	before, err := df.ColumnStats("qty_total")
+	require.NoError(t, err)
+
+	require.NoError(t, df.SetRecord(1, dm.Record{"1002", "", "", "900", "5", "905"}))
+	after, err := df.ColumnStats("qty_total")
+	require.NoError(t, err)
+	assert.Equal(t, 905.0, after.MaxNumber)
+	assert.NotEqual(t, before, after)
+
+	// direct edits of Rows are not noticed until InvalidateStats
+	df.Rows[0][5] = "1"
+	cached, err := df.ColumnStats("qty_total")
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, cached.MinNumber)
+	df.InvalidateStats()
+	fresh, err := df.ColumnStats("qty_total")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, fresh.MinNumber)
+
+	require.NoError(t, df.Mask("wo", func(v string) string { return strings.Repeat("*", len(v)) }))
+	wo, err := df.ColumnStats("wo")
+	require.NoError(t, err)
+	assert.Equal(t, 1, wo.Distinct)
+}
@@ -0,0 +1,44 @@
+package datamanagement_test
+
+import (
+	"slices"
+	"testing"
+
+	dm "github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	df := shiftFrame(t)
+	fp := df.Fingerprint()
+	assert.Len(t, fp, 64)
+	assert.Equal(t, fp, shiftFrame(t).Fingerprint(), "equal dataframes must have equal fingerprints")
+
+	parsed, err := dm.NewDataframeFromData(dm.ByteDefinition{
+		Data:    []byte("date;operator_name;badge;output\n2024-05-01;Ivan Petrov;BG-00912;120\n2024-05-01;Maria Ivanova;BG-00457;98\n2024-05-02;Ivan Petrov;BG-00912;131"),
+		LineSep: "\n",
+		ValSep:  ";",
+	}, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, fp, parsed.Fingerprint(), "the source of the rows must not matter")
+
+	changed := shiftFrame(t)
+	require.NoError(t, changed.SetRecord(1, dm.Record{"2024-05-01", "Maria Ivanova", "BG-00457", "99"}))
+	assert.NotEqual(t, fp, changed.Fingerprint())
+
+	shifted := shiftFrame(t)
+	shifted.Rows[0] = dm.Record{"2024-05-01Ivan", " Petrov", "BG-00912", "120"}
+	assert.NotEqual(t, fp, shifted.Fingerprint(), "moving text between values must change the fingerprint")
+}
+
+func TestFingerprintIgnoreRowOrder(t *testing.T) {
+	df := shiftFrame(t)
+	reversed := shiftFrame(t)
+	slices.Reverse(reversed.Rows)
+	assert.NotEqual(t, df.Fingerprint(), reversed.Fingerprint())
+	assert.Equal(t, df.Fingerprint(dm.IgnoreRowOrder()), reversed.Fingerprint(dm.IgnoreRowOrder()))
+
+	reversed.Rows = append(reversed.Rows, reversed.Rows[0])
+	assert.NotEqual(t, df.Fingerprint(dm.IgnoreRowOrder()), reversed.Fingerprint(dm.IgnoreRowOrder()), "duplicated rows must count")
+}
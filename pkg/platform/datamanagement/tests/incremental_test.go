@@ -0,0 +1,105 @@
+package datamanagement_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dm "github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func appendFile(t *testing.T, path string, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestNewDataframeFromFilesSince(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "line1.csv")
+	b := filepath.Join(dir, "line2.csv")
+	appendFile(t, a, "Date,Qty\n2025-05-01,1\n2025-05-02,2\n")
+	appendFile(t, b, "Date,Qty\n2025-05-01,7\n")
+	cp := dm.NewCheckpoint(dm.NewSimpleStore[string, dm.Mark]())
+	files := []string{a, b}
+
+	df, commit, err := dm.NewDataframeFromFilesSince(files, cp, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "qty"}, df.Header())
+	assert.Len(t, df.Rows, 3)
+
+	// without a commit the same rows are delivered again
+	df, commit, err = dm.NewDataframeFromFilesSince(files, cp, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Len(t, df.Rows, 3)
+	require.NoError(t, commit())
+
+	_, commit, err = dm.NewDataframeFromFilesSince(files, cp, nil, dm.WithInterpretedColumns())
+	assert.ErrorIs(t, err, dm.ErrNoNewRows)
+	require.NoError(t, commit())
+
+	// only the appended rows are parsed; the unfinished line waits for the next run
+	appendFile(t, a, "2025-05-03,3\n2025-05-04,")
+	df, commit, err = dm.NewDataframeFromFilesSince(files, cp, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []dm.Record{{"2025-05-03", "3"}}, df.Rows)
+	require.NoError(t, commit())
+
+	appendFile(t, a, "4\n")
+	df, commit, err = dm.NewDataframeFromFilesSince(files, cp, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []dm.Record{{"2025-05-04", "4"}}, df.Rows)
+	require.NoError(t, commit())
+
+	// a rewritten file is parsed whole
+	require.NoError(t, os.WriteFile(b, []byte("Date,Qty\n2025-06-01,9\n2025-06-02,8\n"), 0o644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(b, later, later))
+	df, _, err = dm.NewDataframeFromFilesSince(files, cp, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []dm.Record{{"2025-06-01", "9"}, {"2025-06-02", "8"}}, df.Rows)
+}
+
+func TestNewDataframeFromFilesSinceHeaderMismatch(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.csv")
+	b := filepath.Join(dir, "b.csv")
+	appendFile(t, a, "Date,Qty\n2025-05-01,1\n")
+	appendFile(t, b, "Date,Weight\n2025-05-01,7\n")
+	cp := dm.NewCheckpoint(dm.NewSimpleStore[string, dm.Mark]())
+
+	_, _, err := dm.NewDataframeFromFilesSince([]string{a, b}, cp, nil, dm.WithInterpretedColumns())
+	var mismatch *dm.HeaderMismatchErr
+	assert.ErrorAs(t, err, &mismatch)
+}
+
+func TestNewDataframeFromCompressedFiles(t *testing.T) {
+	dir := t.TempDir()
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte("Date,Qty\n2025-05-01,1\n2025-05-02,2\n"))
+	require.NoError(t, gw.Close())
+	file := filepath.Join(dir, "line1.csv.gz")
+	require.NoError(t, os.WriteFile(file, gz.Bytes(), 0o644))
+
+	df, err := dm.NewDataframeFromFiles([]string{file}, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "qty"}, df.Header())
+	assert.Len(t, df.Rows, 2)
+
+	cp := dm.NewCheckpoint(dm.NewSimpleStore[string, dm.Mark]())
+	df, commit, err := dm.NewDataframeFromFilesSince([]string{file}, cp, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Len(t, df.Rows, 2)
+	require.NoError(t, commit())
+	_, _, err = dm.NewDataframeFromFilesSince([]string{file}, cp, nil, dm.WithInterpretedColumns())
+	assert.ErrorIs(t, err, dm.ErrNoNewRows)
+}
@@ -0,0 +1,45 @@
+package datamanagement_test
+
+import (
+	"testing"
+
+	dm "github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func shiftFrame(t *testing.T) *dm.Dataframe {
+	t.Helper()
+	df, err := dm.NewDataframeFromRecords([]dm.Record{
+		{"date", "operator_name", "badge", "output"},
+		{"2024-05-01", "Ivan Petrov", "BG-00912", "120"},
+		{"2024-05-01", "Maria Ivanova", "BG-00457", "98"},
+		{"2024-05-02", "Ivan Petrov", "BG-00912", "131"},
+	}, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	return df
+}
+
+func TestMask(t *testing.T) {
+	df := shiftFrame(t)
+	require.NoError(t, df.Mask("operator_name", dm.SaltedHashMask([]byte("pepper"))))
+	require.NoError(t, df.Mask("badge", dm.PartialMask(3, 2)))
+	require.NoError(t, df.Mask("date", dm.ConstantMask("redacted")))
+
+	assert.Len(t, df.Rows[0][1], 64)
+	assert.Equal(t, df.Rows[0][1], df.Rows[2][1], "equal values must stay joinable")
+	assert.NotEqual(t, df.Rows[0][1], df.Rows[1][1])
+	assert.NotEqual(t, dm.HashMask("Ivan Petrov"), df.Rows[0][1])
+	assert.Equal(t, "BG-***12", df.Rows[0][2])
+	assert.Equal(t, "redacted", df.Rows[1][0])
+	assert.Equal(t, "98", df.Rows[1][3])
+
+	err := df.Mask("shift", dm.HashMask)
+	var nf *dm.ColumnsNotFoundErr
+	require.ErrorAs(t, err, &nf)
+}
+
+func TestPartialMaskShortValues(t *testing.T) {
+	assert.Equal(t, "***", dm.PartialMask(2, 2)("abc"))
+	assert.Equal(t, "", dm.HashMask(""))
+}
@@ -0,0 +1,62 @@
+package datamanagement_test
+
+import (
+	"testing"
+
+	dm "github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNumber(t *testing.T) {
+	cases := []struct {
+		in   string
+		f    dm.NumberFormat
+		v    float64
+		unit string
+	}{
+		{in: "1.234,56", f: dm.EuropeanFormat, v: 1234.56},
+		{in: "1,234.56", f: dm.EnglishFormat, v: 1234.56},
+		{in: "85%", f: dm.EuropeanFormat, v: 85, unit: "%"},
+		{in: " 12 kg", f: dm.EuropeanFormat, v: 12, unit: "kg"},
+		{in: "-3,5 °C", f: dm.EuropeanFormat, v: -3.5, unit: "°C"},
+		{in: "1 234 567", f: dm.EnglishFormat, v: 1234567},
+		{in: "12'000.5 m/s", f: dm.EnglishFormat, v: 12000.5, unit: "m/s"},
+	}
+	for _, c := range cases {
+		v, unit, err := dm.ParseNumber(c.in, c.f)
+		require.NoError(t, err, c.in)
+		assert.InDelta(t, c.v, v, 1e-9, c.in)
+		assert.Equal(t, c.unit, unit, c.in)
+	}
+	for _, bad := range []string{"kg", "1,2,3", "12-5", ""} {
+		_, _, err := dm.ParseNumber(bad, dm.EuropeanFormat)
+		assert.ErrorIs(t, err, dm.ErrBadNumber, bad)
+	}
+}
+
+func TestNormalizeNumbers(t *testing.T) {
+	df, err := dm.NewDataframeFromRecords([]dm.Record{
+		{"date", "weight", "yield"},
+		{"2024-05-01", "1.234,5 kg", "85%"},
+		{"2024-05-02", "", "91,5%"},
+	}, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+
+	require.NoError(t, df.NormalizeNumbers("weight", dm.EuropeanFormat, "weight_unit"))
+	require.NoError(t, df.NormalizeNumbers("yield", dm.EuropeanFormat, ""))
+	assert.Equal(t, []string{"date", "weight", "yield", "weight_unit"}, df.Header())
+	assert.Equal(t, dm.Record{"2024-05-01", "1234.5", "85", "kg"}, df.Rows[0])
+	assert.Equal(t, dm.Record{"2024-05-02", "", "91.5", ""}, df.Rows[1])
+
+	type row struct {
+		Weight float64 `df:"weight"`
+		Yield  float64 `df:"yield"`
+		Unit   string  `df:"weight_unit"`
+	}
+	rows, err := dm.DfRowsAsStructList[row](&dm.Dataframe{Columns: df.Columns, Rows: df.Rows[:1]})
+	require.NoError(t, err)
+	assert.Equal(t, row{Weight: 1234.5, Yield: 85, Unit: "kg"}, rows[0])
+
+	assert.ErrorIs(t, df.NormalizeNumbers("date", dm.EuropeanFormat, ""), dm.ErrBadNumber)
+}
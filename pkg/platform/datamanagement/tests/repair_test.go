@@ -0,0 +1,85 @@
+package datamanagement_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dm "github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, contents ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var files []string
+	for i, c := range contents {
+		f := filepath.Join(dir, "line"+string(rune('1'+i))+".csv")
+		require.NoError(t, os.WriteFile(f, []byte(c), 0o644))
+		files = append(files, f)
+	}
+	return files
+}
+
+func TestRepairRaggedRows(t *testing.T) {
+	files := writeFiles(t,
+		"Date,Qty,Unit\n2025-05-01,1,pcs\n2025-05-02,2\n",
+		"Date,Qty,Unit\n2025-05-03,3,pcs,extra\n2025-05-04,4,pcs\n",
+	)
+
+	df, report, err := dm.NewDataframeFromFilesWithRepair(files, nil,
+		dm.Repair{ShortRows: dm.RowFix, LongRows: dm.RowFix}, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []dm.Record{
+		{"2025-05-01", "1", "pcs"},
+		{"2025-05-02", "2", ""},
+		{"2025-05-03", "3", "pcs"},
+		{"2025-05-04", "4", "pcs"},
+	}, df.Rows)
+	assert.Equal(t, 1, report[files[0]].Padded)
+	assert.Equal(t, 1, report[files[1]].Truncated)
+
+	df, report, err = dm.NewDataframeFromFilesWithRepair(files, nil,
+		dm.Repair{ShortRows: dm.RowReject, LongRows: dm.RowReject}, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Len(t, df.Rows, 2)
+	assert.Equal(t, files, report.Files())
+	assert.Equal(t, []dm.RejectedRow{{Row: 3, Cells: dm.Record{"2025-05-02", "2"}, Reason: "2 of 3 cells"}}, report[files[0]].Rejected)
+	assert.Equal(t, 2, report[files[1]].Rejected[0].Row)
+
+	// without a repair the rows are kept as they are
+	df, report, err = dm.NewDataframeFromFilesWithRepair(files, nil, dm.Repair{}, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Len(t, df.Rows, 4)
+	assert.True(t, report.Clean())
+}
+
+func TestRepairSkipMismatchedFiles(t *testing.T) {
+	files := writeFiles(t,
+		"Date,Qty\n2025-05-01,1\n",
+		"Date,Amount\n2025-05-02,2\n",
+		"Date,Qty\n2025-05-03,3\n",
+	)
+	_, _, err := dm.NewDataframeFromFilesWithRepair(files, nil, dm.Repair{}, dm.WithInterpretedColumns())
+	var mismatch *dm.HeaderMismatchErr
+	assert.ErrorAs(t, err, &mismatch)
+
+	df, report, err := dm.NewDataframeFromFilesWithRepair(files, nil, dm.Repair{SkipMismatchedFiles: true}, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []dm.Record{{"2025-05-01", "1"}, {"2025-05-03", "3"}}, df.Rows)
+	assert.ErrorAs(t, report[files[1]].Skipped, &mismatch)
+}
+
+func TestDuplicateColumnNames(t *testing.T) {
+	df, err := dm.NewDataframeFromRecords([]dm.Record{
+		{"Date", "Qty", "Qty", "Qty_2", "Qty"},
+		{"2025-05-01", "1", "2", "3", "4"},
+	}, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "qty", "qty_3", "qty_2", "qty_4"}, df.Header())
+
+	row, err := df.Get(0, "qty_3")
+	require.NoError(t, err)
+	assert.Equal(t, dm.Record{"2"}, row.Rows[0])
+}
@@ -0,0 +1,114 @@
+package datamanagement_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dm "github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func productionFrame(t *testing.T) *dm.Dataframe {
+	t.Helper()
+	df, err := dm.NewDataframeFromRecords([]dm.Record{
+		{"wo", "start_time", "end_time", "qty_good", "qty_scrap", "qty_total"},
+		{"1001", "2024-05-01 06:00:00", "2024-05-01 14:00:00", "95.5", "4.5", "100"},
+		{"1002", "2024-05-01 14:00:00", "2024-05-01 13:00:00", "90", "5", "100"},
+		{"1003", "2024-05-01 22:00:00", "", "abc", "0", "10"},
+	}, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+	return df
+}
+
+func TestValidateRows(t *testing.T) {
+	df := productionFrame(t)
+	rules := []dm.RowRule{
+		dm.MustCompileRowRule(dm.RowRuleSpec{Name: "ends after start", Check: "end_time after start_time", When: "end_time != ''"}),
+		dm.MustCompileRowRule(dm.RowRuleSpec{Name: "quantities add up", Check: "qty_good + qty_scrap == qty_total", Message: "good and scrap do not add up"}),
+		dm.FuncRule("known order", []string{"wo"}, func(cells map[string]string) error {
+			if strings.HasPrefix(cells["wo"], "10") {
+				return nil
+			}
+			return errors.New("unknown work order")
+		}),
+	}
+
+	report, err := df.ValidateRows(rules...)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Checked)
+	assert.False(t, report.Clean())
+	assert.Equal(t, []int{1, 2}, report.Invalid())
+	assert.Equal(t, map[string]int{"ends after start": 1, "quantities add up": 2}, report.ByRule())
+
+	second := report.Rows[0]
+	require.Len(t, second.Violations, 2)
+	assert.Equal(t, dm.RowViolation{
+		Rule:    "ends after start",
+		Message: "failed end_time after start_time",
+		Cells:   map[string]string{"end_time": "2024-05-01 13:00:00", "start_time": "2024-05-01 14:00:00"},
+	}, second.Violations[0])
+	assert.Equal(t, "good and scrap do not add up", second.Violations[1].Message)
+
+	third := report.Rows[1]
+	require.Len(t, third.Violations, 1)
+	assert.Equal(t, `good and scrap do not add up: qty_good: "abc" is not a number`, third.Violations[0].Message)
+}
+
+func TestRowRuleExpressions(t *testing.T) {
+	df, err := dm.NewDataframeFromRecords([]dm.Record{
+		{"status", "qty", "qty(kg)", "shift"},
+		{"done", "12", "3.5", "night"},
+	}, nil, dm.WithInterpretedColumns())
+	require.NoError(t, err)
+
+	for check, valid := range map[string]bool{
+		"qty * 2 - 4 == 20":                        true,
+		"qty / 4 > `qty(kg)`":                      false,
+		"-qty < 0 and (status == 'done' or false)": true,
+		"not (shift == 'day') && qty >= 12":        true,
+		"status == 'open' || `qty(kg)` <= 3":       false,
+		"qty != 12.000000000001":                   false,
+		"(qty + 1) / (qty - 12) > 0":               false,
+		"status > shift":                           false,
+	} {
+		report, err := df.ValidateRows(dm.MustCompileRowRule(dm.RowRuleSpec{Check: check}))
+		require.NoError(t, err, check)
+		assert.Equal(t, valid, report.Clean(), check)
+	}
+
+	for _, bad := range []string{"qty +", "qty = 1", "qty + 1", "(qty > 1", "status == 'x", ""} {
+		_, err := dm.CompileRowRule(dm.RowRuleSpec{Name: "bad", Check: bad})
+		assert.ErrorIs(t, err, dm.ErrBadRule, bad)
+	}
+
+	_, err = df.ValidateRows(dm.MustCompileRowRule(dm.RowRuleSpec{Check: "line > 0"}))
+	var notFound *dm.ColumnsNotFoundErr
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, []string{"line"}, notFound.Required)
+}
+
+func TestLoadRowRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`rules:
+  - name: ends after start
+    check: end_time after start_time
+    when: end_time != ''
+  - name: quantities add up
+    check: qty_good + qty_scrap == qty_total
+`), 0o644))
+	rules, err := dm.LoadRowRules(path)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, []string{"end_time", "start_time"}, rules[0].Columns())
+
+	report, err := productionFrame(t).ValidateRows(rules...)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, report.Invalid())
+
+	_, err = dm.ReadRowRules(strings.NewReader("rules:\n  - check: qty >\n"))
+	assert.ErrorIs(t, err, dm.ErrBadRule)
+}
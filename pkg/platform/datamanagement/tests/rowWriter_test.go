@@ -0,0 +1,97 @@
+package datamanagement_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dm "github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySink struct {
+	chunks    []string
+	committed bool
+	aborted   bool
+	fail      bool
+}
+
+func (s *memorySink) WriteChunk(ctx context.Context, chunk []byte) error {
+	if s.fail {
+		return errors.New("upload failed")
+	}
+	s.chunks = append(s.chunks, string(chunk))
+	return nil
+}
+
+func (s *memorySink) Commit(ctx context.Context) error {
+	s.committed = true
+	return nil
+}
+
+func (s *memorySink) Abort(ctx context.Context) error {
+	s.aborted = true
+	return nil
+}
+
+func TestCSVChunkWriter(t *testing.T) {
+	sink := new(memorySink)
+	w, err := dm.NewCSVChunkWriter(context.Background(), sink, []string{"wo", "qty", "note"}, dm.WithChunkBytes(64))
+	require.NoError(t, err)
+	for i := range 1000 {
+		require.NoError(t, w.WriteRow(dm.Record{fmt.Sprintf("WO-%04d", i), fmt.Sprint(i % 7), "a, quoted \"note\""}))
+	}
+	assert.ErrorIs(t, w.WriteRow(dm.Record{"short"}), dm.ErrRowWidth)
+	require.Greater(t, len(sink.chunks), 1, "rows must be flushed before Close")
+	require.NoError(t, w.Close())
+	assert.True(t, sink.committed)
+	assert.Equal(t, 1000, w.Rows())
+	assert.ErrorIs(t, w.WriteRow(dm.Record{"WO-1", "1", ""}), dm.ErrWriterClosed)
+
+	for _, c := range sink.chunks {
+		assert.LessOrEqual(t, len(c), 64)
+	}
+	content := strings.Join(sink.chunks, "")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	require.Len(t, lines, 1001)
+	assert.Equal(t, "wo,qty,note", lines[0])
+	assert.Equal(t, `WO-0999,5,"a, quoted ""note"""`, lines[1000])
+}
+
+func TestCSVChunkWriterAbortsOnFailure(t *testing.T) {
+	sink := &memorySink{fail: true}
+	w, err := dm.NewCSVChunkWriter(context.Background(), sink, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow(dm.Record{"WO-1", "3"}))
+	require.Error(t, w.Close())
+	assert.True(t, sink.aborted)
+	assert.False(t, sink.committed)
+}
+
+func TestCSVFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	w, err := dm.NewCSVFileWriter(path, []string{"date", "operator_name", "badge", "output"}, dm.WithValueSeparator(';'), dm.WithChunkBytes(16))
+	require.NoError(t, err)
+	require.NoError(t, dm.WriteDataframe(w, shiftFrame(t)))
+	_, err = os.Stat(path)
+	assert.True(t, errors.Is(err, os.ErrNotExist), "the file must not appear before Close")
+	require.NoError(t, w.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "date;operator_name;badge;output\n2024-05-01;Ivan Petrov;BG-00912;120\n2024-05-01;Maria Ivanova;BG-00457;98\n2024-05-02;Ivan Petrov;BG-00912;131\n", string(content))
+
+	aborted := filepath.Join(t.TempDir(), "aborted.csv")
+	w, err = dm.NewCSVFileWriter(aborted, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow(dm.Record{"x"}))
+	require.NoError(t, w.Abort())
+	entries, err := os.ReadDir(filepath.Dir(aborted))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,160 @@
+package fsops
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var ErrMultiFileArchive = errors.New("the archive holds more than one file")
+
+// Compression is the compression format of a file
+type Compression string
+
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionZip  Compression = "zip"
+	CompressionZstd Compression = "zstd"
+)
+
+var compressionMagic = []struct {
+	magic       []byte
+	compression Compression
+}{
+	{[]byte{0x1f, 0x8b}, CompressionGzip},
+	{[]byte("PK\x03\x04"), CompressionZip},
+	{[]byte{0x28, 0xb5, 0x2f, 0xfd}, CompressionZstd},
+}
+
+// officeManifest is an entry of the zip container of xlsx and the other office open xml documents
+const officeManifest = "[Content_Types].xml"
+
+// compressionExts are the file extensions of the compression formats, stripped by UncompressedName
+var compressionExts = []string{".gz", ".gzip", ".zip", ".zst", ".zstd"}
+
+// DetectCompression tells the compression format from the magic bytes at the start of a file; the extension is not consulted
+func DetectCompression(header []byte) Compression {
+	for _, m := range compressionMagic {
+		if bytes.HasPrefix(header, m.magic) {
+			return m.compression
+		}
+	}
+	return CompressionNone
+}
+
+// UncompressedName strips the compression extension of a file name, e.g. orders.csv.gz becomes orders.csv
+func UncompressedName(name string) string {
+	ext := filepath.Ext(name)
+	for _, ce := range compressionExts {
+		if strings.EqualFold(ext, ce) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
+
+// OpenMaybeCompressed opens the file and returns a reader of its content, decompressed when the magic bytes show
+// a gzip, zstd or zip file; uncompressed files are read as they are. A zip archive must hold exactly one file,
+// except for office documents such as xlsx, which are zip files themselves and are read as they are
+func OpenMaybeCompressed(path string) (io.ReadCloser, Compression, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, CompressionNone, err
+	}
+	br := bufio.NewReader(f)
+	header, err := br.Peek(4)
+	if err != nil && !errors.Is(err, io.EOF) {
+		f.Close()
+		return nil, CompressionNone, err
+	}
+	compression := DetectCompression(header)
+	rc, compression, err := decompress(f, br, compression)
+	if err != nil {
+		f.Close()
+		return nil, compression, fmt.Errorf("%w; file:%s;compression:%s", err, path, compression)
+	}
+	return rc, compression, nil
+}
+
+func decompress(f *os.File, br *bufio.Reader, compression Compression) (io.ReadCloser, Compression, error) {
+	plain := &stackedCloser{Reader: br, closers: []io.Closer{f}}
+	switch compression {
+	case CompressionGzip:
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, compression, err
+		}
+		return &stackedCloser{Reader: zr, closers: []io.Closer{zr, f}}, compression, nil
+	case CompressionZstd:
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, compression, err
+		}
+		return &stackedCloser{Reader: zr, closers: []io.Closer{closerFunc(zr.Close), f}}, compression, nil
+	case CompressionZip:
+		info, err := f.Stat()
+		if err != nil {
+			return nil, compression, err
+		}
+		// zip.Reader reads at offsets, so br still starts at the beginning of the file
+		zr, err := zip.NewReader(f, info.Size())
+		if err != nil {
+			return nil, compression, err
+		}
+		if slices.ContainsFunc(zr.File, func(zf *zip.File) bool { return zf.Name == officeManifest }) {
+			return plain, CompressionNone, nil
+		}
+		var entry *zip.File
+		for _, zf := range zr.File {
+			if zf.FileInfo().IsDir() {
+				continue
+			}
+			if entry != nil {
+				return nil, compression, ErrMultiFileArchive
+			}
+			entry = zf
+		}
+		if entry == nil {
+			return nil, compression, fmt.Errorf("the archive holds no file")
+		}
+		er, err := entry.Open()
+		if err != nil {
+			return nil, compression, err
+		}
+		return &stackedCloser{Reader: er, closers: []io.Closer{er, f}}, compression, nil
+	default:
+		return plain, compression, nil
+	}
+}
+
+// stackedCloser closes the decompressor before the file underneath it
+type stackedCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (sc *stackedCloser) Close() error {
+	var errs []error
+	for _, c := range sc.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+type closerFunc func()
+
+func (fn closerFunc) Close() error {
+	fn()
+	return nil
+}
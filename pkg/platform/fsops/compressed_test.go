@@ -0,0 +1,91 @@
+package fsops_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/fsops"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const compressedContent = "Date,Qty\n2025-05-01,1\n2025-05-02,2\n"
+
+func writeZip(t *testing.T, path string, names ...string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		w.Write([]byte(compressedContent))
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+}
+
+func TestOpenMaybeCompressed(t *testing.T) {
+	dir := t.TempDir()
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte(compressedContent))
+	require.NoError(t, gw.Close())
+	zst, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+
+	files := map[string]fsops.Compression{
+		"orders.csv":     fsops.CompressionNone,
+		"orders.csv.gz":  fsops.CompressionGzip,
+		"orders.csv.zst": fsops.CompressionZstd,
+		"orders.zip":     fsops.CompressionZip,
+		// the extension does not matter, the content does
+		"orders.dat": fsops.CompressionGzip,
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.csv"), []byte(compressedContent), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.csv.gz"), gz.Bytes(), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.dat"), gz.Bytes(), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.csv.zst"), zst.EncodeAll([]byte(compressedContent), nil), 0o644))
+	writeZip(t, filepath.Join(dir, "orders.zip"), "orders.csv")
+
+	for name, want := range files {
+		rc, compression, err := fsops.OpenMaybeCompressed(filepath.Join(dir, name))
+		require.NoError(t, err, name)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err, name)
+		require.NoError(t, rc.Close())
+		assert.Equal(t, want, compression, name)
+		assert.Equal(t, compressedContent, string(content), name)
+	}
+}
+
+func TestOpenMaybeCompressedZipArchives(t *testing.T) {
+	dir := t.TempDir()
+	multi := filepath.Join(dir, "drop.zip")
+	writeZip(t, multi, "line1.csv", "line2.csv")
+	_, _, err := fsops.OpenMaybeCompressed(multi)
+	assert.ErrorIs(t, err, fsops.ErrMultiFileArchive)
+
+	// an xlsx is a zip container but not a compressed drop
+	sheet := filepath.Join(dir, "orders.xlsx")
+	writeZip(t, sheet, "[Content_Types].xml", "xl/workbook.xml")
+	rc, compression, err := fsops.OpenMaybeCompressed(sheet)
+	require.NoError(t, err)
+	defer rc.Close()
+	assert.Equal(t, fsops.CompressionNone, compression)
+	raw, _ := os.ReadFile(sheet)
+	content, _ := io.ReadAll(rc)
+	assert.Equal(t, raw, content)
+}
+
+func TestUncompressedName(t *testing.T) {
+	assert.Equal(t, "orders.csv", fsops.UncompressedName("orders.csv.gz"))
+	assert.Equal(t, "orders.csv", fsops.UncompressedName("orders.csv.ZST"))
+	assert.Equal(t, "orders.csv", fsops.UncompressedName("orders.csv"))
+}
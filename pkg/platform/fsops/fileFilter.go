@@ -2,9 +2,12 @@ package fsops
 
 import (
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"time"
 )
 
@@ -14,21 +17,35 @@ type FileFilterOption func(*FileFilter) error
 
 // FileFilter operates and filters files over a range of fs.FS objects
 type FileFilter struct {
-	pattern string
-	maxAge  time.Duration
-	dir     map[string]fs.FS
-	matches []string
-	drill   bool
+	patterns []string
+	maxAge   time.Duration
+	dir      map[string]fs.FS
+	matches  []string
+	drill    bool
+	// retries of operations failing with transient network errors
+	retries   int
+	retryWait time.Duration
+	sidecar   *sidecarFilter
+	// perm is applied to the files written by CopyTo
+	perm *Permissions
+}
+
+// validatePattern checks the fs.Glob (path.Match) syntax of p
+func validatePattern(p string) error {
+	if _, err := path.Match(p, ""); err != nil {
+		return fmt.Errorf("%w; pattern:%s", err, p)
+	}
+	return nil
 }
 
 func WithGlobPattern(p string) FileFilterOption {
+	return WithGlobPatterns(p)
+}
+
+// WithGlobPatterns matches files against any of the provided patterns
+func WithGlobPatterns(p ...string) FileFilterOption {
 	return func(ff *FileFilter) error {
-		// check if the provided pattern is valid
-		if _, err := fs.Glob(os.DirFS(""), p); err != nil {
-			return err
-		}
-		ff.pattern = p
-		return nil
+		return ff.SetPatterns(p...)
 	}
 }
 
@@ -63,10 +80,17 @@ func NewFileFilter(opts ...FileFilterOption) (*FileFilter, error) {
 }
 
 func (ff *FileFilter) SetPattern(p string) error {
-	if _, err := fs.Glob(os.DirFS(""), p); err != nil {
-		return err
+	return ff.SetPatterns(p)
+}
+
+// SetPatterns replaces the patterns of the filter; a file matching any of them is kept
+func (ff *FileFilter) SetPatterns(p ...string) error {
+	for _, pattern := range p {
+		if err := validatePattern(pattern); err != nil {
+			return err
+		}
 	}
-	ff.pattern = p
+	ff.patterns = slices.Clone(p)
 	return nil
 }
 
@@ -77,27 +101,14 @@ func (ff FileFilter) Filter() ([]string, error) {
 	}
 	// loop over the registered file systems
 	for path, fsys := range ff.dir {
-		matches, err := fs.Glob(fsys, ff.pattern)
+		var matches []string
+		err := ff.retry(func() (err error) {
+			matches, err = ff.filterFS(fsys)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
-		// if the age filter is set
-		if ff.maxAge != 0 {
-			for _, m := range matches {
-				f, err := fsys.Open(m)
-				if err != nil {
-					return nil, err
-				}
-
-				finfo, _ := f.Stat()
-				if finfo.ModTime().After(time.Now().Add(-ff.maxAge)) {
-					ff.matches = append(ff.matches, filepath.Join(path, m))
-				}
-				f.Close()
-			}
-			continue
-		}
-
 		// enrich the found files with the rest of the path stucture before returning
 		for idx, m := range matches {
 			matches[idx] = filepath.Join(path, m)
@@ -106,3 +117,46 @@ func (ff FileFilter) Filter() ([]string, error) {
 	}
 	return ff.matches, nil
 }
+
+// filterFS returns the paths, relative to fsys, of the files matching the filter
+func (ff FileFilter) filterFS(fsys fs.FS) ([]string, error) {
+	matches, err := globAny(fsys, ff.patterns)
+	if err == nil && ff.sidecar != nil {
+		matches, err = ff.sidecar.keep(fsys, matches)
+	}
+	if err != nil || ff.maxAge == 0 {
+		return matches, err
+	}
+	// if the age filter is set
+	fresh := make([]string, 0, len(matches))
+	for _, m := range matches {
+		f, err := fsys.Open(m)
+		if err != nil {
+			return nil, err
+		}
+
+		finfo, _ := f.Stat()
+		if finfo.ModTime().After(time.Now().Add(-ff.maxAge)) {
+			fresh = append(fresh, m)
+		}
+		f.Close()
+	}
+	return fresh, nil
+}
+
+// globAny returns the files matching any of the patterns, without duplicates
+func globAny(fsys fs.FS, patterns []string) ([]string, error) {
+	var matches []string
+	for _, p := range patterns {
+		m, err := fs.Glob(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range m {
+			if !slices.Contains(matches, f) {
+				matches = append(matches, f)
+			}
+		}
+	}
+	return matches, nil
+}
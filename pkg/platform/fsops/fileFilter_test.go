@@ -3,6 +3,7 @@ package fsops_test
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"testing"
@@ -296,3 +297,38 @@ func TestFileFilter_Filter_PathConstructionDifference(t *testing.T) {
 // 	_, err := ff.Filter()
 // 	assert.Error(t, err) // Expecting filepath.ErrBadPattern or similar
 // }
+
+func TestFileFilter_GlobPatterns(t *testing.T) {
+	tmpDir, _ := setupTestDirs(t)
+	dir1 := filepath.Join(tmpDir, "dir1")
+	dir2 := filepath.Join(tmpDir, "dir2")
+
+	ff, err := fsops.NewFileFilter(
+		fsops.WithGlobPatterns("*.txt", "*.dat", "a.*"),
+		fsops.SetLoc([]string{dir1, dir2}),
+	)
+	require.NoError(t, err)
+
+	matches, err := ff.Filter()
+	require.NoError(t, err)
+
+	// a.txt matches two patterns but is reported once
+	expected := []string{
+		filepath.Join(dir1, "a.txt"),
+		filepath.Join(dir1, "old.txt"),
+		filepath.Join(dir2, "c.txt"),
+		filepath.Join(dir2, "d.dat"),
+	}
+	sort.Strings(matches)
+	sort.Strings(expected)
+	assert.Equal(t, expected, matches)
+}
+
+func TestFileFilter_InvalidPattern(t *testing.T) {
+	_, err := fsops.NewFileFilter(fsops.WithGlobPatterns("*.csv", "[a-"))
+	require.ErrorIs(t, err, path.ErrBadPattern)
+
+	ff, err := fsops.NewFileFilter(fsops.WithGlobPattern("*.csv"))
+	require.NoError(t, err)
+	require.ErrorIs(t, ff.SetPattern("\\"), path.ErrBadPattern)
+}
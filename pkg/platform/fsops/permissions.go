@@ -0,0 +1,75 @@
+package fsops
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// ErrGrantsUnsupported is returned for access grants on platforms without file ACLs in the operating system API
+var ErrGrantsUnsupported = errors.New("access grants are only supported on windows")
+
+// Grant gives an account access to a file besides its owner and group; Account is a user or group name,
+// qualified as DOMAIN\user on windows
+type Grant struct {
+	Account string
+	// Write grants modifying the file on top of reading it
+	Write bool
+}
+
+// Permissions describes who may read and write a file.
+// Mode holds the unix permission bits; on windows only the owner write bit is honoured and a cleared one makes the
+// file read-only. Owner and Group are account names, or numeric ids on unix; changing them usually takes elevated
+// privileges. Grants are ACL entries added on windows next to the inherited ones, e.g. for the operator account
+type Permissions struct {
+	Mode   fs.FileMode
+	Owner  string
+	Group  string
+	Grants []Grant
+}
+
+// SetPermissions applies p to the file at path; a zero Mode and empty fields leave the file as it is
+func SetPermissions(path string, p Permissions) error {
+	path = LongPath(path)
+	if p.Mode != 0 {
+		if err := os.Chmod(path, p.Mode.Perm()); err != nil {
+			return err
+		}
+	}
+	if len(p.Owner) != 0 || len(p.Group) != 0 {
+		if err := setOwnership(path, p.Owner, p.Group); err != nil {
+			return fmt.Errorf("%w; file:%s;owner:%s;group:%s", err, path, p.Owner, p.Group)
+		}
+	}
+	if len(p.Grants) != 0 {
+		if err := setGrants(path, p.Grants); err != nil {
+			return fmt.Errorf("%w; file:%s", err, path)
+		}
+	}
+	return nil
+}
+
+// GetPermissions reads the permissions of the file at path; accounts without a name are reported by their id (or SID).
+// Grants lists the explicit ACL entries on windows and is empty elsewhere
+func GetPermissions(path string) (Permissions, error) {
+	path = LongPath(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return Permissions{}, err
+	}
+	p, err := getOwnership(path, info)
+	if err != nil {
+		return Permissions{}, fmt.Errorf("%w; file:%s", err, path)
+	}
+	p.Mode = info.Mode().Perm()
+	return p, nil
+}
+
+// WithPermissions applies p to every file CopyTo writes, before the file is renamed into place
+func WithPermissions(p Permissions) FileFilterOption {
+	return func(ff *FileFilter) error {
+		ff.perm = &p
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+//go:build !windows
+
+package fsops
+
+import (
+	"io/fs"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+func setOwnership(path string, owner string, group string) error {
+	uid, gid := -1, -1
+	var err error
+	if len(owner) != 0 {
+		if uid, err = lookupID(owner, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		}); err != nil {
+			return err
+		}
+	}
+	if len(group) != 0 {
+		if gid, err = lookupID(group, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		}); err != nil {
+			return err
+		}
+	}
+	return os.Chown(path, uid, gid)
+}
+
+// lookupID resolves an account name, taking numeric names as the id itself
+func lookupID(name string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	id, err := lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
+
+func getOwnership(path string, info fs.FileInfo) (Permissions, error) {
+	var p Permissions
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return p, nil
+	}
+	p.Owner = strconv.FormatUint(uint64(st.Uid), 10)
+	if u, err := user.LookupId(p.Owner); err == nil {
+		p.Owner = u.Username
+	}
+	p.Group = strconv.FormatUint(uint64(st.Gid), 10)
+	if g, err := user.LookupGroupId(p.Group); err == nil {
+		p.Group = g.Name
+	}
+	return p, nil
+}
+
+// unix files carry ACLs only as file system extended attributes, so grants are left to the owner, group and mode
+func setGrants(path string, grants []Grant) error {
+	return ErrGrantsUnsupported
+}
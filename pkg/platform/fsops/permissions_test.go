@@ -0,0 +1,57 @@
+package fsops_test
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/fsops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permission bits")
+	}
+	file := filepath.Join(t.TempDir(), "report.csv")
+	require.NoError(t, os.WriteFile(file, []byte("wo;qty\n"), 0o600))
+	me, err := user.Current()
+	require.NoError(t, err)
+
+	require.NoError(t, fsops.SetPermissions(file, fsops.Permissions{Mode: 0o640, Owner: me.Username, Group: me.Gid}))
+	p, err := fsops.GetPermissions(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), p.Mode)
+	assert.Equal(t, me.Username, p.Owner)
+	assert.NotEmpty(t, p.Group)
+	assert.Empty(t, p.Grants)
+
+	err = fsops.SetPermissions(file, fsops.Permissions{Grants: []fsops.Grant{{Account: "operator"}}})
+	assert.ErrorIs(t, err, fsops.ErrGrantsUnsupported)
+	assert.Error(t, fsops.SetPermissions(file, fsops.Permissions{Owner: "no-such-account-here"}))
+}
+
+func TestCopyToWithPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permission bits")
+	}
+	tmpDir, _ := setupTestDirs(t)
+	dst := filepath.Join(t.TempDir(), "reports")
+	ff, err := fsops.NewFileFilter(
+		fsops.WithGlobPattern("*.txt"),
+		fsops.SetLoc([]string{filepath.Join(tmpDir, "dir1")}),
+		fsops.WithPermissions(fsops.Permissions{Mode: 0o604}),
+	)
+	require.NoError(t, err)
+	copied, err := ff.CopyTo(dst)
+	require.NoError(t, err)
+	require.NotEmpty(t, copied)
+	for _, c := range copied {
+		info, err := os.Stat(c)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o604), info.Mode().Perm(), c)
+	}
+}
@@ -0,0 +1,117 @@
+package fsops
+
+import (
+	"io/fs"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// access masks of the grants, the read and modify rights of the explorer security tab
+const (
+	accessRead   = windows.ACCESS_MASK(0x1200a9)
+	accessModify = windows.ACCESS_MASK(0x1301bf)
+	// fileWriteData is the right to change the content of a file
+	fileWriteData = windows.ACCESS_MASK(0x2)
+)
+
+func setOwnership(path string, owner string, group string) error {
+	var info windows.SECURITY_INFORMATION
+	var ownerSID, groupSID *windows.SID
+	var err error
+	if len(owner) != 0 {
+		if ownerSID, _, _, err = windows.LookupSID("", owner); err != nil {
+			return err
+		}
+		info |= windows.OWNER_SECURITY_INFORMATION
+	}
+	if len(group) != 0 {
+		if groupSID, _, _, err = windows.LookupSID("", group); err != nil {
+			return err
+		}
+		info |= windows.GROUP_SECURITY_INFORMATION
+	}
+	return windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, info, ownerSID, groupSID, nil, nil)
+}
+
+func getOwnership(path string, info fs.FileInfo) (Permissions, error) {
+	var p Permissions
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT,
+		windows.OWNER_SECURITY_INFORMATION|windows.GROUP_SECURITY_INFORMATION|windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return p, err
+	}
+	if owner, _, err := sd.Owner(); err == nil && owner != nil {
+		p.Owner = accountName(owner)
+	}
+	if group, _, err := sd.Group(); err == nil && group != nil {
+		p.Group = accountName(group)
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil || dacl == nil {
+		// a missing DACL grants everyone full access
+		return p, nil
+	}
+	for i := uint32(0); i < uint32(dacl.AceCount); i++ {
+		var ace *windows.ACCESS_ALLOWED_ACE
+		if err := windows.GetAce(dacl, i, &ace); err != nil {
+			return p, err
+		}
+		if ace.Header.AceType != windows.ACCESS_ALLOWED_ACE_TYPE || ace.Header.AceFlags&windows.INHERITED_ACE != 0 {
+			continue
+		}
+		sid := (*windows.SID)(unsafe.Pointer(&ace.SidStart))
+		p.Grants = append(p.Grants, Grant{Account: accountName(sid), Write: ace.Mask&fileWriteData != 0})
+	}
+	return p, nil
+}
+
+// accountName returns DOMAIN\account for sid, or the SID string for accounts that can not be resolved
+func accountName(sid *windows.SID) string {
+	account, domain, _, err := sid.LookupAccount("")
+	if err != nil {
+		return sid.String()
+	}
+	if len(domain) == 0 {
+		return account
+	}
+	return domain + `\` + account
+}
+
+// setGrants merges the grants into the DACL of the file, keeping its inherited entries
+func setGrants(path string, grants []Grant) error {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return err
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return err
+	}
+	entries := make([]windows.EXPLICIT_ACCESS, 0, len(grants))
+	for _, g := range grants {
+		sid, _, _, err := windows.LookupSID("", g.Account)
+		if err != nil {
+			return err
+		}
+		access := accessRead
+		if g.Write {
+			access = accessModify
+		}
+		entries = append(entries, windows.EXPLICIT_ACCESS{
+			AccessPermissions: access,
+			AccessMode:        windows.SET_ACCESS,
+			Inheritance:       windows.NO_INHERITANCE,
+			Trustee: windows.TRUSTEE{
+				TrusteeForm:  windows.TRUSTEE_IS_SID,
+				TrusteeType:  windows.TRUSTEE_IS_UNKNOWN,
+				TrusteeValue: windows.TrusteeValueFromSID(sid),
+			},
+		})
+	}
+	merged, err := windows.ACLFromEntries(entries, dacl)
+	if err != nil {
+		return err
+	}
+	return windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION, nil, nil, merged, nil)
+}
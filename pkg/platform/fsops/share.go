@@ -0,0 +1,185 @@
+package fsops
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrNotUNC = errors.New("not a UNC path")
+
+// ErrCredentialsUnsupported is returned for share credentials on platforms that mount shares outside of the process
+var ErrCredentialsUnsupported = errors.New("share credentials are only supported on windows")
+
+// Credentials authenticate the connection to a network share; User may be qualified as DOMAIN\user
+type Credentials struct {
+	User     string
+	Password string
+}
+
+// IsUNC reports whether p is a UNC path such as \\plantserver\exports; forward slashes are accepted
+func IsUNC(p string) bool {
+	_, ok := ShareRoot(p)
+	return ok
+}
+
+// ShareRoot returns the \\server\share part of a UNC path
+func ShareRoot(p string) (string, bool) {
+	p = strings.ReplaceAll(p, "/", `\`)
+	if !strings.HasPrefix(p, `\\`) || strings.HasPrefix(p, `\\?\`) || strings.HasPrefix(p, `\\.\`) {
+		return "", false
+	}
+	parts := strings.SplitN(p[2:], `\`, 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", false
+	}
+	return `\\` + parts[0] + `\` + parts[1], true
+}
+
+// ConnectShare authenticates the process against the share of the UNC path p
+func ConnectShare(p string, cred Credentials) error {
+	root, ok := ShareRoot(p)
+	if !ok {
+		return fmt.Errorf("%w; path:%s", ErrNotUNC, p)
+	}
+	return connectShare(root, cred)
+}
+
+// DisconnectShare drops the connection made by ConnectShare
+func DisconnectShare(p string) error {
+	root, ok := ShareRoot(p)
+	if !ok {
+		return fmt.Errorf("%w; path:%s", ErrNotUNC, p)
+	}
+	return disconnectShare(root)
+}
+
+// LongPath returns p in a form that is not bound by the windows MAX_PATH limit (\\?\ or \\?\UNC\ prefixed);
+// other platforms have no such limit and get p unchanged
+func LongPath(p string) string {
+	return longPath(p)
+}
+
+// WithShare adds a network share location, connecting to it with cred first unless cred is nil;
+// deep trees on the share are read through LongPath
+func WithShare(loc string, cred *Credentials) FileFilterOption {
+	return func(ff *FileFilter) error {
+		if cred != nil {
+			if err := ConnectShare(loc, *cred); err != nil {
+				return err
+			}
+		}
+		if ff.dir == nil {
+			ff.dir = make(map[string]fs.FS)
+		}
+		ff.dir[loc] = os.DirFS(LongPath(loc))
+		return nil
+	}
+}
+
+// WithRetry retries the filtering and copying of a location up to n times, waiting wait between attempts,
+// when it fails with a transient network error (see IsTransient)
+func WithRetry(n int, wait time.Duration) FileFilterOption {
+	return func(ff *FileFilter) error {
+		ff.retries, ff.retryWait = n, wait
+		return nil
+	}
+}
+
+// IsTransient reports whether err is a network error that may clear up on its own, such as a dropped SMB session
+func IsTransient(err error) bool {
+	var errno syscall.Errno
+	return errors.As(err, &errno) && isTransientErrno(errno)
+}
+
+func (ff FileFilter) retry(op func() error) error {
+	err := op()
+	for i := 0; i < ff.retries && IsTransient(err); i++ {
+		time.Sleep(ff.retryWait)
+		err = op()
+	}
+	return err
+}
+
+// CopyTo copies the files matching the filter into dst, keeping their paths relative to their location,
+// and returns the destination paths; files are written under a temporary name and renamed once complete
+func (ff FileFilter) CopyTo(dst string) ([]string, error) {
+	if len(ff.dir) == 0 {
+		return nil, ErrNoDirsProvided
+	}
+	var copied []string
+	for loc, fsys := range ff.dir {
+		var matches []string
+		err := ff.retry(func() (err error) {
+			matches, err = ff.filterFS(fsys)
+			return err
+		})
+		if err != nil {
+			return copied, err
+		}
+		for _, m := range matches {
+			target := filepath.Join(dst, filepath.FromSlash(m))
+			err := ff.retry(func() error {
+				return copyFile(LongPath(filepath.Join(loc, filepath.FromSlash(m))), target, ff.perm)
+			})
+			if err != nil {
+				return copied, err
+			}
+			copied = append(copied, target)
+		}
+	}
+	return copied, nil
+}
+
+// CopyFile copies src to dst, creating the parent directories of dst and keeping the modification time of src
+func CopyFile(src string, dst string) error {
+	return copyFile(src, dst, nil)
+}
+
+// copyFile is CopyFile applying perm, when set, to the complete file before it is renamed
+func copyFile(src string, dst string, perm *Permissions) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	dst = LongPath(dst)
+	if err = os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	part := dst + ".part"
+	out, err := os.Create(part)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chtimes(part, info.ModTime(), info.ModTime())
+	}
+	if err == nil && perm != nil {
+		err = SetPermissions(part, *perm)
+	}
+	if err == nil {
+		err = os.Rename(part, dst)
+	}
+	if err != nil {
+		os.Remove(part)
+		return fmt.Errorf("%w; src:%s", err, src)
+	}
+	return nil
+}
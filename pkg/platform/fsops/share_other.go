@@ -0,0 +1,30 @@
+//go:build !windows
+
+package fsops
+
+import "syscall"
+
+// shares are mounted by the system (e.g. mount.cifs) and bring their own credentials
+func connectShare(root string, cred Credentials) error {
+	if len(cred.User) == 0 {
+		return nil
+	}
+	return ErrCredentialsUnsupported
+}
+
+func disconnectShare(root string) error {
+	return nil
+}
+
+func isTransientErrno(errno syscall.Errno) bool {
+	switch errno {
+	case syscall.EIO, syscall.EAGAIN, syscall.ETIMEDOUT, syscall.ECONNRESET, syscall.ECONNABORTED,
+		syscall.EHOSTDOWN, syscall.EHOSTUNREACH, syscall.ENETUNREACH, syscall.ESTALE:
+		return true
+	}
+	return false
+}
+
+func longPath(p string) string {
+	return p
+}
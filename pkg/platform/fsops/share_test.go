@@ -0,0 +1,69 @@
+package fsops_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/fsops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareRoot(t *testing.T) {
+	for p, want := range map[string]string{
+		`\\plantserver\exports`:            `\\plantserver\exports`,
+		`\\plantserver\exports\2025\a.csv`: `\\plantserver\exports`,
+		`//plantserver/exports/2025/a.csv`: `\\plantserver\exports`,
+		`\\plantserver`:                    "",
+		`\\?\C:\exports`:                   "",
+		`C:\exports`:                       "",
+		`/mnt/exports`:                     "",
+	} {
+		root, ok := fsops.ShareRoot(p)
+		assert.Equal(t, want, root, p)
+		assert.Equal(t, want != "", ok, p)
+		assert.Equal(t, want != "", fsops.IsUNC(p), p)
+	}
+	assert.ErrorIs(t, fsops.ConnectShare("/mnt/exports", fsops.Credentials{}), fsops.ErrNotUNC)
+}
+
+func TestCopyTo(t *testing.T) {
+	tmpDir, files := setupTestDirs(t)
+	dir2 := filepath.Join(tmpDir, "dir2")
+	dst := filepath.Join(t.TempDir(), "inbox")
+
+	ff, err := fsops.NewFileFilter(
+		fsops.WithGlobPatterns("*.txt", "sub/*.txt"),
+		fsops.WithShare(dir2, nil),
+		fsops.WithRetry(2, time.Millisecond),
+	)
+	require.NoError(t, err)
+	copied, err := ff.CopyTo(dst)
+	require.NoError(t, err)
+
+	expected := []string{filepath.Join(dst, "c.txt"), filepath.Join(dst, "sub", "e.txt")}
+	sort.Strings(copied)
+	assert.Equal(t, expected, copied)
+	info, err := os.Stat(filepath.Join(dst, "c.txt"))
+	require.NoError(t, err)
+	assert.WithinDuration(t, files[filepath.Join(dir2, "c.txt")], info.ModTime(), time.Second)
+	_, err = os.Stat(filepath.Join(dst, "c.txt.part"))
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestIsTransient(t *testing.T) {
+	transient := syscall.Errno(64) // ERROR_NETNAME_DELETED
+	if runtime.GOOS != "windows" {
+		transient = syscall.ETIMEDOUT
+	}
+	assert.True(t, fsops.IsTransient(&fs.PathError{Op: "open", Path: "a.csv", Err: transient}))
+	assert.False(t, fsops.IsTransient(&fs.PathError{Op: "open", Path: "a.csv", Err: fs.ErrNotExist}))
+	assert.False(t, fsops.IsTransient(nil))
+}
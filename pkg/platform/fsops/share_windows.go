@@ -0,0 +1,107 @@
+package fsops
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// maxDirPath is the longest directory path the windows API accepts without the \\?\ prefix (MAX_PATH less 8.3 file name)
+const maxDirPath = 248
+
+const (
+	resourceTypeDisk   = 0x1
+	connectTemporary   = 0x4
+	errorNotConnected  = syscall.Errno(2250)
+	errorBadNetPath    = syscall.Errno(53)
+	errorNetworkBusy   = syscall.Errno(54)
+	errorUnexpNetErr   = syscall.Errno(59)
+	errorNetnameGone   = syscall.Errno(64)
+	errorRemNotList    = syscall.Errno(51)
+	errorSemTimeout    = syscall.Errno(121)
+	errorNetUnreach    = syscall.Errno(1231)
+	errorConnAborted   = syscall.Errno(1236)
+	errorNoNetOrBadDir = syscall.Errno(1222)
+)
+
+var (
+	mpr                        = syscall.NewLazyDLL("mpr.dll")
+	procWNetAddConnection2W    = mpr.NewProc("WNetAddConnection2W")
+	procWNetCancelConnection2W = mpr.NewProc("WNetCancelConnection2W")
+)
+
+// netResource mirrors the NETRESOURCEW structure
+type netResource struct {
+	Scope       uint32
+	Type        uint32
+	DisplayType uint32
+	Usage       uint32
+	LocalName   *uint16
+	RemoteName  *uint16
+	Comment     *uint16
+	Provider    *uint16
+}
+
+func connectShare(root string, cred Credentials) error {
+	remote, err := syscall.UTF16PtrFromString(root)
+	if err != nil {
+		return err
+	}
+	var user, password *uint16
+	if len(cred.User) != 0 {
+		if user, err = syscall.UTF16PtrFromString(cred.User); err != nil {
+			return err
+		}
+		if password, err = syscall.UTF16PtrFromString(cred.Password); err != nil {
+			return err
+		}
+	}
+	nr := netResource{Type: resourceTypeDisk, RemoteName: remote}
+	r, _, _ := procWNetAddConnection2W.Call(
+		uintptr(unsafe.Pointer(&nr)),
+		uintptr(unsafe.Pointer(password)),
+		uintptr(unsafe.Pointer(user)),
+		connectTemporary,
+	)
+	if r != 0 {
+		return fmt.Errorf("%w; share:%s", syscall.Errno(r), root)
+	}
+	return nil
+}
+
+func disconnectShare(root string) error {
+	remote, err := syscall.UTF16PtrFromString(root)
+	if err != nil {
+		return err
+	}
+	r, _, _ := procWNetCancelConnection2W.Call(uintptr(unsafe.Pointer(remote)), 0, 1)
+	if r != 0 && syscall.Errno(r) != errorNotConnected {
+		return fmt.Errorf("%w; share:%s", syscall.Errno(r), root)
+	}
+	return nil
+}
+
+func isTransientErrno(errno syscall.Errno) bool {
+	switch errno {
+	case errorBadNetPath, errorNetworkBusy, errorUnexpNetErr, errorNetnameGone, errorRemNotList,
+		errorSemTimeout, errorNetUnreach, errorConnAborted, errorNoNetOrBadDir:
+		return true
+	}
+	return false
+}
+
+func longPath(p string) string {
+	if len(p) < maxDirPath || strings.HasPrefix(p, `\\?\`) {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,202 @@
+package fsops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SidecarSuffix is appended to the name of a file to get the name of its sidecar, e.g. orders.csv.meta.json
+const SidecarSuffix = ".meta.json"
+
+var (
+	ErrNoSidecar        = errors.New("the file has no sidecar")
+	ErrChecksumMismatch = errors.New("the file does not match the checksum of its sidecar")
+)
+
+// IngestStatus is the processing state recorded in a sidecar
+type IngestStatus string
+
+const (
+	StatusPending  IngestStatus = "pending"
+	StatusIngested IngestStatus = "ingested"
+	StatusFailed   IngestStatus = "failed"
+	StatusSkipped  IngestStatus = "skipped"
+)
+
+// Sidecar is the metadata kept next to a data file in <file>.meta.json
+type Sidecar struct {
+	// Source names the system or share the file came from
+	Source string `json:"source"`
+	// Checksum is the sha256 of the file content as "sha256:<hex>"
+	Checksum string       `json:"checksum"`
+	Status   IngestStatus `json:"status"`
+	// Error describes why the ingest failed or was skipped
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	IngestedAt time.Time `json:"ingested_at,omitzero"`
+}
+
+// SidecarPath returns the path of the sidecar of file
+func SidecarPath(file string) string {
+	return file + SidecarSuffix
+}
+
+// IsSidecar reports whether name is a sidecar file
+func IsSidecar(name string) bool {
+	return strings.HasSuffix(name, SidecarSuffix)
+}
+
+// NewSidecar describes file as a pending file from source, with the checksum of its current content
+func NewSidecar(file string, source string) (Sidecar, error) {
+	sum, err := FileChecksum(file)
+	if err != nil {
+		return Sidecar{}, err
+	}
+	return Sidecar{Source: source, Checksum: sum, Status: StatusPending}, nil
+}
+
+// FileChecksum returns the sha256 of the file content as "sha256:<hex>"
+func FileChecksum(file string) (string, error) {
+	f, err := os.Open(LongPath(file))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadSidecar reads the sidecar of file; ErrNoSidecar when there is none
+func ReadSidecar(file string) (Sidecar, error) {
+	return readSidecar(os.DirFS(filepath.Dir(file)), filepath.Base(file))
+}
+
+func readSidecar(fsys fs.FS, name string) (Sidecar, error) {
+	var sc Sidecar
+	content, err := fs.ReadFile(fsys, SidecarPath(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return sc, fmt.Errorf("%w; file:%s", ErrNoSidecar, name)
+	}
+	if err != nil {
+		return sc, err
+	}
+	if err = json.Unmarshal(content, &sc); err != nil {
+		return sc, fmt.Errorf("%w; sidecar:%s", err, SidecarPath(name))
+	}
+	return sc, nil
+}
+
+// WriteSidecar writes the sidecar of file, replacing the previous one atomically; UpdatedAt is set to now,
+// CreatedAt too when it is zero, and IngestedAt when the status becomes ingested
+func WriteSidecar(file string, sc Sidecar) error {
+	now := time.Now().UTC()
+	if sc.CreatedAt.IsZero() {
+		sc.CreatedAt = now
+	}
+	if sc.Status == StatusIngested && sc.IngestedAt.IsZero() {
+		sc.IngestedAt = now
+	}
+	sc.UpdatedAt = now
+	content, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return err
+	}
+	dst := LongPath(SidecarPath(file))
+	part := dst + ".part"
+	if err = os.WriteFile(part, content, 0o644); err != nil {
+		return err
+	}
+	if err = os.Rename(part, dst); err != nil {
+		os.Remove(part)
+		return err
+	}
+	return nil
+}
+
+// SetSidecarStatus updates the status of the sidecar of file, keeping the other fields; reason is recorded as the Error
+func SetSidecarStatus(file string, status IngestStatus, reason string) error {
+	sc, err := ReadSidecar(file)
+	if err != nil {
+		return err
+	}
+	sc.Status = status
+	sc.Error = reason
+	return WriteSidecar(file, sc)
+}
+
+// Verify checks that the file still has the content the sidecar was written for
+func (sc Sidecar) Verify(file string) error {
+	sum, err := FileChecksum(file)
+	if err != nil {
+		return err
+	}
+	if sum != sc.Checksum {
+		return fmt.Errorf("%w; file:%s;expected:%s;found:%s", ErrChecksumMismatch, file, sc.Checksum, sum)
+	}
+	return nil
+}
+
+// sidecarFilter selects files by their sidecar
+type sidecarFilter struct {
+	without  bool
+	statuses []IngestStatus
+}
+
+// WithSidecarStatus keeps only the files whose sidecar has one of the statuses; combined with WithoutSidecar the files
+// without a sidecar are kept as well. Sidecar files never match once a sidecar option is set
+func WithSidecarStatus(statuses ...IngestStatus) FileFilterOption {
+	return func(ff *FileFilter) error {
+		if ff.sidecar == nil {
+			ff.sidecar = new(sidecarFilter)
+		}
+		ff.sidecar.statuses = append(ff.sidecar.statuses, statuses...)
+		return nil
+	}
+}
+
+// WithoutSidecar keeps only the files that have no sidecar yet, e.g. the files no ingest has picked up
+func WithoutSidecar() FileFilterOption {
+	return func(ff *FileFilter) error {
+		if ff.sidecar == nil {
+			ff.sidecar = new(sidecarFilter)
+		}
+		ff.sidecar.without = true
+		return nil
+	}
+}
+
+// keep applies the filter to the matches of fsys
+func (sf *sidecarFilter) keep(fsys fs.FS, matches []string) ([]string, error) {
+	kept := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if IsSidecar(m) {
+			continue
+		}
+		sc, err := readSidecar(fsys, m)
+		switch {
+		case errors.Is(err, ErrNoSidecar):
+			if sf.without {
+				kept = append(kept, m)
+			}
+		case err != nil:
+			return nil, err
+		case slices.Contains(sf.statuses, sc.Status):
+			kept = append(kept, m)
+		}
+	}
+	return kept, nil
+}
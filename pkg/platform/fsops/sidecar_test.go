@@ -0,0 +1,79 @@
+package fsops_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/fsops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSidecar_WriteRead(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "orders.csv")
+	require.NoError(t, os.WriteFile(file, []byte("id,qty\n1,2\n"), 0o644))
+
+	_, err := fsops.ReadSidecar(file)
+	assert.ErrorIs(t, err, fsops.ErrNoSidecar)
+
+	sc, err := fsops.NewSidecar(file, "mes-share")
+	require.NoError(t, err)
+	assert.Equal(t, fsops.StatusPending, sc.Status)
+	require.NoError(t, fsops.WriteSidecar(file, sc))
+	assert.FileExists(t, file+".meta.json")
+
+	got, err := fsops.ReadSidecar(file)
+	require.NoError(t, err)
+	assert.Equal(t, "mes-share", got.Source)
+	assert.Equal(t, sc.Checksum, got.Checksum)
+	assert.False(t, got.CreatedAt.IsZero())
+	assert.True(t, got.IngestedAt.IsZero())
+	require.NoError(t, got.Verify(file))
+
+	require.NoError(t, fsops.SetSidecarStatus(file, fsops.StatusIngested, ""))
+	updated, err := fsops.ReadSidecar(file)
+	require.NoError(t, err)
+	assert.Equal(t, fsops.StatusIngested, updated.Status)
+	assert.Equal(t, got.CreatedAt, updated.CreatedAt)
+	assert.False(t, updated.IngestedAt.IsZero())
+
+	require.NoError(t, os.WriteFile(file, []byte("id,qty\n1,3\n"), 0o644))
+	assert.ErrorIs(t, updated.Verify(file), fsops.ErrChecksumMismatch)
+}
+
+func TestFileFilter_Filter_Sidecar(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, status fsops.IngestStatus) {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte(name), 0o644))
+		if status == "" {
+			return
+		}
+		sc, err := fsops.NewSidecar(file, "test")
+		require.NoError(t, err)
+		sc.Status = status
+		require.NoError(t, fsops.WriteSidecar(file, sc))
+	}
+	write("new.csv", "")
+	write("done.csv", fsops.StatusIngested)
+	write("broken.csv", fsops.StatusFailed)
+
+	filter := func(opts ...fsops.FileFilterOption) []string {
+		ff, err := fsops.NewFileFilter(append(opts, fsops.WithGlobPattern("*"), fsops.SetLoc([]string{dir}))...)
+		require.NoError(t, err)
+		matches, err := ff.Filter()
+		require.NoError(t, err)
+		sort.Strings(matches)
+		return matches
+	}
+
+	assert.Len(t, filter(), 5, "without a sidecar option the sidecars match too")
+	assert.Equal(t, []string{filepath.Join(dir, "new.csv")}, filter(fsops.WithoutSidecar()))
+	assert.Equal(t, []string{filepath.Join(dir, "done.csv")}, filter(fsops.WithSidecarStatus(fsops.StatusIngested)))
+	assert.Equal(t,
+		[]string{filepath.Join(dir, "broken.csv"), filepath.Join(dir, "new.csv")},
+		filter(fsops.WithoutSidecar(), fsops.WithSidecarStatus(fsops.StatusFailed)),
+	)
+}
@@ -0,0 +1,162 @@
+package logging
+
+import (
+	"net/http"
+	"time"
+)
+
+// Headers the work order, plant and request ID of an access log are taken from.
+const (
+	WorkOrderHeader = "X-Work-Order"
+	PlantHeader     = "X-Plant"
+	RequestIDHeader = "X-Request-ID"
+)
+
+const (
+	accessServedMsg = "request served"
+	accessSentMsg   = "request sent"
+)
+
+// AccessLog builds the CommonLog of an outgoing request and its response: the work order and plant come from the
+// request headers and the details hold the method, host, route, status, latency and request ID.
+// resp is nil when the request failed with err.
+func AccessLog(req *http.Request, resp *http.Response, latency time.Duration, err error) CommonLog {
+	status := 0
+	var bytes int64 = -1
+	if resp != nil {
+		status = resp.StatusCode
+		bytes = resp.ContentLength
+	}
+	cl := accessLog(req, status, bytes, latency, err)
+	cl.Details["host"] = req.URL.Host
+	cl.msg = accessSentMsg
+	return cl
+}
+
+func accessLog(req *http.Request, status int, bytes int64, latency time.Duration, err error) CommonLog {
+	route := req.Pattern
+	if route == "" {
+		route = req.URL.Path
+	}
+	details := map[string]any{
+		"method":     req.Method,
+		"route":      route,
+		"path":       req.URL.Path,
+		"latency_ms": float64(latency.Microseconds()) / 1000,
+	}
+	if status != 0 {
+		details["status"] = status
+	}
+	if bytes >= 0 {
+		details["bytes"] = bytes
+	}
+	if id := req.Header.Get(RequestIDHeader); id != "" {
+		details["request_id"] = id
+	}
+	if err != nil {
+		details["error"] = err.Error()
+	}
+	return CommonLog{
+		Wonum:   req.Header.Get(WorkOrderHeader),
+		Plant:   req.Header.Get(PlantHeader),
+		Details: details,
+	}
+}
+
+// LogAccess logs an access log at the level of its outcome: errors and 5xx responses at error level,
+// 4xx responses at warn level and everything else at info level.
+func (l *Logger) LogAccess(cl CommonLog) {
+	msg := cl.msg
+	if msg == "" {
+		msg = "http request"
+	}
+	attrs := []any{"details", cl.Details}
+	if cl.Wonum != "" {
+		attrs = append(attrs, "wo", cl.Wonum)
+	}
+	if cl.Plant != "" {
+		attrs = append(attrs, "plant", cl.Plant)
+	}
+	if cl.Error != nil {
+		attrs = append(attrs, "err", cl.Error)
+	}
+
+	status, _ := cl.Details["status"].(int)
+	_, failed := cl.Details["error"]
+	switch {
+	case failed || cl.Error != nil || status >= 500:
+		l.Error(msg, attrs...)
+	case status >= 400:
+		l.Warn(msg, attrs...)
+	default:
+		l.Info(msg, attrs...)
+	}
+}
+
+// AccessLogHandler returns server middleware logging every request it serves with LogAccess.
+// The route is the pattern of the http.ServeMux that matched the request, or the path otherwise.
+func AccessLogHandler(logger *Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			aw := &accessWriter{ResponseWriter: w}
+			start := time.Now()
+			defer func() {
+				status := aw.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				cl := accessLog(r, status, aw.bytes, time.Since(start), nil)
+				cl.msg = accessServedMsg
+				logger.LogAccess(cl)
+			}()
+			next.ServeHTTP(aw, r)
+		})
+	}
+}
+
+// AccessLogTransport returns client middleware logging every request sent through it with LogAccess;
+// it matches netcom.Middleware, e.g. netcom.ClientConfig{Middlewares: []netcom.Middleware{logging.AccessLogTransport(logger)}}.
+func AccessLogTransport(logger *Logger) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			logger.LogAccess(AccessLog(req, resp, time.Since(start), err))
+			return resp, err
+		})
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// accessWriter records the status and size of the response.
+type accessWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *accessWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *accessWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
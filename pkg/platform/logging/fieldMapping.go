@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// FieldMapping renames the standard fields of every record and controls how the time and level are written.
+// Empty names keep the slog defaults (time, level, msg, source).
+type FieldMapping struct {
+	Time    string
+	Level   string
+	Message string
+	Source  string
+	// TimeFormat is the layout of the time field; empty keeps the slog default (RFC 3339 with milliseconds).
+	TimeFormat string
+	// UTC converts the time field to UTC before formatting.
+	UTC bool
+	// LowercaseLevel writes levels as "info" instead of "INFO".
+	LowercaseLevel bool
+}
+
+// ECSMapping follows the Elastic Common Schema so records ingest into ELK without a mutate pipeline.
+var ECSMapping = FieldMapping{
+	Time:           "@timestamp",
+	Level:          "log.level",
+	Message:        "message",
+	Source:         "log.origin",
+	TimeFormat:     "2006-01-02T15:04:05.000Z07:00",
+	UTC:            true,
+	LowercaseLevel: true,
+}
+
+// OTelMapping uses the OpenTelemetry log data model field names.
+var OTelMapping = FieldMapping{
+	Time:       "timestamp",
+	Level:      "severity_text",
+	Message:    "body",
+	Source:     "code",
+	TimeFormat: time.RFC3339Nano,
+	UTC:        true,
+}
+
+// replaceAttr returns the slog.HandlerOptions.ReplaceAttr implementing the mapping.
+func (fm *FieldMapping) replaceAttr() func(groups []string, a slog.Attr) slog.Attr {
+	if fm == nil {
+		return nil
+	}
+	mapping := *fm
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) != 0 {
+			return a
+		}
+		switch a.Key {
+		case slog.TimeKey:
+			if t, ok := a.Value.Any().(time.Time); ok {
+				if mapping.UTC {
+					t = t.UTC()
+				}
+				if mapping.TimeFormat != "" {
+					a.Value = slog.StringValue(t.Format(mapping.TimeFormat))
+				} else {
+					a.Value = slog.TimeValue(t)
+				}
+			}
+			a.Key = rename(a.Key, mapping.Time)
+		case slog.LevelKey:
+			if l, ok := a.Value.Any().(slog.Level); ok && mapping.LowercaseLevel {
+				a.Value = slog.StringValue(strings.ToLower(l.String()))
+			}
+			a.Key = rename(a.Key, mapping.Level)
+		case slog.MessageKey:
+			a.Key = rename(a.Key, mapping.Message)
+		case slog.SourceKey:
+			a.Key = rename(a.Key, mapping.Source)
+		}
+		return a
+	}
+}
+
+func rename(key, to string) string {
+	if to == "" {
+		return key
+	}
+	return to
+}
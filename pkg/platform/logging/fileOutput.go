@@ -0,0 +1,218 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBadFileTemplate is returned for file path templates with unknown or empty placeholders.
+var ErrBadFileTemplate = errors.New("invalid log file template")
+
+// timePlaceholders are the placeholders of a file template filled from the time of the write.
+var timePlaceholders = map[string]string{
+	"date":  "2006-01-02",
+	"year":  "2006",
+	"month": "01",
+	"day":   "02",
+	"hour":  "15",
+}
+
+var placeholder = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// FileOutputConfig configures a FileWriter.
+type FileOutputConfig struct {
+	// Path is the template of the log file path, e.g. "logs/{plant}/service-{date}-{plant}.log".
+	// {date}, {year}, {month}, {day} and {hour} are filled from the time of the write, so a new file is started
+	// when they change; {index} is the number of the file within the period when MaxSize splits it, and the other
+	// placeholders are keys of Fields.
+	Path string
+	// Fields fill the custom placeholders of Path, e.g. {"plant": "gradec"}.
+	Fields map[string]string
+	// MaxSize starts a new file once writing would grow the current one past it; without the {index} placeholder
+	// the number is inserted before the extension, e.g. service-2025-06-01.1.log. Zero means no size limit.
+	MaxSize int64
+	// UTC fills the time placeholders in UTC instead of local time.
+	UTC bool
+	// FileMode and DirMode are the permissions of the created files and directories, 0o644 and 0o755 by default.
+	FileMode fs.FileMode
+	DirMode  fs.FileMode
+	// Now returns the time of a write; defaults to time.Now.
+	Now func() time.Time
+}
+
+// FileWriter writes log records to files named by a template, creating their directories and starting new files
+// when the period of the name passes or the size limit is reached. Existing files are appended to.
+type FileWriter struct {
+	mu     sync.Mutex
+	config FileOutputConfig
+	file   *os.File
+	// period is the path of the first file of the period, which identifies it
+	period string
+	index  int
+	size   int64
+}
+
+// NewFileWriter validates the template and opens the file of the current period.
+func NewFileWriter(config FileOutputConfig) (*FileWriter, error) {
+	if len(config.Path) == 0 {
+		return nil, fmt.Errorf("%w: the path is empty", ErrBadFileTemplate)
+	}
+	for _, m := range placeholder.FindAllStringSubmatch(config.Path, -1) {
+		key := m[1]
+		if _, ok := timePlaceholders[key]; ok || key == "index" {
+			continue
+		}
+		if v, ok := config.Fields[key]; !ok || len(v) == 0 {
+			return nil, fmt.Errorf("%w: no value for {%s} in %s", ErrBadFileTemplate, key, config.Path)
+		}
+	}
+	if config.FileMode == 0 {
+		config.FileMode = 0o644
+	}
+	if config.DirMode == 0 {
+		config.DirMode = 0o755
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	fw := &FileWriter{config: config}
+	if err := fw.rotate(0); err != nil {
+		return nil, err
+	}
+	return fw, nil
+}
+
+// Name returns the path of the file being written.
+func (fw *FileWriter) Name() string {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.file == nil {
+		return ""
+	}
+	return fw.file.Name()
+}
+
+// Write implements io.Writer; a record is never split across files.
+func (fw *FileWriter) Write(p []byte) (int, error) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.file == nil {
+		return 0, os.ErrClosed
+	}
+	if err := fw.rotate(int64(len(p))); err != nil {
+		return 0, err
+	}
+	n, err := fw.file.Write(p)
+	fw.size += int64(n)
+	return n, err
+}
+
+// Flush syncs the current file to disk.
+func (fw *FileWriter) Flush() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.file == nil {
+		return nil
+	}
+	return fw.file.Sync()
+}
+
+// Close closes the current file; later writes fail with os.ErrClosed.
+func (fw *FileWriter) Close() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.file == nil {
+		return nil
+	}
+	err := fw.file.Close()
+	fw.file = nil
+	return err
+}
+
+// rotate opens the file a write of n bytes belongs in, when it is not the current one.
+func (fw *FileWriter) rotate(n int64) error {
+	now := fw.config.Now()
+	if fw.config.UTC {
+		now = now.UTC()
+	}
+	period := fw.path(now, 0)
+	index := fw.index
+	switch {
+	case fw.file == nil || period != fw.period:
+		index = fw.lastIndex(now)
+	case fw.config.MaxSize > 0 && fw.size > 0 && fw.size+n > fw.config.MaxSize:
+		index++
+	default:
+		return nil
+	}
+	name := fw.path(now, index)
+	for {
+		info, err := os.Stat(name)
+		if err != nil || fw.config.MaxSize <= 0 || info.Size() == 0 || info.Size()+n <= fw.config.MaxSize {
+			break
+		}
+		index++
+		name = fw.path(now, index)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), fw.config.DirMode); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, fw.config.FileMode)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if fw.file != nil {
+		fw.file.Close()
+	}
+	fw.file, fw.period, fw.index, fw.size = f, period, index, info.Size()
+	return nil
+}
+
+// lastIndex returns the index of the last existing file of the period, so a restart continues it.
+func (fw *FileWriter) lastIndex(now time.Time) int {
+	if fw.config.MaxSize <= 0 {
+		return 0
+	}
+	index := 0
+	for {
+		if _, err := os.Stat(fw.path(now, index+1)); err != nil {
+			return index
+		}
+		index++
+	}
+}
+
+// path fills the template for the time and file index.
+func (fw *FileWriter) path(now time.Time, index int) string {
+	hasIndex := false
+	name := placeholder.ReplaceAllStringFunc(fw.config.Path, func(m string) string {
+		key := m[1 : len(m)-1]
+		if layout, ok := timePlaceholders[key]; ok {
+			return now.Format(layout)
+		}
+		if key == "index" {
+			hasIndex = true
+			return strconv.Itoa(index)
+		}
+		return fw.config.Fields[key]
+	})
+	if hasIndex || index == 0 {
+		return name
+	}
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + strconv.Itoa(index) + ext
+}
@@ -0,0 +1,237 @@
+package logging
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrLoggerClosed is returned by Flush and Close once the logger was closed.
+var ErrLoggerClosed = errors.New("logger closed")
+
+// Flusher is implemented by outputs that buffer records, such as BufferedWriter and bufio.Writer.
+type Flusher interface {
+	Flush() error
+}
+
+// ContextFlusher is implemented by outputs whose flush can block, such as network shippers; the flush gives up when ctx is done.
+type ContextFlusher interface {
+	Flush(ctx context.Context) error
+}
+
+// outputs returns the writers of the configuration, the main output first.
+func (c LoggerConfig) outputs() []io.Writer {
+	var writers []io.Writer
+	if c.Output != nil {
+		writers = append(writers, c.Output)
+	}
+	for _, o := range c.AdditionalOutputs {
+		if o.Writer != nil {
+			writers = append(writers, o.Writer)
+		}
+	}
+	return writers
+}
+
+// outputState is shared by a logger and the loggers derived from it; once closed their records are dropped.
+type outputState struct {
+	mu     sync.RWMutex
+	closed bool
+}
+
+// closableHandler drops records once the outputs were closed and keeps Close from closing an output mid-write.
+type closableHandler struct {
+	slog.Handler
+	state *outputState
+}
+
+// Enabled implements slog.Handler.Enabled.
+func (h *closableHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	h.state.mu.RLock()
+	defer h.state.mu.RUnlock()
+	return !h.state.closed && h.Handler.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.Handle.
+func (h *closableHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.state.mu.RLock()
+	defer h.state.mu.RUnlock()
+	if h.state.closed {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.WithAttrs.
+func (h *closableHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &closableHandler{Handler: h.Handler.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup implements slog.Handler.WithGroup.
+func (h *closableHandler) WithGroup(name string) slog.Handler {
+	return &closableHandler{Handler: h.Handler.WithGroup(name), state: h.state}
+}
+
+// Flush writes out the records buffered by every output and syncs file outputs to disk.
+// It returns the errors of all outputs joined, or ctx.Err() if ctx is done before every output was flushed.
+func (l *Logger) Flush(ctx context.Context) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	l.state.mu.RLock()
+	defer l.state.mu.RUnlock()
+	if l.state.closed {
+		return ErrLoggerClosed
+	}
+	return flushOutputs(ctx, l.config.outputs())
+}
+
+// Close flushes and closes every output that implements io.Closer, except the standard streams.
+// Records logged afterwards are dropped, also by the loggers derived with With, which share the outputs.
+// A logger derived with WithOutputs is closed on its own and leaves the outputs it inherited to its parent.
+func (l *Logger) Close() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	// waits for the records being written and keeps new ones out
+	l.state.mu.Lock()
+	defer l.state.mu.Unlock()
+	if l.state.closed {
+		return ErrLoggerClosed
+	}
+	l.state.closed = true
+	outputs := l.config.outputs()
+	errs := []error{flushOutputs(context.Background(), outputs)}
+	for _, w := range outputs {
+		if isStdStream(w) || slices.Contains(l.inherited, w) {
+			continue
+		}
+		if c, ok := w.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func flushOutputs(ctx context.Context, outputs []io.Writer) error {
+	var errs []error
+	for _, w := range outputs {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		switch f := w.(type) {
+		case ContextFlusher:
+			errs = append(errs, f.Flush(ctx))
+		case Flusher:
+			errs = append(errs, f.Flush())
+		case *os.File:
+			// the standard streams are unbuffered and Sync fails on terminals and pipes
+			if !isStdStream(f) {
+				errs = append(errs, f.Sync())
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isStdStream(w io.Writer) bool {
+	return w == os.Stdout || w == os.Stderr
+}
+
+// BufferedWriter batches writes to an output in memory and writes them out when the buffer fills,
+// at every flush interval, and on Flush and Close, so that slow outputs such as files on network shares
+// do not stall every log call.
+type BufferedWriter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	buf    *bufio.Writer
+	stop   chan struct{}
+	done   chan struct{}
+	closed bool
+}
+
+// NewBufferedWriter buffers up to size bytes of writes to w and flushes them at least every interval;
+// a non-positive size uses 64 KiB and a non-positive interval only flushes when the buffer fills or on Flush and Close.
+func NewBufferedWriter(w io.Writer, size int, interval time.Duration) *BufferedWriter {
+	if size <= 0 {
+		size = 64 << 10
+	}
+	bw := &BufferedWriter{out: w, buf: bufio.NewWriterSize(w, size), stop: make(chan struct{}), done: make(chan struct{})}
+	if interval <= 0 {
+		close(bw.done)
+		return bw
+	}
+	go bw.flushEvery(interval)
+	return bw
+}
+
+func (bw *BufferedWriter) flushEvery(interval time.Duration) {
+	defer close(bw.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-bw.stop:
+			return
+		case <-ticker.C:
+			bw.Flush()
+		}
+	}
+}
+
+// Write implements io.Writer.
+func (bw *BufferedWriter) Write(p []byte) (int, error) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if bw.closed {
+		return 0, os.ErrClosed
+	}
+	return bw.buf.Write(p)
+}
+
+// Flush writes the buffered data to the underlying writer and syncs it when it is a file.
+func (bw *BufferedWriter) Flush() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if bw.closed {
+		return nil
+	}
+	return bw.flushLocked()
+}
+
+func (bw *BufferedWriter) flushLocked() error {
+	if err := bw.buf.Flush(); err != nil {
+		return err
+	}
+	if f, ok := bw.out.(*os.File); ok && !isStdStream(f) {
+		return f.Sync()
+	}
+	return nil
+}
+
+// Close flushes the buffered data, stops the flush interval and closes the underlying writer if it is an io.Closer.
+func (bw *BufferedWriter) Close() error {
+	bw.mu.Lock()
+	if bw.closed {
+		bw.mu.Unlock()
+		return nil
+	}
+	bw.closed = true
+	err := bw.flushLocked()
+	bw.mu.Unlock()
+
+	select {
+	case <-bw.done:
+	default:
+		close(bw.stop)
+		<-bw.done
+	}
+	if c, ok := bw.out.(io.Closer); ok && !isStdStream(bw.out) {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
@@ -0,0 +1,322 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+)
+
+var ErrMalformedLog = errors.New("log line is not a JSON log record")
+
+// tailChunk is the size of the blocks TailLogFile reads backwards from the end of a file.
+const tailChunk = 64 << 10
+
+// Entry is a log record read back from a JSON log file written by this package.
+type Entry struct {
+	Time  time.Time
+	Level slog.Level
+	Msg   string
+	// Attrs holds every other key of the record; groups are nested maps and numbers are json.Number.
+	Attrs map[string]any
+}
+
+// Attr returns the attribute at key; nested group attributes are addressed with dots, e.g. "err.code".
+func (e Entry) Attr(key string) (any, bool) {
+	var cur any = e.Attrs
+	for part := range strings.SplitSeq(key, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// ReadOpt narrows down the entries returned by the read functions.
+type ReadOpt func(*readFilter) error
+
+type readFilter struct {
+	minLevel *slog.Level
+	since    time.Time
+	until    time.Time
+	msg      string
+	attrs    map[string]string
+	strict   bool
+	mapping  *FieldMapping
+}
+
+// WithMinLevel keeps entries at or above the given level.
+func WithMinLevel(level LoggerLevel) ReadOpt {
+	return func(f *readFilter) error {
+		l := getLevelFromString(level)
+		f.minLevel = &l
+		return nil
+	}
+}
+
+// WithTimeRange keeps entries logged in [since, until); a zero bound is open.
+func WithTimeRange(since, until time.Time) ReadOpt {
+	return func(f *readFilter) error {
+		f.since, f.until = since, until
+		return nil
+	}
+}
+
+// WithMessage keeps entries whose message contains substr.
+func WithMessage(substr string) ReadOpt {
+	return func(f *readFilter) error {
+		f.msg = substr
+		return nil
+	}
+}
+
+// WithAttr keeps entries whose attribute at key (see Entry.Attr) formats to the same string as value.
+// Repeated options must all match.
+func WithAttr(key string, value any) ReadOpt {
+	return func(f *readFilter) error {
+		if f.attrs == nil {
+			f.attrs = make(map[string]string)
+		}
+		f.attrs[key] = fmt.Sprint(value)
+		return nil
+	}
+}
+
+// WithStrict makes the read functions fail on lines that are not JSON log records instead of skipping them.
+func WithStrict() ReadOpt {
+	return func(f *readFilter) error {
+		f.strict = true
+		return nil
+	}
+}
+
+// WithFieldMapping reads files written with a FieldMapping (e.g. ECSMapping).
+func WithFieldMapping(fm FieldMapping) ReadOpt {
+	return func(f *readFilter) error {
+		f.mapping = &fm
+		return nil
+	}
+}
+
+func newReadFilter(opts []ReadOpt) (*readFilter, error) {
+	f := new(readFilter)
+	for _, opt := range opts {
+		if err := opt(f); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (f *readFilter) match(e Entry) bool {
+	if f.minLevel != nil && e.Level < *f.minLevel {
+		return false
+	}
+	if !f.since.IsZero() && e.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !e.Time.Before(f.until) {
+		return false
+	}
+	if f.msg != "" && !strings.Contains(e.Msg, f.msg) {
+		return false
+	}
+	for k, want := range f.attrs {
+		v, ok := e.Attr(k)
+		if !ok || fmt.Sprint(v) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseEntry decodes a single JSON log line.
+func ParseEntry(line []byte) (Entry, error) {
+	return parseEntry(line, nil)
+}
+
+func parseEntry(line []byte, fm *FieldMapping) (Entry, error) {
+	timeKey, levelKey, msgKey, layout := slog.TimeKey, slog.LevelKey, slog.MessageKey, time.RFC3339Nano
+	if fm != nil {
+		timeKey, levelKey, msgKey = rename(timeKey, fm.Time), rename(levelKey, fm.Level), rename(msgKey, fm.Message)
+		if fm.TimeFormat != "" {
+			layout = fm.TimeFormat
+		}
+	}
+	var raw map[string]any
+	d := json.NewDecoder(bytes.NewReader(line))
+	d.UseNumber()
+	if err := d.Decode(&raw); err != nil {
+		return Entry{}, fmt.Errorf("%w: %w", ErrMalformedLog, err)
+	}
+	var e Entry
+	if s, ok := raw[timeKey].(string); ok {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return Entry{}, fmt.Errorf("%w: %w", ErrMalformedLog, err)
+		}
+		e.Time = t
+	}
+	s, ok := raw[levelKey].(string)
+	if !ok {
+		return Entry{}, fmt.Errorf("%w: missing %q", ErrMalformedLog, levelKey)
+	}
+	if err := e.Level.UnmarshalText([]byte(s)); err != nil {
+		return Entry{}, fmt.Errorf("%w: %w", ErrMalformedLog, err)
+	}
+	e.Msg, _ = raw[msgKey].(string)
+	delete(raw, timeKey)
+	delete(raw, levelKey)
+	delete(raw, msgKey)
+	e.Attrs = raw
+	return e, nil
+}
+
+// ReadLogs reads every matching entry from r.
+func ReadLogs(r io.Reader, opts ...ReadOpt) ([]Entry, error) {
+	f, err := newReadFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for sc.Scan() {
+		e, ok, err := f.parse(sc.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
+
+// ReadLogFile reads every matching entry from the log file at path.
+func ReadLogFile(path string, opts ...ReadOpt) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadLogs(file, opts...)
+}
+
+// TailLogFile returns the last n matching entries of the log file at path, oldest first.
+// The file is read backwards, so only the tail of large files is loaded.
+func TailLogFile(path string, n int, opts ...ReadOpt) ([]Entry, error) {
+	f, err := newReadFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	var rest []byte
+	for off := info.Size(); off > 0 && len(entries) < n; {
+		size := min(int64(tailChunk), off)
+		off -= size
+		chunk := make([]byte, size, int(size)+len(rest))
+		if _, err = file.ReadAt(chunk, off); err != nil {
+			return nil, err
+		}
+		chunk = append(chunk, rest...)
+		lines := bytes.Split(chunk, []byte("\n"))
+		// the first line may continue in the previous chunk
+		rest, lines = lines[0], lines[1:]
+		if off == 0 {
+			lines = append([][]byte{rest}, lines...)
+			rest = nil
+		}
+		for i := len(lines) - 1; i >= 0 && len(entries) < n; i-- {
+			e, ok, err := f.parse(lines[i])
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				entries = append(entries, e)
+			}
+		}
+	}
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+func (f *readFilter) parse(line []byte) (Entry, bool, error) {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return Entry{}, false, nil
+	}
+	e, err := parseEntry(line, f.mapping)
+	if err != nil {
+		if f.strict {
+			return Entry{}, false, err
+		}
+		return Entry{}, false, nil
+	}
+	return e, f.match(e), nil
+}
+
+// EntriesToDataframe lays the entries out as a dataframe with the time, level and msg columns followed by
+// one column per attribute key (sorted, groups flattened with dots); missing attributes are empty values.
+func EntriesToDataframe(entries []Entry) (*datamanagement.Dataframe, error) {
+	flat := make([]map[string]string, len(entries))
+	keys := make(map[string]struct{})
+	for i, e := range entries {
+		flat[i] = make(map[string]string)
+		flattenAttrs("", e.Attrs, flat[i])
+		for k := range flat[i] {
+			keys[k] = struct{}{}
+		}
+	}
+	header := append([]string{slog.TimeKey, slog.LevelKey, slog.MessageKey}, slices.Sorted(maps.Keys(keys))...)
+	records := make([]datamanagement.Record, 0, len(entries)+1)
+	records = append(records, header)
+	for i, e := range entries {
+		r := make(datamanagement.Record, len(header))
+		r[0], r[1], r[2] = e.Time.Format(time.RFC3339Nano), e.Level.String(), e.Msg
+		for j, k := range header[3:] {
+			r[j+3] = flat[i][k]
+		}
+		records = append(records, r)
+	}
+	return datamanagement.NewDataframeFromRecords(records, nil, datamanagement.WithInterpretedColumns())
+}
+
+func flattenAttrs(prefix string, attrs map[string]any, out map[string]string) {
+	for k, v := range attrs {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if group, ok := v.(map[string]any); ok {
+			flattenAttrs(k, group, out)
+			continue
+		}
+		if v == nil {
+			out[k] = ""
+			continue
+		}
+		out[k] = fmt.Sprint(v)
+	}
+}
@@ -3,12 +3,12 @@ package logging
 import (
 	"strconv"
 
-	h "github.com/ivanehh/go-boiler-lib/internal/helpers"
+	"github.com/ivanehh/go-boiler-lib/pkg/typeutil"
 )
 
 type MapableError interface {
 	error
-	h.Mapable
+	typeutil.Mapable
 }
 
 type ConfigurableError interface {
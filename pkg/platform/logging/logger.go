@@ -5,6 +5,7 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"slices"
 	"sync"
 	"time"
 )
@@ -29,12 +30,17 @@ type LoggerConfig struct {
 
 	// Additional outputs with format specification
 	AdditionalOutputs []OutputConfig
+
+	// FieldMapping renames the standard fields (e.g. ECSMapping); nil keeps the slog names
+	FieldMapping *FieldMapping
 }
 
 // OutputConfig specifies an output destination with its format
 type OutputConfig struct {
 	Writer     io.Writer
 	JSONFormat bool
+	// FieldMapping overrides LoggerConfig.FieldMapping for this output
+	FieldMapping *FieldMapping
 }
 
 // DefaultConfig returns the default logger configuration
@@ -54,14 +60,45 @@ type Logger struct {
 	slogger *slog.Logger
 	config  LoggerConfig
 	mu      sync.RWMutex
+	state   *outputState
+	// attrs added by With, replayed when the handler is rebuilt
+	attrs []any
+	// outputs owned by the logger this one was derived from with WithOutputs, which Close leaves open
+	inherited []io.Writer
 }
 
 // New creates a new Logger instance with the provided configuration
 func New(config LoggerConfig) *Logger {
+	state := new(outputState)
+	return &Logger{
+		slogger: slog.New(&closableHandler{Handler: newHandler(config), state: state}),
+		config:  config,
+		state:   state,
+	}
+}
+
+// newHandler builds the handler writing to every output of the configuration
+func newHandler(config LoggerConfig) slog.Handler {
+	return verboseHandler{newOutputsHandler(config)}
+}
+
+func newOutputsHandler(config LoggerConfig) slog.Handler {
 	level := getLevelFromString(config.Level)
-	opts := &slog.HandlerOptions{
-		Level:     level,
-		AddSource: config.AddSource,
+	handlerOpts := func(fm *FieldMapping) *slog.HandlerOptions {
+		return &slog.HandlerOptions{
+			Level:       level,
+			AddSource:   config.AddSource,
+			ReplaceAttr: fm.replaceAttr(),
+		}
+	}
+	newOutput := func(w io.Writer, jsonFormat bool, fm *FieldMapping) slog.Handler {
+		if fm == nil {
+			fm = config.FieldMapping
+		}
+		if jsonFormat {
+			return slog.NewJSONHandler(w, handlerOpts(fm))
+		}
+		return slog.NewTextHandler(w, handlerOpts(fm))
 	}
 
 	// Create handlers for each output
@@ -69,38 +106,25 @@ func New(config LoggerConfig) *Logger {
 
 	// Main output
 	if config.Output != nil {
-		if config.JSONFormat {
-			handlers = append(handlers, slog.NewJSONHandler(config.Output, opts))
-		} else {
-			handlers = append(handlers, slog.NewTextHandler(config.Output, opts))
-		}
+		handlers = append(handlers, newOutput(config.Output, config.JSONFormat, nil))
 	}
 
 	// Additional outputs
 	for _, outputConfig := range config.AdditionalOutputs {
 		if outputConfig.Writer != nil {
-			if outputConfig.JSONFormat {
-				handlers = append(handlers, slog.NewJSONHandler(outputConfig.Writer, opts))
-			} else {
-				handlers = append(handlers, slog.NewTextHandler(outputConfig.Writer, opts))
-			}
+			handlers = append(handlers, newOutput(outputConfig.Writer, outputConfig.JSONFormat, outputConfig.FieldMapping))
 		}
 	}
 
 	// Create multi handler if we have multiple outputs
-	var handler slog.Handler
-	if len(handlers) > 1 {
-		handler = NewMultiHandler(handlers...)
-	} else if len(handlers) == 1 {
-		handler = handlers[0]
-	} else {
+	switch len(handlers) {
+	case 0:
 		// Fallback to stdout with text format if no outputs specified
-		handler = slog.NewTextHandler(os.Stdout, opts)
-	}
-
-	return &Logger{
-		slogger: slog.New(handler),
-		config:  config,
+		return newOutput(os.Stdout, false, nil)
+	case 1:
+		return handlers[0]
+	default:
+		return NewMultiHandler(handlers...)
 	}
 }
 
@@ -175,8 +199,11 @@ func (l *Logger) With(attrs ...any) *Logger {
 	defer l.mu.RUnlock()
 
 	newLogger := &Logger{
-		slogger: l.slogger.With(attrs...),
-		config:  l.config,
+		slogger:   l.slogger.With(attrs...),
+		config:    l.config,
+		state:     l.state,
+		attrs:     append(slices.Clip(l.attrs), attrs...),
+		inherited: l.inherited,
 	}
 	return newLogger
 }
@@ -209,51 +236,47 @@ func (l *Logger) Error(msg string, attrs ...any) {
 	l.slogger.Error(msg, attrs...)
 }
 
-// UpdateConfig updates the logger configuration dynamically
-func (l *Logger) UpdateConfig(config LoggerConfig) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+// DebugContext logs a debug message with the given attributes; it is emitted at any level when ctx is verbose (see WithVerbose)
+func (l *Logger) DebugContext(ctx context.Context, msg string, attrs ...any) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	l.slogger.DebugContext(ctx, msg, attrs...)
+}
 
-	level := getLevelFromString(config.Level)
-	opts := &slog.HandlerOptions{
-		Level:     level,
-		AddSource: config.AddSource,
-	}
+// InfoContext logs an info message with the given attributes
+func (l *Logger) InfoContext(ctx context.Context, msg string, attrs ...any) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	l.slogger.InfoContext(ctx, msg, attrs...)
+}
 
-	// Create handlers for each output
-	var handlers []slog.Handler
+// WarnContext logs a warning message with the given attributes
+func (l *Logger) WarnContext(ctx context.Context, msg string, attrs ...any) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	l.slogger.WarnContext(ctx, msg, attrs...)
+}
 
-	// Main output
-	if config.Output != nil {
-		if config.JSONFormat {
-			handlers = append(handlers, slog.NewJSONHandler(config.Output, opts))
-		} else {
-			handlers = append(handlers, slog.NewTextHandler(config.Output, opts))
-		}
-	}
+// ErrorContext logs an error message with the given attributes
+func (l *Logger) ErrorContext(ctx context.Context, msg string, attrs ...any) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	l.slogger.ErrorContext(ctx, msg, attrs...)
+}
 
-	// Additional outputs
-	for _, outputConfig := range config.AdditionalOutputs {
-		if outputConfig.Writer != nil {
-			if outputConfig.JSONFormat {
-				handlers = append(handlers, slog.NewJSONHandler(outputConfig.Writer, opts))
-			} else {
-				handlers = append(handlers, slog.NewTextHandler(outputConfig.Writer, opts))
-			}
-		}
-	}
+// UpdateConfig updates the logger configuration dynamically.
+// The outputs that are dropped are flushed but not closed; the caller owns them.
+func (l *Logger) UpdateConfig(config LoggerConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
-	// Create multi handler if we have multiple outputs
-	var handler slog.Handler
-	if len(handlers) > 1 {
-		handler = NewMultiHandler(handlers...)
-	} else if len(handlers) == 1 {
-		handler = handlers[0]
-	} else {
-		// Fallback to stdout with text format if no outputs specified
-		handler = slog.NewTextHandler(os.Stdout, opts)
+	var dropped []io.Writer
+	for _, w := range l.config.outputs() {
+		if !slices.Contains(config.outputs(), w) {
+			dropped = append(dropped, w)
+		}
 	}
-
-	l.slogger = slog.New(handler)
+	flushOutputs(context.Background(), dropped)
+	l.slogger = slog.New(&closableHandler{Handler: newHandler(config), state: l.state}).With(l.attrs...)
 	l.config = config
 }
@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+)
+
+// OutputChange adds or removes outputs of a logger derived with WithOutputs.
+type OutputChange func(*LoggerConfig)
+
+// AddOutput writes the records of the derived logger to an additional output.
+func AddOutput(output OutputConfig) OutputChange {
+	return func(c *LoggerConfig) {
+		c.AdditionalOutputs = append(c.AdditionalOutputs, output)
+	}
+}
+
+// RemoveOutput stops the derived logger from writing to w, whether it is the main or an additional output.
+func RemoveOutput(w io.Writer) OutputChange {
+	return func(c *LoggerConfig) {
+		if c.Output == w {
+			c.Output = nil
+		}
+		c.AdditionalOutputs = slices.DeleteFunc(c.AdditionalOutputs, func(o OutputConfig) bool { return o.Writer == w })
+	}
+}
+
+// OnlyOutputs replaces every inherited output, e.g. for a subsystem logging to an audit file only.
+func OnlyOutputs(outputs ...OutputConfig) OutputChange {
+	return func(c *LoggerConfig) {
+		c.Output = nil
+		c.AdditionalOutputs = slices.Clone(outputs)
+	}
+}
+
+// WithOutputs returns a logger that keeps the level, format, and attributes of l but writes to the outputs left by the changes.
+// The derived logger is flushed and closed independently of l: its Close closes the outputs it added and leaves the
+// inherited ones to l. Removing every output falls back to stdout, like New.
+func (l *Logger) WithOutputs(changes ...OutputChange) *Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	config := l.config
+	config.AdditionalOutputs = slices.Clone(l.config.AdditionalOutputs)
+	for _, change := range changes {
+		change(&config)
+	}
+	var inherited []io.Writer
+	parent := l.config.outputs()
+	for _, w := range config.outputs() {
+		if slices.Contains(parent, w) {
+			inherited = append(inherited, w)
+		}
+	}
+	state := new(outputState)
+	return &Logger{
+		slogger:   slog.New(&closableHandler{Handler: newHandler(config), state: state}).With(l.attrs...),
+		config:    config,
+		state:     state,
+		attrs:     slices.Clone(l.attrs),
+		inherited: inherited,
+	}
+}
@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// Panic describes a recovered panic.
+type Panic struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+	// Name identifies the goroutine or request that panicked, as set with WithTaskName or by RecoverHandler.
+	Name string
+}
+
+// Err returns the panic value as an error, wrapping it when it is one.
+func (p Panic) Err() error {
+	if err, ok := p.Value.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", p.Value)
+}
+
+// RecoverOption configures how a panic is handled.
+type RecoverOption func(*recoverConfig)
+
+type recoverConfig struct {
+	name    string
+	notify  []func(Panic)
+	repanic bool
+}
+
+// WithTaskName names the goroutine in the panic record, e.g. "order-sync-worker".
+func WithTaskName(name string) RecoverOption {
+	return func(rc *recoverConfig) {
+		rc.name = name
+	}
+}
+
+// WithPanicNotify calls fn after the panic was logged, e.g. to alert or count crashes; fn must not panic.
+func WithPanicNotify(fn func(Panic)) RecoverOption {
+	return func(rc *recoverConfig) {
+		rc.notify = append(rc.notify, fn)
+	}
+}
+
+// WithRepanic panics again with the original value after logging and notifying, for crashes that must stay fatal.
+func WithRepanic() RecoverOption {
+	return func(rc *recoverConfig) {
+		rc.repanic = true
+	}
+}
+
+func newRecoverConfig(opts []RecoverOption) recoverConfig {
+	var rc recoverConfig
+	for _, opt := range opts {
+		opt(&rc)
+	}
+	return rc
+}
+
+// Recover logs a panic of the current goroutine as an error record with its stack trace and stops it there.
+// It must be deferred directly: defer logging.Recover(logger, logging.WithTaskName("ingest")).
+func Recover(logger *Logger, opts ...RecoverOption) {
+	if v := recover(); v != nil {
+		handlePanic(logger, newRecoverConfig(opts), v)
+	}
+}
+
+// Go runs fn in a new goroutine whose panics are recovered and logged like with Recover.
+func Go(logger *Logger, fn func(), opts ...RecoverOption) {
+	go func() {
+		defer Recover(logger, opts...)
+		fn()
+	}()
+}
+
+// RecoverHandler returns server middleware recovering panics of the handlers it wraps: the panic is logged with the
+// method and path of the request and the client gets a 500 response if nothing was written yet.
+// http.ErrAbortHandler is passed on untouched, since net/http uses it to abort a response on purpose.
+func RecoverHandler(logger *Logger, opts ...RecoverOption) func(http.Handler) http.Handler {
+	rc := newRecoverConfig(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &recoverWriter{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(v)
+				}
+				rc := rc
+				if rc.name == "" {
+					rc.name = r.Method + " " + r.URL.Path
+				}
+				handlePanic(logger.With("method", r.Method, "path", r.URL.Path), rc, v)
+				if !rw.wrote {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// recoverWriter tracks whether the handler started the response.
+type recoverWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *recoverWriter) WriteHeader(status int) {
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recoverWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *recoverWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// handlePanic logs, notifies and, if configured, repanics.
+func handlePanic(logger *Logger, rc recoverConfig, v any) {
+	p := Panic{Value: v, Stack: debug.Stack(), Name: rc.name}
+	attrs := []any{"panic", fmt.Sprint(v), "stack", string(p.Stack)}
+	if p.Name != "" {
+		attrs = append(attrs, "task", p.Name)
+	}
+	if logger != nil {
+		logger.Error("panic recovered", attrs...)
+	}
+	for _, fn := range rc.notify {
+		fn(p)
+	}
+	if rc.repanic {
+		panic(v)
+	}
+}
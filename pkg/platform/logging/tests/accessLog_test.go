@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/logging"
+)
+
+func lastRecord(t *testing.T, out *syncBuffer) map[string]any {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+		t.Fatalf("the access record is not a JSON line: %v; %s", err, out.String())
+	}
+	return record
+}
+
+func TestAccessLogHandler(t *testing.T) {
+	var out syncBuffer
+	logger := newJSONLogger(&out)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("open"))
+	})
+	handler := logging.AccessLogHandler(logger)(mux)
+
+	r := httptest.NewRequest(http.MethodGet, "/orders/1001", nil)
+	r.Header.Set(logging.WorkOrderHeader, "4711")
+	r.Header.Set(logging.PlantHeader, "gradec")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	record := lastRecord(t, &out)
+	details, _ := record["details"].(map[string]any)
+	if record["level"] != "INFO" || record["msg"] != "request served" || record["wo"] != "4711" || record["plant"] != "gradec" {
+		t.Errorf("unexpected record %v", record)
+	}
+	if details["route"] != "GET /orders/{id}" || details["path"] != "/orders/1001" || details["status"] != float64(200) || details["bytes"] != float64(4) {
+		t.Errorf("unexpected details %v", details)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/missing", nil))
+	if record = lastRecord(t, &out); record["level"] != "WARN" {
+		t.Errorf("expected a 404 at warn level, got %v", record)
+	}
+}
+
+func TestAccessLogTransport(t *testing.T) {
+	var out syncBuffer
+	logger := newJSONLogger(&out)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: logging.AccessLogTransport(logger)(http.DefaultTransport)}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/sync", nil)
+	req.Header.Set(logging.RequestIDHeader, "req-1")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	record := lastRecord(t, &out)
+	details, _ := record["details"].(map[string]any)
+	if record["level"] != "ERROR" || record["msg"] != "request sent" || details["status"] != float64(502) || details["request_id"] != "req-1" {
+		t.Errorf("unexpected record %v", record)
+	}
+
+	failing := logging.AccessLogTransport(logger)(roundTripper(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}))
+	req, _ = http.NewRequest(http.MethodGet, "http://mes.local/orders", nil)
+	failing.RoundTrip(req)
+	record = lastRecord(t, &out)
+	details, _ = record["details"].(map[string]any)
+	if record["level"] != "ERROR" || details["error"] != "connection refused" || details["host"] != "mes.local" {
+		t.Errorf("unexpected record %v", record)
+	}
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/logging"
+)
+
+func TestECSFieldMapping(t *testing.T) {
+	var buf bytes.Buffer
+	config := logging.DefaultConfig()
+	config.Output = &buf
+	config.JSONFormat = true
+	config.FieldMapping = &logging.ECSMapping
+	logging.New(config).Warn("disk almost full", "free", 12)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["message"] != "disk almost full" || record["log.level"] != "warn" {
+		t.Fatalf("unexpected record: %v", record)
+	}
+	ts, ok := record["@timestamp"].(string)
+	if !ok || !strings.HasSuffix(ts, "Z") {
+		t.Fatalf("expected a UTC @timestamp, got %v", record["@timestamp"])
+	}
+	if _, err := time.Parse(logging.ECSMapping.TimeFormat, ts); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"msg", "level", "time"} {
+		if _, ok := record[k]; ok {
+			t.Fatalf("slog field %q should have been renamed", k)
+		}
+	}
+
+	entries, err := logging.ReadLogs(&buf, logging.WithFieldMapping(logging.ECSMapping), logging.WithMinLevel(logging.WarnLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Msg != "disk almost full" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestFieldMappingPerOutput(t *testing.T) {
+	var plain, mapped bytes.Buffer
+	config := logging.DefaultConfig()
+	config.Output = &plain
+	config.JSONFormat = true
+	config.AdditionalOutputs = []logging.OutputConfig{{Writer: &mapped, JSONFormat: true, FieldMapping: &logging.OTelMapping}}
+	logging.New(config).Info("started")
+
+	if !strings.Contains(plain.String(), `"msg":"started"`) {
+		t.Fatalf("unexpected main output: %s", plain.String())
+	}
+	if !strings.Contains(mapped.String(), `"body":"started"`) || !strings.Contains(mapped.String(), `"severity_text":"INFO"`) {
+		t.Fatalf("unexpected mapped output: %s", mapped.String())
+	}
+}
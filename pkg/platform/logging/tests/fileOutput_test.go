@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/logging"
+)
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func TestFileWriterTemplate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 6, 1, 23, 59, 0, 0, time.UTC)
+	fw, err := logging.NewFileWriter(logging.FileOutputConfig{
+		Path:   filepath.Join(dir, "{plant}", "{year}", "mes-sync-{date}-{plant}.log"),
+		Fields: map[string]string{"plant": "gradec"},
+		UTC:    true,
+		Now:    func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.New(logging.LoggerConfig{Level: logging.InfoLevel, Output: fw})
+	logger.Info("shift closed")
+	now = now.Add(2 * time.Minute)
+	logger.Info("shift opened")
+	if err = logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	first := filepath.Join(dir, "gradec", "2025", "mes-sync-2025-06-01-gradec.log")
+	second := filepath.Join(dir, "gradec", "2025", "mes-sync-2025-06-02-gradec.log")
+	if lines := readLines(t, first); len(lines) != 1 || !strings.Contains(lines[0], "shift closed") {
+		t.Errorf("unexpected first day file: %q", lines)
+	}
+	if lines := readLines(t, second); len(lines) != 1 || !strings.Contains(lines[0], "shift opened") {
+		t.Errorf("unexpected second day file: %q", lines)
+	}
+	if _, err = fw.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expected os.ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestFileWriterMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	config := logging.FileOutputConfig{Path: filepath.Join(dir, "audit.log"), MaxSize: 10}
+	fw, err := logging.NewFileWriter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range []string{"order 1\n", "order 2\n", "order 3\n"} {
+		if _, err = fw.Write([]byte(record)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := fw.Name(); got != filepath.Join(dir, "audit.2.log") {
+		t.Errorf("expected the third file to be written, got %s", got)
+	}
+	fw.Close()
+
+	// a restart continues the last file while it has room
+	config.MaxSize = 20
+	fw, err = logging.NewFileWriter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fw.Close()
+	if _, err = fw.Write([]byte("order 4\n")); err != nil {
+		t.Fatal(err)
+	}
+	if lines := readLines(t, filepath.Join(dir, "audit.2.log")); len(lines) != 2 || lines[1] != "order 4" {
+		t.Errorf("unexpected last file: %q", lines)
+	}
+	if lines := readLines(t, filepath.Join(dir, "audit.log")); len(lines) != 1 || lines[0] != "order 1" {
+		t.Errorf("unexpected first file: %q", lines)
+	}
+}
+
+func TestFileWriterBadTemplate(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"", filepath.Join(dir, "service-{plant}.log"), filepath.Join(dir, "service-{}.log")} {
+		if _, err := logging.NewFileWriter(logging.FileOutputConfig{Path: path}); !errors.Is(err, logging.ErrBadFileTemplate) {
+			t.Errorf("expected ErrBadFileTemplate for %q, got %v", path, err)
+		}
+	}
+}
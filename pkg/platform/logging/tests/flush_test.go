@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/logging"
+)
+
+// syncBuffer is a bytes.Buffer safe for the flush goroutine of a BufferedWriter
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLoggerFlushBufferedOutput(t *testing.T) {
+	var out syncBuffer
+	bw := logging.NewBufferedWriter(&out, 4096, 0)
+	config := logging.DefaultConfig()
+	config.Output = bw
+	logger := logging.New(config)
+
+	logger.Info("batch received", "rows", 120)
+	if out.String() != "" {
+		t.Fatalf("Expected the record to stay buffered, got: %s", out.String())
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "batch received") {
+		t.Errorf("Expected the flushed record, got: %s", out.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := logger.Flush(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled flush to fail, got: %v", err)
+	}
+}
+
+func TestBufferedWriterInterval(t *testing.T) {
+	var out syncBuffer
+	bw := logging.NewBufferedWriter(&out, 4096, 5*time.Millisecond)
+	defer bw.Close()
+	bw.Write([]byte("line 4 stopped\n"))
+	deadline := time.Now().Add(time.Second)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if out.String() != "line 4 stopped\n" {
+		t.Errorf("Expected the interval to flush the write, got: %q", out.String())
+	}
+}
+
+func TestLoggerClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.log")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := logging.DefaultConfig()
+	config.Output = logging.NewBufferedWriter(f, 4096, time.Hour)
+	logger := logging.New(config)
+	derived := logger.With("component", "ingest")
+
+	derived.Info("shutting down")
+	if err = logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "shutting down") || !strings.Contains(string(content), "component=ingest") {
+		t.Errorf("Expected Close to flush the buffered record, got: %s", content)
+	}
+	if _, err = f.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected the file output to be closed, got: %v", err)
+	}
+
+	// records after Close are dropped instead of failing on the closed output
+	derived.Error("too late")
+	logger.Info("too late")
+	if err = logger.Close(); !errors.Is(err, logging.ErrLoggerClosed) {
+		t.Errorf("Expected ErrLoggerClosed, got: %v", err)
+	}
+	if err = derived.Flush(context.Background()); !errors.Is(err, logging.ErrLoggerClosed) {
+		t.Errorf("Expected ErrLoggerClosed, got: %v", err)
+	}
+}
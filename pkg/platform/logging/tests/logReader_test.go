@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/logging"
+)
+
+func writeLogFile(t *testing.T, lines int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "service.log")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	config := logging.DefaultConfig()
+	config.Output = file
+	config.JSONFormat = true
+	config.Level = logging.DebugLevel
+	logger := logging.New(config)
+	for i := range lines {
+		switch i % 3 {
+		case 0:
+			logger.Info("order processed", "wo", i, "plant", "gradec")
+		case 1:
+			logger.Debug("polling")
+		case 2:
+			logger.Error("upload failed", "err", map[string]any{"code": 503}, "plant", "sofia")
+		}
+	}
+	fmt.Fprintln(file, "not a json line")
+	return path
+}
+
+func TestReadLogFileFilters(t *testing.T) {
+	path := writeLogFile(t, 9)
+
+	all, err := logging.ReadLogFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 9 {
+		t.Fatalf("expected 9 entries, got %d", len(all))
+	}
+
+	errs, err := logging.ReadLogFile(path, logging.WithMinLevel(logging.WarnLevel), logging.WithAttr("err.code", 503))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 3 || errs[0].Msg != "upload failed" {
+		t.Fatalf("unexpected error entries: %+v", errs)
+	}
+
+	orders, err := logging.ReadLogFile(path, logging.WithAttr("plant", "gradec"), logging.WithAttr("wo", 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1 {
+		t.Fatalf("expected a single entry for wo 3, got %d", len(orders))
+	}
+
+	future, err := logging.ReadLogFile(path, logging.WithTimeRange(time.Now().Add(time.Hour), time.Time{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(future) != 0 {
+		t.Fatalf("expected no entries in the future, got %d", len(future))
+	}
+
+	if _, err = logging.ReadLogFile(path, logging.WithStrict()); err == nil {
+		t.Fatal("expected the strict reader to reject the malformed line")
+	}
+}
+
+func TestTailLogFile(t *testing.T) {
+	// enough lines to span several read chunks
+	path := writeLogFile(t, 3000)
+	last, err := logging.TailLogFile(path, 5, logging.WithMessage("order"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(last) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(last))
+	}
+	for i, wo := range []string{"2985", "2988", "2991", "2994", "2997"} {
+		if v, _ := last[i].Attr("wo"); fmt.Sprint(v) != wo {
+			t.Fatalf("expected wo %s at %d, got %v", wo, i, v)
+		}
+	}
+	orders, err := logging.TailLogFile(path, 2000, logging.WithMessage("order"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1000 {
+		t.Fatalf("expected every order entry, got %d", len(orders))
+	}
+	for i, e := range orders {
+		if v, _ := e.Attr("wo"); fmt.Sprint(v) != fmt.Sprint(i*3) {
+			t.Fatalf("expected wo %d at %d, got %v", i*3, i, v)
+		}
+	}
+}
+
+func TestEntriesToDataframe(t *testing.T) {
+	path := writeLogFile(t, 3)
+	entries, err := logging.ReadLogFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	df, err := logging.EntriesToDataframe(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(df.Header(), ","); got != "time,level,msg,err.code,plant,wo" {
+		t.Fatalf("unexpected header: %s", got)
+	}
+	if len(df.Rows) != 3 || df.Rows[2][3] != "503" || df.Rows[0][5] != "0" {
+		t.Fatalf("unexpected rows: %v", df.Rows)
+	}
+}
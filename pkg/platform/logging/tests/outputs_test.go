@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/logging"
+)
+
+func TestLoggerWithOutputs(t *testing.T) {
+	var main, audit bytes.Buffer
+	config := logging.DefaultConfig()
+	config.Output = &main
+	logger := logging.New(config).With("plant", "gradec")
+
+	both := logger.WithOutputs(logging.AddOutput(logging.OutputConfig{Writer: &audit, JSONFormat: true}))
+	both.Info("order released", "wo", 42)
+	if !strings.Contains(main.String(), "plant=gradec") {
+		t.Errorf("Expected the inherited output to keep the attributes, got: %s", main.String())
+	}
+	if !strings.Contains(audit.String(), `"plant":"gradec"`) || !strings.Contains(audit.String(), `"wo":42`) {
+		t.Errorf("Expected the added output to keep the attributes, got: %s", audit.String())
+	}
+
+	main.Reset()
+	audit.Reset()
+	auditOnly := logger.WithOutputs(logging.RemoveOutput(&main), logging.AddOutput(logging.OutputConfig{Writer: &audit}))
+	auditOnly.Warn("recipe changed")
+	if main.Len() != 0 {
+		t.Errorf("Expected the removed output to stay empty, got: %s", main.String())
+	}
+	if !strings.Contains(audit.String(), "recipe changed") || !strings.Contains(audit.String(), "plant=gradec") {
+		t.Errorf("Expected the audit record, got: %s", audit.String())
+	}
+
+	// the parent is unaffected
+	audit.Reset()
+	logger.Info("parent record")
+	if !strings.Contains(main.String(), "parent record") || audit.Len() != 0 {
+		t.Errorf("Expected the parent to keep its outputs, got main: %s, audit: %s", main.String(), audit.String())
+	}
+}
+
+func TestLoggerWithOutputsClose(t *testing.T) {
+	dir := t.TempDir()
+	shared, err := os.Create(filepath.Join(dir, "service.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit, err := os.Create(filepath.Join(dir, "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := logging.DefaultConfig()
+	config.Output = shared
+	logger := logging.New(config)
+	child := logger.WithOutputs(logging.AddOutput(logging.OutputConfig{Writer: audit}))
+
+	if err = child.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = audit.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected the added output to be closed, got: %v", err)
+	}
+	logger.Info("still running")
+	if err = logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(shared.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "still running") {
+		t.Errorf("Expected closing the child to leave the inherited output open, got: %s", content)
+	}
+}
+
+func TestUpdateConfigKeepsAttributes(t *testing.T) {
+	var before, after bytes.Buffer
+	config := logging.DefaultConfig()
+	config.Output = &before
+	logger := logging.New(config).With("component", "ingest")
+
+	config.Output = &after
+	logger.UpdateConfig(config)
+	logger.Info("reconfigured")
+	if !strings.Contains(after.String(), "component=ingest") {
+		t.Errorf("Expected the attributes to survive UpdateConfig, got: %s", after.String())
+	}
+}
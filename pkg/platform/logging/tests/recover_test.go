@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/logging"
+)
+
+func newJSONLogger(out *syncBuffer) *logging.Logger {
+	config := logging.DefaultConfig()
+	config.Output = out
+	config.JSONFormat = true
+	return logging.New(config)
+}
+
+func TestGoRecoversPanic(t *testing.T) {
+	var out syncBuffer
+	logger := newJSONLogger(&out)
+	notified := make(chan logging.Panic, 1)
+
+	logging.Go(logger, func() {
+		panic(errors.New("nil work order"))
+	}, logging.WithTaskName("order-sync"), logging.WithPanicNotify(func(p logging.Panic) { notified <- p }))
+
+	p := <-notified
+	if p.Name != "order-sync" || !strings.Contains(p.Err().Error(), "nil work order") {
+		t.Errorf("unexpected panic %+v", p)
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(out.String()), &record); err != nil {
+		t.Fatalf("the panic record is not a single JSON line: %v; %s", err, out.String())
+	}
+	if record["level"] != "ERROR" || record["panic"] != "nil work order" || record["task"] != "order-sync" {
+		t.Errorf("unexpected record %v", record)
+	}
+	if stack, _ := record["stack"].(string); !strings.Contains(stack, "TestGoRecoversPanic") {
+		t.Errorf("the stack does not show the panicking function: %s", stack)
+	}
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	var out syncBuffer
+	logger := newJSONLogger(&out)
+	defer func() {
+		if v := recover(); v != "fatal" {
+			t.Errorf("expected the panic to be raised again, got %v", v)
+		}
+		if !strings.Contains(out.String(), "panic recovered") {
+			t.Errorf("the panic was not logged before repanicking: %s", out.String())
+		}
+	}()
+	func() {
+		defer logging.Recover(logger, logging.WithRepanic())
+		panic("fatal")
+	}()
+}
+
+func TestRecoverHandler(t *testing.T) {
+	var out syncBuffer
+	logger := newJSONLogger(&out)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/boom", func(w http.ResponseWriter, r *http.Request) { panic("boom") })
+	mux.HandleFunc("/late", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("late")
+	})
+	h := logging.RecoverHandler(logger)(mux)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/boom", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+	if !strings.Contains(out.String(), `"path":"/boom"`) || !strings.Contains(out.String(), `"task":"POST /boom"`) {
+		t.Errorf("the record misses the request: %s", out.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/late", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("a started response must be kept, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/logging"
+)
+
+func TestVerboseContext(t *testing.T) {
+	var out syncBuffer
+	logger := newJSONLogger(&out).With("service", "mes-sync")
+	logger.DebugContext(context.Background(), "quiet")
+	logger.Debug("quiet too")
+	logger.DebugContext(logging.WithVerbose(context.Background()), "loud", "wo", "4711")
+
+	if strings.Contains(out.String(), "quiet") {
+		t.Errorf("debug records of plain contexts must be dropped at info level: %s", out.String())
+	}
+	record := lastRecord(t, &out)
+	if record["level"] != "DEBUG" || record["msg"] != "loud" || record["service"] != "mes-sync" {
+		t.Errorf("unexpected record %v", record)
+	}
+}
+
+func TestVerboseRequests(t *testing.T) {
+	var out syncBuffer
+	logger := newJSONLogger(&out)
+	handler := logging.VerboseRequests(logging.VerboseConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.DebugContext(r.Context(), "handling", "path", r.URL.Path)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+	r := httptest.NewRequest(http.MethodGet, "/orders/2", nil)
+	r.Header.Set(logging.DefaultVerboseHeader, "true")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 1 || !strings.Contains(lines[0], "/orders/2") {
+		t.Errorf("expected only the marked request to log at debug level, got %q", lines)
+	}
+
+	sampled := logging.VerboseRequests(logging.VerboseConfig{SampleRate: 1})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !logging.IsVerbose(r.Context()) {
+			t.Error("expected every request to be sampled")
+		}
+	}))
+	sampled.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/3", nil))
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+)
+
+// DefaultVerboseHeader is the request header VerboseRequests marks requests verbose by, e.g. "X-Debug-Log: true".
+const DefaultVerboseHeader = "X-Debug-Log"
+
+type verboseKey struct{}
+
+// WithVerbose marks the context verbose: the context methods of every logger, e.g. DebugContext, emit debug records
+// for it even when the configured level is higher.
+func WithVerbose(ctx context.Context) context.Context {
+	return context.WithValue(ctx, verboseKey{}, true)
+}
+
+// IsVerbose reports whether the context was marked with WithVerbose.
+func IsVerbose(ctx context.Context) bool {
+	verbose, _ := ctx.Value(verboseKey{}).(bool)
+	return verbose
+}
+
+// VerboseConfig selects the requests VerboseRequests marks verbose.
+type VerboseConfig struct {
+	// Header marks a request verbose when its value is true (see strconv.ParseBool); empty uses DefaultVerboseHeader.
+	// Anyone able to send it can raise the log volume, so strip it at the edge of public services.
+	Header string
+	// SampleRate is the fraction of the other requests marked verbose, e.g. 0.01 for one in a hundred; zero samples none.
+	SampleRate float64
+}
+
+// VerboseRequests returns server middleware marking the contexts of the selected requests verbose, so the handlers
+// log them at debug level for targeted production debugging.
+func VerboseRequests(config VerboseConfig) func(http.Handler) http.Handler {
+	if config.Header == "" {
+		config.Header = DefaultVerboseHeader
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verbose, _ := strconv.ParseBool(r.Header.Get(config.Header))
+			if verbose || (config.SampleRate > 0 && rand.Float64() < config.SampleRate) {
+				r = r.WithContext(WithVerbose(r.Context()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// verboseHandler enables debug records for verbose contexts on top of the level of the wrapped handler;
+// the slog handlers check the level in Enabled only, so Handle writes them out.
+type verboseHandler struct {
+	slog.Handler
+}
+
+// Enabled implements slog.Handler.Enabled.
+func (h verboseHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return (level >= slog.LevelDebug && IsVerbose(ctx)) || h.Handler.Enabled(ctx, level)
+}
+
+// WithAttrs implements slog.Handler.WithAttrs.
+func (h verboseHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return verboseHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler.WithGroup.
+func (h verboseHandler) WithGroup(name string) slog.Handler {
+	return verboseHandler{h.Handler.WithGroup(name)}
+}
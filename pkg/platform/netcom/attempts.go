@@ -0,0 +1,130 @@
+package netcom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Attempt describes a single try of a request.
+type Attempt struct {
+	URL      string
+	Status   int // zero when no response was received
+	Duration time.Duration
+	Err      error
+	// Backoff is the wait before the next attempt; zero for the last one.
+	Backoff time.Duration
+}
+
+func (a Attempt) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "url=%s", a.URL)
+	if a.Status != 0 {
+		fmt.Fprintf(&b, " status=%d", a.Status)
+	}
+	fmt.Fprintf(&b, " duration=%s", a.Duration)
+	if a.Err != nil {
+		fmt.Fprintf(&b, " err=%q", a.Err.Error())
+	}
+	if a.Backoff > 0 {
+		fmt.Fprintf(&b, " backoff=%s", a.Backoff)
+	}
+	return b.String()
+}
+
+// AttemptError wraps the final error of a request together with every attempt made for it.
+type AttemptError struct {
+	Err      error
+	Attempts []Attempt
+}
+
+func (e *AttemptError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AttemptError) Unwrap() error {
+	return e.Err
+}
+
+// AttemptsFromError returns the attempts recorded for the request that failed with err.
+func AttemptsFromError(err error) []Attempt {
+	var ae *AttemptError
+	if errors.As(err, &ae) {
+		return ae.Attempts
+	}
+	return nil
+}
+
+// Attempts returns the attempts made to obtain resp, oldest first.
+func Attempts(resp *http.Response) []Attempt {
+	if resp == nil || resp.Request == nil {
+		return nil
+	}
+	if t := traceFromContext(resp.Request.Context()); t != nil {
+		return t.snapshot()
+	}
+	return nil
+}
+
+type attemptTraceKey struct{}
+
+// attemptTrace collects the attempts of one logical request; it lives in the request context
+// so every try of the same request appends to it.
+type attemptTrace struct {
+	mu       sync.Mutex
+	attempts []Attempt
+}
+
+func traceFromContext(ctx context.Context) *attemptTrace {
+	t, _ := ctx.Value(attemptTraceKey{}).(*attemptTrace)
+	return t
+}
+
+// withAttemptTrace makes sure req carries an attempt trace.
+func withAttemptTrace(req *http.Request) (*http.Request, *attemptTrace) {
+	if t := traceFromContext(req.Context()); t != nil {
+		return req, t
+	}
+	t := new(attemptTrace)
+	return req.WithContext(context.WithValue(req.Context(), attemptTraceKey{}, t)), t
+}
+
+func (t *attemptTrace) record(a Attempt) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.attempts = append(t.attempts, a)
+}
+
+// setBackoff records the wait scheduled after the latest attempt.
+func (t *attemptTrace) setBackoff(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n := len(t.attempts); n > 0 {
+		t.attempts[n-1].Backoff = d
+	}
+}
+
+func (t *attemptTrace) snapshot() []Attempt {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Attempt(nil), t.attempts...)
+}
+
+// withAttempts attaches the attempts recorded for req to err.
+func withAttempts(req *http.Request, err error) error {
+	if err == nil || req == nil {
+		return err
+	}
+	var ae *AttemptError
+	if errors.As(err, &ae) {
+		return err
+	}
+	if t := traceFromContext(req.Context()); t != nil {
+		return &AttemptError{Err: err, Attempts: t.snapshot()}
+	}
+	return err
+}
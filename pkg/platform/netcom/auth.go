@@ -0,0 +1,164 @@
+package netcom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrTokenFetchFailed indicates that no access token could be obtained from the token endpoint.
+var ErrTokenFetchFailed = errors.New("failed to fetch access token")
+
+// DefaultTokenRefreshBefore is how long before its expiry a cached token is replaced when RefreshBefore is zero.
+const DefaultTokenRefreshBefore = time.Minute
+
+// ClientCredentialsConfig configures the OAuth2 client credentials flow (RFC 6749 section 4.4).
+type ClientCredentialsConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// EndpointParams are sent with every token request, e.g. "audience" or "resource".
+	EndpointParams url.Values
+	// AuthInBody sends the client ID and secret as form fields instead of HTTP basic authentication.
+	AuthInBody bool
+	// RefreshBefore is how long before its expiry a token is refreshed; zero uses DefaultTokenRefreshBefore.
+	RefreshBefore time.Duration
+	// HTTPClient sends the token requests; nil uses the http.Client of the API client, without its middlewares.
+	HTTPClient *http.Client
+}
+
+// ClientCredentials is a TokenProvider fetching access tokens with the client credentials flow and caching them
+// until shortly before they expire. It is safe for concurrent use; concurrent callers share a single token request.
+type ClientCredentials struct {
+	*CachingTokenProvider
+	config ClientCredentialsConfig
+	client *http.Client
+}
+
+// NewClientCredentials returns a token manager for the configuration.
+func NewClientCredentials(config ClientCredentialsConfig) (*ClientCredentials, error) {
+	u, err := url.Parse(config.TokenURL)
+	if err != nil || !u.IsAbs() {
+		return nil, fmt.Errorf("token URL '%s' must be absolute", config.TokenURL)
+	}
+	if config.ClientID == "" {
+		return nil, errors.New("client ID must not be empty")
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	cc := &ClientCredentials{config: config, client: client}
+	cc.CachingTokenProvider = NewCachingTokenProvider(cc.fetch, config.RefreshBefore)
+	return cc, nil
+}
+
+// tokenResponse is the successful (RFC 6749 section 5.1) or error (section 5.2) response of a token endpoint.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (cc *ClientCredentials) fetch(ctx context.Context) (string, time.Time, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(cc.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(cc.config.Scopes, " "))
+	}
+	for k, v := range cc.config.EndpointParams {
+		form[k] = v
+	}
+	if cc.config.AuthInBody {
+		form.Set("client_id", cc.config.ClientID)
+		form.Set("client_secret", cc.config.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cc.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: %v", ErrTokenFetchFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !cc.config.AuthInBody {
+		req.SetBasicAuth(url.QueryEscape(cc.config.ClientID), url.QueryEscape(cc.config.ClientSecret))
+	}
+
+	requested := time.Now()
+	resp, err := cc.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: %v", ErrTokenFetchFailed, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: %w: %w", ErrTokenFetchFailed, ErrReadResponseFailed, err)
+	}
+	var tr tokenResponse
+	jsonErr := json.Unmarshal(body, &tr)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || tr.Error != "" {
+		if tr.Error != "" {
+			return "", time.Time{}, fmt.Errorf("%w: status %d: %s: %s", ErrTokenFetchFailed, resp.StatusCode, tr.Error, tr.ErrorDescription)
+		}
+		return "", time.Time{}, fmt.Errorf("%w: status %d", ErrTokenFetchFailed, resp.StatusCode)
+	}
+	if jsonErr != nil {
+		return "", time.Time{}, fmt.Errorf("%w: decoding token response: %v", ErrTokenFetchFailed, jsonErr)
+	}
+	if tr.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("%w: response has no access_token", ErrTokenFetchFailed)
+	}
+	if tr.TokenType != "" && !strings.EqualFold(tr.TokenType, "bearer") {
+		return "", time.Time{}, fmt.Errorf("%w: unsupported token type '%s'", ErrTokenFetchFailed, tr.TokenType)
+	}
+	var expires time.Time
+	if tr.ExpiresIn > 0 {
+		// measured from the request so network latency does not extend the lifetime
+		expires = requested.Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return tr.AccessToken, expires, nil
+}
+
+// bearerAuth injects the token of p into every attempt. When p caches its tokens, a 401 response invalidates
+// the token and, if the request body can be rewound, the request is sent once more with a fresh token.
+func bearerAuth(p TokenProvider) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			send := func(req *http.Request) (*http.Response, error) {
+				token, err := p.Token(req.Context())
+				if err != nil && !errors.Is(err, ErrTokenFetchFailed) {
+					err = fmt.Errorf("%w: %w", ErrTokenFetchFailed, err)
+				}
+				if err != nil {
+					return nil, err
+				}
+				// the request belongs to the caller; a RoundTripper must not modify it
+				req = req.Clone(req.Context())
+				req.Header.Set("Authorization", "Bearer "+token)
+				return next.RoundTrip(req)
+			}
+			resp, err := send(req)
+			inv, ok := p.(invalidator)
+			if err != nil || resp.StatusCode != http.StatusUnauthorized || !ok {
+				return resp, err
+			}
+			inv.Invalidate()
+			if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+				return resp, nil
+			}
+			again, err := nextAttempt(req)
+			if err != nil {
+				return resp, nil
+			}
+			discard(resp)
+			return send(again)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package netcom
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen indicates a request that was not sent because the circuit of its host is open.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Default values of the zero CircuitBreakerConfig fields.
+const (
+	DefaultFailureThreshold = 5
+	DefaultCircuitCooldown  = 30 * time.Second
+)
+
+// CircuitState is the state of the circuit of a host.
+type CircuitState int
+
+const (
+	// CircuitClosed sends requests normally.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests with ErrCircuitOpen until the cooldown passed.
+	CircuitOpen
+	// CircuitHalfOpen lets a single trial request through; its outcome closes or reopens the circuit.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitBreakerConfig enables a circuit breaker per host: after FailureThreshold consecutive failures
+// (connection errors and 5xx responses) the circuit opens and requests to the host fail fast for the Cooldown.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit; zero uses DefaultFailureThreshold.
+	FailureThreshold int
+	// Cooldown is how long an open circuit fails fast before a trial request is let through; zero uses DefaultCircuitCooldown.
+	Cooldown time.Duration
+	// OnStateChange is called on every transition, e.g. to log or alert; it must not block.
+	OnStateChange func(host string, from, to CircuitState)
+	// IsFailure replaces the default decision of which outcomes count as failures; resp is nil when err is not.
+	IsFailure func(resp *http.Response, err error) bool
+}
+
+// circuitBreakers tracks the circuits of the hosts a client talks to.
+type circuitBreakers struct {
+	config CircuitBreakerConfig
+	mu     sync.Mutex
+	hosts  map[string]*circuit
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trial    bool // a half-open trial request is in flight
+}
+
+func newCircuitBreakers(config CircuitBreakerConfig) *circuitBreakers {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = DefaultFailureThreshold
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultCircuitCooldown
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= 500
+		}
+	}
+	return &circuitBreakers{config: config, hosts: make(map[string]*circuit)}
+}
+
+// allow reports whether a request to host may be sent; it moves an open circuit whose cooldown passed to half-open.
+func (cb *circuitBreakers) allow(host string) error {
+	cb.mu.Lock()
+	c := cb.circuit(host)
+	var changed bool
+	switch c.state {
+	case CircuitOpen:
+		if time.Since(c.openedAt) < cb.config.Cooldown {
+			cb.mu.Unlock()
+			return fmt.Errorf("%w: host %s", ErrCircuitOpen, host)
+		}
+		c.state, c.trial, changed = CircuitHalfOpen, true, true
+	case CircuitHalfOpen:
+		if c.trial {
+			cb.mu.Unlock()
+			return fmt.Errorf("%w: host %s (trial request in flight)", ErrCircuitOpen, host)
+		}
+		c.trial = true
+	}
+	cb.mu.Unlock()
+	if changed {
+		cb.notify(host, CircuitOpen, CircuitHalfOpen)
+	}
+	return nil
+}
+
+// report records the outcome of a request sent to host.
+func (cb *circuitBreakers) report(host string, resp *http.Response, err error) {
+	failed := cb.config.IsFailure(resp, err)
+	cb.mu.Lock()
+	c := cb.circuit(host)
+	from := c.state
+	c.trial = false
+	switch {
+	case !failed:
+		c.state, c.failures = CircuitClosed, 0
+	case c.state == CircuitHalfOpen:
+		c.state, c.openedAt = CircuitOpen, time.Now()
+	default:
+		c.failures++
+		if c.state == CircuitClosed && c.failures >= cb.config.FailureThreshold {
+			c.state, c.openedAt = CircuitOpen, time.Now()
+		}
+	}
+	to := c.state
+	cb.mu.Unlock()
+	if from != to {
+		cb.notify(host, from, to)
+	}
+}
+
+// abandon forgets a request that ended with its context, which says nothing about the host.
+func (cb *circuitBreakers) abandon(host string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.circuit(host).trial = false
+}
+
+// state returns the state of the circuit of host as last recorded.
+func (cb *circuitBreakers) state(host string) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if c, ok := cb.hosts[host]; ok {
+		return c.state
+	}
+	return CircuitClosed
+}
+
+func (cb *circuitBreakers) circuit(host string) *circuit {
+	c, ok := cb.hosts[host]
+	if !ok {
+		c = new(circuit)
+		cb.hosts[host] = c
+	}
+	return c
+}
+
+func (cb *circuitBreakers) notify(host string, from, to CircuitState) {
+	if cb.config.OnStateChange != nil {
+		cb.config.OnStateChange(host, from, to)
+	}
+}
+
+// CircuitState returns the state of the circuit of host (as in the request URL, e.g. "mes.local:8443");
+// CircuitClosed when the client has no circuit breaker.
+func (c *Client) CircuitState(host string) CircuitState {
+	if c.breakers == nil {
+		return CircuitClosed
+	}
+	return c.breakers.state(host)
+}
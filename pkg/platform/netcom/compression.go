@@ -0,0 +1,114 @@
+package netcom
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WithGzipBody compresses the request body with gzip and sets Content-Encoding; the server must accept
+// gzip-encoded requests. The compressed body can be rewound, so the request stays retryable.
+func WithGzipBody() RequestOption {
+	return func(req *http.Request) error {
+		if req.Body == nil || req.Body == http.NoBody {
+			return nil
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := io.Copy(zw, req.Body); err != nil {
+			return fmt.Errorf("compressing the request body failed: %w", err)
+		}
+		req.Body.Close()
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("compressing the request body failed: %w", err)
+		}
+		data := buf.Bytes()
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil }
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Length", strconv.Itoa(len(data)))
+		req.Header.Set("Content-Encoding", "gzip")
+		return nil
+	}
+}
+
+// decompressBody replaces a gzip or deflate encoded response body with its decompressed content and drops
+// the Content-Encoding header. Go's transport already decompresses gzip when it asked for it; this covers
+// requests sending their own Accept-Encoding. The client's MaxResponseBytes limit is applied again to the
+// decompressed bytes, so a small compressed body can not inflate past it.
+func decompressBody(resp *http.Response) {
+	if resp == nil || resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "gzip", "x-gzip", "deflate":
+	default:
+		return
+	}
+	limit := bodyLimit(resp.Body)
+	resp.Body = &decompressingBody{rc: resp.Body, encoding: encoding}
+	if limit > 0 {
+		resp.Body = &limitedBody{rc: resp.Body, remaining: limit, limit: limit}
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// decompressingBody sets up the decompressor on the first read, so a corrupt header surfaces as a read error.
+type decompressingBody struct {
+	rc       io.ReadCloser
+	encoding string
+	r        io.Reader
+	err      error
+}
+
+func (db *decompressingBody) Read(p []byte) (int, error) {
+	if db.r == nil && db.err == nil {
+		db.r, db.err = db.reader()
+	}
+	if db.err != nil {
+		return 0, db.err
+	}
+	return db.r.Read(p)
+}
+
+func (db *decompressingBody) reader() (io.Reader, error) {
+	if db.encoding != "deflate" {
+		zr, err := gzip.NewReader(db.rc)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing the gzip body failed: %w", err)
+		}
+		return zr, nil
+	}
+	// "deflate" is zlib-wrapped by the standard, but some servers send a raw deflate stream
+	br := bufio.NewReader(db.rc)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing the deflate body failed: %w", err)
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing the deflate body failed: %w", err)
+		}
+		return zr, nil
+	}
+	return flate.NewReader(br), nil
+}
+
+func (db *decompressingBody) Close() error {
+	if c, ok := db.r.(io.Closer); ok {
+		c.Close()
+	}
+	return db.rc.Close()
+}
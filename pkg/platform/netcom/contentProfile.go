@@ -0,0 +1,165 @@
+package netcom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ErrUnexpectedContentType indicates a response whose Content-Type is none of the expected media types,
+// typically the HTML error page of a gateway or login portal served where JSON was expected.
+var ErrUnexpectedContentType = errors.New("unexpected response content type")
+
+// ContentTypeError describes a response rejected by the content type expectations; it matches ErrUnexpectedContentType.
+type ContentTypeError struct {
+	// ContentType is the Content-Type header of the response, empty when it had none.
+	ContentType string
+	Expected    []string
+	Status      int
+	// Snippet is the start of the body, which usually tells what the page is about.
+	Snippet string
+}
+
+func (e *ContentTypeError) Error() string {
+	got := e.ContentType
+	if got == "" {
+		got = "none"
+	}
+	msg := fmt.Sprintf("%s: got %s, expected %s (status %d)", ErrUnexpectedContentType, got, strings.Join(e.Expected, " or "), e.Status)
+	if e.Snippet != "" {
+		msg += ": " + e.Snippet
+	}
+	return msg
+}
+
+func (e *ContentTypeError) Unwrap() error {
+	return ErrUnexpectedContentType
+}
+
+// HTML reports whether the response was an HTML page.
+func (e *ContentTypeError) HTML() bool {
+	mediaType, _, _ := mime.ParseMediaType(e.ContentType)
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// ContentProfile holds the content negotiation of a service: the Accept headers sent with its requests and
+// the media types its responses must have. Empty fields are left alone.
+type ContentProfile struct {
+	Accept         string
+	AcceptLanguage string
+	// AcceptEncoding replaces the gzip negotiation of Go's transport; DecodeResponse and ReadResponseBody
+	// decompress gzip and deflate bodies, other encodings are left to the caller.
+	AcceptEncoding string
+	// Expect lists the media types DecodeResponse and endpoint calls decode, e.g. "application/json" or "text/*";
+	// other responses fail with a *ContentTypeError. Types with a structured syntax suffix match their base type,
+	// so application/problem+json is accepted as application/json. Empty accepts any type.
+	Expect []string
+}
+
+// Profiles of the common formats.
+var (
+	JSONProfile = ContentProfile{Accept: "application/json", Expect: []string{"application/json"}}
+	XMLProfile  = ContentProfile{Accept: "application/xml, text/xml", Expect: []string{"application/xml", "text/xml"}}
+)
+
+type expectedContentTypesKey struct{}
+
+// WithContentProfile applies the profile to a single request, replacing the Accept headers and expectations
+// of the client profile.
+func WithContentProfile(p ContentProfile) RequestOption {
+	return func(req *http.Request) error {
+		p.setHeaders(req.Header, true)
+		if len(p.Expect) > 0 {
+			*req = *req.WithContext(context.WithValue(req.Context(), expectedContentTypesKey{}, p.Expect))
+		}
+		return nil
+	}
+}
+
+// WithExpectedContentType fails the decoding with a *ContentTypeError unless the response has one of the
+// media types; it takes precedence over the expectations of the request profile.
+func WithExpectedContentType(mediaTypes ...string) ResponseOption {
+	return func(rc *responseConfig) {
+		rc.expect = mediaTypes
+	}
+}
+
+// setHeaders sets the Accept headers of the profile; without replace the ones already present are kept.
+func (p ContentProfile) setHeaders(h http.Header, replace bool) {
+	for key, value := range map[string]string{
+		"Accept":          p.Accept,
+		"Accept-Encoding": p.AcceptEncoding,
+		"Accept-Language": p.AcceptLanguage,
+	} {
+		if value != "" && (replace || h.Get(key) == "") {
+			h.Set(key, value)
+		}
+	}
+}
+
+// apply applies the client profile to a new request before its options run.
+func (p *ContentProfile) apply(req *http.Request) *http.Request {
+	if p == nil {
+		return req
+	}
+	p.setHeaders(req.Header, false)
+	if len(p.Expect) == 0 {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), expectedContentTypesKey{}, p.Expect))
+}
+
+// checkContentType returns a *ContentTypeError when the response misses the expected content types of the
+// response options or the request.
+func checkContentType(resp *http.Response, rc *responseConfig) error {
+	var expect []string
+	if rc != nil {
+		expect = rc.expect
+	}
+	if len(expect) == 0 && resp.Request != nil {
+		expect, _ = resp.Request.Context().Value(expectedContentTypesKey{}).([]string)
+	}
+	if len(expect) == 0 {
+		return nil
+	}
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		for _, expected := range expect {
+			if matchMediaType(mediaType, strings.ToLower(strings.TrimSpace(expected))) {
+				return nil
+			}
+		}
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return withAttempts(resp.Request, &ContentTypeError{
+		ContentType: contentType,
+		Expected:    expect,
+		Status:      resp.StatusCode,
+		Snippet:     strings.TrimSpace(string(snippet)),
+	})
+}
+
+// matchMediaType matches a media type against an expected one, which may be a "type/*" or "*/*" range.
+func matchMediaType(mediaType, expected string) bool {
+	if mediaType == expected || expected == "*/*" {
+		return true
+	}
+	typ, sub, _ := strings.Cut(mediaType, "/")
+	expectedType, expectedSub, _ := strings.Cut(expected, "/")
+	if typ != expectedType {
+		return false
+	}
+	if expectedSub == "*" {
+		return true
+	}
+	// application/problem+json is json, application/atom+xml is xml
+	if i := strings.LastIndexByte(sub, '+'); i >= 0 {
+		return sub[i+1:] == expectedSub
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package netcom
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ErrNoCookieJar is returned by the cookie helpers of a client without a cookie jar.
+var ErrNoCookieJar = errors.New("the client has no cookie jar")
+
+// newCookieJar returns the jar of the configuration: the CookieJar given or, with EnableCookies,
+// an in-memory jar that keeps cookies from being set for whole public suffixes such as co.uk.
+func newCookieJar(config ClientConfig) (http.CookieJar, error) {
+	if config.CookieJar != nil {
+		return config.CookieJar, nil
+	}
+	return cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+}
+
+// Cookies returns the cookies the client sends with a request to path, resolved like the path of a request.
+func (c *Client) Cookies(path string) ([]*http.Cookie, error) {
+	if c.httpClient.Jar == nil {
+		return nil, ErrNoCookieJar
+	}
+	u, err := c.resolveURL(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.httpClient.Jar.Cookies(u), nil
+}
+
+// Cookie returns the named cookie the client sends with a request to path, or http.ErrNoCookie.
+func (c *Client) Cookie(path, name string) (*http.Cookie, error) {
+	cookies, err := c.Cookies(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", http.ErrNoCookie, name)
+}
+
+// SetCookies stores cookies as if a response to path had set them, e.g. a session copied from a browser login.
+// Cookies without a Path apply to the directory of path, as the jar does for Set-Cookie headers.
+func (c *Client) SetCookies(path string, cookies ...*http.Cookie) error {
+	if c.httpClient.Jar == nil {
+		return ErrNoCookieJar
+	}
+	u, err := c.resolveURL(path)
+	if err != nil {
+		return err
+	}
+	c.httpClient.Jar.SetCookies(u, cookies)
+	return nil
+}
@@ -0,0 +1,83 @@
+package netcom
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// APIKeyIn is where an API key is sent.
+type APIKeyIn string
+
+const (
+	APIKeyInHeader APIKeyIn = "header"
+	APIKeyInQuery  APIKeyIn = "query"
+)
+
+// BasicAuth holds the credentials of HTTP basic authentication.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// APIKey is an API key sent as a header or query parameter, e.g. {In: APIKeyInHeader, Name: "X-API-Key"}.
+type APIKey struct {
+	In    APIKeyIn
+	Name  string
+	Value string
+}
+
+// WithBasicAuth sets HTTP basic authentication on the request, replacing any client-level credentials.
+func WithBasicAuth(username, password string) RequestOption {
+	return func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	}
+}
+
+// WithAPIKey sends an API key as the header or query parameter name, replacing a value already set under that name.
+// headerOrQuery is APIKeyInHeader or APIKeyInQuery.
+func WithAPIKey(headerOrQuery APIKeyIn, name, value string) RequestOption {
+	return APIKey{In: headerOrQuery, Name: name, Value: value}.apply
+}
+
+func (k APIKey) validate() error {
+	if k.Name == "" {
+		return fmt.Errorf("API key name must not be empty")
+	}
+	if k.In != APIKeyInHeader && k.In != APIKeyInQuery {
+		return fmt.Errorf("API key location must be %q or %q, got %q", APIKeyInHeader, APIKeyInQuery, k.In)
+	}
+	return nil
+}
+
+func (k APIKey) apply(req *http.Request) error {
+	if err := k.validate(); err != nil {
+		return err
+	}
+	if k.In == APIKeyInHeader {
+		req.Header.Set(k.Name, k.Value)
+		return nil
+	}
+	q := req.URL.Query()
+	q.Set(k.Name, k.Value)
+	req.URL.RawQuery = q.Encode()
+	return nil
+}
+
+// credentialOptions returns the request options of the client-level credentials of config.
+func credentialOptions(config ClientConfig) ([]RequestOption, error) {
+	var options []RequestOption
+	if config.BasicAuth != nil {
+		if config.Auth != nil || config.TokenProvider != nil {
+			return nil, fmt.Errorf("BasicAuth can not be combined with bearer auth")
+		}
+		options = append(options, WithBasicAuth(config.BasicAuth.Username, config.BasicAuth.Password))
+	}
+	if config.APIKey != nil {
+		if err := config.APIKey.validate(); err != nil {
+			return nil, err
+		}
+		options = append(options, config.APIKey.apply)
+	}
+	return options, nil
+}
@@ -0,0 +1,159 @@
+package netcom
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/vmihailenco/msgpack/v5"
+	"golang.org/x/net/html/charset"
+)
+
+// ErrUnsupportedTarget indicates a decode target the decoder of the response content type can not fill.
+var ErrUnsupportedTarget = errors.New("unsupported decode target")
+
+// Decoder decodes a response body into v.
+type Decoder func(r io.Reader, v any) error
+
+var decoders = struct {
+	sync.RWMutex
+	byType map[string]Decoder
+}{byType: map[string]Decoder{
+	"application/json":         decodeJSON,
+	"application/xml":          decodeXML,
+	"text/xml":                 decodeXML,
+	"text/csv":                 decodeCSV,
+	"application/msgpack":      decodeMsgpack,
+	"application/x-msgpack":    decodeMsgpack,
+	"application/vnd.msgpack":  decodeMsgpack,
+	"application/octet-stream": decodeBytes,
+}}
+
+// RegisterDecoder makes DecodeResponse and endpoint calls decode responses of the media type (e.g. "application/cbor") with d,
+// replacing the decoder registered for it before. A nil decoder removes the registration.
+func RegisterDecoder(mediaType string, d Decoder) {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	decoders.Lock()
+	defer decoders.Unlock()
+	if d == nil {
+		delete(decoders.byType, mediaType)
+		return
+	}
+	decoders.byType[mediaType] = d
+}
+
+// WithDecoder decodes the response with d regardless of its Content-Type.
+func WithDecoder(d Decoder) ResponseOption {
+	return func(rc *responseConfig) {
+		rc.decoder = d
+	}
+}
+
+// decoderFor returns the decoder registered for the Content-Type header value and the media type it was chosen for.
+// Structured syntax suffixes (+json, +xml) use the JSON and XML decoders; a missing or unknown type is decoded as JSON.
+func decoderFor(contentType string) (Decoder, string) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return decodeJSON, "json"
+	}
+	decoders.RLock()
+	d, ok := decoders.byType[mediaType]
+	decoders.RUnlock()
+	switch {
+	case ok:
+		return d, mediaType
+	case strings.HasSuffix(mediaType, "+json"):
+		return decodeJSON, mediaType
+	case strings.HasSuffix(mediaType, "+xml"):
+		return decodeXML, mediaType
+	default:
+		return decodeJSON, "json"
+	}
+}
+
+// decodeBody decodes the body with the option's decoder or the one registered for the response content type.
+func decodeBody(resp *http.Response, rc *responseConfig, v any) (string, error) {
+	d, name := decoderFor(resp.Header.Get("Content-Type"))
+	if rc != nil && rc.decoder != nil {
+		d, name = rc.decoder, "response"
+	}
+	return name, d(resp.Body, v)
+}
+
+func decodeJSON(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// decodeXML converts documents declaring a non UTF-8 encoding, e.g. ISO-8859-1, while decoding.
+func decodeXML(r io.Reader, v any) error {
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charset.NewReaderLabel
+	return dec.Decode(v)
+}
+
+func decodeMsgpack(r io.Reader, v any) error {
+	return msgpack.NewDecoder(r).Decode(v)
+}
+
+// decodeBytes copies the body into a *[]byte, *string or io.Writer.
+func decodeBytes(r io.Reader, v any) error {
+	switch t := v.(type) {
+	case io.Writer:
+		_, err := io.Copy(t, r)
+		return err
+	case *[]byte:
+		b, err := io.ReadAll(r)
+		*t = b
+		return err
+	case *string:
+		b, err := io.ReadAll(r)
+		*t = string(b)
+		return err
+	default:
+		return fmt.Errorf("%w: %T for binary content", ErrUnsupportedTarget, v)
+	}
+}
+
+// decodeCSV reads the records into a *datamanagement.Dataframe, whose columns come from the header row,
+// a *[]datamanagement.Record or a *[][]string.
+func decodeCSV(r io.Reader, v any) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case *[][]string:
+		*t = rows
+	case *[]datamanagement.Record:
+		*t = make([]datamanagement.Record, len(rows))
+		for i, row := range rows {
+			(*t)[i] = row
+		}
+	case *datamanagement.Dataframe:
+		if len(rows) == 0 {
+			return io.EOF
+		}
+		records := make([]datamanagement.Record, len(rows))
+		for i, row := range rows {
+			records[i] = row
+		}
+		df, err := datamanagement.NewDataframeFromRecords(records, t.CleanerFunc, datamanagement.WithInterpretedColumns())
+		if err != nil {
+			return err
+		}
+		*t = *df
+	default:
+		return fmt.Errorf("%w: %T for csv content", ErrUnsupportedTarget, v)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package netcom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrIncompleteDownload indicates a download whose size differs from the Content-Length of the response.
+var ErrIncompleteDownload = errors.New("download size does not match the Content-Length")
+
+// DownloadOption configures Download and DownloadFile.
+type DownloadOption func(*downloadConfig)
+
+type downloadConfig struct {
+	request  []RequestOption
+	response []ResponseOption
+	progress func(written, total int64)
+}
+
+// WithProgress calls fn after every chunk written with the bytes written so far and the Content-Length,
+// which is -1 when the server did not announce it. fn runs on the downloading goroutine and should return quickly.
+func WithProgress(fn func(written, total int64)) DownloadOption {
+	return func(dc *downloadConfig) {
+		dc.progress = fn
+	}
+}
+
+// WithDownloadRequest applies request options, e.g. headers or query parameters, to the download request.
+func WithDownloadRequest(options ...RequestOption) DownloadOption {
+	return func(dc *downloadConfig) {
+		dc.request = append(dc.request, options...)
+	}
+}
+
+// WithDownloadLimits applies response options, e.g. WithMaxBodySize, to the downloaded body.
+func WithDownloadLimits(options ...ResponseOption) DownloadOption {
+	return func(dc *downloadConfig) {
+		dc.response = append(dc.response, options...)
+	}
+}
+
+// Download sends a GET request and streams the response body to w without holding it in memory,
+// returning the number of bytes written. Non-2xx responses return ErrBadStatusCode and write nothing.
+// When the response announces a Content-Length, a body of a different size returns ErrIncompleteDownload;
+// w may then hold a partial body. Gzip and deflate encoded bodies, sent when the request sets its own
+// Accept-Encoding, are decompressed like in DecodeResponse; their size is then not checked against Content-Length.
+func (c *Client) Download(ctx context.Context, path string, w io.Writer, opts ...DownloadOption) (int64, error) {
+	dc := new(downloadConfig)
+	for _, opt := range opts {
+		opt(dc)
+	}
+	resp, err := c.Get(ctx, path, dc.request...)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// DecodeResponse reports the status together with the start of the error body
+		return 0, DecodeResponse(resp, nil)
+	}
+	decompressBody(resp)
+	applyResponseOptions(resp, dc.response)
+	defer resp.Body.Close()
+
+	if dc.progress != nil {
+		w = &progressWriter{w: w, total: resp.ContentLength, fn: dc.progress}
+	}
+	n, err := io.Copy(w, resp.Body)
+	if resp.ContentLength >= 0 && n != resp.ContentLength {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return n, withAttempts(resp.Request, fmt.Errorf("%w: got %d of %d bytes: %w%s",
+			ErrIncompleteDownload, n, resp.ContentLength, err, requestIDSuffix(resp.Request)))
+	}
+	if err != nil {
+		return n, fmt.Errorf("%w: %w", ErrReadResponseFailed, err)
+	}
+	return n, nil
+}
+
+// DownloadFile downloads to file, replacing it only once the download completed,
+// so a failed download never leaves a truncated file behind. The body is written to file+".part" first.
+func (c *Client) DownloadFile(ctx context.Context, path, file string, opts ...DownloadOption) (int64, error) {
+	part := file + ".part"
+	f, err := os.Create(part)
+	if err != nil {
+		return 0, err
+	}
+	n, err := c.Download(ctx, path, f, opts...)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(part, file)
+	}
+	if err != nil {
+		os.Remove(part)
+		return n, err
+	}
+	return n, nil
+}
+
+// progressWriter reports the bytes written through it.
+type progressWriter struct {
+	w       io.Writer
+	written int64
+	total   int64
+	fn      func(written, total int64)
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.written += int64(n)
+	pw.fn(pw.written, pw.total)
+	return n, err
+}
@@ -0,0 +1,214 @@
+package netcom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidEndpoint indicates an Endpoint definition that can not produce valid requests.
+var ErrInvalidEndpoint = errors.New("invalid endpoint definition")
+
+// Endpoint declares the conventions of a single API operation.
+// Path may contain {name} placeholders that are filled from the TReq fields tagged `path:"name"`;
+// fields tagged `query:"name"` become query parameters. For methods that carry a body,
+// the request value is sent as JSON; responses are decoded by their Content-Type like DecodeResponse.
+type Endpoint[TReq, TResp any] struct {
+	// Name tags the requests with WithEndpointName, so they count towards the SLO of that name; optional.
+	Name   string
+	Method string
+	Path   string
+	// ExpectedStatus lists the accepted status codes; any 2xx is accepted when empty.
+	ExpectedStatus []int
+	// Timeout bounds a single call, including reading the response; no timeout when zero.
+	Timeout time.Duration
+	// Options are applied to every request made through the endpoint.
+	Options []RequestOption
+}
+
+// CallFunc is a typed call against an Endpoint bound to a Client.
+type CallFunc[TReq, TResp any] func(ctx context.Context, req TReq, options ...RequestOption) (TResp, error)
+
+var validMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// Validate checks the endpoint definition against the request type.
+func (e Endpoint[TReq, TResp]) Validate() error {
+	if !slices.Contains(validMethods, e.Method) {
+		return fmt.Errorf("%w: unsupported method '%s'", ErrInvalidEndpoint, e.Method)
+	}
+	if e.Path == "" {
+		return fmt.Errorf("%w: empty path", ErrInvalidEndpoint)
+	}
+	placeholders, err := pathPlaceholders(e.Path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEndpoint, err)
+	}
+	tagged := taggedFields(reflect.TypeFor[TReq](), "path")
+	for _, p := range placeholders {
+		if !slices.Contains(tagged, p) {
+			return fmt.Errorf("%w: placeholder '{%s}' has no matching `path` field on %s", ErrInvalidEndpoint, p, reflect.TypeFor[TReq]())
+		}
+	}
+	return nil
+}
+
+// Bind validates the endpoint and returns a typed call function using the client.
+func (e Endpoint[TReq, TResp]) Bind(c *Client) (CallFunc[TReq, TResp], error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, req TReq, options ...RequestOption) (TResp, error) {
+		return e.call(ctx, c, req, options...)
+	}, nil
+}
+
+// MustBind is like Bind but panics on an invalid endpoint; meant for package-level endpoint declarations.
+func (e Endpoint[TReq, TResp]) MustBind(c *Client) CallFunc[TReq, TResp] {
+	call, err := e.Bind(c)
+	if err != nil {
+		panic(err)
+	}
+	return call
+}
+
+func (e Endpoint[TReq, TResp]) call(ctx context.Context, c *Client, req TReq, options ...RequestOption) (TResp, error) {
+	var out TResp
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+
+	rv := reflect.ValueOf(req)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	path := expandPath(e.Path, tagValues(rv, "path"))
+
+	var body io.Reader
+	opts := slices.Clone(e.Options)
+	if e.Name != "" {
+		opts = append([]RequestOption{WithEndpointName(e.Name)}, opts...)
+	}
+	if query := tagValues(rv, "query"); len(query) > 0 {
+		opts = append(opts, WithQueryParams(query))
+	}
+	if e.Method == http.MethodPost || e.Method == http.MethodPut || e.Method == http.MethodPatch {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return out, fmt.Errorf("%w: %v", ErrJSONMarshalFailed, err)
+		}
+		body = bytes.NewReader(data)
+		opts = append(opts, WithSetHeader("Content-Type", "application/json"))
+	}
+	opts = append(opts, options...)
+
+	resp, err := c.Request(ctx, e.Method, path, body, opts...)
+	if err != nil {
+		return out, err
+	}
+	defer resp.Body.Close()
+
+	if !e.accepts(resp.StatusCode) {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err = fmt.Errorf("%w: %s %s: status %d: %s%s", ErrBadStatusCode, e.Method, path, resp.StatusCode, string(snippet), requestIDSuffix(resp.Request))
+		return out, withAttempts(resp.Request, retryableStatusError(resp, err))
+	}
+	if resp.StatusCode == http.StatusNoContent || e.Method == http.MethodHead {
+		return out, nil
+	}
+	if err := checkContentType(resp, nil); err != nil {
+		return out, err
+	}
+	if name, err := decodeBody(resp, nil, &out); err != nil && !errors.Is(err, io.EOF) {
+		return out, fmt.Errorf("%s decode failed: %w", name, err)
+	}
+	return out, nil
+}
+
+func (e Endpoint[TReq, TResp]) accepts(status int) bool {
+	if len(e.ExpectedStatus) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(e.ExpectedStatus, status)
+}
+
+// pathPlaceholders returns the names of the {name} placeholders in a path template.
+func pathPlaceholders(path string) ([]string, error) {
+	var names []string
+	rest := path
+	for {
+		open := strings.IndexByte(rest, '{')
+		closing := strings.IndexByte(rest, '}')
+		if open == -1 && closing == -1 {
+			return names, nil
+		}
+		if open == -1 || closing < open {
+			return nil, fmt.Errorf("%w: unbalanced braces in path '%s'", ErrBadPathTemplate, path)
+		}
+		name := rest[open+1 : closing]
+		if name == "" || strings.ContainsAny(name, "{/") {
+			return nil, fmt.Errorf("%w: bad placeholder in path '%s'", ErrBadPathTemplate, path)
+		}
+		names = append(names, name)
+		rest = rest[closing+1:]
+	}
+}
+
+// expandPath substitutes the {name} placeholders, escaping the values as path segments.
+func expandPath(path string, params map[string]string) string {
+	for name, value := range params {
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(value))
+	}
+	return path
+}
+
+// taggedFields lists the tag names of the fields of t carrying the given tag.
+func taggedFields(t reflect.Type, tag string) []string {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for i := range t.NumField() {
+		if name, ok := t.Field(i).Tag.Lookup(tag); ok && name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// tagValues reads the fields of a struct value carrying the given tag as strings.
+func tagValues(rv reflect.Value, tag string) map[string]string {
+	if !rv.IsValid() || rv.Kind() != reflect.Struct {
+		return nil
+	}
+	values := make(map[string]string)
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		name, ok := rt.Field(i).Tag.Lookup(tag)
+		if !ok || name == "" || name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		if tag == "query" && fv.IsZero() {
+			continue
+		}
+		values[name] = fmt.Sprint(fv.Interface())
+	}
+	return values
+}
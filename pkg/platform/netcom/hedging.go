@@ -0,0 +1,148 @@
+package netcom
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// hedgedMethods are the methods hedged without an idempotency key: reads, which a duplicate can not change anything with.
+var hedgedMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+
+// WithHedging returns a middleware sending a duplicate of a request that has not been answered within delay,
+// up to maxHedges duplicates one delay apart, and returning the first successful response; the requests still
+// in flight are canceled. A response counts as successful unless its status is 5xx. When every request fails the
+// outcome of the last one is returned and left to the retry policy.
+// Only GET, HEAD and OPTIONS requests and requests with an idempotency key (see WithIdempotencyKey) are hedged,
+// and only when their body can be rewound. A delay of zero or less disables hedging; maxHedges defaults to 1.
+// Middlewares registered after it, e.g. WithRateLimit, see every duplicate.
+func WithHedging(delay time.Duration, maxHedges int) Middleware {
+	if delay <= 0 {
+		return func(next http.RoundTripper) http.RoundTripper { return next }
+	}
+	if maxHedges <= 0 {
+		maxHedges = 1
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !hedgeable(req) {
+				return next.RoundTrip(req)
+			}
+			return hedge(next, req, delay, maxHedges)
+		})
+	}
+}
+
+func hedgeable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	return slices.Contains(hedgedMethods, req.Method) || IdempotencyKey(req) != ""
+}
+
+// hedgeOutcome is the result of one of the hedged requests; cancel ends its context.
+type hedgeOutcome struct {
+	index  int
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+}
+
+func (o hedgeOutcome) succeeded() bool {
+	return o.err == nil && o.resp.StatusCode < 500
+}
+
+// release cancels a request whose outcome is not returned, closing its response.
+func (o hedgeOutcome) release() {
+	if o.resp != nil {
+		o.resp.Body.Close()
+	}
+	o.cancel()
+}
+
+// returned hands the outcome to the caller; its context is canceled once the body is closed.
+func (o hedgeOutcome) returned() (*http.Response, error) {
+	if o.resp == nil {
+		o.cancel()
+		return nil, o.err
+	}
+	o.resp.Body = &cancelOnClose{ReadCloser: o.resp.Body, cancel: o.cancel}
+	return o.resp, o.err
+}
+
+func hedge(next http.RoundTripper, req *http.Request, delay time.Duration, maxHedges int) (*http.Response, error) {
+	outcomes := make(chan hedgeOutcome, maxHedges+1)
+	var cancels []context.CancelFunc
+	send := func() error {
+		ctx, cancel := context.WithCancel(req.Context())
+		attempt := req.WithContext(ctx)
+		if len(cancels) > 0 {
+			// every duplicate needs a body of its own
+			var err error
+			if attempt, err = nextAttempt(attempt); err != nil {
+				cancel()
+				return err
+			}
+		}
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := next.RoundTrip(attempt)
+			outcomes <- hedgeOutcome{index: index, resp: resp, err: err, cancel: cancel}
+		}()
+		return nil
+	}
+
+	send()
+	pending := 1
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	var last *hedgeOutcome
+	for {
+		select {
+		case <-timer.C:
+			// a body that can not be rewound ends the hedging
+			if send() == nil {
+				pending++
+				if len(cancels) <= maxHedges {
+					timer.Reset(delay)
+				}
+			}
+		case o := <-outcomes:
+			pending--
+			if last != nil {
+				last.release()
+			}
+			last = &o
+			if !o.succeeded() && pending > 0 {
+				continue
+			}
+			// cancel the requests still in flight and release their outcomes as they arrive
+			for i, cancel := range cancels {
+				if i != o.index {
+					cancel()
+				}
+			}
+			go func(pending int) {
+				for range pending {
+					(<-outcomes).release()
+				}
+			}(pending)
+			return o.returned()
+		}
+	}
+}
+
+// cancelOnClose cancels the context of a hedged request when its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
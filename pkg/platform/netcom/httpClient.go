@@ -15,6 +15,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"sync"
 	"time"
 )
 
@@ -31,6 +33,55 @@ type ClientConfig struct {
 	// If nil, a default one will be created (with Timeout if specified).
 	// If HTTPClient is provided, ClientConfig.Timeout is ignored.
 	HTTPClient *http.Client
+	// MaxResponseBytes caps every response body; reads past it fail with ErrResponseTooLarge.
+	// Zero means no limit.
+	MaxResponseBytes int64
+	// BodyReadTimeout bounds how long reading a response body may take after the headers arrived;
+	// slower reads fail with ErrBodyReadTimeout. Zero means no deadline.
+	BodyReadTimeout time.Duration
+	// RequestIDHeader enables request ID injection under the given header name (e.g. DefaultRequestIDHeader).
+	// The ID is taken from the context (see ContextWithRequestID) or generated, and reported in errors.
+	RequestIDHeader string
+	// Traceparent enables W3C traceparent propagation, continuing the trace found in the context if any.
+	Traceparent bool
+	// Tracing enables OpenTelemetry client spans with trace context propagation; nil disables tracing at no cost.
+	Tracing *TracingConfig
+	// Metrics enables Prometheus request metrics, exposed through Client.Collector; nil disables them.
+	Metrics *MetricsConfig
+	// SLO enables tracking of per-endpoint objectives for the requests tagged with WithEndpointName;
+	// the gauges of the error budgets are part of Client.Collector, named by the Metrics namespace.
+	SLO *SLOConfig
+	// Retry enables retries of failed requests; nil sends every request once unless WithRetryPolicy is used.
+	Retry *RetryPolicy
+	// CircuitBreaker enables a circuit breaker per host; every attempt, including retries, counts.
+	CircuitBreaker *CircuitBreakerConfig
+	// Auth enables OAuth2 client credentials: tokens are fetched, cached, refreshed before expiry
+	// and sent as "Authorization: Bearer" on every request, ahead of the Middlewares.
+	Auth *ClientCredentialsConfig
+	// TokenProvider is consulted on every request for the bearer token, e.g. StaticToken or a
+	// CachingTokenProvider around a custom identity provider; it can not be combined with Auth.
+	TokenProvider TokenProvider
+	// BasicAuth sends HTTP basic authentication with every request; it can not be combined with bearer auth.
+	BasicAuth *BasicAuth
+	// APIKey sends an API key with every request.
+	APIKey *APIKey
+	// Middlewares wrap the transport of the client, the first one outermost; more can be added with Client.Use.
+	Middlewares []Middleware
+	// Proxy selects the proxy of the default transport; it can not be combined with HTTPClient,
+	// whose transport brings its own.
+	Proxy *ProxyConfig
+	// TLS configures the certificates and versions of the default transport; like Proxy it can not be combined
+	// with HTTPClient.
+	TLS *TLSConfig
+	// EnableCookies keeps the cookies set by responses in an in-memory jar and sends them with later requests,
+	// as session based web applications expect; see Client.Cookies and Client.SetCookies.
+	EnableCookies bool
+	// CookieJar replaces the in-memory jar, e.g. to persist sessions, and implies EnableCookies.
+	// Neither can be combined with HTTPClient, whose Jar is used instead.
+	CookieJar http.CookieJar
+	// Profile sets the Accept headers DefaultHeaders leave unset on every request and the content types
+	// the responses must have; request options can replace both, e.g. WithContentProfile.
+	Profile *ContentProfile
 }
 
 // Client represents a configurable HTTP client.
@@ -38,6 +89,25 @@ type Client struct {
 	baseURL        *url.URL
 	httpClient     *http.Client
 	defaultHeaders http.Header // Default headers applied to every request.
+	maxBodyBytes   int64
+	bodyTimeout    time.Duration
+	// correlation headers injected on every request
+	requestIDHeader string
+	traceparent     bool
+	retry           *RetryPolicy
+	breakers        *circuitBreakers
+	tracer          *clientTracer
+	metrics         *clientMetrics
+	slo             *sloTracker
+	sloMetrics      *sloCollector
+	// client-level credentials applied before the request options
+	credentials []RequestOption
+	profile     *ContentProfile
+
+	// mu guards baseURL, defaultHeaders and the middlewares, which may be changed while requests are in flight
+	mu          sync.RWMutex
+	middlewares []Middleware
+	sender      *http.Client // httpClient with the middleware chain as transport; nil without middlewares
 }
 
 // ErrRequestOptionFailed indicates an error applying a request option.
@@ -63,7 +133,38 @@ var ErrBadStatusCode = errors.New("request failed with non-2xx status code")
 
 // NewClient creates a new HTTP client with the given configuration.
 func NewClient(config ClientConfig) (*Client, error) {
-	c := &Client{}
+	c := &Client{
+		maxBodyBytes: config.MaxResponseBytes,
+		bodyTimeout:  config.BodyReadTimeout,
+
+		requestIDHeader: config.RequestIDHeader,
+		traceparent:     config.Traceparent,
+	}
+	if config.Retry != nil {
+		p := config.Retry.normalized()
+		c.retry = &p
+	}
+	if config.CircuitBreaker != nil {
+		c.breakers = newCircuitBreakers(*config.CircuitBreaker)
+	}
+	if config.Tracing != nil {
+		c.tracer = newClientTracer(*config.Tracing)
+	}
+	if config.Metrics != nil {
+		c.metrics = newClientMetrics(*config.Metrics)
+	}
+	if config.SLO != nil {
+		t, err := newSLOTracker(*config.SLO)
+		if err != nil {
+			return nil, err
+		}
+		c.slo = t
+		var metrics MetricsConfig
+		if config.Metrics != nil {
+			metrics = *config.Metrics
+		}
+		c.sloMetrics = newSLOCollector(t, metrics)
+	}
 
 	if config.BaseURL != "" {
 		u, err := url.Parse(config.BaseURL)
@@ -80,11 +181,34 @@ func NewClient(config ClientConfig) (*Client, error) {
 		c.httpClient = config.HTTPClient
 		// If user provides a client, ClientConfig.Timeout is ignored.
 		// The provided client's configuration (including timeout) is used as-is.
+		if config.Proxy != nil {
+			return nil, errors.New("configuring proxy failed: Proxy can not be combined with HTTPClient")
+		}
+		if config.TLS != nil {
+			return nil, errors.New("configuring TLS failed: TLS can not be combined with HTTPClient")
+		}
+		if config.EnableCookies || config.CookieJar != nil {
+			return nil, errors.New("configuring cookies failed: cookies can not be combined with HTTPClient")
+		}
 	} else {
 		c.httpClient = &http.Client{}
 		if config.Timeout > 0 {
 			c.httpClient.Timeout = config.Timeout
 		}
+		if config.Proxy != nil || config.TLS != nil {
+			transport, err := newDefaultTransport(config)
+			if err != nil {
+				return nil, err
+			}
+			c.httpClient.Transport = transport
+		}
+		if config.EnableCookies || config.CookieJar != nil {
+			jar, err := newCookieJar(config)
+			if err != nil {
+				return nil, fmt.Errorf("configuring cookies failed: %w", err)
+			}
+			c.httpClient.Jar = jar
+		}
 	}
 
 	if config.DefaultHeaders != nil {
@@ -94,30 +218,90 @@ func NewClient(config ClientConfig) (*Client, error) {
 		c.defaultHeaders = make(http.Header) // Ensure it's initialized
 	}
 
+	if config.Profile != nil {
+		profile := *config.Profile
+		profile.Expect = slices.Clone(profile.Expect)
+		c.profile = &profile
+	}
+
+	credentials, err := credentialOptions(config)
+	if err != nil {
+		return nil, fmt.Errorf("configuring auth failed: %w", err)
+	}
+	c.credentials = credentials
+
+	middlewares := config.Middlewares
+	if config.Auth != nil && config.TokenProvider != nil {
+		return nil, errors.New("configuring auth failed: Auth and TokenProvider are mutually exclusive")
+	}
+	if config.TokenProvider != nil {
+		middlewares = append([]Middleware{bearerAuth(config.TokenProvider)}, middlewares...)
+	}
+	if config.Auth != nil {
+		auth := *config.Auth
+		if auth.HTTPClient == nil {
+			auth.HTTPClient = c.httpClient
+		}
+		cc, err := NewClientCredentials(auth)
+		if err != nil {
+			return nil, fmt.Errorf("configuring auth failed: %w", err)
+		}
+		middlewares = append([]Middleware{bearerAuth(cc)}, middlewares...)
+	}
+	if len(middlewares) > 0 {
+		c.Use(middlewares...)
+	}
+
 	return c, nil
 }
 
+// newDefaultTransport returns a clone of http.DefaultTransport with the configured proxy and TLS settings.
+func newDefaultTransport(config ClientConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.Proxy != nil {
+		proxy, err := config.Proxy.proxyFunc()
+		if err != nil {
+			return nil, fmt.Errorf("configuring proxy failed: %w", err)
+		}
+		transport.Proxy = proxy
+	}
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.tlsConfig()
+		if err != nil {
+			return nil, fmt.Errorf("configuring TLS failed: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport, nil
+}
+
 // SetBaseURL updates the base URL for the client.
 // The newBaseURL string must be a valid absolute URL.
 // Passing an empty string will clear the base URL.
+// It is safe to call while requests are in flight; requests built afterwards use the new base URL.
 func (c *Client) SetBaseURL(newBaseURL string) error {
-	if newBaseURL == "" {
-		c.baseURL = nil
-		return nil
-	}
-	u, err := url.Parse(newBaseURL)
-	if err != nil {
-		return fmt.Errorf("parsing new base URL failed: %w", err)
-	}
-	if !u.IsAbs() {
-		return fmt.Errorf("new base URL must be absolute: %s", newBaseURL)
+	var u *url.URL
+	if newBaseURL != "" {
+		var err error
+		u, err = url.Parse(newBaseURL)
+		if err != nil {
+			return fmt.Errorf("parsing new base URL failed: %w", err)
+		}
+		if !u.IsAbs() {
+			return fmt.Errorf("new base URL must be absolute: %s", newBaseURL)
+		}
 	}
+	c.mu.Lock()
 	c.baseURL = u
+	c.mu.Unlock()
 	return nil
 }
 
 // SetDefaultHeader sets a default header, replacing any existing values for the key.
+// It is safe to call while requests are in flight.
 func (c *Client) SetDefaultHeader(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.defaultHeaders == nil { // Should be initialized by NewClient
 		c.defaultHeaders = make(http.Header)
 	}
@@ -126,7 +310,10 @@ func (c *Client) SetDefaultHeader(key, value string) {
 
 // AddDefaultHeader adds a default header value. If the header key already exists,
 // it appends the new value to the existing ones.
+// It is safe to call while requests are in flight.
 func (c *Client) AddDefaultHeader(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.defaultHeaders == nil { // Should be initialized by NewClient
 		c.defaultHeaders = make(http.Header)
 	}
@@ -182,7 +369,10 @@ func WithQueryParams(params map[string]string) RequestOption {
 // resolveURL resolves a relative path against the client's base URL.
 // If the client has no base URL, it attempts to parse the path as an absolute URL.
 func (c *Client) resolveURL(path string) (*url.URL, error) {
-	if c.baseURL == nil {
+	c.mu.RLock()
+	baseURL := c.baseURL
+	c.mu.RUnlock()
+	if baseURL == nil {
 		// If no base URL, the path must be absolute or parsing will fail correctly.
 		u, err := url.Parse(path)
 		if err != nil {
@@ -213,7 +403,7 @@ func (c *Client) resolveURL(path string) (*url.URL, error) {
 			err,
 		)
 	}
-	return c.baseURL.ResolveReference(relativeURL), nil
+	return baseURL.ResolveReference(relativeURL), nil
 }
 
 // newRequest creates a new http.Request with client defaults and request options applied.
@@ -232,54 +422,143 @@ func (c *Client) newRequest(ctx context.Context, method, path string, body io.Re
 	// 1. Apply client-level default headers.
 	// These are added first. Request-specific options can then override (using Set)
 	// or add further values (using Add).
-	if c.defaultHeaders != nil {
-		for key, values := range c.defaultHeaders {
-			for _, value := range values {
-				req.Header.Add(key, value)
-			}
+	c.mu.RLock()
+	for key, values := range c.defaultHeaders {
+		for _, value := range values {
+			req.Header.Add(key, value)
 		}
 	}
+	c.mu.RUnlock()
+	req = c.profile.apply(req)
 
-	// 2. Apply request-specific options.
-	for _, option := range options {
+	// 2. Apply the client-level credentials and then the request-specific options, which can replace them.
+	for _, option := range slices.Concat(c.credentials, options) {
 		if err := option(req); err != nil {
 			return nil, fmt.Errorf("%w: %v", ErrRequestOptionFailed, err)
 		}
 	}
 
+	// 3. Attach correlation headers last so explicitly provided values win.
+	req, _ = withAttemptTrace(c.injectCorrelation(req))
 	return req, nil
 }
 
-// Do sends an HTTP request using the configured underlying client.
-// It wraps errors related to the HTTP execution itself.
+// Do sends an HTTP request using the configured underlying client, retrying it according to the retry policy.
+// It wraps errors related to the HTTP execution itself; failures are *AttemptError values
+// and responses expose their attempts through Attempts.
+// With tracing enabled the request, including its retries, is sent inside one client span.
+// With SLO tracking enabled the outcome of a request tagged with WithEndpointName counts towards its objective.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		// Add context about the request method and URL if possible
-		errCtx := fmt.Sprintf("method=%s url=%s", req.Method, req.URL.String())
-		// Check for context cancellation or deadline exceeded
-		if ctxErr := req.Context().Err(); ctxErr != nil {
-			return nil, fmt.Errorf(
-				"%w: context error: %v (%s)",
-				ErrRequestFailed,
-				ctxErr,
-				errCtx,
-			)
+	if c.slo != nil {
+		if name, ok := endpointName(req.Context()); ok {
+			start := time.Now()
+			resp, err := c.send(req)
+			c.slo.record(name, resp, time.Since(start), err)
+			return resp, err
 		}
-		// Check for URL errors (e.g., DNS resolution)
-		var urlErr *url.Error
-		if errors.As(err, &urlErr) {
-			return nil, fmt.Errorf(
-				"%w: network error: %v (%s)",
-				ErrRequestFailed,
-				urlErr,
-				errCtx,
-			)
+	}
+	return c.send(req)
+}
+
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.tracer != nil {
+		return c.tracer.do(req, c.do)
+	}
+	return c.do(req)
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	req, trace := withAttemptTrace(req)
+	policy := c.retryPolicy(req)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(req, trace)
+		if errors.Is(err, ErrCircuitOpen) {
+			return nil, withAttempts(req, err)
+		}
+
+		var next *http.Request
+		if policy != nil && attempt < policy.MaxAttempts && req.Context().Err() == nil && policy.ShouldRetry(resp, err) && !policy.waitsTooLong(resp) {
+			// a body that can not be rewound ends the retries with the current outcome
+			next, _ = nextAttempt(req)
+		}
+		if next == nil {
+			if err != nil {
+				return nil, withAttempts(req, c.doError(req, err))
+			}
+			if !isStreaming(req) {
+				limitBody(resp, c.maxBodyBytes, c.bodyTimeout)
+			}
+			return resp, nil
 		}
-		// Generic request failure
-		return nil, fmt.Errorf("%w: %v (%s)", ErrRequestFailed, err, errCtx)
+
+		wait := policy.backoff(attempt, resp)
+		if resp != nil {
+			discard(resp)
+		}
+		trace.setBackoff(wait)
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, withAttempts(req, c.doError(req, err))
+		}
+		req = next
 	}
-	return resp, nil
+}
+
+// attempt sends req once, guarded by the circuit breaker of its host, and records the attempt.
+func (c *Client) attempt(req *http.Request, trace *attemptTrace) (*http.Response, error) {
+	if c.breakers != nil {
+		if err := c.breakers.allow(req.URL.Host); err != nil {
+			trace.record(Attempt{URL: req.URL.String(), Err: err})
+			return nil, err
+		}
+	}
+	start := time.Now()
+	var resp *http.Response
+	var err error
+	if c.metrics != nil {
+		resp, err = c.metrics.observe(req, func() (*http.Response, error) { return c.client().Do(req) })
+	} else {
+		resp, err = c.client().Do(req)
+	}
+	a := Attempt{URL: req.URL.String(), Duration: time.Since(start), Err: err}
+	if resp != nil {
+		a.Status = resp.StatusCode
+	}
+	trace.record(a)
+	if c.breakers != nil {
+		if req.Context().Err() != nil {
+			c.breakers.abandon(req.URL.Host)
+		} else {
+			c.breakers.report(req.URL.Host, resp, err)
+		}
+	}
+	return resp, err
+}
+
+// doError wraps an error returned by the underlying client with the request details.
+func (c *Client) doError(req *http.Request, err error) error {
+	// Add context about the request method and URL if possible
+	errCtx := fmt.Sprintf("method=%s url=%s%s", req.Method, req.URL.String(), requestIDSuffix(req))
+	// Check for context cancellation or deadline exceeded
+	if ctxErr := req.Context().Err(); ctxErr != nil {
+		return fmt.Errorf(
+			"%w: context error: %w (%s)",
+			ErrRequestFailed,
+			ctxErr,
+			errCtx,
+		)
+	}
+	// Check for URL errors (e.g., DNS resolution)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf(
+			"%w: network error: %w (%s)",
+			ErrRequestFailed,
+			urlErr,
+			errCtx,
+		)
+	}
+	// Generic request failure
+	return fmt.Errorf("%w: %w (%s)", ErrRequestFailed, err, errCtx)
 }
 
 // Request sends an HTTP request with the given method, path, body, and options.
@@ -339,10 +618,18 @@ func (c *Client) Patch(ctx context.Context, path string, body io.Reader, options
 // --- Response Handling Helpers ---
 
 // DecodeResponse checks for non-2xx status codes, reads and closes the response body,
-// and then decodes the JSON body into the provided value `v`.
+// and then decodes the body into the provided value `v` with the decoder registered for its Content-Type
+// (see RegisterDecoder); responses without a known type are decoded as JSON.
 // If `v` is nil, the body is read and discarded (useful for checking success without needing data).
-// Returns ErrBadStatusCode if the status code is outside the 200-299 range.
-func DecodeResponse(resp *http.Response, v any) error {
+// Returns ErrBadStatusCode if the status code is outside the 200-299 range; for 429 and 503 it is wrapped
+// in a *RetryableError carrying the Retry-After wait.
+// Options can bound the body size and read time for this response.
+// Gzip and deflate encoded bodies are decompressed according to their Content-Encoding.
+// With expected content types (see ContentProfile and WithExpectedContentType) other responses fail
+// with a *ContentTypeError instead of being decoded.
+func DecodeResponse(resp *http.Response, v any, opts ...ResponseOption) error {
+	decompressBody(resp)
+	rc := applyResponseOptions(resp, opts)
 	defer resp.Body.Close()
 
 	// Check for non-successful status codes first.
@@ -362,7 +649,7 @@ func DecodeResponse(resp *http.Response, v any) error {
 			errMsg = fmt.Sprintf("%s (failed to read response body: %v)", errMsg, err)
 		}
 		// Wrap the specific status code error.
-		return fmt.Errorf("%w: %s", ErrBadStatusCode, errMsg)
+		return withAttempts(resp.Request, retryableStatusError(resp, fmt.Errorf("%w: %s%s", ErrBadStatusCode, errMsg, requestIDSuffix(resp.Request))))
 	}
 
 	// If v is nil, we don't need to decode, just consume the body.
@@ -370,7 +657,7 @@ func DecodeResponse(resp *http.Response, v any) error {
 		_, err := io.Copy(io.Discard, resp.Body) // Efficiently discard body
 		if err != nil {
 			return fmt.Errorf(
-				"%w: discarding body failed: %v",
+				"%w: discarding body failed: %w",
 				ErrReadResponseFailed,
 				err,
 			)
@@ -378,18 +665,21 @@ func DecodeResponse(resp *http.Response, v any) error {
 		return nil
 	}
 
-	// Decode the JSON body.
-	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+	if err := checkContentType(resp, rc); err != nil {
+		return err
+	}
+	// Decode the body with the decoder of its content type.
+	if name, err := decodeBody(resp, rc, v); err != nil {
 		// Check if it's an EOF error on an empty body, which might be acceptable
 		// depending on the API contract, but generally indicates an issue if
 		// decoding was expected.
 		if errors.Is(err, io.EOF) {
 			// Treat empty body as a decoding error if v was non-nil.
 			return fmt.Errorf(
-				"json decode failed: unexpected end of input (empty body?)",
+				"%s decode failed: unexpected end of input (empty body?)", name,
 			)
 		}
-		return fmt.Errorf("json decode failed: %w", err)
+		return fmt.Errorf("%s decode failed: %w", name, err)
 	}
 
 	return nil
@@ -399,28 +689,32 @@ func DecodeResponse(resp *http.Response, v any) error {
 // It also checks for non-2xx status codes before reading.
 // Returns ErrBadStatusCode if the status code is outside the 200-299 range.
 // If a non-2xx status occurs, the read body content is returned along with the error.
-func ReadResponseBody(resp *http.Response) (string, error) {
+// Options can bound the body size and read time for this response.
+// Gzip and deflate encoded bodies are decompressed according to their Content-Encoding.
+func ReadResponseBody(resp *http.Response, opts ...ResponseOption) (string, error) {
+	decompressBody(resp)
+	applyResponseOptions(resp, opts)
 	defer resp.Body.Close()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		// Still check status code if reading failed, it might be more informative.
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			return "", fmt.Errorf(
-				"%w: status %d (also failed to read body: %v)",
+			return "", retryableStatusError(resp, fmt.Errorf(
+				"%w: status %d (also failed to read body: %w)",
 				ErrBadStatusCode,
 				resp.StatusCode,
 				err,
-			)
+			))
 		}
-		return "", fmt.Errorf("%w: %v", ErrReadResponseFailed, err)
+		return "", fmt.Errorf("%w: %w", ErrReadResponseFailed, err)
 	}
 
 	// Check status code after successfully reading the body.
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		errMsg := fmt.Sprintf("status %d: %s", resp.StatusCode, string(bodyBytes))
+		errMsg := fmt.Sprintf("status %d: %s%s", resp.StatusCode, string(bodyBytes), requestIDSuffix(resp.Request))
 		// Return body content along with the status error
-		return string(bodyBytes), fmt.Errorf("%w: %s", ErrBadStatusCode, errMsg)
+		return string(bodyBytes), withAttempts(resp.Request, retryableStatusError(resp, fmt.Errorf("%w: %s", ErrBadStatusCode, errMsg)))
 	}
 
 	return string(bodyBytes), nil
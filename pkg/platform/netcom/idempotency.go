@@ -0,0 +1,40 @@
+package netcom
+
+import (
+	"errors"
+	"net/http"
+)
+
+// IdempotencyKeyHeader is the header of the idempotency key, as proposed by the IETF httpapi working group and
+// supported by many payment and order APIs.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKey sends key as the Idempotency-Key header. The key stays the same on every attempt, so the
+// retry policy retries the request even for POST and PATCH: the server recognises a repeated key and answers
+// with the outcome of the first attempt instead of applying the request twice. Use a key derived from the
+// operation, such as an order number, to also deduplicate sends repeated by the caller.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(req *http.Request) error {
+		if key == "" {
+			return errors.New("idempotency key must not be empty")
+		}
+		req.Header.Set(IdempotencyKeyHeader, key)
+		return nil
+	}
+}
+
+// WithNewIdempotencyKey is WithIdempotencyKey with a random (version 4) UUID generated for the request,
+// for operations without a natural key; it keeps a key already set on the request.
+func WithNewIdempotencyKey() RequestOption {
+	return func(req *http.Request) error {
+		if req.Header.Get(IdempotencyKeyHeader) == "" {
+			req.Header.Set(IdempotencyKeyHeader, NewRequestID())
+		}
+		return nil
+	}
+}
+
+// IdempotencyKey returns the idempotency key of the request, empty when it has none.
+func IdempotencyKey(req *http.Request) string {
+	return req.Header.Get(IdempotencyKeyHeader)
+}
@@ -0,0 +1,123 @@
+package netcom
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsConfig enables Prometheus metrics of the requests sent by the client.
+type MetricsConfig struct {
+	// Namespace prefixes the metric names; defaults to "netcom".
+	Namespace string
+	// Buckets are the latency histogram buckets in seconds; defaults to prometheus.DefBuckets.
+	Buckets []float64
+	// ConstLabels are added to every metric, e.g. {"client": "mes"} to tell several clients apart.
+	ConstLabels prometheus.Labels
+}
+
+// clientMetrics counts every attempt, including retries, by method, host and status;
+// failures without a response have the status "error".
+type clientMetrics struct {
+	requests *prometheus.CounterVec
+	inFlight *prometheus.GaugeVec
+	duration *prometheus.HistogramVec
+}
+
+func newClientMetrics(config MetricsConfig) *clientMetrics {
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = "netcom"
+	}
+	return &clientMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "client",
+			Name:        "requests_total",
+			Help:        "HTTP requests sent, including retries.",
+			ConstLabels: config.ConstLabels,
+		}, []string{"method", "host", "status"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   "client",
+			Name:        "requests_in_flight",
+			Help:        "HTTP requests waiting for their response headers.",
+			ConstLabels: config.ConstLabels,
+		}, []string{"method", "host"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   "client",
+			Name:        "request_duration_seconds",
+			Help:        "Time until the response headers of an HTTP request arrived.",
+			Buckets:     config.Buckets,
+			ConstLabels: config.ConstLabels,
+		}, []string{"method", "host", "status"}),
+	}
+}
+
+// observe wraps one attempt of req sent by send.
+func (m *clientMetrics) observe(req *http.Request, send func() (*http.Response, error)) (*http.Response, error) {
+	inFlight := m.inFlight.WithLabelValues(req.Method, req.URL.Host)
+	inFlight.Inc()
+	start := time.Now()
+	resp, err := send()
+	elapsed := time.Since(start)
+	inFlight.Dec()
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	m.requests.WithLabelValues(req.Method, req.URL.Host, status).Inc()
+	m.duration.WithLabelValues(req.Method, req.URL.Host, status).Observe(elapsed.Seconds())
+	return resp, err
+}
+
+func (m *clientMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.inFlight.Describe(ch)
+	m.duration.Describe(ch)
+}
+
+func (m *clientMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.inFlight.Collect(ch)
+	m.duration.Collect(ch)
+}
+
+// noMetrics is the collector of a client without metrics.
+type noMetrics struct{}
+
+func (noMetrics) Describe(chan<- *prometheus.Desc) {}
+func (noMetrics) Collect(chan<- prometheus.Metric) {}
+
+// clientCollector collects the request metrics and the SLO gauges of a client that has both.
+type clientCollector []prometheus.Collector
+
+func (cc clientCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range cc {
+		c.Describe(ch)
+	}
+}
+
+func (cc clientCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range cc {
+		c.Collect(ch)
+	}
+}
+
+// Collector returns the Prometheus collector of the client metrics for registration, e.g.
+// prometheus.MustRegister(client.Collector()). It collects nothing unless ClientConfig.Metrics or ClientConfig.SLO is set.
+func (c *Client) Collector() prometheus.Collector {
+	switch {
+	case c.metrics != nil && c.sloMetrics != nil:
+		return clientCollector{c.metrics, c.sloMetrics}
+	case c.metrics != nil:
+		return c.metrics
+	case c.sloMetrics != nil:
+		return c.sloMetrics
+	}
+	return noMetrics{}
+}
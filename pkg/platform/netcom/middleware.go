@@ -0,0 +1,55 @@
+package netcom
+
+import (
+	"net/http"
+)
+
+// Middleware wraps the transport of a client to add a cross-cutting concern such as authentication, logging or metrics.
+// It runs for every attempt the client sends, so retries and circuit breaking (see RetryPolicy and CircuitBreakerConfig)
+// happen around the whole chain; a middleware may also answer a request itself without calling next.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper, which keeps small middlewares short.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain composes middlewares into one; the first one is the outermost and sees the request first.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// Use appends middlewares to the client's chain; every request sent afterwards, including those of the helpers
+// like Get, PostJSON and the typed endpoints, passes through them in registration order.
+// Use is safe to call while requests are in flight; they keep the chain they started with.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middlewares = append(c.middlewares, middlewares...)
+
+	base := c.httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	sender := *c.httpClient
+	sender.Transport = Chain(c.middlewares...)(base)
+	c.sender = &sender
+}
+
+// client returns the http.Client sending the attempts: the configured one with its transport wrapped by the middlewares.
+func (c *Client) client() *http.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.sender != nil {
+		return c.sender
+	}
+	return c.httpClient
+}
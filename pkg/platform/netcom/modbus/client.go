@@ -0,0 +1,290 @@
+// Package modbus implements a Modbus TCP master on top of the raw TCP client.
+package modbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom/raw"
+)
+
+// Function codes of the supported requests.
+const (
+	FuncReadCoils              byte = 0x01
+	FuncReadDiscreteInputs     byte = 0x02
+	FuncReadHoldingRegisters   byte = 0x03
+	FuncReadInputRegisters     byte = 0x04
+	FuncWriteSingleCoil        byte = 0x05
+	FuncWriteSingleRegister    byte = 0x06
+	FuncWriteMultipleCoils     byte = 0x0F
+	FuncWriteMultipleRegisters byte = 0x10
+)
+
+// Protocol limits on the quantity of a single request.
+const (
+	MaxReadBits       = 2000
+	MaxReadRegisters  = 125
+	MaxWriteBits      = 1968
+	MaxWriteRegisters = 123
+)
+
+const (
+	mbapLen        = 7
+	maxADU         = 260
+	defaultTimeout = 5 * time.Second
+)
+
+// ErrBadQuantity indicates a request for zero items or more than the protocol allows.
+var ErrBadQuantity = errors.New("invalid quantity")
+
+// ErrBadResponse indicates a response that does not match its request.
+var ErrBadResponse = errors.New("malformed modbus response")
+
+// ExceptionError is the exception response of a device.
+type ExceptionError struct {
+	Function byte
+	Code     byte
+}
+
+var exceptionNames = map[byte]string{
+	1:  "illegal function",
+	2:  "illegal data address",
+	3:  "illegal data value",
+	4:  "server device failure",
+	5:  "acknowledge",
+	6:  "server device busy",
+	10: "gateway path unavailable",
+	11: "gateway target device failed to respond",
+}
+
+func (e *ExceptionError) Error() string {
+	desc := exceptionNames[e.Code]
+	if desc == "" {
+		desc = "unknown exception"
+	}
+	return fmt.Sprintf("modbus exception %d (%s) for function 0x%02x", e.Code, desc, e.Function)
+}
+
+// Config holds configuration for a Modbus TCP client.
+type Config struct {
+	Addr   string // Device address as host:port; Modbus TCP listens on 502.
+	UnitID byte   // Unit identifier, relevant behind gateways.
+	// Timeout bounds every request that is issued with a context without deadline. Defaults to 5s.
+	Timeout time.Duration
+	// TCP overrides the connection settings; its Addr and Framer are ignored.
+	TCP raw.TCPConfig
+}
+
+// Client is a Modbus TCP master for a single device; requests are serialised over one connection
+// which is reopened after errors.
+type Client struct {
+	conn    *raw.TCPClient
+	addr    string
+	unitID  byte
+	timeout time.Duration
+
+	mu   sync.Mutex
+	txID uint16
+}
+
+// NewClient creates a client for the configured device; it connects on first use.
+func NewClient(config Config) (*Client, error) {
+	tcp := config.TCP
+	tcp.Addr = config.Addr
+	tcp.Framer = mbapFramer{}
+	tcp.MaxFrameBytes = maxADU
+	conn, err := raw.NewTCPClient(tcp)
+	if err != nil {
+		return nil, err
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+	return &Client{conn: conn, addr: config.Addr, unitID: config.UnitID, timeout: config.Timeout}, nil
+}
+
+// Close closes the connection to the device.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// ReadCoils reads qty coils starting at addr.
+func (c *Client) ReadCoils(ctx context.Context, addr, qty uint16) ([]bool, error) {
+	return c.readBits(ctx, FuncReadCoils, addr, qty)
+}
+
+// ReadDiscreteInputs reads qty discrete inputs starting at addr.
+func (c *Client) ReadDiscreteInputs(ctx context.Context, addr, qty uint16) ([]bool, error) {
+	return c.readBits(ctx, FuncReadDiscreteInputs, addr, qty)
+}
+
+// ReadHoldingRegisters reads qty holding registers starting at addr.
+func (c *Client) ReadHoldingRegisters(ctx context.Context, addr, qty uint16) ([]uint16, error) {
+	return c.readRegisters(ctx, FuncReadHoldingRegisters, addr, qty)
+}
+
+// ReadInputRegisters reads qty input registers starting at addr.
+func (c *Client) ReadInputRegisters(ctx context.Context, addr, qty uint16) ([]uint16, error) {
+	return c.readRegisters(ctx, FuncReadInputRegisters, addr, qty)
+}
+
+// WriteSingleCoil sets the coil at addr.
+func (c *Client) WriteSingleCoil(ctx context.Context, addr uint16, on bool) error {
+	v := uint16(0x0000)
+	if on {
+		v = 0xFF00
+	}
+	_, err := c.call(ctx, FuncWriteSingleCoil, be16(addr, v), 4)
+	return err
+}
+
+// WriteSingleRegister writes the holding register at addr.
+func (c *Client) WriteSingleRegister(ctx context.Context, addr uint16, v uint16) error {
+	_, err := c.call(ctx, FuncWriteSingleRegister, be16(addr, v), 4)
+	return err
+}
+
+// WriteMultipleCoils sets the coils starting at addr.
+func (c *Client) WriteMultipleCoils(ctx context.Context, addr uint16, values []bool) error {
+	if len(values) == 0 || len(values) > MaxWriteBits {
+		return fmt.Errorf("%w: %d coils", ErrBadQuantity, len(values))
+	}
+	packed := make([]byte, (len(values)+7)/8)
+	for i, on := range values {
+		if on {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	data := append(be16(addr, uint16(len(values))), byte(len(packed)))
+	_, err := c.call(ctx, FuncWriteMultipleCoils, append(data, packed...), 4)
+	return err
+}
+
+// WriteMultipleRegisters writes the holding registers starting at addr.
+func (c *Client) WriteMultipleRegisters(ctx context.Context, addr uint16, values []uint16) error {
+	if len(values) == 0 || len(values) > MaxWriteRegisters {
+		return fmt.Errorf("%w: %d registers", ErrBadQuantity, len(values))
+	}
+	data := append(be16(addr, uint16(len(values))), byte(2*len(values)))
+	_, err := c.call(ctx, FuncWriteMultipleRegisters, append(data, be16(values...)...), 4)
+	return err
+}
+
+func (c *Client) readBits(ctx context.Context, fn byte, addr, qty uint16) ([]bool, error) {
+	if qty == 0 || qty > MaxReadBits {
+		return nil, fmt.Errorf("%w: %d bits", ErrBadQuantity, qty)
+	}
+	n := (int(qty) + 7) / 8
+	data, err := c.call(ctx, fn, be16(addr, qty), 1+n)
+	if err != nil {
+		return nil, err
+	}
+	if int(data[0]) != n {
+		return nil, fmt.Errorf("%w: %d data bytes for %d bits", ErrBadResponse, data[0], qty)
+	}
+	bits := make([]bool, qty)
+	for i := range bits {
+		bits[i] = data[1+i/8]&(1<<(i%8)) != 0
+	}
+	return bits, nil
+}
+
+func (c *Client) readRegisters(ctx context.Context, fn byte, addr, qty uint16) ([]uint16, error) {
+	if qty == 0 || qty > MaxReadRegisters {
+		return nil, fmt.Errorf("%w: %d registers", ErrBadQuantity, qty)
+	}
+	data, err := c.call(ctx, fn, be16(addr, qty), 1+2*int(qty))
+	if err != nil {
+		return nil, err
+	}
+	if int(data[0]) != 2*int(qty) {
+		return nil, fmt.Errorf("%w: %d data bytes for %d registers", ErrBadResponse, data[0], qty)
+	}
+	regs := make([]uint16, qty)
+	for i := range regs {
+		regs[i] = binary.BigEndian.Uint16(data[1+2*i:])
+	}
+	return regs, nil
+}
+
+// call sends a request PDU and returns the data of the response PDU, which must be want bytes long.
+func (c *Client) call(ctx context.Context, fn byte, data []byte, want int) ([]byte, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	c.mu.Lock()
+	c.txID++
+	txID := c.txID
+	c.mu.Unlock()
+
+	adu := make([]byte, mbapLen, mbapLen+1+len(data))
+	binary.BigEndian.PutUint16(adu[0:], txID)
+	binary.BigEndian.PutUint16(adu[4:], uint16(2+len(data)))
+	adu[6] = c.unitID
+	adu = append(append(adu, fn), data...)
+
+	resp, err := c.conn.Request(ctx, adu)
+	if err != nil {
+		return nil, err
+	}
+	if got := binary.BigEndian.Uint16(resp[0:]); got != txID {
+		return nil, fmt.Errorf("%w: transaction %d answered as %d; addr:%v", ErrBadResponse, txID, got, c.addr)
+	}
+	pdu := resp[mbapLen:]
+	switch {
+	case pdu[0] == fn|0x80 && len(pdu) == 2:
+		return nil, fmt.Errorf("%w; addr:%v", &ExceptionError{Function: fn, Code: pdu[1]}, c.addr)
+	case pdu[0] != fn:
+		return nil, fmt.Errorf("%w: function 0x%02x answered as 0x%02x; addr:%v", ErrBadResponse, fn, pdu[0], c.addr)
+	case len(pdu)-1 != want:
+		return nil, fmt.Errorf("%w: %d data bytes, expected %d; addr:%v", ErrBadResponse, len(pdu)-1, want, c.addr)
+	}
+	return pdu[1:], nil
+}
+
+func be16(values ...uint16) []byte {
+	b := make([]byte, 2*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint16(b[2*i:], v)
+	}
+	return b
+}
+
+// mbapFramer frames Modbus TCP application data units by the length field of their MBAP header.
+type mbapFramer struct{}
+
+func (mbapFramer) WriteFrame(w io.Writer, p []byte) error {
+	_, err := w.Write(p)
+	return err
+}
+
+func (mbapFramer) ReadFrame(r *bufio.Reader, max int) ([]byte, error) {
+	header := make([]byte, mbapLen-1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	n := int(binary.BigEndian.Uint16(header[4:]))
+	if n < 2 {
+		return nil, fmt.Errorf("%w: length field %d", ErrBadResponse, n)
+	}
+	if len(header)+n > max {
+		return nil, fmt.Errorf("%w: %d bytes announced", raw.ErrFrameTooLarge, n)
+	}
+	adu := make([]byte, len(header)+n)
+	copy(adu, header)
+	if _, err := io.ReadFull(r, adu[len(header):]); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return adu, nil
+}
@@ -0,0 +1,181 @@
+package modbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrBadTag indicates a struct that cannot be mapped onto registers.
+var ErrBadTag = errors.New("invalid modbus struct tag")
+
+// ErrShortBlock indicates a register block smaller than the struct decoded from it.
+var ErrShortBlock = errors.New("register block too short")
+
+type registerField struct {
+	index  int
+	offset int
+	words  int
+	swap   bool
+}
+
+// registerFields maps the tagged fields of a struct type. Fields are tagged `modbus:"offset[,swap][,len=N]"`:
+// offset is the register offset into the block, swap reads multi-register values low word first and len is
+// the number of registers of a string field. bool, int16, uint16 take one register, int32, uint32 and float32 two,
+// int64, uint64 and float64 four; untagged fields are skipped.
+func registerFields(t reflect.Type) ([]registerField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %v is not a struct", ErrBadTag, t)
+	}
+	var fields []registerField
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("modbus")
+		if !ok || tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		offset, err := strconv.Atoi(parts[0])
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("%w: offset %q; field:%v", ErrBadTag, parts[0], sf.Name)
+		}
+		f := registerField{index: i, offset: offset}
+		for _, opt := range parts[1:] {
+			switch {
+			case opt == "swap":
+				f.swap = true
+			case strings.HasPrefix(opt, "len="):
+				if f.words, err = strconv.Atoi(strings.TrimPrefix(opt, "len=")); err != nil || f.words <= 0 {
+					return nil, fmt.Errorf("%w: %q; field:%v", ErrBadTag, opt, sf.Name)
+				}
+			default:
+				return nil, fmt.Errorf("%w: unknown option %q; field:%v", ErrBadTag, opt, sf.Name)
+			}
+		}
+		switch sf.Type.Kind() {
+		case reflect.Bool, reflect.Int16, reflect.Uint16:
+			f.words = 1
+		case reflect.Int32, reflect.Uint32, reflect.Float32:
+			f.words = 2
+		case reflect.Int64, reflect.Uint64, reflect.Float64:
+			f.words = 4
+		case reflect.String:
+			if f.words == 0 {
+				return nil, fmt.Errorf("%w: a string needs len=N; field:%v", ErrBadTag, sf.Name)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported type %v; field:%v", ErrBadTag, sf.Type, sf.Name)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// RegisterSpan returns the number of registers covered by the tagged fields of the struct v points to.
+func RegisterSpan(v any) (int, error) {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return 0, fmt.Errorf("%w: %T is not a pointer to a struct", ErrBadTag, v)
+	}
+	fields, err := registerFields(t.Elem())
+	if err != nil {
+		return 0, err
+	}
+	span := 0
+	for _, f := range fields {
+		span = max(span, f.offset+f.words)
+	}
+	return span, nil
+}
+
+// DecodeRegisters fills the tagged fields of the struct v points to from a register block.
+// Values are big endian as the protocol mandates; strings hold two ASCII characters per register,
+// high byte first, with trailing NULs and spaces trimmed.
+func DecodeRegisters(regs []uint16, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: %T is not a pointer to a struct", ErrBadTag, v)
+	}
+	rv = rv.Elem()
+	fields, err := registerFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.offset+f.words > len(regs) {
+			return fmt.Errorf("%w: %d registers, field %v needs %d", ErrShortBlock, len(regs), rv.Type().Field(f.index).Name, f.offset+f.words)
+		}
+		setRegisterField(rv.Field(f.index), regs[f.offset:f.offset+f.words], f.swap)
+	}
+	return nil
+}
+
+func setRegisterField(fv reflect.Value, words []uint16, swap bool) {
+	if fv.Kind() == reflect.String {
+		b := make([]byte, 0, 2*len(words))
+		for _, w := range words {
+			b = append(b, byte(w>>8), byte(w))
+		}
+		fv.SetString(strings.TrimRight(string(b), "\x00 "))
+		return
+	}
+	var u uint64
+	for i := range words {
+		w := words[i]
+		if swap {
+			w = words[len(words)-1-i]
+		}
+		u = u<<16 | uint64(w)
+	}
+	switch fv.Kind() {
+	case reflect.Bool:
+		fv.SetBool(u != 0)
+	case reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fv.SetUint(u)
+	case reflect.Int16:
+		fv.SetInt(int64(int16(u)))
+	case reflect.Int32:
+		fv.SetInt(int64(int32(u)))
+	case reflect.Int64:
+		fv.SetInt(int64(u))
+	case reflect.Float32:
+		fv.SetFloat(float64(math.Float32frombits(uint32(u))))
+	case reflect.Float64:
+		fv.SetFloat(math.Float64frombits(u))
+	}
+}
+
+// ReadHoldingStruct reads the holding registers covered by the struct v points to, starting at addr,
+// and decodes them with DecodeRegisters. Blocks over MaxReadRegisters are read in several requests.
+func (c *Client) ReadHoldingStruct(ctx context.Context, addr uint16, v any) error {
+	return c.readStruct(ctx, FuncReadHoldingRegisters, addr, v)
+}
+
+// ReadInputStruct is ReadHoldingStruct for input registers.
+func (c *Client) ReadInputStruct(ctx context.Context, addr uint16, v any) error {
+	return c.readStruct(ctx, FuncReadInputRegisters, addr, v)
+}
+
+func (c *Client) readStruct(ctx context.Context, fn byte, addr uint16, v any) error {
+	span, err := RegisterSpan(v)
+	if err != nil {
+		return err
+	}
+	if span == 0 || int(addr)+span > math.MaxUint16+1 {
+		return fmt.Errorf("%w: %d registers from %d", ErrBadQuantity, span, addr)
+	}
+	regs := make([]uint16, 0, span)
+	for len(regs) < span {
+		qty := min(span-len(regs), MaxReadRegisters)
+		block, err := c.readRegisters(ctx, fn, addr+uint16(len(regs)), uint16(qty))
+		if err != nil {
+			return err
+		}
+		regs = append(regs, block...)
+	}
+	return DecodeRegisters(regs, v)
+}
@@ -0,0 +1,60 @@
+package modbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBadSchedule indicates a poll task without a positive interval or read function.
+var ErrBadSchedule = errors.New("invalid poll schedule")
+
+// PollTask is a read issued against a device on a fixed interval.
+type PollTask struct {
+	Name  string
+	Every time.Duration
+	Read  func(ctx context.Context, c *Client) error
+}
+
+// Poll runs every task on its own interval, starting immediately, until ctx is done.
+// Failed reads are reported to onErr with the task name and retried on the next tick; a read that overruns
+// its interval skips the missed ticks. The tasks share the client's connection, so reads never overlap.
+func (c *Client) Poll(ctx context.Context, tasks []PollTask, onErr func(task string, err error)) error {
+	for _, task := range tasks {
+		if task.Every <= 0 || task.Read == nil {
+			return fmt.Errorf("%w; task:%v", ErrBadSchedule, task.Name)
+		}
+	}
+	var wg sync.WaitGroup
+	for _, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(task.Every)
+			defer ticker.Stop()
+			for {
+				if err := task.Read(ctx, c); err != nil && !done(ctx) {
+					onErr(task.Name, err)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// done reports whether ctx is over, including a deadline that passed before the context noticed.
+func done(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	deadline, ok := ctx.Deadline()
+	return ok && !time.Now().Before(deadline)
+}
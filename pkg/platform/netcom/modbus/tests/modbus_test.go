@@ -0,0 +1,212 @@
+package modbus_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom/modbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// device is an in-memory Modbus TCP server with 200 coils and 200 registers, serving the registers
+// as both holding and input registers.
+type device struct {
+	mu    sync.Mutex
+	coils [200]bool
+	regs  [200]uint16
+	reads atomic.Int32
+}
+
+func startDevice(t *testing.T) (*device, string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	d := new(device)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go d.serve(conn)
+		}
+	}()
+	return d, l.Addr().String()
+}
+
+func (d *device) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		header := make([]byte, 7)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		pdu := make([]byte, binary.BigEndian.Uint16(header[4:])-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+		resp := d.handle(pdu)
+		binary.BigEndian.PutUint16(header[4:], uint16(len(resp)+1))
+		conn.Write(append(header, resp...))
+	}
+}
+
+func (d *device) handle(pdu []byte) []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fn := pdu[0]
+	addr, qty := int(binary.BigEndian.Uint16(pdu[1:])), int(binary.BigEndian.Uint16(pdu[3:]))
+	exception := []byte{fn | 0x80, 2}
+	switch fn {
+	case modbus.FuncReadCoils:
+		if addr+qty > len(d.coils) {
+			return exception
+		}
+		out := make([]byte, (qty+7)/8)
+		for i := range qty {
+			if d.coils[addr+i] {
+				out[i/8] |= 1 << (i % 8)
+			}
+		}
+		return append([]byte{fn, byte(len(out))}, out...)
+	case modbus.FuncReadHoldingRegisters, modbus.FuncReadInputRegisters:
+		if addr+qty > len(d.regs) {
+			return exception
+		}
+		d.reads.Add(1)
+		out := []byte{fn, byte(2 * qty)}
+		for i := range qty {
+			out = binary.BigEndian.AppendUint16(out, d.regs[addr+i])
+		}
+		return out
+	case modbus.FuncWriteSingleCoil:
+		d.coils[addr] = qty == 0xFF00
+		return pdu
+	case modbus.FuncWriteSingleRegister:
+		d.regs[addr] = uint16(qty)
+		return pdu
+	case modbus.FuncWriteMultipleCoils:
+		for i := range qty {
+			d.coils[addr+i] = pdu[6+i/8]&(1<<(i%8)) != 0
+		}
+		return pdu[:5]
+	case modbus.FuncWriteMultipleRegisters:
+		if addr+qty > len(d.regs) {
+			return exception
+		}
+		for i := range qty {
+			d.regs[addr+i] = binary.BigEndian.Uint16(pdu[6+2*i:])
+		}
+		return pdu[:5]
+	}
+	return []byte{fn | 0x80, 1}
+}
+
+func newClient(t *testing.T, addr string) *modbus.Client {
+	t.Helper()
+	c, err := modbus.NewClient(modbus.Config{Addr: addr, UnitID: 1, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestReadWrite(t *testing.T) {
+	_, addr := startDevice(t)
+	c := newClient(t, addr)
+	ctx := context.Background()
+
+	require.NoError(t, c.WriteMultipleCoils(ctx, 3, []bool{true, false, true}))
+	require.NoError(t, c.WriteSingleCoil(ctx, 10, true))
+	coils, err := c.ReadCoils(ctx, 2, 9)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, true, false, true, false, false, false, false, true}, coils)
+
+	require.NoError(t, c.WriteMultipleRegisters(ctx, 0, []uint16{1, 2, 3}))
+	require.NoError(t, c.WriteSingleRegister(ctx, 1, 42))
+	regs, err := c.ReadHoldingRegisters(ctx, 0, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []uint16{1, 42, 3}, regs)
+
+	_, err = c.ReadInputRegisters(ctx, 199, 2)
+	var exc *modbus.ExceptionError
+	require.True(t, errors.As(err, &exc))
+	assert.Equal(t, byte(2), exc.Code)
+
+	_, err = c.ReadHoldingRegisters(ctx, 0, modbus.MaxReadRegisters+1)
+	assert.ErrorIs(t, err, modbus.ErrBadQuantity)
+}
+
+type lineStatus struct {
+	Running bool    `modbus:"0"`
+	Speed   int16   `modbus:"1"`
+	Counter uint32  `modbus:"2"`
+	Weight  float32 `modbus:"4,swap"`
+	Order   string  `modbus:"6,len=3"`
+	Skipped int
+	Tail    uint16 `modbus:"129"`
+}
+
+func TestReadHoldingStruct(t *testing.T) {
+	d, addr := startDevice(t)
+	w := math.Float32bits(12.5)
+	copy(d.regs[10:], []uint16{1, 0xFFFE, 0x0001, 0x0002, uint16(w), uint16(w >> 16), 'W'<<8 | 'O', '4'<<8 | '2'})
+	d.regs[139] = 7
+	c := newClient(t, addr)
+
+	var s lineStatus
+	require.NoError(t, c.ReadHoldingStruct(context.Background(), 10, &s))
+	assert.Equal(t, lineStatus{Running: true, Speed: -2, Counter: 0x00010002, Weight: 12.5, Order: "WO42", Tail: 7}, s)
+	// 130 registers need two requests
+	assert.Equal(t, int32(2), d.reads.Load())
+
+	span, err := modbus.RegisterSpan(&s)
+	require.NoError(t, err)
+	assert.Equal(t, 130, span)
+
+	assert.ErrorIs(t, modbus.DecodeRegisters(make([]uint16, 4), &s), modbus.ErrShortBlock)
+	var bad struct {
+		Name string `modbus:"0"`
+	}
+	assert.ErrorIs(t, modbus.DecodeRegisters(make([]uint16, 4), &bad), modbus.ErrBadTag)
+}
+
+func TestPoll(t *testing.T) {
+	d, addr := startDevice(t)
+	d.regs[0] = 5
+	c := newClient(t, addr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	var fast, failed atomic.Int32
+	err := c.Poll(ctx, []modbus.PollTask{
+		{Name: "counter", Every: 20 * time.Millisecond, Read: func(ctx context.Context, c *modbus.Client) error {
+			regs, err := c.ReadHoldingRegisters(ctx, 0, 1)
+			if err == nil && regs[0] == 5 {
+				fast.Add(1)
+			}
+			return err
+		}},
+		{Name: "missing", Every: time.Hour, Read: func(ctx context.Context, c *modbus.Client) error {
+			_, err := c.ReadHoldingRegisters(ctx, 500, 1)
+			return err
+		}},
+	}, func(task string, err error) {
+		assert.Equal(t, "missing", task)
+		failed.Add(1)
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.GreaterOrEqual(t, fast.Load(), int32(5))
+	assert.Equal(t, int32(1), failed.Load())
+
+	assert.ErrorIs(t, c.Poll(context.Background(), []modbus.PollTask{{Name: "never"}}, nil), modbus.ErrBadSchedule)
+}
@@ -0,0 +1,93 @@
+package netcom
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strings"
+)
+
+// FilePart is a file of a multipart/form-data body.
+type FilePart struct {
+	// FieldName is the form field of the file, e.g. "file".
+	FieldName string
+	// FileName is the name reported to the server; it may differ from the local name.
+	FileName string
+	// ContentType defaults to application/octet-stream.
+	ContentType string
+	// Content is streamed into the body as it is sent; a Content that is also an io.Closer is closed afterwards.
+	Content io.Reader
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// PostMultipart sends a POST request with a multipart/form-data body of the fields, in key order, followed by the files.
+// The body is streamed, so large files are never held in memory, and the Content-Type with its boundary is set automatically.
+// A streamed body can not be rewound, so the request is sent once regardless of the retry policy.
+func (c *Client) PostMultipart(ctx context.Context, path string, fields map[string]string, files []FilePart, options ...RequestOption) (*http.Response, error) {
+	for i, f := range files {
+		if f.FieldName == "" || f.Content == nil {
+			return nil, fmt.Errorf("%w: file part %d needs a field name and content", ErrRequestCreationFailed, i)
+		}
+	}
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	options = append([]RequestOption{WithSetHeader("Content-Type", mw.FormDataContentType())}, options...)
+	req, err := c.newRequest(ctx, http.MethodPost, path, pr, options...)
+	if err != nil {
+		pr.Close()
+		closeParts(files)
+		return nil, err
+	}
+
+	go func() {
+		defer closeParts(files)
+		pw.CloseWithError(writeMultipart(mw, fields, files))
+	}()
+	resp, err := c.Do(req)
+	if err != nil {
+		// unblocks the writer when the request failed before the body was consumed
+		pr.CloseWithError(err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func writeMultipart(mw *multipart.Writer, fields map[string]string, files []FilePart) error {
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		if err := mw.WriteField(k, fields[k]); err != nil {
+			return err
+		}
+	}
+	for _, f := range files {
+		contentType := f.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(f.FieldName), quoteEscaper.Replace(f.FileName)))
+		h.Set("Content-Type", contentType)
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(part, f.Content); err != nil {
+			return fmt.Errorf("streaming file part %s: %w", f.FieldName, err)
+		}
+	}
+	return mw.Close()
+}
+
+func closeParts(files []FilePart) {
+	for _, f := range files {
+		if closer, ok := f.Content.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package netcomtest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+)
+
+// ErrNoInteraction is returned in replay mode for a request that has no unused recorded interaction.
+var ErrNoInteraction = errors.New("netcomtest: no recorded interaction")
+
+// RecordEnv is the environment variable switching NewRecordingClient to recording when set to a non-empty value.
+const RecordEnv = "NETCOM_RECORD"
+
+// Redacted replaces the secrets in a cassette.
+const Redacted = "REDACTED"
+
+// Header names and query, form or JSON field names that are always redacted.
+var (
+	defaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "Api-Key"}
+	defaultRedactedFields  = []string{"access_token", "refresh_token", "id_token", "client_secret", "password", "api_key", "apikey"}
+)
+
+// Mode selects whether a Recorder talks to the real server.
+type Mode int
+
+const (
+	// ModeReplay answers requests from the cassette without sending them.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the server and records the interactions for Save.
+	ModeRecord
+)
+
+// ModeFromEnv returns ModeRecord when RecordEnv is set and ModeReplay otherwise.
+func ModeFromEnv() Mode {
+	if os.Getenv(RecordEnv) != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// RecorderConfig configures a Recorder.
+type RecorderConfig struct {
+	Mode Mode
+	// Transport sends the requests in record mode when the recorder is used as a RoundTripper; defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// RedactHeaders are headers redacted in addition to the credential headers, e.g. the header of a netcom.APIKey.
+	RedactHeaders []string
+	// RedactFields are query parameters and form or JSON body fields redacted in addition to the usual token and secret fields.
+	RedactFields []string
+}
+
+// Interaction is a recorded request and the response it got.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request as stored in a cassette, with its secrets redacted.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	// BodyEncoding is "base64" for a body that is not valid UTF-8.
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+// RecordedResponse is a response as stored in a cassette, with its secrets redacted.
+type RecordedResponse struct {
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is a record-and-replay transport. In record mode it sends requests to the server and keeps the
+// interactions, with credentials redacted, until Save writes them to the cassette file; in replay mode it answers
+// requests from the cassette. A request is answered by the first interaction not used yet with the same method
+// and redacted URL, so repeated requests replay in recording order.
+type Recorder struct {
+	path    string
+	config  RecorderConfig
+	headers []string
+	fields  []string
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder returns a recorder of the cassette file at path; in replay mode the cassette must exist.
+func NewRecorder(path string, config RecorderConfig) (*Recorder, error) {
+	r := &Recorder{
+		path:    path,
+		config:  config,
+		headers: append(slices.Clone(defaultRedactedHeaders), config.RedactHeaders...),
+		fields:  append(slices.Clone(defaultRedactedFields), config.RedactFields...),
+	}
+	if config.Mode == ModeRecord {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("netcomtest: reading the cassette failed (record it with %s=1): %w", RecordEnv, err)
+	}
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("netcomtest: decoding the cassette %s failed: %w", path, err)
+	}
+	r.interactions = c.Interactions
+	r.used = make([]bool, len(c.Interactions))
+	return r, nil
+}
+
+// NewRecordingClient returns a netcom client recording to or replaying from the cassette at path, depending on
+// ModeFromEnv; recorded interactions are saved when the test ends. The recorder is the innermost middleware,
+// so it sees the requests with their credentials applied and redacts them.
+func NewRecordingClient(t testing.TB, path string, config netcom.ClientConfig) *netcom.Client {
+	t.Helper()
+	r, err := NewRecorder(path, RecorderConfig{Mode: ModeFromEnv()})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if r.config.Mode == ModeRecord {
+		t.Cleanup(func() {
+			if err := r.Save(); err != nil {
+				t.Errorf("%v", err)
+			}
+		})
+	}
+	config.Middlewares = append(slices.Clone(config.Middlewares), r.Middleware())
+	c, err := netcom.NewClient(config)
+	if err != nil {
+		t.Fatalf("netcomtest: creating the client failed: %v", err)
+	}
+	return c
+}
+
+// RoundTrip records or replays the request, sending it through RecorderConfig.Transport in record mode.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := r.config.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return r.roundTrip(req, next)
+}
+
+// Middleware returns the recorder as a netcom.Middleware, sending through the rest of the chain in record mode.
+func (r *Recorder) Middleware() netcom.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return netcom.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return r.roundTrip(req, next)
+		})
+	}
+}
+
+// Interactions returns the interactions recorded or loaded so far.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.interactions)
+}
+
+// Save writes the recorded interactions to the cassette file, creating its directory; it does nothing in replay mode.
+func (r *Recorder) Save() error {
+	if r.config.Mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(cassette{Interactions: r.interactions}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("netcomtest: encoding the cassette failed: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("netcomtest: creating the cassette directory failed: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("netcomtest: writing the cassette failed: %w", err)
+	}
+	return nil
+}
+
+func (r *Recorder) roundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	recorded := r.recordRequest(req, reqBody)
+	if r.config.Mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(reqBody))
+	resp, err := next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{Request: recorded, Response: RecordedResponse{Status: resp.StatusCode, Header: r.redactHeader(resp.Header)}}
+	interaction.Response.Body, interaction.Response.BodyEncoding = r.encodeBody(resp.Header, respBody)
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.used = append(r.used, true)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	var found *Interaction
+	for i, in := range r.interactions {
+		if !r.used[i] && in.Request.Method == recorded.Method && in.Request.URL == recorded.URL {
+			r.used[i] = true
+			found = &in
+			break
+		}
+	}
+	r.mu.Unlock()
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, recorded.Method, recorded.URL)
+	}
+
+	body := []byte(found.Response.Body)
+	if found.Response.BodyEncoding == "base64" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(found.Response.Body); err != nil {
+			return nil, fmt.Errorf("netcomtest: decoding the recorded body failed: %w", err)
+		}
+	}
+	header := found.Response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", found.Response.Status, http.StatusText(found.Response.Status)),
+		StatusCode:    found.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) recordRequest(req *http.Request, body []byte) RecordedRequest {
+	u := *req.URL
+	u.User = nil
+	if q := u.Query(); len(q) > 0 {
+		r.redactValues(q)
+		u.RawQuery = q.Encode()
+	}
+	recorded := RecordedRequest{Method: req.Method, URL: u.String(), Header: r.redactHeader(req.Header)}
+	recorded.Body, recorded.BodyEncoding = r.encodeBody(req.Header, body)
+	return recorded
+}
+
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	h = h.Clone()
+	for _, name := range r.headers {
+		if _, ok := h[http.CanonicalHeaderKey(name)]; ok {
+			h.Set(name, Redacted)
+		}
+	}
+	return h
+}
+
+func (r *Recorder) redactValues(v url.Values) {
+	for key := range v {
+		if r.secretField(key) {
+			v.Set(key, Redacted)
+		}
+	}
+}
+
+func (r *Recorder) secretField(name string) bool {
+	return slices.ContainsFunc(r.fields, func(f string) bool { return strings.EqualFold(f, name) })
+}
+
+// encodeBody redacts the secret fields of form and JSON bodies and encodes the body for the cassette.
+func (r *Recorder) encodeBody(h http.Header, body []byte) (string, string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			r.redactValues(form)
+			body = []byte(form.Encode())
+		}
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var v any
+		if err := json.Unmarshal(body, &v); err == nil && r.redactJSON(v) {
+			if redacted, err := json.Marshal(v); err == nil {
+				body = redacted
+			}
+		}
+	}
+	if !utf8.Valid(body) {
+		return base64.StdEncoding.EncodeToString(body), "base64"
+	}
+	return string(body), ""
+}
+
+// redactJSON replaces the secret fields of a decoded JSON value and reports whether it changed anything.
+func (r *Recorder) redactJSON(v any) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if r.secretField(key) {
+				v[key] = Redacted
+				changed = true
+				continue
+			}
+			changed = r.redactJSON(value) || changed
+		}
+	case []any:
+		for _, value := range v {
+			changed = r.redactJSON(value) || changed
+		}
+	}
+	return changed
+}
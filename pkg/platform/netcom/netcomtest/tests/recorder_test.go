@@ -0,0 +1,81 @@
+package netcomtest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom/netcomtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=s3cr3t")
+		switch r.URL.Path {
+		case "/token":
+			w.Write([]byte(`{"access_token":"t0ken","expires_in":3600}`))
+		default:
+			w.Write([]byte(`{"id":"A1","status":"` + r.URL.Query().Get("status") + `"}`))
+		}
+	}))
+	cassette := filepath.Join(t.TempDir(), "cassettes", "orders.json")
+	config := netcom.ClientConfig{
+		BaseURL:   srv.URL,
+		BasicAuth: &netcom.BasicAuth{Username: "svc", Password: "pw"},
+	}
+
+	rec, err := netcomtest.NewRecorder(cassette, netcomtest.RecorderConfig{Mode: netcomtest.ModeRecord})
+	require.NoError(t, err)
+	config.Middlewares = []netcom.Middleware{rec.Middleware()}
+	c, err := netcom.NewClient(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+	token, err := c.Post(ctx, "/token", strings.NewReader("grant_type=client_credentials&client_secret=xyz"),
+		netcom.WithSetHeader("Content-Type", "application/x-www-form-urlencoded"))
+	require.NoError(t, err)
+	body, err := netcom.ReadResponseBody(token)
+	require.NoError(t, err)
+	assert.Contains(t, body, "t0ken", "the live response is not redacted")
+	for _, status := range []string{"open", "closed"} {
+		resp, err := c.Get(ctx, "/orders/A1", netcom.WithQueryParams(map[string]string{"status": status, "api_key": "k1"}))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	require.NoError(t, rec.Save())
+	srv.Close()
+
+	data, err := os.ReadFile(cassette)
+	require.NoError(t, err)
+	for _, secret := range []string{"t0ken", "xyz", "k1", "s3cr3t", "Basic "} {
+		assert.NotContains(t, string(data), secret)
+	}
+
+	rec, err = netcomtest.NewRecorder(cassette, netcomtest.RecorderConfig{})
+	require.NoError(t, err)
+	config.Middlewares = []netcom.Middleware{rec.Middleware()}
+	c, err = netcom.NewClient(config)
+	require.NoError(t, err)
+	for _, status := range []string{"open", "closed"} {
+		resp, err := c.Get(ctx, "/orders/A1", netcom.WithQueryParams(map[string]string{"status": status, "api_key": "k2"}))
+		require.NoError(t, err)
+		var got order
+		require.NoError(t, netcom.DecodeResponse(resp, &got))
+		assert.Equal(t, order{ID: "A1", Status: status}, got)
+	}
+	// every interaction replays once
+	_, err = c.Get(ctx, "/orders/A1", netcom.WithQueryParams(map[string]string{"status": "open"}))
+	assert.ErrorIs(t, err, netcomtest.ErrNoInteraction)
+}
+
+func TestRecorderMissingCassette(t *testing.T) {
+	_, err := netcomtest.NewRecorder(filepath.Join(t.TempDir(), "missing.json"), netcomtest.RecorderConfig{})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
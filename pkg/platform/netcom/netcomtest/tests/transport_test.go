@@ -0,0 +1,87 @@
+package netcomtest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom/netcomtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type order struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+func TestTransportCannedJSON(t *testing.T) {
+	tr := netcomtest.NewTransport()
+	tr.On(http.MethodGet, "/orders/*").RespondJSON(http.StatusOK, order{ID: "A1", Status: "open"})
+	tr.On(http.MethodPost, "/orders").WithHeader("X-Plant", "gradec").Respond(http.StatusCreated, "")
+	c := netcomtest.NewClient(t, tr, netcom.ClientConfig{})
+
+	resp, err := c.Get(context.Background(), "/orders/A1", netcom.WithQueryParams(map[string]string{"full": "1"}))
+	require.NoError(t, err)
+	var got order
+	require.NoError(t, netcom.DecodeResponse(resp, &got))
+	assert.Equal(t, order{ID: "A1", Status: "open"}, got)
+
+	resp, err = c.Post(context.Background(), "/orders", strings.NewReader(`{"id":"A2"}`), netcom.WithHeader("X-Plant", "gradec"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tr.AssertExpectations(t)
+	calls := tr.CallsTo(http.MethodPost, "/orders")
+	require.Len(t, calls, 1)
+	assert.JSONEq(t, `{"id":"A2"}`, string(calls[0].Body))
+	assert.Equal(t, "1", tr.Calls()[0].Query.Get("full"))
+}
+
+func TestTransportSequence(t *testing.T) {
+	tr := netcomtest.NewTransport()
+	tr.On(http.MethodGet, "/status").Respond(http.StatusServiceUnavailable, "").Times(2)
+	tr.On(http.MethodGet, "/status").Respond(http.StatusOK, "up")
+	c := netcomtest.NewClient(t, tr, netcom.ClientConfig{
+		Retry: &netcom.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	})
+
+	resp, err := c.Get(context.Background(), "/status")
+	require.NoError(t, err)
+	body, err := netcom.ReadResponseBody(resp)
+	require.NoError(t, err)
+	assert.Equal(t, "up", body)
+	assert.Len(t, tr.Calls(), 3)
+	tr.AssertExpectations(t)
+}
+
+func TestTransportUnexpectedRequest(t *testing.T) {
+	tr := netcomtest.NewTransport()
+	tr.On(http.MethodGet, "/orders").RespondError(errors.New("connection reset"))
+	c := netcomtest.NewClient(t, tr, netcom.ClientConfig{})
+
+	_, err := c.Get(context.Background(), "/orders")
+	assert.ErrorContains(t, err, "connection reset")
+	_, err = c.Delete(context.Background(), "/orders")
+	assert.ErrorIs(t, err, netcomtest.ErrUnexpectedRequest)
+
+	// an unused expectation fails the test
+	rec := &recordingT{TB: t}
+	tr.On(http.MethodGet, "/never")
+	tr.AssertExpectations(rec)
+	assert.Equal(t, 1, rec.errors)
+}
+
+type recordingT struct {
+	testing.TB
+	errors int
+}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors++
+}
@@ -0,0 +1,232 @@
+// Package netcomtest provides a programmable mock transport for unit tests of code built on netcom.Client,
+// so no httptest server is needed, and a record-and-replay transport for integration tests against recorded responses.
+package netcomtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+)
+
+// BaseURL is the base URL of the clients created by NewClient.
+const BaseURL = "http://netcomtest.local"
+
+// ErrUnexpectedRequest is returned for a request that matches no expectation.
+var ErrUnexpectedRequest = errors.New("netcomtest: unexpected request")
+
+// Call is a request received by the transport.
+type Call struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Transport is an http.RoundTripper answering requests from expectations registered with On.
+// Expectations are matched in registration order; an expectation that has been used up by Times is skipped,
+// so several expectations for the same request answer in sequence.
+type Transport struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+}
+
+// NewTransport returns a transport without expectations.
+func NewTransport() *Transport {
+	return new(Transport)
+}
+
+// NewClient returns a netcom client sending its requests to the transport, with BaseURL as the base URL.
+// The HTTPClient and BaseURL of config are replaced.
+func NewClient(t testing.TB, tr *Transport, config netcom.ClientConfig) *netcom.Client {
+	t.Helper()
+	config.HTTPClient = &http.Client{Transport: tr}
+	config.BaseURL = BaseURL
+	c, err := netcom.NewClient(config)
+	if err != nil {
+		t.Fatalf("netcomtest: creating the client failed: %v", err)
+	}
+	return c
+}
+
+// On registers an expectation for the requests with the method and a path matching pattern,
+// which may contain path.Match wildcards, e.g. "/orders/*". Without a response the expectation answers 200 with no body.
+func (tr *Transport) On(method, pattern string) *Expectation {
+	e := &Expectation{method: method, pattern: pattern, status: http.StatusOK, header: make(http.Header), times: -1}
+	tr.mu.Lock()
+	tr.expectations = append(tr.expectations, e)
+	tr.mu.Unlock()
+	return e
+}
+
+// RoundTrip records the request and answers it from the first matching expectation.
+func (tr *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	call := Call{Method: req.Method, Path: req.URL.Path, Query: req.URL.Query(), Header: req.Header.Clone()}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		call.Body = body
+	}
+
+	tr.mu.Lock()
+	tr.calls = append(tr.calls, call)
+	var match *Expectation
+	for _, e := range tr.expectations {
+		if e.matches(call) && e.times != 0 {
+			match = e
+			break
+		}
+	}
+	if match != nil {
+		match.calls++
+		if match.times > 0 {
+			match.times--
+		}
+	}
+	tr.mu.Unlock()
+
+	if match == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrUnexpectedRequest, req.Method, req.URL.Path)
+	}
+	return match.respond(req)
+}
+
+// Calls returns the requests received so far, in order.
+func (tr *Transport) Calls() []Call {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return append([]Call(nil), tr.calls...)
+}
+
+// CallsTo returns the requests received with the method and a path matching pattern.
+func (tr *Transport) CallsTo(method, pattern string) []Call {
+	e := &Expectation{method: method, pattern: pattern}
+	var calls []Call
+	for _, c := range tr.Calls() {
+		if e.matches(c) {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// AssertExpectations fails the test for every expectation that was not used, or not used as often as Times requires.
+func (tr *Transport) AssertExpectations(t testing.TB) {
+	t.Helper()
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	for _, e := range tr.expectations {
+		switch {
+		case e.calls == 0:
+			t.Errorf("netcomtest: expected a call to %s %s", e.method, e.pattern)
+		case e.times > 0:
+			t.Errorf("netcomtest: expected %d more calls to %s %s", e.times, e.method, e.pattern)
+		}
+	}
+}
+
+// Expectation is a request the transport expects and the response it answers with.
+type Expectation struct {
+	method  string
+	pattern string
+	headers map[string]string
+	status  int
+	header  http.Header
+	body    []byte
+	err     error
+	// times is the number of calls left; -1 is unlimited
+	times int
+	calls int
+}
+
+// WithHeader restricts the expectation to requests carrying the header value.
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	if e.headers == nil {
+		e.headers = make(map[string]string)
+	}
+	e.headers[http.CanonicalHeaderKey(key)] = value
+	return e
+}
+
+// Times limits the expectation to n calls; the following matching requests go to the next expectation.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Once is Times(1).
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+// Respond answers with the status, body and headers given as key, value pairs.
+func (e *Expectation) Respond(status int, body string, headers ...string) *Expectation {
+	e.status = status
+	e.body = []byte(body)
+	for i := 0; i+1 < len(headers); i += 2 {
+		e.header.Add(headers[i], headers[i+1])
+	}
+	return e
+}
+
+// RespondJSON answers with the status and v encoded as JSON; it panics if v can not be encoded.
+func (e *Expectation) RespondJSON(status int, v any) *Expectation {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("netcomtest: encoding the response failed: %v", err))
+	}
+	e.header.Set("Content-Type", "application/json")
+	return e.Respond(status, string(body))
+}
+
+// RespondError fails the request with err, as a network failure would.
+func (e *Expectation) RespondError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) matches(c Call) bool {
+	if !strings.EqualFold(e.method, c.Method) {
+		return false
+	}
+	if ok, _ := path.Match(e.pattern, c.Path); !ok {
+		return false
+	}
+	for k, v := range e.headers {
+		if c.Header.Get(k) != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Expectation) respond(req *http.Request) (*http.Response, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.status, http.StatusText(e.status)),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,262 @@
+package netcom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+)
+
+var (
+	// ErrOutboxClosed is returned by Enqueue once the outbox was closed.
+	ErrOutboxClosed = errors.New("outbox closed")
+	// ErrOutboxExpired is passed to OutboxConfig.OnDropped for messages older than the MaxAge.
+	ErrOutboxExpired = errors.New("outbox message expired before it was delivered")
+)
+
+// Default backoff of the zero OutboxConfig.Backoff fields; the other fields use the RetryPolicy defaults.
+const (
+	DefaultOutboxBackoff    = time.Second
+	DefaultOutboxMaxBackoff = 5 * time.Minute
+)
+
+const outboxBucket = "outbox"
+
+// OutboxConfig configures the delivery of the messages of an Outbox.
+type OutboxConfig struct {
+	// Backoff spaces the attempts of a message with its InitialBackoff, MaxBackoff, Multiplier and Jitter;
+	// its ShouldRetry decides which failures are tried again, the others drop the message. MaxAttempts is ignored:
+	// a message is attempted until it is delivered or expires.
+	Backoff RetryPolicy
+	// MaxAge drops messages that were not delivered within it of being enqueued; zero keeps them until delivered.
+	MaxAge time.Duration
+	// OnDelivered is called with every delivered message.
+	OnDelivered func(msg OutboxMessage)
+	// OnDropped is called with every message given up on and the reason: ErrOutboxExpired or the last failure.
+	OnDropped func(msg OutboxMessage, err error)
+}
+
+// OutboxMessage is a POST request queued in an Outbox.
+type OutboxMessage struct {
+	ID     uint64
+	Path   string
+	Header http.Header
+	Body   []byte
+	// Key is sent as the Idempotency-Key header of every attempt, so the receiver can ignore redeliveries.
+	Key         string
+	Enqueued    time.Time
+	Attempts    int
+	NextAttempt time.Time
+	LastError   string
+}
+
+// Outbox is a durable queue of POST requests that must eventually be delivered, such as order confirmations.
+// Messages are kept in a bbolt database file, survive restarts and are delivered one at a time in the order
+// they were enqueued: a failing message holds back the ones behind it until it is delivered, dropped or expired.
+type Outbox struct {
+	client *Client
+	store  *datamanagement.BoltStore[uint64, OutboxMessage]
+	config OutboxConfig
+	wake   chan struct{}
+
+	mu     sync.Mutex
+	next   uint64
+	closed bool
+}
+
+// NewOutbox opens (or creates) the outbox database file at path; the messages are sent with c, relative to its base URL.
+// Call Run to deliver them.
+func NewOutbox(c *Client, path string, config OutboxConfig) (*Outbox, error) {
+	if config.Backoff.InitialBackoff <= 0 {
+		config.Backoff.InitialBackoff = DefaultOutboxBackoff
+	}
+	if config.Backoff.MaxBackoff <= 0 {
+		config.Backoff.MaxBackoff = DefaultOutboxMaxBackoff
+	}
+	config.Backoff = config.Backoff.normalized()
+	store, err := datamanagement.NewBoltStore[uint64, OutboxMessage](path, outboxBucket)
+	if err != nil {
+		return nil, err
+	}
+	o := &Outbox{client: c, store: store, config: config, wake: make(chan struct{}, 1), next: 1}
+	err = store.ScanPrefix("", func(id uint64, _ OutboxMessage) error {
+		o.next = id + 1
+		return nil
+	})
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return o, nil
+}
+
+// Enqueue stores a POST request of body to path for delivery and returns its ID; header is sent with every attempt.
+func (o *Outbox) Enqueue(path string, body []byte, header http.Header) (uint64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return 0, ErrOutboxClosed
+	}
+	msg := OutboxMessage{
+		ID:       o.next,
+		Path:     path,
+		Header:   header.Clone(),
+		Body:     bytes.Clone(body),
+		Key:      NewRequestID(),
+		Enqueued: time.Now(),
+	}
+	if err := o.store.Add(msg.ID, msg); err != nil {
+		return 0, err
+	}
+	o.next++
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return msg.ID, nil
+}
+
+// EnqueueJSON enqueues data marshalled as JSON with the "Content-Type" header set to "application/json".
+func (o *Outbox) EnqueueJSON(path string, data any, header http.Header) (uint64, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrJSONMarshalFailed, err)
+	}
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+	return o.Enqueue(path, body, header)
+}
+
+// Pending returns the messages waiting for delivery in delivery order.
+func (o *Outbox) Pending() ([]OutboxMessage, error) {
+	var pending []OutboxMessage
+	err := o.store.ScanPrefix("", func(_ uint64, msg OutboxMessage) error {
+		pending = append(pending, msg)
+		return nil
+	})
+	return pending, err
+}
+
+// Close releases the database file; the remaining messages are delivered by the next Outbox opened on it.
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return ErrOutboxClosed
+	}
+	o.closed = true
+	return o.store.Close()
+}
+
+// Run delivers the messages until ctx is done, waiting for new ones when the outbox is empty, and returns ctx.Err();
+// it also returns the errors of the database. A message whose attempt is cut short by ctx stays queued.
+// Run must not be called concurrently.
+func (o *Outbox) Run(ctx context.Context) error {
+	for {
+		msg, ok, err := o.head()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-o.wake:
+			}
+			continue
+		}
+		if o.config.MaxAge > 0 && time.Since(msg.Enqueued) > o.config.MaxAge {
+			if err := o.drop(msg, ErrOutboxExpired); err != nil {
+				return err
+			}
+			continue
+		}
+		if wait := time.Until(msg.NextAttempt); wait > 0 {
+			if o.config.MaxAge > 0 {
+				wait = min(wait, time.Until(msg.Enqueued.Add(o.config.MaxAge))+time.Millisecond)
+			}
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := o.deliver(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// errStopScan ends a scan after the first message.
+var errStopScan = errors.New("stop scan")
+
+// head returns the oldest queued message.
+func (o *Outbox) head() (OutboxMessage, bool, error) {
+	var head OutboxMessage
+	var found bool
+	err := o.store.ScanPrefix("", func(_ uint64, msg OutboxMessage) error {
+		head, found = msg, true
+		return errStopScan
+	})
+	if errors.Is(err, errStopScan) {
+		err = nil
+	}
+	return head, found, err
+}
+
+// deliver sends a message once and removes it, or records the failed attempt for the next one.
+func (o *Outbox) deliver(ctx context.Context, msg OutboxMessage) error {
+	options := []RequestOption{WithRetryPolicy(RetryPolicy{MaxAttempts: 1})}
+	for key, values := range msg.Header {
+		for _, v := range values {
+			options = append(options, WithHeader(key, v))
+		}
+	}
+	options = append(options, WithIdempotencyKey(msg.Key))
+
+	resp, err := o.client.Post(ctx, msg.Path, bytes.NewReader(msg.Body), options...)
+	if ctx.Err() != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return ctx.Err()
+	}
+	retry := o.config.Backoff.ShouldRetry(resp, err)
+	if err == nil {
+		// drains and closes the body; non-2xx statuses become ErrBadStatusCode
+		if err = DecodeResponse(resp, nil); err == nil {
+			if err := o.store.Delete(msg.ID); err != nil {
+				return err
+			}
+			if o.config.OnDelivered != nil {
+				o.config.OnDelivered(msg)
+			}
+			return nil
+		}
+	}
+	if !retry {
+		return o.drop(msg, err)
+	}
+	msg.Attempts++
+	msg.LastError = err.Error()
+	msg.NextAttempt = time.Now().Add(o.config.Backoff.backoff(msg.Attempts, resp))
+	return o.store.Update(msg.ID, msg)
+}
+
+func (o *Outbox) drop(msg OutboxMessage, reason error) error {
+	if err := o.store.Delete(msg.ID); err != nil {
+		return err
+	}
+	if o.config.OnDropped != nil {
+		o.config.OnDropped(msg, reason)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package netcom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrPaginationLoop indicates a next page reference that was already followed.
+var ErrPaginationLoop = errors.New("pagination returned a page that was already fetched")
+
+// PageFunc extracts the items of a page and the reference of the next page from a successful response:
+// a path or URL, or a token when WithPageToken is used. An empty next ends the pagination.
+// The body is closed after PageFunc returns.
+type PageFunc[T any] func(resp *http.Response) (items []T, next string, err error)
+
+// PaginateOption configures Paginate.
+type PaginateOption func(*paginateConfig)
+
+type paginateConfig struct {
+	request    []RequestOption
+	tokenParam string
+	maxPages   int
+}
+
+// WithPageRequest applies request options, e.g. headers or filter query parameters, to every page request.
+func WithPageRequest(options ...RequestOption) PaginateOption {
+	return func(pc *paginateConfig) {
+		pc.request = append(pc.request, options...)
+	}
+}
+
+// WithPageToken treats the next reference returned by the PageFunc as a token sent as the query parameter
+// param of the first path, e.g. "?cursor=abc", instead of a path or URL.
+func WithPageToken(param string) PaginateOption {
+	return func(pc *paginateConfig) {
+		pc.tokenParam = param
+	}
+}
+
+// WithMaxPages stops the pagination after n pages; zero means no limit.
+func WithMaxPages(n int) PaginateOption {
+	return func(pc *paginateConfig) {
+		pc.maxPages = n
+	}
+}
+
+// Paginate sends GET requests starting at path and yields the items of every page, following the next page
+// references extracted by page until one is empty. A nil page decodes every page as a JSON array of T and follows
+// the rel="next" Link header (see LinkPages). Non-2xx responses yield ErrBadStatusCode and end the iteration,
+// as does any other error; stopping the loop early sends no further requests.
+func Paginate[T any](ctx context.Context, c *Client, path string, page PageFunc[T], opts ...PaginateOption) iter.Seq2[[]T, error] {
+	pc := new(paginateConfig)
+	for _, opt := range opts {
+		opt(pc)
+	}
+	if page == nil {
+		page = LinkPages[T]()
+	}
+	return func(yield func([]T, error) bool) {
+		// the pages fetched so far by resolved URL
+		seen := map[string]bool{}
+		current := path
+		for n := 1; pc.maxPages == 0 || n <= pc.maxPages; n++ {
+			if u, err := c.resolveURL(current); err == nil {
+				seen[u.String()] = true
+			}
+			items, next, err := fetchPage(ctx, c, current, page, pc.request)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(items, nil) || next == "" {
+				return
+			}
+			if pc.tokenParam != "" {
+				next, err = withQueryParam(path, pc.tokenParam, next)
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+			}
+			if u, err := c.resolveURL(next); err == nil && seen[u.String()] {
+				yield(nil, fmt.Errorf("%w: %s", ErrPaginationLoop, next))
+				return
+			}
+			current = next
+		}
+	}
+}
+
+func fetchPage[T any](ctx context.Context, c *Client, path string, page PageFunc[T], options []RequestOption) ([]T, string, error) {
+	resp, err := c.Get(ctx, path, options...)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", DecodeResponse(resp, nil)
+	}
+	defer resp.Body.Close()
+	return page(resp)
+}
+
+// LinkPages decodes a page as a JSON array of T, or with the decoder of its Content-Type, and follows the
+// rel="next" Link header (RFC 8288).
+func LinkPages[T any]() PageFunc[T] {
+	return func(resp *http.Response) ([]T, string, error) {
+		var items []T
+		if err := DecodeResponse(resp, &items); err != nil {
+			return nil, "", err
+		}
+		return items, NextLink(resp), nil
+	}
+}
+
+// NextLink returns the rel="next" target of the Link header of the response, resolved against the request URL;
+// empty when there is none.
+func NextLink(resp *http.Response) string {
+	for _, header := range resp.Header.Values("Link") {
+		for _, link := range strings.Split(header, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			target = strings.TrimSpace(target)
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(key, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return resolveLink(resp, target[1:len(target)-1])
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func resolveLink(resp *http.Response, target string) string {
+	u, err := url.Parse(target)
+	if err != nil || resp.Request == nil || resp.Request.URL == nil {
+		return target
+	}
+	return resp.Request.URL.ResolveReference(u).String()
+}
+
+// withQueryParam sets the query parameter on a path or URL.
+func withQueryParam(path, param, value string) (string, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: parsing path '%s' failed: %v", ErrURLResolutionFailed, path, err)
+	}
+	q := u.Query()
+	q.Set(param, value)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,45 @@
+package netcom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// ErrBadPathTemplate indicates a path template with unbalanced braces or a malformed placeholder, or parameters
+// that do not match its placeholders.
+var ErrBadPathTemplate = errors.New("invalid path template")
+
+// ExpandPath fills the {name} placeholders of a path template such as "/orders/{id}/items/{item}" with the params,
+// escaped as path segments, so values containing "/", "?" or "#" stay within their segment.
+// Every placeholder needs a parameter and every parameter a placeholder; a leftover one usually is a typo.
+func ExpandPath(template string, params map[string]string) (string, error) {
+	placeholders, err := pathPlaceholders(template)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range placeholders {
+		if _, ok := params[name]; !ok {
+			return "", fmt.Errorf("%w: no value for '{%s}' in path '%s'", ErrBadPathTemplate, name, template)
+		}
+	}
+	for name := range params {
+		if !slices.Contains(placeholders, name) {
+			return "", fmt.Errorf("%w: parameter '%s' has no placeholder in path '%s'", ErrBadPathTemplate, name, template)
+		}
+	}
+	return expandPath(template, params), nil
+}
+
+// RequestTemplate is Request with the path expanded from a template with ExpandPath, e.g.
+// c.RequestTemplate(ctx, http.MethodGet, "/orders/{id}/items/{item}", map[string]string{"id": wo, "item": pos}, nil).
+func (c *Client) RequestTemplate(ctx context.Context, method, template string, params map[string]string, body io.Reader, options ...RequestOption) (*http.Response, error) {
+	path, err := ExpandPath(template, params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRequestCreationFailed, err)
+	}
+	return c.Request(ctx, method, path, body, options...)
+}
@@ -0,0 +1,91 @@
+package netcom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+)
+
+// ErrProbeBodyMismatch indicates a probe response whose body does not contain the expected text.
+var ErrProbeBodyMismatch = errors.New("probe response body does not contain the expected text")
+
+// maxProbeBody bounds how much of a probe response is searched for the expected text.
+const maxProbeBody = 64 << 10
+
+// ProbeResult is the outcome of a single health probe of an upstream API.
+type ProbeResult struct {
+	URL     string
+	Status  int // zero when no response was received
+	Latency time.Duration
+	// TLSExpiry is the expiry of the server's leaf certificate; zero for plain HTTP.
+	TLSExpiry time.Time
+	// TLSExpiryDays is the number of whole days until TLSExpiry, negative once expired; zero for plain HTTP.
+	TLSExpiryDays int
+	// Err is nil for a healthy upstream.
+	Err error
+}
+
+// Healthy reports whether the probe succeeded.
+func (r ProbeResult) Healthy() bool {
+	return r.Err == nil
+}
+
+func (r ProbeResult) String() string {
+	state := "healthy"
+	if r.Err != nil {
+		state = fmt.Sprintf("unhealthy: %v", r.Err)
+	}
+	if r.TLSExpiry.IsZero() {
+		return fmt.Sprintf("%s status=%d latency=%s %s", r.URL, r.Status, r.Latency, state)
+	}
+	return fmt.Sprintf("%s status=%d latency=%s tls_expiry_days=%d %s", r.URL, r.Status, r.Latency, r.TLSExpiryDays, state)
+}
+
+// Probe sends a single GET to path, without retries, and checks the response.
+// The probe succeeds when the status equals expectStatus (any 2xx when zero) and, if expectBodyContains is not empty,
+// the first 64 KiB of the body contain it. Latency covers the time until the response headers arrived.
+func (c *Client) Probe(ctx context.Context, path string, expectStatus int, expectBodyContains string, options ...RequestOption) ProbeResult {
+	options = append(options, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil, options...)
+	if err != nil {
+		return ProbeResult{URL: path, Err: err}
+	}
+	res := ProbeResult{URL: req.URL.String()}
+	start := time.Now()
+	resp, err := c.Do(req)
+	res.Latency = time.Since(start)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer resp.Body.Close()
+	res.Status = resp.StatusCode
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		res.TLSExpiry = resp.TLS.PeerCertificates[0].NotAfter
+		res.TLSExpiryDays = int(math.Floor(time.Until(res.TLSExpiry).Hours() / 24))
+	}
+
+	statusOK := resp.StatusCode == expectStatus || (expectStatus == 0 && resp.StatusCode >= 200 && resp.StatusCode < 300)
+	if !statusOK {
+		res.Err = fmt.Errorf("%w: status %d, expected %d", ErrBadStatusCode, resp.StatusCode, expectStatus)
+		return res
+	}
+	if len(expectBodyContains) == 0 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxProbeBody))
+		return res
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+	if err != nil {
+		res.Err = fmt.Errorf("%w: %w", ErrReadResponseFailed, err)
+		return res
+	}
+	if !bytes.Contains(body, []byte(expectBodyContains)) {
+		res.Err = fmt.Errorf("%w: %q", ErrProbeBodyMismatch, expectBodyContains)
+	}
+	return res
+}
@@ -0,0 +1,60 @@
+package netcom
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ProxyConfig selects the proxy of the default transport, for networks that reach other sites only through one.
+// Without a ProxyConfig the client uses the proxy of the environment, as http.DefaultTransport does;
+// an empty one connects directly whatever the environment says.
+type ProxyConfig struct {
+	// URL is the proxy of every request, e.g. "http://proxy.plant.local:3128"; http, https and socks5 proxies are
+	// supported and user info in the URL is sent as proxy credentials.
+	URL string
+	// NoProxy lists the hosts reached directly, in the format of the NO_PROXY variable: host names,
+	// domains matching their subdomains (".plant.local" or "plant.local"), IP addresses and CIDR ranges,
+	// each with an optional port, or "*" for all. Loopback addresses are always reached directly.
+	NoProxy []string
+	// FromEnvironment reads the proxy from HTTP_PROXY, HTTPS_PROXY and NO_PROXY (or their lowercase forms) once,
+	// when the client is created; it can not be combined with URL and NoProxy.
+	FromEnvironment bool
+}
+
+// proxyFunc returns the http.Transport.Proxy function of the configuration.
+func (p ProxyConfig) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	if p.FromEnvironment {
+		if p.URL != "" || len(p.NoProxy) > 0 {
+			return nil, errors.New("FromEnvironment can not be combined with URL and NoProxy")
+		}
+		return proxyFromConfig(httpproxy.FromEnvironment()), nil
+	}
+	if p.URL != "" {
+		u, err := url.Parse(p.URL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy URL '%s' failed: %w", p.URL, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("proxy URL '%s' must use the http, https or socks5 scheme", p.URL)
+		}
+	}
+	return proxyFromConfig(&httpproxy.Config{
+		HTTPProxy:  p.URL,
+		HTTPSProxy: p.URL,
+		NoProxy:    strings.Join(p.NoProxy, ","),
+	}), nil
+}
+
+func proxyFromConfig(config *httpproxy.Config) func(*http.Request) (*url.URL, error) {
+	proxy := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
@@ -0,0 +1,102 @@
+package netcom
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithRateLimit returns a middleware throttling all requests of a client to rps per second on average,
+// allowing bursts of up to burst requests; requests over the limit wait for their turn or until their context is done.
+// Register it with Client.Use or ClientConfig.Middlewares. A rps of zero or less disables the limit.
+// The wait counts towards the http.Client Timeout, and every retry attempt takes a token of its own.
+func WithRateLimit(rps float64, burst int) Middleware {
+	if rps <= 0 {
+		return func(next http.RoundTripper) http.RoundTripper { return next }
+	}
+	b := newTokenBucket(rps, burst)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := b.wait(req); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// WithHostRateLimit is WithRateLimit with a separate budget for every host (as in the request URL, e.g. "mes.local:8443"),
+// for clients talking to several APIs with quotas of their own.
+func WithHostRateLimit(rps float64, burst int) Middleware {
+	if rps <= 0 {
+		return func(next http.RoundTripper) http.RoundTripper { return next }
+	}
+	var mu sync.Mutex
+	buckets := make(map[string]*tokenBucket)
+	bucket := func(host string) *tokenBucket {
+		mu.Lock()
+		defer mu.Unlock()
+		b, ok := buckets[host]
+		if !ok {
+			b = newTokenBucket(rps, burst)
+			buckets[host] = b
+		}
+		return b
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := bucket(req.URL.Host).wait(req); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// tokenBucket refills rate tokens per second up to burst; every request takes one.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rps float64, burst int) *tokenBucket {
+	burst = max(burst, 1)
+	return &tokenBucket{rate: rps, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// reserve takes a token and returns how long to wait until it is due.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// release gives back a token that was reserved but not used.
+func (b *tokenBucket) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+1)
+}
+
+// wait blocks until req may be sent or its context is done.
+func (b *tokenBucket) wait(req *http.Request) error {
+	d := b.reserve()
+	if d == 0 {
+		return nil
+	}
+	if err := sleep(req.Context(), d); err != nil {
+		b.release()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Package raw provides framed TCP and UDP helpers for devices that speak raw socket protocols,
+// such as weighing scales and label printers.
+package raw
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxFrameBytes is the frame size limit applied when a configuration does not set one.
+const DefaultMaxFrameBytes = 64 << 10
+
+// ErrFrameTooLarge indicates a frame exceeding the configured size limit.
+var ErrFrameTooLarge = errors.New("frame too large")
+
+// ErrBadFraming indicates an invalid framing definition.
+var ErrBadFraming = errors.New("invalid framing")
+
+// Framer splits a byte stream into frames.
+type Framer interface {
+	// WriteFrame writes p as a single frame.
+	WriteFrame(w io.Writer, p []byte) error
+	// ReadFrame reads the next frame, failing with ErrFrameTooLarge past max bytes.
+	ReadFrame(r *bufio.Reader, max int) ([]byte, error)
+}
+
+type lengthPrefix struct {
+	size  int
+	order binary.ByteOrder
+}
+
+// LengthPrefix frames every payload with its length encoded on size bytes (1, 2 or 4) in the given byte order.
+// The prefix does not count itself.
+func LengthPrefix(size int, order binary.ByteOrder) (Framer, error) {
+	switch size {
+	case 1, 2, 4:
+	default:
+		return nil, fmt.Errorf("%w: a length prefix of %d bytes", ErrBadFraming, size)
+	}
+	if order == nil {
+		order = binary.BigEndian
+	}
+	return lengthPrefix{size: size, order: order}, nil
+}
+
+func (f lengthPrefix) WriteFrame(w io.Writer, p []byte) error {
+	if uint64(len(p)) >= 1<<(8*f.size) {
+		return fmt.Errorf("%w: %d bytes do not fit a %d byte prefix", ErrFrameTooLarge, len(p), f.size)
+	}
+	buf := make([]byte, f.size, f.size+len(p))
+	switch f.size {
+	case 1:
+		buf[0] = byte(len(p))
+	case 2:
+		f.order.PutUint16(buf, uint16(len(p)))
+	case 4:
+		f.order.PutUint32(buf, uint32(len(p)))
+	}
+	_, err := w.Write(append(buf, p...))
+	return err
+}
+
+func (f lengthPrefix) ReadFrame(r *bufio.Reader, max int) ([]byte, error) {
+	prefix := make([]byte, f.size)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, err
+	}
+	var n uint64
+	switch f.size {
+	case 1:
+		n = uint64(prefix[0])
+	case 2:
+		n = uint64(f.order.Uint16(prefix))
+	case 4:
+		n = uint64(f.order.Uint32(prefix))
+	}
+	if n > uint64(max) {
+		return nil, fmt.Errorf("%w: %d bytes announced", ErrFrameTooLarge, n)
+	}
+	p := make([]byte, n)
+	if _, err := io.ReadFull(r, p); err != nil {
+		return nil, noEOF(err)
+	}
+	return p, nil
+}
+
+type delimiter []byte
+
+// Delimiter frames payloads by terminating them with delim, e.g. "\r\n" or an ETX byte.
+// The delimiter is not part of the frames returned by ReadFrame.
+func Delimiter(delim []byte) (Framer, error) {
+	if len(delim) == 0 {
+		return nil, fmt.Errorf("%w: an empty delimiter", ErrBadFraming)
+	}
+	return delimiter(bytes.Clone(delim)), nil
+}
+
+func (f delimiter) WriteFrame(w io.Writer, p []byte) error {
+	_, err := w.Write(append(bytes.Clone(p), f...))
+	return err
+}
+
+func (f delimiter) ReadFrame(r *bufio.Reader, max int) ([]byte, error) {
+	last := f[len(f)-1]
+	var frame []byte
+	for {
+		chunk, err := r.ReadSlice(last)
+		frame = append(frame, chunk...)
+		if len(frame) > max+len(f) {
+			return nil, fmt.Errorf("%w: no delimiter within %d bytes", ErrFrameTooLarge, max)
+		}
+		switch {
+		case err == nil:
+			if bytes.HasSuffix(frame, f) {
+				return frame[:len(frame)-len(f)], nil
+			}
+		case errors.Is(err, bufio.ErrBufferFull):
+		case len(frame) != 0:
+			return nil, noEOF(err)
+		default:
+			return nil, err
+		}
+	}
+}
+
+// noEOF reports a stream ending inside a frame as io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,209 @@
+package raw
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDialTimeout         = 5 * time.Second
+	defaultReconnectBackoff    = 500 * time.Millisecond
+	defaultMaxReconnectBackoff = 30 * time.Second
+)
+
+// ErrMissingFramer indicates a TCP configuration without a Framer.
+var ErrMissingFramer = errors.New("missing framer")
+
+// ErrConnectFailed indicates that no connection to the device could be established.
+var ErrConnectFailed = errors.New("failed to connect")
+
+// ErrClosed indicates use of a closed client or connection.
+var ErrClosed = errors.New("connection closed")
+
+// TCPConfig holds configuration for a framed TCP client.
+type TCPConfig struct {
+	Addr   string // Device address as host:port.
+	Framer Framer // Required framing of the byte stream, see LengthPrefix and Delimiter.
+	// DialTimeout bounds a single connection attempt. Defaults to 5s.
+	DialTimeout time.Duration
+	// MaxFrameBytes caps the frames read from the device. Defaults to DefaultMaxFrameBytes.
+	MaxFrameBytes int
+	// ReconnectBackoff is the wait after the first failed connection attempt; it doubles on every further
+	// failure up to MaxReconnectBackoff. Defaults to 500ms and 30s.
+	ReconnectBackoff    time.Duration
+	MaxReconnectBackoff time.Duration
+	// MaxConnectAttempts bounds the connection attempts of a single call.
+	// Zero keeps trying until the call's context is done.
+	MaxConnectAttempts int
+}
+
+// TCPClient exchanges frames with a device over a single TCP connection.
+// The connection is opened on first use and reopened, with backoff, on the call after any I/O error,
+// since the position in the stream is unknown at that point. Calls are serialised.
+// Frames are never resent after a reconnect: device commands such as label prints are rarely idempotent.
+type TCPClient struct {
+	config TCPConfig
+
+	mu     sync.Mutex
+	conn   net.Conn
+	r      *bufio.Reader
+	closed bool
+}
+
+// NewTCPClient creates a TCP client with the given configuration; it does not connect.
+func NewTCPClient(config TCPConfig) (*TCPClient, error) {
+	if config.Framer == nil {
+		return nil, ErrMissingFramer
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = defaultDialTimeout
+	}
+	if config.MaxFrameBytes <= 0 {
+		config.MaxFrameBytes = DefaultMaxFrameBytes
+	}
+	if config.ReconnectBackoff <= 0 {
+		config.ReconnectBackoff = defaultReconnectBackoff
+	}
+	if config.MaxReconnectBackoff < config.ReconnectBackoff {
+		config.MaxReconnectBackoff = max(defaultMaxReconnectBackoff, config.ReconnectBackoff)
+	}
+	return &TCPClient{config: config}, nil
+}
+
+// Connect opens the connection unless it is already open.
+func (c *TCPClient) Connect(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	if c.conn != nil {
+		return nil
+	}
+	return c.dial(ctx)
+}
+
+// Send writes p as a single frame.
+func (c *TCPClient) Send(ctx context.Context, p []byte) error {
+	return c.exchange(ctx, func() error {
+		return c.config.Framer.WriteFrame(c.conn, p)
+	})
+}
+
+// Receive reads the next frame sent by the device.
+func (c *TCPClient) Receive(ctx context.Context) ([]byte, error) {
+	var frame []byte
+	err := c.exchange(ctx, func() (err error) {
+		frame, err = c.config.Framer.ReadFrame(c.r, c.config.MaxFrameBytes)
+		return err
+	})
+	return frame, err
+}
+
+// Request writes p as a single frame and reads the device's reply.
+func (c *TCPClient) Request(ctx context.Context, p []byte) ([]byte, error) {
+	var frame []byte
+	err := c.exchange(ctx, func() (err error) {
+		if err = c.config.Framer.WriteFrame(c.conn, p); err != nil {
+			return err
+		}
+		frame, err = c.config.Framer.ReadFrame(c.r, c.config.MaxFrameBytes)
+		return err
+	})
+	return frame, err
+}
+
+// Close closes the connection; the client cannot be used afterwards.
+// It waits for the call in flight, which is interrupted by cancelling its context.
+func (c *TCPClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.r = nil, nil
+	return err
+}
+
+func (c *TCPClient) exchange(ctx context.Context, fn func() error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	if c.conn == nil {
+		if err := c.dial(ctx); err != nil {
+			return err
+		}
+	}
+	stop := watchContext(ctx, c.conn.SetDeadline)
+	err := fn()
+	stop()
+	if err == nil {
+		return nil
+	}
+	c.conn.Close()
+	c.conn, c.r = nil, nil
+	return fmt.Errorf("%w; addr:%v", contextErr(ctx, err), c.config.Addr)
+}
+
+func (c *TCPClient) dial(ctx context.Context) error {
+	backoff := c.config.ReconnectBackoff
+	d := net.Dialer{Timeout: c.config.DialTimeout}
+	for attempt := 1; ; attempt++ {
+		conn, err := d.DialContext(ctx, "tcp", c.config.Addr)
+		if err == nil {
+			c.conn, c.r = conn, bufio.NewReader(conn)
+			return nil
+		}
+		if c.config.MaxConnectAttempts > 0 && attempt >= c.config.MaxConnectAttempts {
+			return fmt.Errorf("%w: %w; addr:%v", ErrConnectFailed, err, c.config.Addr)
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return fmt.Errorf("%w: %w; addr:%v; last error:%v", ErrConnectFailed, ctx.Err(), c.config.Addr, err)
+		case <-t.C:
+		}
+		backoff = min(2*backoff, c.config.MaxReconnectBackoff)
+	}
+}
+
+// watchContext applies the context deadline through set and interrupts blocked I/O once the context is done.
+// The returned function must be called when the I/O is over.
+func watchContext(ctx context.Context, set func(time.Time) error) (stop func()) {
+	deadline, _ := ctx.Deadline()
+	set(deadline)
+	fired := make(chan struct{})
+	stopAfter := context.AfterFunc(ctx, func() {
+		set(time.Unix(1, 0))
+		close(fired)
+	})
+	return func() {
+		// wait for an interrupt in flight so it cannot hit the next call
+		if !stopAfter() {
+			<-fired
+		}
+	}
+}
+
+// contextErr reports I/O interrupted by watchContext as the context error.
+func contextErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	// the socket deadline may expire just before the context notices
+	var ne net.Error
+	if deadline, ok := ctx.Deadline(); ok && errors.As(err, &ne) && ne.Timeout() && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
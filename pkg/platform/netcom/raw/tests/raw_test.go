@@ -0,0 +1,161 @@
+package raw_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom/raw"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveLines answers every "\r\n" terminated line with its upper-case form and drops a connection after
+// the line "BYE", counting the connections it accepted.
+func serveLines(t *testing.T) (addr string, conns chan struct{}) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	conns = make(chan struct{}, 16)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conns <- struct{}{}
+			go func() {
+				defer conn.Close()
+				sc := bufio.NewScanner(conn)
+				for sc.Scan() {
+					line := strings.TrimSuffix(sc.Text(), "\r")
+					if line == "BYE" {
+						return
+					}
+					conn.Write([]byte(strings.ToUpper(line) + "\r\n"))
+				}
+			}()
+		}
+	}()
+	return l.Addr().String(), conns
+}
+
+func TestFramers(t *testing.T) {
+	prefix, err := raw.LengthPrefix(2, binary.BigEndian)
+	require.NoError(t, err)
+	delim, err := raw.Delimiter([]byte{0x03})
+	require.NoError(t, err)
+	for name, f := range map[string]raw.Framer{"prefix": prefix, "delimiter": delim} {
+		var buf bytes.Buffer
+		require.NoError(t, f.WriteFrame(&buf, []byte("W 12.50 kg")), name)
+		require.NoError(t, f.WriteFrame(&buf, []byte("W 12.55 kg")), name)
+		r := bufio.NewReader(&buf)
+		for _, want := range []string{"W 12.50 kg", "W 12.55 kg"} {
+			frame, err := f.ReadFrame(r, 64)
+			require.NoError(t, err, name)
+			assert.Equal(t, want, string(frame), name)
+		}
+		require.NoError(t, f.WriteFrame(&buf, bytes.Repeat([]byte("x"), 100)), name)
+		_, err = f.ReadFrame(bufio.NewReader(&buf), 64)
+		assert.ErrorIs(t, err, raw.ErrFrameTooLarge, name)
+	}
+
+	_, err = raw.LengthPrefix(3, nil)
+	assert.ErrorIs(t, err, raw.ErrBadFraming)
+	_, err = raw.Delimiter(nil)
+	assert.ErrorIs(t, err, raw.ErrBadFraming)
+}
+
+func TestTCPClientReconnects(t *testing.T) {
+	addr, conns := serveLines(t)
+	framer, err := raw.Delimiter([]byte("\r\n"))
+	require.NoError(t, err)
+	client, err := raw.NewTCPClient(raw.TCPConfig{Addr: addr, Framer: framer, ReconnectBackoff: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := client.Request(ctx, []byte("print wo 42"))
+	require.NoError(t, err)
+	assert.Equal(t, "PRINT WO 42", string(reply))
+
+	// the device hangs up: the failed read drops the connection and the next call reconnects
+	_, err = client.Request(ctx, []byte("BYE"))
+	require.Error(t, err)
+	reply, err = client.Request(ctx, []byte("status"))
+	require.NoError(t, err)
+	assert.Equal(t, "STATUS", string(reply))
+	assert.Len(t, conns, 2)
+}
+
+func TestTCPClientHonoursContext(t *testing.T) {
+	addr, _ := serveLines(t)
+	framer, err := raw.Delimiter([]byte("\r\n"))
+	require.NoError(t, err)
+	client, err := raw.NewTCPClient(raw.TCPConfig{Addr: addr, Framer: framer})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	// nothing was asked, so nothing arrives
+	_, err = client.Receive(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, client.Close())
+	_, err = client.Request(context.Background(), []byte("status"))
+	assert.ErrorIs(t, err, raw.ErrClosed)
+}
+
+func TestTCPClientGivesUpConnecting(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+	framer, err := raw.LengthPrefix(4, binary.LittleEndian)
+	require.NoError(t, err)
+	client, err := raw.NewTCPClient(raw.TCPConfig{Addr: addr, Framer: framer, ReconnectBackoff: time.Millisecond, MaxConnectAttempts: 3})
+	require.NoError(t, err)
+	err = client.Send(context.Background(), []byte("tare"))
+	assert.ErrorIs(t, err, raw.ErrConnectFailed)
+
+	_, err = raw.NewTCPClient(raw.TCPConfig{Addr: addr})
+	assert.ErrorIs(t, err, raw.ErrMissingFramer)
+}
+
+func TestUDP(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	scale, err := raw.ListenUDP("127.0.0.1:0")
+	require.NoError(t, err)
+	defer scale.Close()
+	go func() {
+		p, from, err := scale.Receive(ctx)
+		if err != nil {
+			return
+		}
+		scale.SendTo(ctx, append([]byte("ACK "), p...), from)
+	}()
+
+	conn, err := raw.DialUDP(ctx, scale.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	reply, err := conn.Request(ctx, []byte("weigh"))
+	require.NoError(t, err)
+	assert.Equal(t, "ACK weigh", string(reply))
+
+	short, stop := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer stop()
+	_, _, err = conn.Receive(short)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.ErrorIs(t, scale.Send(ctx, []byte("x")), raw.ErrNotConnected)
+	require.NoError(t, raw.SendUDP(ctx, scale.LocalAddr().String(), []byte("zero")))
+}
@@ -0,0 +1,117 @@
+package raw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// maxDatagram is the largest UDP payload.
+const maxDatagram = 65535
+
+// ErrNotConnected indicates a Send or Request on a UDP connection opened with ListenUDP.
+var ErrNotConnected = errors.New("udp connection has no remote address")
+
+// UDPConn sends and receives datagrams; every call honours its context.
+// Reads and writes may run concurrently.
+type UDPConn struct {
+	conn      *net.UDPConn
+	connected bool
+}
+
+// ListenUDP opens a connection receiving datagrams on addr, e.g. ":5000".
+func ListenUDP(addr string) (*UDPConn, error) {
+	laddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", laddr)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPConn{conn: conn}, nil
+}
+
+// DialUDP opens a connection exchanging datagrams with the device at addr.
+func DialUDP(ctx context.Context, addr string) (*UDPConn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w; addr:%v", ErrConnectFailed, err, addr)
+	}
+	return &UDPConn{conn: conn.(*net.UDPConn), connected: true}, nil
+}
+
+// SendUDP sends p as a single datagram to addr.
+func SendUDP(ctx context.Context, addr string, p []byte) error {
+	c, err := DialUDP(ctx, addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	return c.Send(ctx, p)
+}
+
+// Send sends p to the remote address of a connection opened with DialUDP.
+func (c *UDPConn) Send(ctx context.Context, p []byte) error {
+	if !c.connected {
+		return ErrNotConnected
+	}
+	stop := watchContext(ctx, c.conn.SetWriteDeadline)
+	_, err := c.conn.Write(p)
+	stop()
+	return c.err(ctx, err)
+}
+
+// SendTo sends p to addr.
+func (c *UDPConn) SendTo(ctx context.Context, p []byte, addr net.Addr) error {
+	if c.connected {
+		return c.Send(ctx, p)
+	}
+	stop := watchContext(ctx, c.conn.SetWriteDeadline)
+	_, err := c.conn.WriteTo(p, addr)
+	stop()
+	return c.err(ctx, err)
+}
+
+// Receive waits for the next datagram and returns it with its sender.
+func (c *UDPConn) Receive(ctx context.Context) ([]byte, net.Addr, error) {
+	buf := make([]byte, maxDatagram)
+	stop := watchContext(ctx, c.conn.SetReadDeadline)
+	n, addr, err := c.conn.ReadFromUDP(buf)
+	stop()
+	if err != nil {
+		return nil, nil, c.err(ctx, err)
+	}
+	return buf[:n], addr, nil
+}
+
+// Request sends p to the remote address and waits for the reply.
+func (c *UDPConn) Request(ctx context.Context, p []byte) ([]byte, error) {
+	if err := c.Send(ctx, p); err != nil {
+		return nil, err
+	}
+	reply, _, err := c.Receive(ctx)
+	return reply, err
+}
+
+// LocalAddr returns the local address of the connection.
+func (c *UDPConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// Close closes the connection.
+func (c *UDPConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *UDPConn) err(ctx context.Context, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, net.ErrClosed):
+		return ErrClosed
+	}
+	return contextErr(ctx, err)
+}
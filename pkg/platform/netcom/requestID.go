@@ -0,0 +1,115 @@
+package netcom
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultRequestIDHeader is the conventional header carrying a request ID.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// TraceparentHeader is the W3C trace context header.
+const TraceparentHeader = "traceparent"
+
+type requestIDKey struct{}
+
+type traceparentKey struct{}
+
+// ContextWithRequestID returns a context carrying the request ID; clients with request ID injection
+// enabled propagate it instead of generating a new one.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored in the context, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// ContextWithTraceparent returns a context carrying an incoming W3C traceparent value;
+// outgoing requests continue its trace.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceparentKey{}, traceparent)
+}
+
+// TraceparentFromContext returns the traceparent stored in the context, if any.
+func TraceparentFromContext(ctx context.Context) (string, bool) {
+	tp, ok := ctx.Value(traceparentKey{}).(string)
+	return tp, ok && tp != ""
+}
+
+// RequestID returns the request ID the client attached to req, or an empty string.
+func RequestID(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	id, _ := RequestIDFromContext(req.Context())
+	return id
+}
+
+// NewRequestID generates a random (version 4) UUID.
+func NewRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// injectCorrelation sets the request ID and traceparent headers on req according to the client configuration.
+// The request ID is also stored in the request context so errors and logs can report it.
+func (c *Client) injectCorrelation(req *http.Request) *http.Request {
+	ctx := req.Context()
+	if c.requestIDHeader != "" {
+		id := req.Header.Get(c.requestIDHeader)
+		if id == "" {
+			if fromCtx, ok := RequestIDFromContext(ctx); ok {
+				id = fromCtx
+			} else {
+				id = NewRequestID()
+			}
+			req.Header.Set(c.requestIDHeader, id)
+		}
+		ctx = ContextWithRequestID(ctx, id)
+	}
+	if c.traceparent && req.Header.Get(TraceparentHeader) == "" {
+		incoming, _ := TraceparentFromContext(ctx)
+		req.Header.Set(TraceparentHeader, childTraceparent(incoming))
+	}
+	if ctx != req.Context() {
+		req = req.WithContext(ctx)
+	}
+	return req
+}
+
+// childTraceparent continues the trace of a valid parent traceparent with a new span ID, or starts a new trace.
+func childTraceparent(parent string) string {
+	traceID := ""
+	flags := "01"
+	if parts := strings.Split(parent, "-"); len(parts) == 4 && len(parts[1]) == 32 && len(parts[3]) == 2 {
+		traceID, flags = parts[1], parts[3]
+	}
+	if traceID == "" {
+		traceID = randomHex(16)
+	}
+	return fmt.Sprintf("00-%s-%s-%s", traceID, randomHex(8), flags)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestIDSuffix formats the request ID of req for error messages.
+func requestIDSuffix(req *http.Request) string {
+	if id := RequestID(req); id != "" {
+		return " request_id=" + id
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+package netcom
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrResponseTooLarge indicates a response body exceeding the configured maximum size.
+var ErrResponseTooLarge = errors.New("response body exceeds the size limit")
+
+// ErrBodyReadTimeout indicates a response body that was not read completely within the read deadline.
+var ErrBodyReadTimeout = errors.New("response body read deadline exceeded")
+
+// ResponseOption configures how DecodeResponse and ReadResponseBody consume a response.
+type ResponseOption func(*responseConfig)
+
+type responseConfig struct {
+	maxBytes    int64
+	readTimeout time.Duration
+	decoder     Decoder
+	expect      []string
+}
+
+// WithMaxBodySize fails the read with ErrResponseTooLarge once more than n bytes are received.
+func WithMaxBodySize(n int64) ResponseOption {
+	return func(rc *responseConfig) {
+		rc.maxBytes = n
+	}
+}
+
+// WithBodyReadTimeout fails the read with ErrBodyReadTimeout if the body is not consumed within d.
+func WithBodyReadTimeout(d time.Duration) ResponseOption {
+	return func(rc *responseConfig) {
+		rc.readTimeout = d
+	}
+}
+
+// applyResponseOptions wraps the response body according to the options and returns them.
+func applyResponseOptions(resp *http.Response, opts []ResponseOption) *responseConfig {
+	rc := new(responseConfig)
+	if len(opts) == 0 {
+		return rc
+	}
+	for _, opt := range opts {
+		opt(rc)
+	}
+	limitBody(resp, rc.maxBytes, rc.readTimeout)
+	return rc
+}
+
+// limitBody replaces the response body with one enforcing the size limit and read deadline; zero disables either.
+func limitBody(resp *http.Response, maxBytes int64, readTimeout time.Duration) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	if maxBytes > 0 {
+		resp.Body = &limitedBody{rc: resp.Body, remaining: maxBytes, limit: maxBytes}
+	}
+	if readTimeout > 0 {
+		resp.Body = newDeadlineBody(resp.Body, readTimeout)
+	}
+}
+
+// bodyLimit returns the size limit limitBody put on rc, or zero without one.
+func bodyLimit(rc io.ReadCloser) int64 {
+	if db, ok := rc.(*deadlineBody); ok {
+		rc = db.rc
+	}
+	if lb, ok := rc.(*limitedBody); ok {
+		return lb.limit
+	}
+	return 0
+}
+
+// limitedBody errors once more than limit bytes have been read, unlike io.LimitReader which truncates silently.
+type limitedBody struct {
+	rc        io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (lb *limitedBody) Read(p []byte) (int, error) {
+	if lb.remaining < 0 {
+		return 0, fmt.Errorf("%w: limit %d bytes", ErrResponseTooLarge, lb.limit)
+	}
+	// read one byte past the limit to tell "exactly at the limit" from "over the limit"
+	if int64(len(p)) > lb.remaining+1 {
+		p = p[:lb.remaining+1]
+	}
+	n, err := lb.rc.Read(p)
+	lb.remaining -= int64(n)
+	if lb.remaining < 0 {
+		return n + int(lb.remaining), fmt.Errorf("%w: limit %d bytes", ErrResponseTooLarge, lb.limit)
+	}
+	return n, err
+}
+
+func (lb *limitedBody) Close() error {
+	return lb.rc.Close()
+}
+
+// deadlineBody closes the underlying body when the deadline passes, unblocking a stalled Read.
+type deadlineBody struct {
+	rc      io.ReadCloser
+	timer   *time.Timer
+	expired atomic.Bool
+	once    sync.Once
+}
+
+func newDeadlineBody(rc io.ReadCloser, d time.Duration) *deadlineBody {
+	db := &deadlineBody{rc: rc}
+	db.timer = time.AfterFunc(d, func() {
+		db.expired.Store(true)
+		db.rc.Close()
+	})
+	return db
+}
+
+func (db *deadlineBody) Read(p []byte) (int, error) {
+	n, err := db.rc.Read(p)
+	if err != nil && db.expired.Load() {
+		return n, ErrBodyReadTimeout
+	}
+	return n, err
+}
+
+func (db *deadlineBody) Close() error {
+	var err error
+	db.once.Do(func() {
+		db.timer.Stop()
+		err = db.rc.Close()
+	})
+	return err
+}
@@ -0,0 +1,187 @@
+package netcom
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Default values of the zero RetryPolicy fields.
+const (
+	DefaultRetryAttempts  = 3
+	DefaultInitialBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff     = 10 * time.Second
+	DefaultJitter         = 0.2
+)
+
+// RetryPolicy retries requests failing with a connection error or a 5xx (or 429) status, waiting an exponentially growing,
+// jittered backoff between the attempts. Only idempotent methods are retried unless the request carries an Idempotency-Key
+// header or RetryNonIdempotent is set; requests whose body can not be rewound (see http.Request.GetBody) are sent once.
+// Bodies created from a *bytes.Reader, *bytes.Buffer or *strings.Reader are rewound automatically.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one; zero uses DefaultRetryAttempts, one disables retries.
+	MaxAttempts int
+	// InitialBackoff is the wait after the first attempt; zero uses DefaultInitialBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps every wait, including the one requested by a Retry-After header unless MaxRetryAfter is set;
+	// zero uses DefaultMaxBackoff.
+	MaxBackoff time.Duration
+	// MaxRetryAfter makes the retries wait as long as a Retry-After header asks, up to MaxRetryAfter; a response asking
+	// for a longer wait is not retried but returned, so DecodeResponse surfaces it as a *RetryableError for the caller
+	// to reschedule. Zero shortens the requested waits to MaxBackoff instead.
+	MaxRetryAfter time.Duration
+	// Multiplier grows the backoff after every attempt; values below one use 2.
+	Multiplier float64
+	// Jitter randomises every wait by up to the given fraction in either direction; zero uses DefaultJitter, a negative value disables it.
+	Jitter float64
+	// RetryNonIdempotent also retries POST and PATCH requests without an Idempotency-Key.
+	RetryNonIdempotent bool
+	// ShouldRetry replaces the default decision of which outcomes are retried; resp is nil when err is not.
+	ShouldRetry func(resp *http.Response, err error) bool
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy overrides the client's retry policy for a single request; pass RetryPolicy{MaxAttempts: 1} to disable retries.
+func WithRetryPolicy(p RetryPolicy) RequestOption {
+	return func(req *http.Request) error {
+		*req = *req.WithContext(context.WithValue(req.Context(), retryPolicyKey{}, p.normalized()))
+		return nil
+	}
+}
+
+// normalized fills the zero fields with the defaults.
+func (p RetryPolicy) normalized() RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = DefaultRetryAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultMaxBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	if p.Jitter == 0 {
+		p.Jitter = DefaultJitter
+	}
+	if p.ShouldRetry == nil {
+		p.ShouldRetry = retryableOutcome
+	}
+	return p
+}
+
+// retryPolicy returns the policy of the request, falling back to the client's; nil when the request is not retried.
+func (c *Client) retryPolicy(req *http.Request) *RetryPolicy {
+	p := c.retry
+	if rp, ok := req.Context().Value(retryPolicyKey{}).(RetryPolicy); ok {
+		p = &rp
+	}
+	if p == nil || p.MaxAttempts <= 1 || !p.allows(req) {
+		return nil
+	}
+	return p
+}
+
+var idempotentMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete,
+}
+
+// allows reports whether the request may be sent more than once.
+func (p *RetryPolicy) allows(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	return p.RetryNonIdempotent || slices.Contains(idempotentMethods, req.Method) || IdempotencyKey(req) != ""
+}
+
+// backoff returns the wait after the given attempt (1-based), honouring a Retry-After header of resp.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if d, ok := retryAfter(resp); ok {
+		if p.MaxRetryAfter > 0 {
+			return d
+		}
+		return min(d, p.MaxBackoff)
+	}
+	d := float64(p.InitialBackoff)
+	for range attempt - 1 {
+		d *= p.Multiplier
+		if d >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.Jitter > 0 {
+		d *= 1 - p.Jitter + 2*p.Jitter*rand.Float64()
+	}
+	return min(time.Duration(d), p.MaxBackoff)
+}
+
+// waitsTooLong reports whether resp asks for a wait beyond MaxRetryAfter.
+func (p *RetryPolicy) waitsTooLong(resp *http.Response) bool {
+	d, ok := retryAfter(resp)
+	return ok && p.MaxRetryAfter > 0 && d > p.MaxRetryAfter
+}
+
+// retryableOutcome retries connection errors, 429 and the 5xx statuses except 501 Not Implemented.
+func retryableOutcome(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// retryAfter parses the Retry-After header of a response, in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// nextAttempt prepares a copy of req with a fresh body for another try.
+func nextAttempt(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = body
+	}
+	return next, nil
+}
+
+// discard drains a little of a response that is thrown away for a retry, so the connection can be reused.
+func discard(resp *http.Response) {
+	io.CopyN(io.Discard, resp.Body, 4<<10)
+	resp.Body.Close()
+}
+
+// sleep waits d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
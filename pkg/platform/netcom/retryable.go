@@ -0,0 +1,47 @@
+package netcom
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RetryableError is the status error of a 429 Too Many Requests or 503 Service Unavailable response, which asks
+// to be tried again later; it wraps the ErrBadStatusCode error of the response.
+type RetryableError struct {
+	Status int
+	// RetryAfter is the wait requested by the Retry-After header of the response, zero without one.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RetryableError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s (retry after %s)", e.Err, e.RetryAfter)
+	}
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfterFromError returns the wait requested by the response that failed with err, and whether err is a
+// *RetryableError at all; callers scheduling their own retries can wait before sending the request again.
+func RetryAfterFromError(err error) (time.Duration, bool) {
+	var re *RetryableError
+	if errors.As(err, &re) {
+		return re.RetryAfter, true
+	}
+	return 0, false
+}
+
+// retryableStatusError wraps the status error of resp in a *RetryableError when its status asks for a later retry.
+func retryableStatusError(resp *http.Response, err error) error {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return err
+	}
+	wait, _ := retryAfter(resp)
+	return &RetryableError{Status: resp.StatusCode, RetryAfter: wait, Err: err}
+}
@@ -0,0 +1,187 @@
+// Package server provides the request binding and response helpers shared by the internal HTTP APIs.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBodyBytes is the request body limit applied by Bind unless WithMaxBodyBytes overrides it.
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// ErrMalformedBody indicates a request body that is not valid JSON for the target type.
+var ErrMalformedBody = errors.New("malformed request body")
+
+// ErrBodyTooLarge indicates a request body exceeding the binding limit.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// ErrUnsupportedMediaType indicates a request body that is not declared as JSON.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// ErrValidation indicates a decoded request that failed validation.
+var ErrValidation = errors.New("request validation failed")
+
+// BindOpt configures how Bind reads a request.
+type BindOpt func(*bindConfig)
+
+type bindConfig struct {
+	maxBytes      int64
+	allowUnknown  bool
+	allowEmpty    bool
+	skipMediaType bool
+}
+
+// WithMaxBodyBytes overrides DefaultMaxBodyBytes; requests over the limit fail with ErrBodyTooLarge.
+func WithMaxBodyBytes(n int64) BindOpt {
+	return func(bc *bindConfig) {
+		bc.maxBytes = n
+	}
+}
+
+// WithUnknownFields accepts JSON objects carrying fields the target type does not declare; they are rejected by default.
+func WithUnknownFields() BindOpt {
+	return func(bc *bindConfig) {
+		bc.allowUnknown = true
+	}
+}
+
+// WithEmptyBody binds an empty body to the zero value (still validated) instead of failing.
+func WithEmptyBody() BindOpt {
+	return func(bc *bindConfig) {
+		bc.allowEmpty = true
+	}
+}
+
+// WithAnyMediaType skips the Content-Type check.
+func WithAnyMediaType() BindOpt {
+	return func(bc *bindConfig) {
+		bc.skipMediaType = true
+	}
+}
+
+// FieldError describes a single field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// BindError is returned by Bind; it carries the HTTP status and payload to answer the request with.
+type BindError struct {
+	Status  int
+	Message string
+	Fields  []FieldError
+	err     error
+}
+
+func (e *BindError) Error() string {
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("%v: %s", e.err, e.Message)
+	}
+	fields := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = f.Field + " " + f.Message
+	}
+	return fmt.Sprintf("%v: %s", e.err, strings.Join(fields, "; "))
+}
+
+func (e *BindError) Unwrap() error {
+	return e.err
+}
+
+// Bind decodes the JSON body of r into a T, enforcing the body size limit, and validates the result
+// (see Validate). Failures are *BindError values; RespondError answers them with a problem listing the field errors.
+func Bind[T any](r *http.Request, opts ...BindOpt) (T, error) {
+	var v T
+	bc := &bindConfig{maxBytes: DefaultMaxBodyBytes}
+	for _, opt := range opts {
+		opt(bc)
+	}
+	if !bc.skipMediaType {
+		if ct := r.Header.Get("Content-Type"); ct != "" {
+			mt, _, err := mime.ParseMediaType(ct)
+			if err != nil || (mt != "application/json" && !strings.HasSuffix(mt, "+json")) {
+				return v, &BindError{Status: http.StatusUnsupportedMediaType, Message: fmt.Sprintf("content type %q is not JSON", ct), err: ErrUnsupportedMediaType}
+			}
+		}
+	}
+	body := io.Reader(http.NoBody)
+	if r.Body != nil {
+		body = r.Body
+		if bc.maxBytes > 0 {
+			body = http.MaxBytesReader(nil, r.Body, bc.maxBytes)
+		}
+	}
+	d := json.NewDecoder(body)
+	if !bc.allowUnknown {
+		d.DisallowUnknownFields()
+	}
+	if err := d.Decode(&v); err != nil {
+		var maxErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxErr):
+			return v, &BindError{Status: http.StatusRequestEntityTooLarge, Message: fmt.Sprintf("body exceeds %d bytes", maxErr.Limit), err: ErrBodyTooLarge}
+		case errors.Is(err, io.EOF) && bc.allowEmpty:
+		case errors.Is(err, io.EOF):
+			return v, &BindError{Status: http.StatusBadRequest, Message: "empty body", err: ErrMalformedBody}
+		default:
+			return v, &BindError{Status: http.StatusBadRequest, Message: decodeMessage(err), err: ErrMalformedBody}
+		}
+	}
+	// a second value (or trailing garbage) means the body is not a single JSON document
+	if err := d.Decode(new(json.RawMessage)); !errors.Is(err, io.EOF) {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return v, &BindError{Status: http.StatusRequestEntityTooLarge, Message: fmt.Sprintf("body exceeds %d bytes", maxErr.Limit), err: ErrBodyTooLarge}
+		}
+		return v, &BindError{Status: http.StatusBadRequest, Message: "body must contain a single JSON value", err: ErrMalformedBody}
+	}
+	if fields := Validate(v); len(fields) > 0 {
+		return v, &BindError{Status: http.StatusBadRequest, Message: "validation failed", Fields: fields, err: ErrValidation}
+	}
+	return v, nil
+}
+
+// decodeMessage turns json decoding errors into messages safe to return to the caller.
+func decodeMessage(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("invalid JSON at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return fmt.Sprintf("field %q must be %s", typeErr.Field, typeErr.Type)
+		}
+		return fmt.Sprintf("body must be %s", typeErr.Type)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "truncated JSON body"
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return "unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	}
+	return "invalid JSON body"
+}
+
+// RespondJSON writes v as a JSON response with the given status.
+func RespondJSON(w http.ResponseWriter, status int, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// RespondError writes the application/problem+json response for err using DefaultProblems; r provides the
+// correlation ID and may be nil.
+func RespondError(w http.ResponseWriter, r *http.Request, err error) error {
+	return DefaultProblems.Respond(w, r, err)
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"sync"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement/db"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+)
+
+// ProblemContentType is the media type of RFC 7807 error bodies.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object.
+// Extensions are serialized as additional top level members.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	RequestID  string
+	Extensions map[string]any
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+6)
+	maps.Copy(m, p.Extensions)
+	m["type"] = p.Type
+	m["title"] = p.Title
+	m["status"] = p.Status
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+	if p.RequestID != "" {
+		m["request_id"] = p.RequestID
+	}
+	return json.Marshal(m)
+}
+
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	p.Type, _ = m["type"].(string)
+	p.Title, _ = m["title"].(string)
+	if status, ok := m["status"].(float64); ok {
+		p.Status = int(status)
+	}
+	p.Detail, _ = m["detail"].(string)
+	p.Instance, _ = m["instance"].(string)
+	p.RequestID, _ = m["request_id"].(string)
+	for _, k := range []string{"type", "title", "status", "detail", "instance", "request_id"} {
+		delete(m, k)
+	}
+	if len(m) > 0 {
+		p.Extensions = m
+	}
+	return nil
+}
+
+// ProblemError is implemented by typed errors that describe their own problem response.
+type ProblemError interface {
+	error
+	Problem() Problem
+}
+
+// ProblemFunc maps err to a problem; ok is false when the function does not handle err.
+type ProblemFunc func(err error) (p Problem, ok bool)
+
+// ProblemMapper turns errors into problem responses. Errors implementing ProblemError are used as-is,
+// then the registered functions are tried newest first, then the built-in mappings; anything else is an opaque 500.
+type ProblemMapper struct {
+	// TypeBase prefixes the problem slug to build the type URI (e.g. "https://errors.example.com/");
+	// when empty the type is "about:blank"
+	TypeBase string
+	// RequestIDHeader is read from the request when the context carries no request ID; defaults to netcom.DefaultRequestIDHeader
+	RequestIDHeader string
+	mu              sync.RWMutex
+	funcs           []ProblemFunc
+}
+
+// DefaultProblems is the mapper used by RespondError.
+var DefaultProblems = new(ProblemMapper)
+
+// Register maps errors matching target (errors.Is) to status; slug names the problem type.
+func (pm *ProblemMapper) Register(target error, status int, slug string) {
+	pm.RegisterFunc(func(err error) (Problem, bool) {
+		if !errors.Is(err, target) {
+			return Problem{}, false
+		}
+		return Problem{Type: slug, Status: status, Detail: clientDetail(status, err)}, true
+	})
+}
+
+// RegisterFunc adds a custom mapping; it takes precedence over the previously registered ones.
+func (pm *ProblemMapper) RegisterFunc(fn ProblemFunc) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.funcs = append(pm.funcs, fn)
+}
+
+// Problem builds the problem for err, filling the correlation ID and instance from r when it is not nil.
+func (pm *ProblemMapper) Problem(r *http.Request, err error) Problem {
+	p := pm.lookup(err)
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	switch {
+	case p.Type == "" || p.Type == "about:blank":
+		p.Type = "about:blank"
+	case isURI(p.Type):
+	case pm.TypeBase != "":
+		p.Type = pm.TypeBase + p.Type
+	default:
+		// without a type base the slug is kept as a code member for clients to switch on
+		p.Extensions = maps.Clone(p.Extensions)
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]any)
+		}
+		p.Extensions["code"] = p.Type
+		p.Type = "about:blank"
+	}
+	if r != nil {
+		if p.Instance == "" {
+			p.Instance = r.URL.Path
+		}
+		if p.RequestID == "" {
+			p.RequestID = pm.requestID(r)
+		}
+	}
+	return p
+}
+
+// Respond writes the problem for err as application/problem+json.
+func (pm *ProblemMapper) Respond(w http.ResponseWriter, r *http.Request, err error) error {
+	p := pm.Problem(r, err)
+	b, merr := json.Marshal(p)
+	if merr != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return merr
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	if p.RequestID != "" {
+		w.Header().Set(pm.requestIDHeader(), p.RequestID)
+	}
+	w.WriteHeader(p.Status)
+	_, werr := w.Write(append(b, '\n'))
+	return werr
+}
+
+func (pm *ProblemMapper) lookup(err error) Problem {
+	var pe ProblemError
+	if errors.As(err, &pe) {
+		return pe.Problem()
+	}
+	pm.mu.RLock()
+	funcs := pm.funcs
+	pm.mu.RUnlock()
+	for i := len(funcs) - 1; i >= 0; i-- {
+		if p, ok := funcs[i](err); ok {
+			return p
+		}
+	}
+	return builtinProblem(err)
+}
+
+func (pm *ProblemMapper) requestIDHeader() string {
+	if pm.RequestIDHeader != "" {
+		return pm.RequestIDHeader
+	}
+	return netcom.DefaultRequestIDHeader
+}
+
+func (pm *ProblemMapper) requestID(r *http.Request) string {
+	if id, ok := netcom.RequestIDFromContext(r.Context()); ok {
+		return id
+	}
+	return r.Header.Get(pm.requestIDHeader())
+}
+
+// builtinProblem maps the errors of this module.
+func builtinProblem(err error) Problem {
+	var be *BindError
+	var he datamanagement.HeaderError
+	switch {
+	case errors.As(err, &be):
+		p := Problem{Type: "invalid-request", Status: be.Status, Detail: be.Message}
+		if len(be.Fields) > 0 {
+			p.Type = "validation"
+			p.Extensions = map[string]any{"errors": be.Fields}
+		}
+		return p
+	case errors.As(err, &he):
+		return Problem{Type: "invalid-header", Status: http.StatusUnprocessableEntity, Detail: err.Error(),
+			Extensions: map[string]any{"header": he.Header(), "other": he.Other()}}
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, datamanagement.ErrNoOrderFound):
+		return Problem{Type: "not-found", Status: http.StatusNotFound}
+	case errors.Is(err, datamanagement.ErrNoOverwrite), errors.Is(err, datamanagement.ErrCheckpointRegression):
+		return Problem{Type: "conflict", Status: http.StatusConflict, Detail: err.Error()}
+	case errors.Is(err, datamanagement.ErrIncompatibleDataframes):
+		return Problem{Type: "incompatible-data", Status: http.StatusUnprocessableEntity, Detail: err.Error()}
+	case db.IsUnreachable(err):
+		return Problem{Type: "dependency-unavailable", Status: http.StatusServiceUnavailable}
+	case errors.Is(err, context.DeadlineExceeded):
+		return Problem{Type: "timeout", Status: http.StatusGatewayTimeout}
+	}
+	return Problem{Status: http.StatusInternalServerError}
+}
+
+// clientDetail exposes the error message for client errors only; server errors keep internal details out of the response.
+func clientDetail(status int, err error) string {
+	if status >= 500 {
+		return ""
+	}
+	return err.Error()
+}
+
+func isURI(s string) bool {
+	for i, c := range s {
+		switch {
+		case c == ':':
+			return i > 0
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', i > 0 && (c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return false
+}
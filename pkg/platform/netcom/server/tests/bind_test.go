@@ -0,0 +1,122 @@
+package server_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type line struct {
+	Sku string `json:"sku" validate:"required"`
+	Qty int    `json:"qty" validate:"min=1,max=100"`
+}
+
+type createOrder struct {
+	Plant    string   `json:"plant" validate:"required,oneof=gradec sofia"`
+	Priority *int     `json:"priority" validate:"min=0"`
+	Comment  string   `json:"comment" validate:"max=10"`
+	Lines    []line   `json:"lines" validate:"min=1"`
+	Tags     []string `json:"tags"`
+}
+
+func (o createOrder) Validate() error {
+	if o.Plant == "sofia" && len(o.Lines) > 1 {
+		return server.FieldError{Field: "lines", Rule: "custom", Message: "sofia orders take a single line"}
+	}
+	return nil
+}
+
+func newRequest(body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return r
+}
+
+func TestBind(t *testing.T) {
+	o, err := server.Bind[createOrder](newRequest(`{"plant":"gradec","lines":[{"sku":"a1","qty":3}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "a1", o.Lines[0].Sku)
+}
+
+func TestBindValidation(t *testing.T) {
+	_, err := server.Bind[createOrder](newRequest(`{"plant":"berlin","priority":-1,"comment":"far too long comment","lines":[{"qty":0}]}`))
+	require.ErrorIs(t, err, server.ErrValidation)
+	var be *server.BindError
+	require.True(t, errors.As(err, &be))
+	assert.Equal(t, http.StatusBadRequest, be.Status)
+	fields := map[string]string{}
+	for _, f := range be.Fields {
+		fields[f.Field] = f.Rule
+	}
+	assert.Equal(t, map[string]string{
+		"plant":        "oneof",
+		"priority":     "min",
+		"comment":      "max",
+		"lines[0].sku": "required",
+		"lines[0].qty": "min",
+	}, fields)
+
+	_, err = server.Bind[createOrder](newRequest(`{"plant":"sofia","lines":[{"sku":"a","qty":1},{"sku":"b","qty":1}]}`))
+	require.True(t, errors.As(err, &be))
+	require.Len(t, be.Fields, 1)
+	assert.Equal(t, "lines", be.Fields[0].Field)
+}
+
+func TestBindRejectsBadBodies(t *testing.T) {
+	cases := map[string]struct {
+		req    *http.Request
+		opts   []server.BindOpt
+		target error
+		status int
+	}{
+		"syntax":        {req: newRequest(`{"plant":`), target: server.ErrMalformedBody, status: http.StatusBadRequest},
+		"type":          {req: newRequest(`{"plant":5}`), target: server.ErrMalformedBody, status: http.StatusBadRequest},
+		"unknown field": {req: newRequest(`{"plant":"gradec","extra":1}`), target: server.ErrMalformedBody, status: http.StatusBadRequest},
+		"two values":    {req: newRequest(`{} {}`), target: server.ErrMalformedBody, status: http.StatusBadRequest},
+		"empty":         {req: newRequest(``), target: server.ErrMalformedBody, status: http.StatusBadRequest},
+		"too large":     {req: newRequest(`{"plant":"gradec","comment":"` + strings.Repeat("x", 64) + `"}`), opts: []server.BindOpt{server.WithMaxBodyBytes(32)}, target: server.ErrBodyTooLarge, status: http.StatusRequestEntityTooLarge},
+	}
+	form := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("plant=gradec"))
+	form.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	cases["media type"] = struct {
+		req    *http.Request
+		opts   []server.BindOpt
+		target error
+		status int
+	}{req: form, target: server.ErrUnsupportedMediaType, status: http.StatusUnsupportedMediaType}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := server.Bind[createOrder](c.req, c.opts...)
+			require.ErrorIs(t, err, c.target)
+			var be *server.BindError
+			require.True(t, errors.As(err, &be))
+			assert.Equal(t, c.status, be.Status)
+		})
+	}
+}
+
+func TestRespondError(t *testing.T) {
+	_, err := server.Bind[createOrder](newRequest(`{"lines":[]}`))
+	rec := httptest.NewRecorder()
+	require.NoError(t, server.RespondError(rec, nil, err))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, server.ProblemContentType, rec.Header().Get("Content-Type"))
+	var payload struct {
+		Errors []server.FieldError `json:"errors"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&payload))
+	assert.Len(t, payload.Errors, 2)
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, server.RespondError(rec, nil, errors.New("db password rejected")))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "password")
+}
@@ -0,0 +1,80 @@
+package server_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errQuotaExceeded = errors.New("quota exceeded")
+
+type lockedErr struct{ owner string }
+
+func (e lockedErr) Error() string { return "locked by " + e.owner }
+
+func (e lockedErr) Problem() server.Problem {
+	return server.Problem{Type: "locked", Status: http.StatusLocked, Detail: e.Error(), Extensions: map[string]any{"owner": e.owner}}
+}
+
+func respond(t *testing.T, pm *server.ProblemMapper, r *http.Request, err error) (*httptest.ResponseRecorder, server.Problem) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	require.NoError(t, pm.Respond(rec, r, err))
+	assert.Equal(t, server.ProblemContentType, rec.Header().Get("Content-Type"))
+	var p server.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	return rec, p
+}
+
+func TestProblemMapping(t *testing.T) {
+	pm := &server.ProblemMapper{TypeBase: "https://errors.example.com/"}
+	pm.Register(errQuotaExceeded, http.StatusTooManyRequests, "quota")
+
+	r := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+	r = r.WithContext(netcom.ContextWithRequestID(r.Context(), "req-1"))
+
+	cases := []struct {
+		err    error
+		status int
+		typ    string
+	}{
+		{err: &datamanagement.ColumnsNotFoundErr{Available: []string{"a"}, Required: []string{"b"}}, status: http.StatusUnprocessableEntity, typ: "https://errors.example.com/invalid-header"},
+		{err: fmt.Errorf("%w; key:7", datamanagement.ErrNoOrderFound), status: http.StatusNotFound, typ: "https://errors.example.com/not-found"},
+		{err: fmt.Errorf("charging: %w", errQuotaExceeded), status: http.StatusTooManyRequests, typ: "https://errors.example.com/quota"},
+		{err: lockedErr{owner: "node-2"}, status: http.StatusLocked, typ: "https://errors.example.com/locked"},
+		{err: errors.New("boom"), status: http.StatusInternalServerError, typ: "about:blank"},
+	}
+	for _, c := range cases {
+		rec, p := respond(t, pm, r, c.err)
+		assert.Equal(t, c.status, rec.Code, c.err.Error())
+		assert.Equal(t, c.status, p.Status)
+		assert.Equal(t, c.typ, p.Type)
+		assert.Equal(t, http.StatusText(c.status), p.Title)
+		assert.Equal(t, "/orders/7", p.Instance)
+		assert.Equal(t, "req-1", p.RequestID)
+		assert.Equal(t, "req-1", rec.Header().Get(netcom.DefaultRequestIDHeader))
+	}
+
+	_, p := respond(t, pm, r, lockedErr{owner: "node-2"})
+	assert.Equal(t, "node-2", p.Extensions["owner"])
+	_, p = respond(t, pm, r, errors.New("select failed: password=hunter2"))
+	assert.Empty(t, p.Detail)
+}
+
+func TestProblemWithoutTypeBase(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	r.Header.Set(netcom.DefaultRequestIDHeader, "from-header")
+	_, p := respond(t, new(server.ProblemMapper), r, &datamanagement.HeaderMismatchErr{})
+	assert.Equal(t, "about:blank", p.Type)
+	assert.Equal(t, "invalid-header", p.Extensions["code"])
+	assert.Equal(t, "from-header", p.RequestID)
+}
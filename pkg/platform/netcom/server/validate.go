@@ -0,0 +1,188 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Validator is implemented by request types with checks that struct tags cannot express;
+// Validate calls it after the tag rules. Returning a FieldError keeps its field name.
+type Validator interface {
+	Validate() error
+}
+
+func (fe FieldError) Error() string {
+	if fe.Field == "" {
+		return fe.Message
+	}
+	return fe.Field + " " + fe.Message
+}
+
+// Validate checks v against the `validate` struct tags of its fields and returns every violation.
+// Fields are reported by their JSON name; nested structs, pointers to structs and slices of structs are checked too.
+//
+// Rules are comma separated:
+//
+//	required    the value must not be the zero value
+//	min=N max=N numbers are compared by value; strings, slices and maps by length
+//	len=N       exact length of a string, slice or map
+//	oneof=a b c the value must format to one of the space separated options
+func Validate(v any) []FieldError {
+	var errs []FieldError
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	validateValue(rv, "", &errs)
+	return errs
+}
+
+func validateValue(rv reflect.Value, prefix string, errs *[]FieldError) {
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if !rv.IsNil() {
+			validateValue(rv.Elem(), prefix, errs)
+		}
+		return
+	case reflect.Slice, reflect.Array:
+		for i := range rv.Len() {
+			validateValue(rv.Index(i), fmt.Sprintf("%s[%d]", prefix, i), errs)
+		}
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		fv := rv.Field(i)
+		if tag, ok := sf.Tag.Lookup("validate"); ok {
+			for rule := range strings.SplitSeq(tag, ",") {
+				if rule = strings.TrimSpace(rule); rule == "" {
+					continue
+				}
+				if msg := checkRule(fv, rule); msg != "" {
+					r, _, _ := strings.Cut(rule, "=")
+					*errs = append(*errs, FieldError{Field: name, Rule: r, Message: msg})
+				}
+			}
+		}
+		if fv.Kind() == reflect.Struct || fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array {
+			validateValue(fv, name, errs)
+		}
+	}
+	if rv.CanAddr() {
+		rv = rv.Addr()
+	}
+	if custom, ok := rv.Interface().(Validator); ok {
+		if err := custom.Validate(); err != nil {
+			var fe FieldError
+			if errors.As(err, &fe) {
+				if prefix != "" && fe.Field != "" {
+					fe.Field = prefix + "." + fe.Field
+				}
+				*errs = append(*errs, fe)
+				return
+			}
+			*errs = append(*errs, FieldError{Field: prefix, Rule: "custom", Message: err.Error()})
+		}
+	}
+}
+
+func fieldName(sf reflect.StructField) string {
+	if tag, ok := sf.Tag.Lookup("json"); ok {
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+// checkRule returns the violation message of rule for fv, or an empty string.
+func checkRule(fv reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	switch name {
+	case "required":
+		if fv.IsZero() {
+			return "is required"
+		}
+	case "min", "max", "len":
+		if fv.Kind() == reflect.Pointer && fv.IsNil() {
+			// optional values are only checked when present
+			return ""
+		}
+		n, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Sprintf("has an invalid %s rule %q", name, arg)
+		}
+		size, isLen, ok := measure(fv)
+		if !ok {
+			return fmt.Sprintf("cannot be checked with %s", name)
+		}
+		unit := ""
+		if isLen {
+			unit = " in length"
+		}
+		switch {
+		case name == "min" && size < n:
+			return fmt.Sprintf("must be at least %s%s", arg, unit)
+		case name == "max" && size > n:
+			return fmt.Sprintf("must be at most %s%s", arg, unit)
+		case name == "len" && size != n:
+			return fmt.Sprintf("must be exactly %s in length", arg)
+		}
+	case "oneof":
+		options := strings.Fields(arg)
+		for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.IsZero() {
+			// combine with required to reject missing values
+			return ""
+		}
+		if !slices.Contains(options, fmt.Sprint(fv.Interface())) {
+			return "must be one of " + strings.Join(options, ", ")
+		}
+	default:
+		return fmt.Sprintf("has an unknown validation rule %q", name)
+	}
+	return ""
+}
+
+// measure returns the value compared by min/max: the number itself or the length of the value.
+func measure(fv reflect.Value) (size float64, isLen bool, ok bool) {
+	for fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return 0, false, false
+		}
+		fv = fv.Elem()
+	}
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), false, true
+	case reflect.String:
+		return float64(len([]rune(fv.String()))), true, true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(fv.Len()), true, true
+	}
+	return 0, false, false
+}
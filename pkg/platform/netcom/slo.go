@@ -0,0 +1,312 @@
+package netcom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrInvalidSLO indicates an SLO definition that can not be tracked.
+var ErrInvalidSLO = errors.New("invalid SLO")
+
+// Default values of the zero SLO and SLOConfig fields.
+const (
+	DefaultSLOWindow         = 24 * time.Hour
+	DefaultSLOAlertWindow    = time.Hour
+	DefaultBurnRateThreshold = 10
+	DefaultBurnMinRequests   = 10
+)
+
+// sloBucketsPerAlertWindow is the resolution of the alert window; the SLO window is made of buckets of the same width.
+const sloBucketsPerAlertWindow = 6
+
+// SLO is the service level objective of a named endpoint.
+// A request counts as good when it got a response other than 429 or 5xx within Latency;
+// requests canceled by the caller are not counted.
+type SLO struct {
+	// Endpoint is the name requests are tagged with through WithEndpointName or Endpoint.Name.
+	Endpoint string
+	// Objective is the share of good requests to reach, e.g. 0.99; it must be between 0 and 1, exclusive.
+	Objective float64
+	// Latency is the slowest response that still counts as good, retries included; zero does not judge latency.
+	Latency time.Duration
+	// Window is the rolling period the objective applies to; zero uses DefaultSLOWindow.
+	Window time.Duration
+}
+
+// SLOConfig enables SLO tracking of the named endpoints: the error budget of every objective is
+// exposed through Client.SLOStatus and Client.Collector, and OnBurn is called when it burns too fast.
+type SLOConfig struct {
+	Objectives []SLO
+	// AlertWindow is the recent period the burn rate is measured over; zero uses DefaultSLOAlertWindow.
+	AlertWindow time.Duration
+	// BurnRateThreshold is the burn rate calling OnBurn; 1 spends exactly the budget of the window.
+	// Zero uses DefaultBurnRateThreshold.
+	BurnRateThreshold float64
+	// BurnMinRequests is the number of requests in the alert window needed before OnBurn is called,
+	// so a single failure of a quiet endpoint does not alert; zero uses DefaultBurnMinRequests.
+	BurnMinRequests int64
+	// OnBurn is called at most once per AlertWindow and endpoint while the burn rate exceeds the threshold,
+	// e.g. to notify the on-call; it must not block.
+	OnBurn func(SLOStatus)
+}
+
+// SLOStatus is the state of an objective over its window.
+type SLOStatus struct {
+	SLO
+	Requests int64
+	Failures int64
+	// SuccessRate is the share of good requests; 1 without requests.
+	SuccessRate float64
+	// BudgetRemaining is the share of the error budget left, 1 when untouched and negative once overspent.
+	BudgetRemaining float64
+	// BurnRate is how fast the budget was spent during the alert window relative to the allowed pace.
+	BurnRate float64
+}
+
+// Healthy reports whether the objective is met, i.e. error budget is left.
+func (s SLOStatus) Healthy() bool {
+	return s.BudgetRemaining > 0
+}
+
+func (s SLOStatus) String() string {
+	return fmt.Sprintf("%s objective=%g success_rate=%.4f budget_remaining=%.2f burn_rate=%.2f requests=%d",
+		s.Endpoint, s.Objective, s.SuccessRate, s.BudgetRemaining, s.BurnRate, s.Requests)
+}
+
+type endpointNameKey struct{}
+
+// WithEndpointName tags the request with the endpoint name its SLO is tracked under.
+func WithEndpointName(name string) RequestOption {
+	return func(req *http.Request) error {
+		*req = *req.WithContext(context.WithValue(req.Context(), endpointNameKey{}, name))
+		return nil
+	}
+}
+
+func endpointName(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(endpointNameKey{}).(string)
+	return name, ok && name != ""
+}
+
+// sloTracker keeps the outcomes of the requests of every objective in rolling time buckets.
+type sloTracker struct {
+	config SLOConfig
+	mu     sync.Mutex
+	series map[string]*sloSeries
+	now    func() time.Time
+}
+
+type sloSeries struct {
+	slo       SLO
+	width     time.Duration
+	buckets   []sloBucket
+	lastAlert time.Time
+}
+
+type sloBucket struct {
+	slot     int64 // the start of the bucket in widths since the epoch
+	requests int64
+	failures int64
+}
+
+func newSLOTracker(config SLOConfig) (*sloTracker, error) {
+	if config.AlertWindow <= 0 {
+		config.AlertWindow = DefaultSLOAlertWindow
+	}
+	if config.BurnRateThreshold <= 0 {
+		config.BurnRateThreshold = DefaultBurnRateThreshold
+	}
+	if config.BurnMinRequests <= 0 {
+		config.BurnMinRequests = DefaultBurnMinRequests
+	}
+	t := &sloTracker{config: config, series: make(map[string]*sloSeries), now: time.Now}
+	width := max(config.AlertWindow/sloBucketsPerAlertWindow, 1)
+	for _, slo := range config.Objectives {
+		switch {
+		case slo.Endpoint == "":
+			return nil, fmt.Errorf("%w: empty endpoint name", ErrInvalidSLO)
+		case slo.Objective <= 0 || slo.Objective >= 1:
+			return nil, fmt.Errorf("%w: objective %g of '%s' is not between 0 and 1", ErrInvalidSLO, slo.Objective, slo.Endpoint)
+		case t.series[slo.Endpoint] != nil:
+			return nil, fmt.Errorf("%w: duplicate endpoint '%s'", ErrInvalidSLO, slo.Endpoint)
+		}
+		if slo.Window <= 0 {
+			slo.Window = DefaultSLOWindow
+		}
+		if slo.Window < config.AlertWindow {
+			return nil, fmt.Errorf("%w: window %s of '%s' is shorter than the alert window", ErrInvalidSLO, slo.Window, slo.Endpoint)
+		}
+		n := int((slo.Window + width - 1) / width)
+		t.series[slo.Endpoint] = &sloSeries{slo: slo, width: width, buckets: make([]sloBucket, n)}
+	}
+	return t, nil
+}
+
+// record counts the outcome of a request to the endpoint; requests to endpoints without an objective are ignored.
+func (t *sloTracker) record(endpoint string, resp *http.Response, latency time.Duration, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	t.mu.Lock()
+	s, ok := t.series[endpoint]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	now := t.now()
+	b := s.bucket(now)
+	b.requests++
+	if !s.good(resp, latency, err) {
+		b.failures++
+	}
+
+	var alert *SLOStatus
+	if t.config.OnBurn != nil && now.Sub(s.lastAlert) >= t.config.AlertWindow {
+		status := s.status(now, t.config.AlertWindow)
+		if status.BurnRate >= t.config.BurnRateThreshold && s.recentRequests(now, t.config.AlertWindow) >= t.config.BurnMinRequests {
+			s.lastAlert = now
+			alert = &status
+		}
+	}
+	t.mu.Unlock()
+
+	if alert != nil {
+		t.config.OnBurn(*alert)
+	}
+}
+
+func (t *sloTracker) status(endpoint string) (SLOStatus, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.series[endpoint]
+	if !ok {
+		return SLOStatus{}, false
+	}
+	return s.status(t.now(), t.config.AlertWindow), true
+}
+
+func (t *sloTracker) statuses() []SLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	statuses := make([]SLOStatus, 0, len(t.series))
+	for _, s := range t.series {
+		statuses = append(statuses, s.status(now, t.config.AlertWindow))
+	}
+	slices.SortFunc(statuses, func(a, b SLOStatus) int { return strings.Compare(a.Endpoint, b.Endpoint) })
+	return statuses
+}
+
+func (s *sloSeries) good(resp *http.Response, latency time.Duration, err error) bool {
+	if err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return false
+	}
+	return s.slo.Latency == 0 || latency <= s.slo.Latency
+}
+
+// bucket returns the bucket of the moment, clearing it if it still holds an older slot.
+func (s *sloSeries) bucket(at time.Time) *sloBucket {
+	slot := at.UnixNano() / int64(s.width)
+	b := &s.buckets[slot%int64(len(s.buckets))]
+	if b.slot != slot {
+		*b = sloBucket{slot: slot}
+	}
+	return b
+}
+
+// sum adds up the buckets of the last n slots.
+func (s *sloSeries) sum(at time.Time, n int64) (requests, failures int64) {
+	slot := at.UnixNano() / int64(s.width)
+	for _, b := range s.buckets {
+		if b.slot > slot-n && b.slot <= slot {
+			requests += b.requests
+			failures += b.failures
+		}
+	}
+	return requests, failures
+}
+
+func (s *sloSeries) recentRequests(at time.Time, alertWindow time.Duration) int64 {
+	requests, _ := s.sum(at, int64(alertWindow/s.width))
+	return requests
+}
+
+func (s *sloSeries) status(at time.Time, alertWindow time.Duration) SLOStatus {
+	status := SLOStatus{SLO: s.slo, SuccessRate: 1, BudgetRemaining: 1}
+	budget := 1 - s.slo.Objective
+	status.Requests, status.Failures = s.sum(at, int64(len(s.buckets)))
+	if status.Requests > 0 {
+		status.SuccessRate = 1 - float64(status.Failures)/float64(status.Requests)
+		status.BudgetRemaining = 1 - float64(status.Failures)/(budget*float64(status.Requests))
+	}
+	if requests, failures := s.sum(at, int64(alertWindow/s.width)); requests > 0 {
+		status.BurnRate = float64(failures) / float64(requests) / budget
+	}
+	return status
+}
+
+// sloCollector exposes the statuses of the objectives as gauges.
+type sloCollector struct {
+	tracker     *sloTracker
+	successRate *prometheus.Desc
+	budget      *prometheus.Desc
+	burnRate    *prometheus.Desc
+	objective   *prometheus.Desc
+}
+
+func newSLOCollector(t *sloTracker, config MetricsConfig) *sloCollector {
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = "netcom"
+	}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "slo", name), help, []string{"endpoint"}, config.ConstLabels)
+	}
+	return &sloCollector{
+		tracker:     t,
+		successRate: desc("success_ratio", "Share of good requests over the SLO window."),
+		budget:      desc("error_budget_remaining_ratio", "Share of the error budget left over the SLO window; negative once overspent."),
+		burnRate:    desc("burn_rate", "Error budget burn rate over the alert window; 1 spends exactly the budget of the window."),
+		objective:   desc("objective_ratio", "Share of good requests the SLO requires."),
+	}
+}
+
+func (c *sloCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.successRate
+	ch <- c.budget
+	ch <- c.burnRate
+	ch <- c.objective
+}
+
+func (c *sloCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range c.tracker.statuses() {
+		ch <- prometheus.MustNewConstMetric(c.successRate, prometheus.GaugeValue, s.SuccessRate, s.Endpoint)
+		ch <- prometheus.MustNewConstMetric(c.budget, prometheus.GaugeValue, s.BudgetRemaining, s.Endpoint)
+		ch <- prometheus.MustNewConstMetric(c.burnRate, prometheus.GaugeValue, s.BurnRate, s.Endpoint)
+		ch <- prometheus.MustNewConstMetric(c.objective, prometheus.GaugeValue, s.Objective, s.Endpoint)
+	}
+}
+
+// SLOStatus returns the current state of the objective of the endpoint; false when it has none.
+func (c *Client) SLOStatus(endpoint string) (SLOStatus, bool) {
+	if c.slo == nil {
+		return SLOStatus{}, false
+	}
+	return c.slo.status(endpoint)
+}
+
+// SLOStatuses returns the current state of every objective, ordered by endpoint, e.g. for a health report.
+func (c *Client) SLOStatuses() []SLOStatus {
+	if c.slo == nil {
+		return nil
+	}
+	return c.slo.statuses()
+}
@@ -0,0 +1,209 @@
+package netcom
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotEventStream indicates a StreamSSE response whose Content-Type is not text/event-stream.
+var ErrNotEventStream = errors.New("response is not an event stream")
+
+// Reconnection delays of StreamSSE.
+const (
+	// DefaultSSERetry is the wait before reconnecting until the server sends a retry field.
+	DefaultSSERetry = 3 * time.Second
+	// MaxSSERetry caps the wait after consecutive failed reconnects.
+	MaxSSERetry = time.Minute
+)
+
+// Event is a Server-Sent Event.
+type Event struct {
+	// ID is the last event ID set by the stream, sent back as Last-Event-ID on reconnect.
+	ID string
+	// Type is the event field; "message" when the event has none.
+	Type string
+	// Data holds the data lines joined by newlines.
+	Data string
+}
+
+type streamingKey struct{}
+
+// streaming keeps the client-level body size limit and read deadline off a long-lived response.
+func streaming() RequestOption {
+	return func(req *http.Request) error {
+		*req = *req.WithContext(context.WithValue(req.Context(), streamingKey{}, true))
+		return nil
+	}
+}
+
+func isStreaming(req *http.Request) bool {
+	s, _ := req.Context().Value(streamingKey{}).(bool)
+	return s
+}
+
+// StreamSSE subscribes to the text/event-stream at path and calls handler with every event until ctx is done,
+// the handler returns an error, or the server ends the subscription with 204 No Content.
+// A dropped or ended stream is reopened with the Last-Event-ID of the last event, after the delay set by the
+// server's retry field (DefaultSSERetry until then), doubled after every reconnect that fails, up to MaxSSERetry.
+// Errors of the first connection, non-2xx statuses and a Content-Type other than text/event-stream end the
+// subscription with that error; done contexts return ctx.Err().
+// The response is exempt from ClientConfig.MaxResponseBytes and BodyReadTimeout, but http.Client.Timeout still
+// bounds every connection, so StreamSSE reconnects after it.
+func (c *Client) StreamSSE(ctx context.Context, path string, handler func(Event) error, opts ...RequestOption) error {
+	s := &eventStream{retry: DefaultSSERetry}
+	failures := 0
+	for attempt := 1; ; attempt++ {
+		err := s.connect(ctx, c, path, handler, opts)
+		if errors.Is(err, errStreamEnded) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		switch {
+		case err == nil:
+			failures = 0
+		case attempt > 1 && errors.Is(err, ErrRequestFailed):
+			// the server is unreachable: keep reconnecting
+			failures++
+		default:
+			return err
+		}
+		if err := sleep(ctx, s.wait(failures)); err != nil {
+			return err
+		}
+	}
+}
+
+// errStreamEnded is returned by connect when the server answered 204 No Content.
+var errStreamEnded = errors.New("event stream ended by the server")
+
+// eventStream keeps the reconnection state of a subscription.
+type eventStream struct {
+	lastID string
+	retry  time.Duration
+}
+
+// wait returns the delay before the next reconnect after the given number of consecutive failures.
+func (s *eventStream) wait(failures int) time.Duration {
+	d := s.retry
+	for range failures {
+		if d >= MaxSSERetry/2 {
+			return MaxSSERetry
+		}
+		d *= 2
+	}
+	return min(d, MaxSSERetry)
+}
+
+// connect opens the stream once and reads it until it drops; it returns nil when the stream should be reopened.
+func (s *eventStream) connect(ctx context.Context, c *Client, path string, handler func(Event) error, opts []RequestOption) error {
+	options := slices.Concat(opts, []RequestOption{
+		streaming(),
+		WithSetHeader("Accept", "text/event-stream"),
+		WithSetHeader("Cache-Control", "no-cache"),
+	})
+	if s.lastID != "" {
+		options = append(options, WithSetHeader("Last-Event-ID", s.lastID))
+	}
+	resp, err := c.Get(ctx, path, options...)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		resp.Body.Close()
+		return errStreamEnded
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return DecodeResponse(resp, nil)
+	}
+	defer resp.Body.Close()
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return fmt.Errorf("%w: content type '%s'%s", ErrNotEventStream, resp.Header.Get("Content-Type"), requestIDSuffix(resp.Request))
+	}
+	return s.read(resp.Body, handler)
+}
+
+// read parses the event stream and dispatches every complete event; read errors end the stream without an error,
+// an event cut off by the end of the stream is dropped.
+func (s *eventStream) read(r io.Reader, handler func(Event) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4<<10), 1<<20)
+	scanner.Split(scanEventLines)
+	var eventType string
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if data.Len() == 0 {
+				eventType = ""
+				continue
+			}
+			e := Event{ID: s.lastID, Type: eventType, Data: strings.TrimSuffix(data.String(), "\n")}
+			if e.Type == "" {
+				e.Type = "message"
+			}
+			eventType = ""
+			data.Reset()
+			if err := handler(e); err != nil {
+				return err
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 32); err == nil {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return nil
+}
+
+// scanEventLines splits lines ending in "\r\n", "\n" or "\r".
+func scanEventLines(data []byte, atEOF bool) (int, []byte, error) {
+	i := bytes.IndexAny(data, "\r\n")
+	switch {
+	case i < 0:
+		if atEOF && len(data) > 0 {
+			// a line cut off by the end of the stream is never part of a complete event
+			return len(data), nil, nil
+		}
+		return 0, nil, nil
+	case data[i] == '\n':
+		return i + 1, data[:i], nil
+	case i+1 < len(data):
+		if data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	case atEOF:
+		return i + 1, data[:i], nil
+	}
+	// a "\r" at the end of the buffer may be followed by "\n"
+	return 0, nil, nil
+}
@@ -0,0 +1,43 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttemptsOnResponse(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	resp, err := c.Get(context.Background(), "/orders")
+	require.NoError(t, err)
+
+	attempts := netcom.Attempts(resp)
+	require.Len(t, attempts, 1)
+	assert.Equal(t, http.StatusServiceUnavailable, attempts[0].Status)
+	assert.Contains(t, attempts[0].URL, "/orders")
+	assert.Positive(t, attempts[0].Duration)
+
+	err = netcom.DecodeResponse(resp, nil)
+	assert.ErrorIs(t, err, netcom.ErrBadStatusCode)
+	assert.Len(t, netcom.AttemptsFromError(err), 1)
+}
+
+func TestAttemptsOnTransportError(t *testing.T) {
+	c, err := netcom.NewClient(netcom.ClientConfig{BaseURL: "http://127.0.0.1:1"})
+	require.NoError(t, err)
+	_, err = c.Get(context.Background(), "/orders")
+	require.ErrorIs(t, err, netcom.ErrRequestFailed)
+
+	var ae *netcom.AttemptError
+	require.ErrorAs(t, err, &ae)
+	require.Len(t, ae.Attempts, 1)
+	assert.Zero(t, ae.Attempts[0].Status)
+	assert.Error(t, ae.Attempts[0].Err)
+	assert.Contains(t, ae.Attempts[0].String(), "url=http://127.0.0.1:1/orders")
+}
@@ -0,0 +1,122 @@
+package netcom_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenServer issues numbered tokens valid for expiresIn seconds and counts the token requests
+func tokenServer(t *testing.T, expiresIn int, issued *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "line-7" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client","error_description":"bad credentials"}`))
+			return
+		}
+		assert.Equal(t, "client_credentials", r.FormValue("grant_type"))
+		assert.Equal(t, "mes.read mes.write", r.FormValue("scope"))
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func credentials(tokenURL string) *netcom.ClientCredentialsConfig {
+	return &netcom.ClientCredentialsConfig{
+		TokenURL:     tokenURL,
+		ClientID:     "line-7",
+		ClientSecret: "s3cret",
+		Scopes:       []string{"mes.read", "mes.write"},
+	}
+}
+
+func TestAuthClientCredentials(t *testing.T) {
+	var issued atomic.Int32
+	tokens := tokenServer(t, 3600, &issued)
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Auth: credentials(tokens.URL)}, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization")))
+	})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.Get(ctx, "/orders")
+			if assert.NoError(t, err) {
+				body, err := netcom.ReadResponseBody(resp)
+				assert.NoError(t, err)
+				assert.Equal(t, "Bearer token-1", body)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), issued.Load(), "the token is cached")
+}
+
+func TestAuthRefreshBeforeExpiry(t *testing.T) {
+	var issued atomic.Int32
+	tokens := tokenServer(t, 30, &issued)
+	cc, err := netcom.NewClientCredentials(*credentials(tokens.URL))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// with the default refresh margin of a minute a 30s token is replaced on every use
+	first, err := cc.Token(ctx)
+	require.NoError(t, err)
+	second, err := cc.Token(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	config := *credentials(tokens.URL)
+	config.RefreshBefore = time.Second
+	cc, err = netcom.NewClientCredentials(config)
+	require.NoError(t, err)
+	first, _ = cc.Token(ctx)
+	second, _ = cc.Token(ctx)
+	assert.Equal(t, first, second)
+}
+
+func TestAuthRetriesRejectedToken(t *testing.T) {
+	var issued atomic.Int32
+	tokens := tokenServer(t, 3600, &issued)
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Auth: credentials(tokens.URL)}, func(w http.ResponseWriter, r *http.Request) {
+		// the API revoked the first token
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	resp, err := c.Get(context.Background(), "/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), issued.Load())
+}
+
+func TestAuthTokenError(t *testing.T) {
+	var issued atomic.Int32
+	tokens := tokenServer(t, 3600, &issued)
+	config := credentials(tokens.URL)
+	config.ClientSecret = "wrong"
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Auth: config}, func(w http.ResponseWriter, r *http.Request) {})
+
+	_, err := c.Get(context.Background(), "/orders")
+	assert.ErrorIs(t, err, netcom.ErrTokenFetchFailed)
+	assert.ErrorContains(t, err, "invalid_client")
+}
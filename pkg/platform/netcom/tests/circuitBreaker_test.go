@@ -0,0 +1,92 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	var mu sync.Mutex
+	var transitions []string
+	c, err := netcom.NewClient(netcom.ClientConfig{
+		BaseURL: srv.URL,
+		CircuitBreaker: &netcom.CircuitBreakerConfig{
+			FailureThreshold: 3,
+			Cooldown:         20 * time.Millisecond,
+			OnStateChange: func(host string, from, to netcom.CircuitState) {
+				mu.Lock()
+				defer mu.Unlock()
+				assert.Equal(t, u.Host, host)
+				transitions = append(transitions, from.String()+">"+to.String())
+			},
+		},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for range 3 {
+		resp, err := c.Get(ctx, "/orders")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, netcom.CircuitOpen, c.CircuitState(u.Host))
+
+	_, err = c.Get(ctx, "/orders")
+	assert.ErrorIs(t, err, netcom.ErrCircuitOpen)
+	assert.Equal(t, int32(3), calls.Load(), "an open circuit must not reach the server")
+	assert.Len(t, netcom.AttemptsFromError(err), 1)
+
+	// after the cooldown a failing trial reopens the circuit
+	time.Sleep(30 * time.Millisecond)
+	resp, err := c.Get(ctx, "/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, netcom.CircuitOpen, c.CircuitState(u.Host))
+
+	// and a successful one closes it
+	healthy.Store(true)
+	time.Sleep(30 * time.Millisecond)
+	resp, err = c.Get(ctx, "/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, netcom.CircuitClosed, c.CircuitState(u.Host))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}, transitions)
+}
+
+func TestCircuitBreakerStopsRetries(t *testing.T) {
+	var calls atomic.Int32
+	retry := netcom.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	c := newTestClientWithConfig(t, netcom.ClientConfig{
+		Retry:          &retry,
+		CircuitBreaker: &netcom.CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Hour},
+	}, flakyHandler(10, &calls, nil))
+
+	_, err := c.Get(context.Background(), "/orders")
+	assert.ErrorIs(t, err, netcom.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Len(t, netcom.AttemptsFromError(err), 3)
+}
@@ -0,0 +1,123 @@
+package netcom_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipRequestBody(t *testing.T) {
+	var calls atomic.Int32
+	retry := fastRetry
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Retry: &retry}, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		zr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":"A1"}`, string(body))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	resp, err := c.Put(context.Background(), "/orders/A1", strings.NewReader(`{"id":"A1"}`), netcom.WithGzipBody())
+	require.NoError(t, err)
+	require.NoError(t, netcom.DecodeResponse(resp, nil))
+	assert.Equal(t, int32(2), calls.Load(), "the compressed body is sent again on retry")
+}
+
+func TestDecompressResponse(t *testing.T) {
+	compress := map[string]func(io.Writer) io.WriteCloser{
+		"gzip":        func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate":     func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"raw deflate": func(w io.Writer) io.WriteCloser { fw, _ := flate.NewWriter(w, flate.DefaultCompression); return fw },
+	}
+	for name, newWriter := range compress {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				var buf bytes.Buffer
+				zw := newWriter(&buf)
+				zw.Write([]byte(`{"id":"A1","status":"ok"}`))
+				zw.Close()
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", strings.TrimPrefix(name, "raw "))
+				w.Write(buf.Bytes())
+			})
+			// setting Accept-Encoding keeps the transport from decompressing on its own
+			accept := netcom.WithSetHeader("Accept-Encoding", "gzip, deflate")
+
+			resp, err := c.Get(context.Background(), "/orders/A1", accept)
+			require.NoError(t, err)
+			var out orderResp
+			require.NoError(t, netcom.DecodeResponse(resp, &out))
+			assert.Equal(t, orderResp{ID: "A1", Status: "ok"}, out)
+
+			resp, err = c.Get(context.Background(), "/orders/A1", accept)
+			require.NoError(t, err)
+			body, err := netcom.ReadResponseBody(resp, netcom.WithMaxBodySize(100))
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"id":"A1","status":"ok"}`, body)
+		})
+	}
+}
+
+func TestDecompressCorruptResponse(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte("this is not a gzip stream"))
+	})
+	resp, err := c.Get(context.Background(), "/", netcom.WithSetHeader("Accept-Encoding", "gzip"))
+	require.NoError(t, err)
+	_, err = netcom.ReadResponseBody(resp)
+	assert.ErrorIs(t, err, netcom.ErrReadResponseFailed)
+	assert.ErrorIs(t, err, gzip.ErrHeader)
+}
+
+func TestDecompressedResponseLimit(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(bytes.Repeat([]byte{'0'}, 1<<20))
+	zw.Close()
+	require.Less(t, buf.Len(), 4096, "the compressed body stays below the limit")
+	c := newTestClientWithConfig(t, netcom.ClientConfig{MaxResponseBytes: 4096}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(buf.Bytes())
+	})
+	accept := netcom.WithSetHeader("Accept-Encoding", "gzip")
+
+	resp, err := c.Get(context.Background(), "/", accept)
+	require.NoError(t, err)
+	_, err = netcom.ReadResponseBody(resp)
+	assert.ErrorIs(t, err, netcom.ErrResponseTooLarge)
+
+	_, err = c.Download(context.Background(), "/", io.Discard, netcom.WithDownloadRequest(accept))
+	assert.ErrorIs(t, err, netcom.ErrResponseTooLarge)
+}
+
+func TestDownloadDecompresses(t *testing.T) {
+	content := strings.Repeat("2025-05-01,42\n", 1000)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(content))
+		zw.Close()
+	})
+
+	var buf bytes.Buffer
+	n, err := c.Download(context.Background(), "/export", &buf, netcom.WithDownloadRequest(netcom.WithSetHeader("Accept-Encoding", "gzip")))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), n)
+	assert.Equal(t, content, buf.String())
+}
@@ -0,0 +1,58 @@
+package netcom_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientMutationWhileInFlight reloads the base URL and default headers while requests are sent; run with -race
+func TestClientMutationWhileInFlight(t *testing.T) {
+	servers := make([]*httptest.Server, 2)
+	for i := range servers {
+		name := fmt.Sprintf("srv-%d", i)
+		servers[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name + " " + r.Header.Get("X-Plant")))
+		}))
+		t.Cleanup(servers[i].Close)
+	}
+	c, err := netcom.NewClient(netcom.ClientConfig{BaseURL: servers[0].URL})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reloaded := make(chan struct{})
+	go func() {
+		defer close(reloaded)
+		for i := 0; ctx.Err() == nil; i++ {
+			assert.NoError(t, c.SetBaseURL(servers[i%2].URL))
+			c.SetDefaultHeader("X-Plant", fmt.Sprintf("plant-%d", i%2))
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				resp, err := c.Get(context.Background(), "/status")
+				if !assert.NoError(t, err) {
+					return
+				}
+				body, err := netcom.ReadResponseBody(resp)
+				assert.NoError(t, err)
+				assert.Regexp(t, `^srv-[01] (plant-[01])?$`, body)
+			}
+		}()
+	}
+	wg.Wait()
+	cancel()
+	<-reloaded
+}
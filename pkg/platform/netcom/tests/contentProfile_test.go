@@ -0,0 +1,88 @@
+package netcom_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentProfileHeaders(t *testing.T) {
+	var got http.Header
+	profile := netcom.ContentProfile{Accept: "application/json", AcceptLanguage: "sl-SI, en;q=0.8"}
+	c := newTestClientWithConfig(t, netcom.ClientConfig{
+		Profile:        &profile,
+		DefaultHeaders: http.Header{"Accept-Language": {"de-DE"}},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	})
+
+	resp, err := c.Get(context.Background(), "/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "application/json", got.Get("Accept"))
+	assert.Equal(t, "de-DE", got.Get("Accept-Language"), "default headers win over the profile")
+
+	resp, err = c.Get(context.Background(), "/orders", netcom.WithContentProfile(netcom.XMLProfile))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "application/xml, text/xml", got.Get("Accept"))
+}
+
+func TestContentProfileExpect(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Profile: &netcom.JSONProfile}, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html><body>Session expired</body></html>"))
+		case "/problem":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.Write([]byte(`{"id":"A1","status":"rejected"}`))
+		default:
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"id":"A1","status":"open"}`))
+		}
+	})
+
+	var order orderResp
+	resp, err := c.Get(ctx, "/orders/A1")
+	require.NoError(t, err)
+	require.NoError(t, netcom.DecodeResponse(resp, &order))
+	assert.Equal(t, "open", order.Status)
+
+	resp, err = c.Get(ctx, "/problem")
+	require.NoError(t, err)
+	require.NoError(t, netcom.DecodeResponse(resp, &order))
+	assert.Equal(t, "rejected", order.Status)
+
+	resp, err = c.Get(ctx, "/login")
+	require.NoError(t, err)
+	err = netcom.DecodeResponse(resp, &order)
+	require.ErrorIs(t, err, netcom.ErrUnexpectedContentType)
+	var cte *netcom.ContentTypeError
+	require.ErrorAs(t, err, &cte)
+	assert.True(t, cte.HTML())
+	assert.Equal(t, http.StatusOK, cte.Status)
+	assert.Contains(t, cte.Snippet, "Session expired")
+	assert.NotEmpty(t, netcom.AttemptsFromError(err))
+
+	// the response option replaces the expectations of the profile
+	resp, err = c.Get(ctx, "/login")
+	require.NoError(t, err)
+	var page string
+	require.NoError(t, netcom.DecodeResponse(resp, &page, netcom.WithExpectedContentType("text/*"), netcom.WithDecoder(func(r io.Reader, v any) error {
+		b, err := io.ReadAll(r)
+		*v.(*string) = string(b)
+		return err
+	})))
+	assert.Contains(t, page, "Session expired")
+
+	getOrder := netcom.Endpoint[struct{}, orderResp]{Method: http.MethodGet, Path: "/login"}.MustBind(c)
+	_, err = getOrder(ctx, struct{}{})
+	assert.ErrorIs(t, err, netcom.ErrUnexpectedContentType)
+}
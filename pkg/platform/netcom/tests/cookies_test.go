@@ -0,0 +1,98 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionApp is a legacy web application issuing a session cookie on login.
+func sessionApp(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "s-1", Path: "/", HttpOnly: true})
+		case "/orders":
+			if cookie, err := r.Cookie("JSESSIONID"); err != nil || cookie.Value == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if lang, err := r.Cookie("lang"); err == nil {
+				w.Header().Set("Content-Language", lang.Value)
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientCookies(t *testing.T) {
+	srv := sessionApp(t)
+	c, err := netcom.NewClient(netcom.ClientConfig{BaseURL: srv.URL, EnableCookies: true})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	resp, err := c.Get(ctx, "/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = c.Post(ctx, "/login", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	session, err := c.Cookie("/orders", "JSESSIONID")
+	require.NoError(t, err)
+	assert.Equal(t, "s-1", session.Value)
+
+	require.NoError(t, c.SetCookies("/", &http.Cookie{Name: "lang", Value: "de"}))
+	resp, err = c.Get(ctx, "/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "de", resp.Header.Get("Content-Language"))
+
+	cookies, err := c.Cookies("/orders")
+	require.NoError(t, err)
+	assert.Len(t, cookies, 2)
+	_, err = c.Cookie("/orders", "missing")
+	assert.ErrorIs(t, err, http.ErrNoCookie)
+}
+
+func TestClientCookiesConfig(t *testing.T) {
+	srv := sessionApp(t)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	_, err := c.Cookies("/")
+	assert.ErrorIs(t, err, netcom.ErrNoCookieJar)
+	assert.ErrorIs(t, c.SetCookies("/", &http.Cookie{Name: "lang", Value: "de"}), netcom.ErrNoCookieJar)
+
+	jar := &countingJar{}
+	custom, err := netcom.NewClient(netcom.ClientConfig{BaseURL: srv.URL, CookieJar: jar})
+	require.NoError(t, err)
+	resp, err := custom.Post(context.Background(), "/login", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 1, jar.set)
+
+	_, err = netcom.NewClient(netcom.ClientConfig{EnableCookies: true, HTTPClient: http.DefaultClient})
+	assert.ErrorContains(t, err, "configuring cookies failed")
+}
+
+// countingJar records how often responses set cookies and never returns any.
+type countingJar struct {
+	set int
+}
+
+func (j *countingJar) SetCookies(_ *url.URL, cookies []*http.Cookie) {
+	j.set += len(cookies)
+}
+
+func (j *countingJar) Cookies(*url.URL) []*http.Cookie {
+	return nil
+}
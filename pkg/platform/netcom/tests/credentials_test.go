@@ -0,0 +1,60 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBasicAuth(t *testing.T) {
+	var user, pass string
+	c := newTestClientWithConfig(t, netcom.ClientConfig{BasicAuth: &netcom.BasicAuth{Username: "svc", Password: "pw"}},
+		func(w http.ResponseWriter, r *http.Request) {
+			user, pass, _ = r.BasicAuth()
+		})
+	ctx := context.Background()
+
+	resp, err := c.Get(ctx, "/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "svc", user)
+	assert.Equal(t, "pw", pass)
+
+	resp, err = c.Get(ctx, "/orders", netcom.WithBasicAuth("admin", "secret"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "admin", user, "request credentials replace the client ones")
+}
+
+func TestAPIKey(t *testing.T) {
+	var header, query string
+	c := newTestClientWithConfig(t, netcom.ClientConfig{APIKey: &netcom.APIKey{In: netcom.APIKeyInHeader, Name: "X-API-Key", Value: "k1"}},
+		func(w http.ResponseWriter, r *http.Request) {
+			header, query = r.Header.Get("X-API-Key"), r.URL.Query().Get("api_key")
+		})
+	ctx := context.Background()
+
+	resp, err := c.Get(ctx, "/orders", netcom.WithQueryParams(map[string]string{"plant": "7"}))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "k1", header)
+
+	resp, err = c.Get(ctx, "/orders", netcom.WithAPIKey(netcom.APIKeyInQuery, "api_key", "k2"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "k2", query)
+
+	_, err = c.Get(ctx, "/orders", netcom.WithAPIKey("cookie", "api_key", "k3"))
+	assert.ErrorIs(t, err, netcom.ErrRequestOptionFailed)
+}
+
+func TestCredentialsConfigErrors(t *testing.T) {
+	_, err := netcom.NewClient(netcom.ClientConfig{APIKey: &netcom.APIKey{In: netcom.APIKeyInHeader}})
+	assert.Error(t, err)
+	_, err = netcom.NewClient(netcom.ClientConfig{BasicAuth: &netcom.BasicAuth{Username: "svc"}, TokenProvider: netcom.StaticToken("t")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package netcom_test
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/datamanagement"
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type xmlOrder struct {
+	XMLName xml.Name `xml:"order"`
+	ID      string   `xml:"id,attr"`
+	Status  string   `xml:"status"`
+}
+
+func serveContent(contentType string, body []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}
+}
+
+func TestDecodeResponseByContentType(t *testing.T) {
+	ctx := context.Background()
+
+	c := newTestClient(t, serveContent("application/xml; charset=utf-8", []byte(`<order id="A1"><status>shipped</status></order>`)))
+	resp, err := c.Get(ctx, "/order")
+	require.NoError(t, err)
+	var order xmlOrder
+	require.NoError(t, netcom.DecodeResponse(resp, &order))
+	assert.Equal(t, xmlOrder{XMLName: xml.Name{Local: "order"}, ID: "A1", Status: "shipped"}, order)
+
+	packed, err := msgpack.Marshal(map[string]string{"id": "A2", "status": "open"})
+	require.NoError(t, err)
+	c = newTestClient(t, serveContent("application/msgpack", packed))
+	resp, err = c.Get(ctx, "/order")
+	require.NoError(t, err)
+	var m map[string]string
+	require.NoError(t, netcom.DecodeResponse(resp, &m))
+	assert.Equal(t, "open", m["status"])
+
+	c = newTestClient(t, serveContent("application/problem+json", []byte(`{"id":"A3","status":"failed"}`)))
+	resp, err = c.Get(ctx, "/order")
+	require.NoError(t, err)
+	var out orderResp
+	require.NoError(t, netcom.DecodeResponse(resp, &out))
+	assert.Equal(t, orderResp{ID: "A3", Status: "failed"}, out)
+
+	c = newTestClient(t, serveContent("application/octet-stream", []byte{0x01, 0x02, 0xff}))
+	resp, err = c.Get(ctx, "/blob")
+	require.NoError(t, err)
+	var raw []byte
+	require.NoError(t, netcom.DecodeResponse(resp, &raw))
+	assert.Equal(t, []byte{0x01, 0x02, 0xff}, raw)
+}
+
+func TestDecodeResponseCSV(t *testing.T) {
+	c := newTestClient(t, serveContent("text/csv", []byte("Order ID,qty\nA1,3\n\"A,2\",5\n")))
+	resp, err := c.Get(context.Background(), "/export")
+	require.NoError(t, err)
+	var df datamanagement.Dataframe
+	require.NoError(t, netcom.DecodeResponse(resp, &df))
+	assert.Equal(t, []string{"orderid", "qty"}, df.Header())
+	require.Len(t, df.Rows, 2)
+	assert.Equal(t, datamanagement.Record{"A,2", "5"}, df.Rows[1])
+
+	resp, err = c.Get(context.Background(), "/export")
+	require.NoError(t, err)
+	var out orderResp
+	assert.ErrorIs(t, netcom.DecodeResponse(resp, &out), netcom.ErrUnsupportedTarget)
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	upper := func(r io.Reader, v any) error {
+		b, err := io.ReadAll(r)
+		*v.(*string) = strings.ToUpper(string(b))
+		return err
+	}
+	netcom.RegisterDecoder("text/x-shout", upper)
+	t.Cleanup(func() { netcom.RegisterDecoder("text/x-shout", nil) })
+
+	c := newTestClient(t, serveContent("text/x-shout", []byte("line 4 down")))
+	resp, err := c.Get(context.Background(), "/status")
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, netcom.DecodeResponse(resp, &s))
+	assert.Equal(t, "LINE 4 DOWN", s)
+
+	// the option wins over the registry
+	c = newTestClient(t, serveContent("application/json", []byte("not json")))
+	resp, err = c.Get(context.Background(), "/status")
+	require.NoError(t, err)
+	require.NoError(t, netcom.DecodeResponse(resp, &s, netcom.WithDecoder(upper)))
+	assert.Equal(t, "NOT JSON", s)
+}
@@ -0,0 +1,89 @@
+package netcom_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownload(t *testing.T) {
+	content := strings.Repeat("2025-05-01,42\n", 50_000)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "plant7", r.URL.Query().Get("plant"))
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Write([]byte(content))
+	})
+
+	var buf bytes.Buffer
+	var calls int
+	var last, total int64
+	n, err := c.Download(context.Background(), "/export", &buf,
+		netcom.WithDownloadRequest(netcom.WithQueryParams(map[string]string{"plant": "plant7"})),
+		netcom.WithProgress(func(written, size int64) {
+			assert.GreaterOrEqual(t, written, last)
+			calls++
+			last, total = written, size
+		}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), n)
+	assert.Equal(t, content, buf.String())
+	assert.Greater(t, calls, 1)
+	assert.Equal(t, int64(len(content)), last)
+	assert.Equal(t, int64(len(content)), total)
+}
+
+func TestDownloadShortBody(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte("only ten.."))
+	})
+	var buf bytes.Buffer
+	n, err := c.Download(context.Background(), "/export", &buf)
+	assert.ErrorIs(t, err, netcom.ErrIncompleteDownload)
+	assert.Equal(t, int64(10), n)
+}
+
+func TestDownloadBadStatus(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such export", http.StatusNotFound)
+	})
+	var buf bytes.Buffer
+	_, err := c.Download(context.Background(), "/export", &buf)
+	assert.ErrorIs(t, err, netcom.ErrBadStatusCode)
+	assert.ErrorContains(t, err, "no such export")
+	assert.Zero(t, buf.Len())
+}
+
+func TestDownloadFile(t *testing.T) {
+	fail := false
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.Header().Set("Content-Length", "100")
+		}
+		w.Write([]byte("order,qty\nA1,3\n"))
+	})
+	file := filepath.Join(t.TempDir(), "orders.csv")
+
+	_, err := c.DownloadFile(context.Background(), "/export", file)
+	require.NoError(t, err)
+	got, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "order,qty\nA1,3\n", string(got))
+
+	// a failed download keeps the previous file and leaves no partial file behind
+	fail = true
+	_, err = c.DownloadFile(context.Background(), "/export", file)
+	assert.ErrorIs(t, err, netcom.ErrIncompleteDownload)
+	got, _ = os.ReadFile(file)
+	assert.Equal(t, "order,qty\nA1,3\n", string(got))
+	assert.NoFileExists(t, file+".part")
+}
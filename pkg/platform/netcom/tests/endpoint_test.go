@@ -0,0 +1,67 @@
+package netcom_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type getOrderReq struct {
+	ID    string `path:"id" json:"-"`
+	Plant string `query:"plant" json:"-"`
+}
+
+type orderResp struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+func newTestClient(t *testing.T, h http.HandlerFunc) *netcom.Client {
+	t.Helper()
+	return newTestClientWithConfig(t, netcom.ClientConfig{}, h)
+}
+
+func newTestClientWithConfig(t *testing.T, config netcom.ClientConfig, h http.HandlerFunc) *netcom.Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	config.BaseURL = srv.URL
+	c, err := netcom.NewClient(config)
+	require.NoError(t, err)
+	return c
+}
+
+func TestEndpointCall(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/orders/A%2F1", r.URL.EscapedPath())
+		assert.Equal(t, "gradec", r.URL.Query().Get("plant"))
+		json.NewEncoder(w).Encode(orderResp{ID: "A/1", Status: "open"})
+	})
+	getOrder := netcom.Endpoint[getOrderReq, orderResp]{Method: http.MethodGet, Path: "/orders/{id}"}.MustBind(c)
+
+	order, err := getOrder(context.Background(), getOrderReq{ID: "A/1", Plant: "gradec"})
+	require.NoError(t, err)
+	assert.Equal(t, orderResp{ID: "A/1", Status: "open"}, order)
+}
+
+func TestEndpointExpectedStatus(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	create := netcom.Endpoint[orderResp, orderResp]{Method: http.MethodPost, Path: "/orders", ExpectedStatus: []int{http.StatusCreated}}.MustBind(c)
+	_, err := create(context.Background(), orderResp{ID: "1"})
+	assert.ErrorIs(t, err, netcom.ErrBadStatusCode)
+}
+
+func TestEndpointValidate(t *testing.T) {
+	err := netcom.Endpoint[getOrderReq, orderResp]{Method: http.MethodGet, Path: "/orders/{order}"}.Validate()
+	assert.ErrorIs(t, err, netcom.ErrInvalidEndpoint)
+	err = netcom.Endpoint[getOrderReq, orderResp]{Method: "FETCH", Path: "/orders"}.Validate()
+	assert.ErrorIs(t, err, netcom.ErrInvalidEndpoint)
+}
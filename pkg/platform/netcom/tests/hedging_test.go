@@ -0,0 +1,87 @@
+package netcom_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHedgingReturnsTheFasterResponse(t *testing.T) {
+	var calls atomic.Int32
+	canceled := make(chan struct{}, 1)
+	c := newTestClientWithConfig(t, netcom.ClientConfig{
+		Middlewares: []netcom.Middleware{netcom.WithHedging(20*time.Millisecond, 2)},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// the first replica hangs until the hedge wins
+			select {
+			case <-r.Context().Done():
+				canceled <- struct{}{}
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		json.NewEncoder(w).Encode(orderResp{ID: "A1", Status: "open"})
+	})
+
+	start := time.Now()
+	resp, err := c.Get(context.Background(), "/orders/A1")
+	require.NoError(t, err)
+	var order orderResp
+	require.NoError(t, netcom.DecodeResponse(resp, &order))
+	assert.Equal(t, "open", order.Status)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.EqualValues(t, 2, calls.Load())
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the slower request was not canceled")
+	}
+}
+
+func TestHedgingSkipsWrites(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClientWithConfig(t, netcom.ClientConfig{
+		Middlewares: []netcom.Middleware{netcom.WithHedging(5*time.Millisecond, 1)},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(30 * time.Millisecond)
+	})
+
+	resp, err := c.PostJSON(context.Background(), "/confirmations", orderResp{ID: "A1"})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.EqualValues(t, 1, calls.Load())
+
+	// an idempotency key makes the write safe to duplicate
+	resp, err = c.PostJSON(context.Background(), "/confirmations", orderResp{ID: "A1"}, netcom.WithIdempotencyKey("confirm-A1"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.EqualValues(t, 3, calls.Load())
+}
+
+func TestHedgingAllFail(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClientWithConfig(t, netcom.ClientConfig{
+		Middlewares: []netcom.Middleware{netcom.WithHedging(5*time.Millisecond, 1)},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	resp, err := c.Get(context.Background(), "/orders/A1")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	resp.Body.Close()
+	assert.EqualValues(t, 2, calls.Load())
+}
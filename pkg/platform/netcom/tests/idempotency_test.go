@@ -0,0 +1,44 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeyRetries(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	retry := fastRetry
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Retry: &retry}, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get(netcom.IdempotencyKeyHeader))
+		if len(keys)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	resp, err := c.PostJSON(context.Background(), "/confirmations", orderResp{ID: "A1"}, netcom.WithNewIdempotencyKey())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, keys, 3)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, []string{keys[0], keys[0], keys[0]}, keys)
+	assert.Equal(t, keys[0], netcom.IdempotencyKey(resp.Request))
+
+	resp, err = c.PostJSON(context.Background(), "/confirmations", orderResp{ID: "A2"},
+		netcom.WithIdempotencyKey("confirm-A2"), netcom.WithNewIdempotencyKey())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"confirm-A2", "confirm-A2", "confirm-A2"}, keys[3:])
+
+	_, err = c.Post(context.Background(), "/confirmations", nil, netcom.WithIdempotencyKey(""))
+	assert.ErrorIs(t, err, netcom.ErrRequestOptionFailed)
+}
@@ -0,0 +1,70 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientMetrics(t *testing.T) {
+	var calls atomic.Int32
+	retry := fastRetry
+	c := newTestClientWithConfig(t, netcom.ClientConfig{
+		Retry:   &retry,
+		Metrics: &netcom.MetricsConfig{ConstLabels: prometheus.Labels{"client": "mes"}},
+	}, flakyHandler(1, &calls, nil))
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(c.Collector()))
+
+	resp, err := c.Get(context.Background(), "/orders/A1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	host := resp.Request.URL.Host
+
+	expected := `
+# HELP netcom_client_requests_total HTTP requests sent, including retries.
+# TYPE netcom_client_requests_total counter
+netcom_client_requests_total{client="mes",host="` + host + `",method="GET",status="200"} 1
+netcom_client_requests_total{client="mes",host="` + host + `",method="GET",status="503"} 1
+# HELP netcom_client_requests_in_flight HTTP requests waiting for their response headers.
+# TYPE netcom_client_requests_in_flight gauge
+netcom_client_requests_in_flight{client="mes",host="` + host + `",method="GET"} 0
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"netcom_client_requests_total", "netcom_client_requests_in_flight"))
+	// one latency series per status
+	assert.Equal(t, 2, testutil.CollectAndCount(c.Collector(), "netcom_client_request_duration_seconds"))
+}
+
+func TestClientMetricsError(t *testing.T) {
+	c, err := netcom.NewClient(netcom.ClientConfig{Metrics: &netcom.MetricsConfig{Namespace: "plant"}})
+	require.NoError(t, err)
+	_, err = c.Get(context.Background(), "http://127.0.0.1:1/orders")
+	require.Error(t, err)
+	u, _ := url.Parse("http://127.0.0.1:1")
+
+	expected := `
+# HELP plant_client_requests_total HTTP requests sent, including retries.
+# TYPE plant_client_requests_total counter
+plant_client_requests_total{host="` + u.Host + `",method="GET",status="error"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(c.Collector(), strings.NewReader(expected), "plant_client_requests_total"))
+}
+
+func TestClientWithoutMetrics(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	resp, err := c.Get(context.Background(), "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Zero(t, testutil.CollectAndCount(c.Collector()))
+	assert.NoError(t, prometheus.NewRegistry().Register(c.Collector()))
+}
@@ -0,0 +1,83 @@
+package netcom_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tagMiddleware appends name to the X-Chain header, so the order of the chain can be checked
+func tagMiddleware(name string) netcom.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return netcom.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Add("X-Chain", name)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Middlewares: []netcom.Middleware{tagMiddleware("config")}},
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(strings.Join(r.Header.Values("X-Chain"), ",")))
+		})
+	c.Use(tagMiddleware("auth"), tagMiddleware("log"))
+	ctx := context.Background()
+
+	resp, err := c.Get(ctx, "/orders")
+	require.NoError(t, err)
+	body, err := netcom.ReadResponseBody(resp)
+	require.NoError(t, err)
+	assert.Equal(t, "config,auth,log", body)
+
+	resp, err = c.PostJSON(ctx, "/orders", map[string]string{"wo": "A1"})
+	require.NoError(t, err)
+	body, err = netcom.ReadResponseBody(resp)
+	require.NoError(t, err)
+	assert.Equal(t, "config,auth,log", body)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) { calls.Add(1) })
+	c.Use(func(next http.RoundTripper) http.RoundTripper {
+		return netcom.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"id":"A1","status":"cached"}`)),
+				Request:    req,
+			}, nil
+		})
+	})
+
+	get := netcom.Endpoint[struct{}, orderResp]{Method: http.MethodGet, Path: "/orders/A1"}.MustBind(c)
+	out, err := get(context.Background(), struct{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "cached", out.Status)
+	assert.Zero(t, calls.Load())
+}
+
+func TestMiddlewareSeesEveryAttempt(t *testing.T) {
+	var calls, seen atomic.Int32
+	retry := fastRetry
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Retry: &retry}, flakyHandler(2, &calls, nil))
+	c.Use(func(next http.RoundTripper) http.RoundTripper {
+		return netcom.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			seen.Add(1)
+			return next.RoundTrip(req)
+		})
+	})
+
+	resp, err := c.Get(context.Background(), "/orders/A1")
+	require.NoError(t, err)
+	require.NoError(t, netcom.DecodeResponse(resp, nil))
+	assert.Equal(t, int32(3), seen.Load())
+}
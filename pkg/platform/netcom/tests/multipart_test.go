@@ -0,0 +1,51 @@
+package netcom_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostMultipart(t *testing.T) {
+	large := strings.Repeat("2025-05-01,42\n", 100_000)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "plant7", r.FormValue("plant"))
+		assert.Equal(t, "nightly", r.FormValue("batch"))
+
+		f, h, err := r.FormFile("report")
+		require.NoError(t, err)
+		defer f.Close()
+		assert.Equal(t, "orders.csv", h.Filename)
+		assert.Equal(t, "text/csv", h.Header.Get("Content-Type"))
+		content, _ := io.ReadAll(f)
+		assert.Equal(t, len(large), len(content))
+
+		_, h, err = r.FormFile("attachment")
+		require.NoError(t, err)
+		assert.Equal(t, "application/octet-stream", h.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	resp, err := c.PostMultipart(context.Background(), "/uploads",
+		map[string]string{"plant": "plant7", "batch": "nightly"},
+		[]netcom.FilePart{
+			{FieldName: "report", FileName: "orders.csv", ContentType: "text/csv", Content: strings.NewReader(large)},
+			{FieldName: "attachment", FileName: `note "1".bin`, Content: strings.NewReader("\x00\x01")},
+		})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
+
+func TestPostMultipartInvalidPart(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	_, err := c.PostMultipart(context.Background(), "/uploads", nil, []netcom.FilePart{{FieldName: "file"}})
+	assert.ErrorIs(t, err, netcom.ErrRequestCreationFailed)
+}
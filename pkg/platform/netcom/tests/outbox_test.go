@@ -0,0 +1,170 @@
+package netcom_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastOutbox = netcom.OutboxConfig{
+	Backoff: netcom.RetryPolicy{InitialBackoff: 5 * time.Millisecond, MaxBackoff: 20 * time.Millisecond},
+}
+
+// runOutbox runs the outbox until the test ends.
+func runOutbox(t *testing.T, o *netcom.Outbox) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- o.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+		o.Close()
+	})
+}
+
+func TestOutboxDeliversInOrderAfterOutage(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	keys := make(map[string]bool)
+	failures := 3
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "plant-7", r.Header.Get("X-Plant"))
+		assert.NotEmpty(t, r.Header.Get("Idempotency-Key"))
+		keys[r.Header.Get("Idempotency-Key")] = true
+		received = append(received, r.URL.Path+" "+string(body))
+	})
+
+	delivered := make(chan netcom.OutboxMessage, 3)
+	config := fastOutbox
+	config.OnDelivered = func(msg netcom.OutboxMessage) { delivered <- msg }
+	o, err := netcom.NewOutbox(c, filepath.Join(t.TempDir(), "outbox.db"), config)
+	require.NoError(t, err)
+	for _, id := range []string{"A1", "A2", "A3"} {
+		_, err := o.EnqueueJSON("/confirmations", orderResp{ID: id, Status: "done"}, http.Header{"X-Plant": {"plant-7"}})
+		require.NoError(t, err)
+	}
+	runOutbox(t, o)
+
+	first := <-delivered
+	assert.Equal(t, 3, first.Attempts)
+	assert.Contains(t, first.LastError, "503")
+	<-delivered
+	<-delivered
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		`/confirmations {"id":"A1","status":"done"}`,
+		`/confirmations {"id":"A2","status":"done"}`,
+		`/confirmations {"id":"A3","status":"done"}`,
+	}, received)
+	assert.Len(t, keys, 3)
+}
+
+func TestOutboxSurvivesRestart(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, string(body)+" "+r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+	})
+	path := filepath.Join(t.TempDir(), "outbox.db")
+
+	o, err := netcom.NewOutbox(c, path, fastOutbox)
+	require.NoError(t, err)
+	_, err = o.Enqueue("/confirmations", []byte("first"), nil)
+	require.NoError(t, err)
+	_, err = o.Enqueue("/confirmations", []byte("second"), nil)
+	require.NoError(t, err)
+	pending, err := o.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.NoError(t, o.Close())
+	_, err = o.Enqueue("/confirmations", []byte("lost"), nil)
+	assert.ErrorIs(t, err, netcom.ErrOutboxClosed)
+
+	delivered := make(chan netcom.OutboxMessage, 3)
+	config := fastOutbox
+	config.OnDelivered = func(msg netcom.OutboxMessage) { delivered <- msg }
+	o, err = netcom.NewOutbox(c, path, config)
+	require.NoError(t, err)
+	id, err := o.Enqueue("/confirmations", []byte("third"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), id)
+	runOutbox(t, o)
+
+	for range 3 {
+		<-delivered
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"first " + pending[0].Key,
+		"second " + pending[1].Key,
+	}, received[:2])
+	assert.Contains(t, received[2], "third ")
+}
+
+func TestOutboxDropsMessages(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "rejected" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte("unknown order"))
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	type drop struct {
+		body string
+		err  error
+	}
+	dropped := make(chan drop, 2)
+	config := fastOutbox
+	config.MaxAge = 50 * time.Millisecond
+	config.OnDropped = func(msg netcom.OutboxMessage, err error) { dropped <- drop{string(msg.Body), err} }
+	o, err := netcom.NewOutbox(c, filepath.Join(t.TempDir(), "outbox.db"), config)
+	require.NoError(t, err)
+	_, err = o.Enqueue("/confirmations", []byte("rejected"), nil)
+	require.NoError(t, err)
+	_, err = o.Enqueue("/confirmations", []byte("stale"), nil)
+	require.NoError(t, err)
+	runOutbox(t, o)
+
+	rejected := <-dropped
+	assert.Equal(t, "rejected", rejected.body)
+	assert.ErrorIs(t, rejected.err, netcom.ErrBadStatusCode)
+	assert.Contains(t, rejected.err.Error(), "unknown order")
+
+	select {
+	case stale := <-dropped:
+		assert.Equal(t, "stale", stale.body)
+		assert.True(t, errors.Is(stale.err, netcom.ErrOutboxExpired))
+	case <-time.After(time.Second):
+		t.Fatal("the stale message did not expire")
+	}
+	pending, err := o.Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
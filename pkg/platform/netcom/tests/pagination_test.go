@@ -0,0 +1,106 @@
+package netcom_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginateLinkHeader(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		assert.Equal(t, "gradec", r.URL.Query().Get("plant"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 2 {
+			w.Header().Add("Link", fmt.Sprintf(`</orders?plant=gradec&page=%d>; rel="next", </orders?page=0>; rel="first"`, page+1))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"id":"A%d"},{"id":"B%d"}]`, page, page)
+	})
+
+	var ids []string
+	for items, err := range netcom.Paginate[orderResp](context.Background(), c, "/orders?plant=gradec", nil) {
+		require.NoError(t, err)
+		for _, it := range items {
+			ids = append(ids, it.ID)
+		}
+	}
+	assert.Equal(t, []string{"A0", "B0", "A1", "B1", "A2", "B2"}, ids)
+
+	// breaking out of the loop sends no further requests
+	calls.Store(0)
+	for range netcom.Paginate[orderResp](context.Background(), c, "/orders?plant=gradec", nil) {
+		break
+	}
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+type cursorPage struct {
+	Items  []orderResp `json:"items"`
+	Cursor string      `json:"next_cursor"`
+}
+
+func TestPaginateToken(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Write([]byte(`{"items":[{"id":"A1"}],"next_cursor":"c2"}`))
+		case "c2":
+			w.Write([]byte(`{"items":[{"id":"A2"}],"next_cursor":"c3"}`))
+		default:
+			w.Write([]byte(`{"items":[{"id":"A3"}]}`))
+		}
+	})
+	cursor := func(resp *http.Response) ([]orderResp, string, error) {
+		var p cursorPage
+		err := json.NewDecoder(resp.Body).Decode(&p)
+		return p.Items, p.Cursor, err
+	}
+
+	var pages [][]orderResp
+	for items, err := range netcom.Paginate(context.Background(), c, "/orders?limit=1", cursor, netcom.WithPageToken("cursor")) {
+		require.NoError(t, err)
+		pages = append(pages, items)
+	}
+	assert.Equal(t, [][]orderResp{{{ID: "A1"}}, {{ID: "A2"}}, {{ID: "A3"}}}, pages)
+
+	pages = nil
+	for items, err := range netcom.Paginate(context.Background(), c, "/orders", cursor, netcom.WithPageToken("cursor"), netcom.WithMaxPages(2)) {
+		require.NoError(t, err)
+		pages = append(pages, items)
+	}
+	assert.Len(t, pages, 2)
+}
+
+func TestPaginateErrors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/loop":
+			w.Header().Set("Link", `</loop>; rel="next"`)
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	})
+
+	var errs []error
+	for _, err := range netcom.Paginate[orderResp](context.Background(), c, "/loop", nil) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 2)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], netcom.ErrPaginationLoop)
+
+	for _, err := range netcom.Paginate[orderResp](context.Background(), c, "/orders", nil) {
+		assert.ErrorIs(t, err, netcom.ErrBadStatusCode)
+	}
+}
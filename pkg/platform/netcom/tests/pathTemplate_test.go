@@ -0,0 +1,44 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandPath(t *testing.T) {
+	path, err := netcom.ExpandPath("/orders/{id}/items/{item}", map[string]string{"id": "A/1", "item": "10?x=1#top"})
+	require.NoError(t, err)
+	assert.Equal(t, "/orders/A%2F1/items/10%3Fx=1%23top", path)
+
+	for _, tc := range []struct {
+		template string
+		params   map[string]string
+	}{
+		{"/orders/{id}/items/{item}", map[string]string{"id": "A1"}},
+		{"/orders/{id}", map[string]string{"id": "A1", "plant": "gradec"}},
+		{"/orders/{id", map[string]string{"id": "A1"}},
+		{"/orders/{}", nil},
+	} {
+		_, err = netcom.ExpandPath(tc.template, tc.params)
+		assert.ErrorIs(t, err, netcom.ErrBadPathTemplate, tc.template)
+	}
+}
+
+func TestRequestTemplate(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/orders/A%2F1/items/10", r.URL.EscapedPath())
+	})
+	resp, err := c.RequestTemplate(context.Background(), http.MethodGet, "/orders/{id}/items/{item}",
+		map[string]string{"id": "A/1", "item": "10"}, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	_, err = c.RequestTemplate(context.Background(), http.MethodGet, "/orders/{id}", nil, nil)
+	assert.ErrorIs(t, err, netcom.ErrRequestCreationFailed)
+	assert.ErrorIs(t, err, netcom.ErrBadPathTemplate)
+}
@@ -0,0 +1,60 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/health":
+		w.Write([]byte(`{"status":"UP","db":"UP"}`))
+	case "/degraded":
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	retry := fastRetry
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Retry: &retry}, healthHandler)
+	ctx := context.Background()
+
+	res := c.Probe(ctx, "/health", http.StatusOK, `"status":"UP"`)
+	require.NoError(t, res.Err)
+	assert.True(t, res.Healthy())
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Positive(t, res.Latency)
+	assert.True(t, res.TLSExpiry.IsZero())
+
+	res = c.Probe(ctx, "/health", 0, `"status":"DOWN"`)
+	assert.ErrorIs(t, res.Err, netcom.ErrProbeBodyMismatch)
+
+	res = c.Probe(ctx, "/degraded", 0, "")
+	assert.ErrorIs(t, res.Err, netcom.ErrBadStatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, res.Status)
+	assert.False(t, res.Healthy())
+
+	res = c.Probe(ctx, "/missing", http.StatusNotFound, "")
+	assert.NoError(t, res.Err, "an expected non-2xx status is healthy")
+}
+
+func TestProbeTLSExpiry(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(healthHandler))
+	defer srv.Close()
+	c, err := netcom.NewClient(netcom.ClientConfig{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	require.NoError(t, err)
+
+	res := c.Probe(context.Background(), "/health", 0, "")
+	require.NoError(t, res.Err)
+	assert.Equal(t, srv.Certificate().NotAfter, res.TLSExpiry)
+	assert.Positive(t, res.TLSExpiryDays)
+	assert.Contains(t, res.String(), "tls_expiry_days=")
+}
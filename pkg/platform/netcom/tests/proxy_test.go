@@ -0,0 +1,97 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// forwardProxy answers the requests it is asked to forward and records their absolute URLs.
+type forwardProxy struct {
+	*httptest.Server
+	mu   sync.Mutex
+	urls []string
+	auth []string
+}
+
+func newForwardProxy(t *testing.T) *forwardProxy {
+	t.Helper()
+	p := new(forwardProxy)
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		p.urls = append(p.urls, r.URL.String())
+		p.auth = append(p.auth, r.Header.Get("Proxy-Authorization"))
+		p.mu.Unlock()
+		w.Write([]byte("via proxy"))
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *forwardProxy) forwarded() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.urls...)
+}
+
+func TestClientProxy(t *testing.T) {
+	proxy := newForwardProxy(t)
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	proxyURL.User = url.UserPassword("plant", "secret")
+
+	c, err := netcom.NewClient(netcom.ClientConfig{
+		BaseURL: "http://orders.plant.invalid",
+		Proxy:   &netcom.ProxyConfig{URL: proxyURL.String(), NoProxy: []string{".direct.invalid"}},
+	})
+	require.NoError(t, err)
+
+	resp, err := c.Get(context.Background(), "/orders/A1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"http://orders.plant.invalid/orders/A1"}, proxy.forwarded())
+	assert.Equal(t, "Basic cGxhbnQ6c2VjcmV0", proxy.auth[0])
+
+	// hosts in the NO_PROXY list are dialled directly, which fails for the reserved .invalid domain
+	_, err = c.Get(context.Background(), "http://mes.direct.invalid/orders")
+	assert.Error(t, err)
+	assert.Len(t, proxy.forwarded(), 1)
+}
+
+func TestClientProxyFromEnvironment(t *testing.T) {
+	proxy := newForwardProxy(t)
+	t.Setenv("HTTP_PROXY", proxy.URL)
+	t.Setenv("NO_PROXY", "")
+
+	c, err := netcom.NewClient(netcom.ClientConfig{Proxy: &netcom.ProxyConfig{FromEnvironment: true}})
+	require.NoError(t, err)
+	resp, err := c.Get(context.Background(), "http://orders.plant.invalid/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"http://orders.plant.invalid/orders"}, proxy.forwarded())
+
+	// an empty configuration ignores the environment
+	direct, err := netcom.NewClient(netcom.ClientConfig{Proxy: &netcom.ProxyConfig{}})
+	require.NoError(t, err)
+	_, err = direct.Get(context.Background(), "http://orders.plant.invalid/orders")
+	assert.Error(t, err)
+	assert.Len(t, proxy.forwarded(), 1)
+}
+
+func TestClientProxyConfigErrors(t *testing.T) {
+	for name, config := range map[string]netcom.ClientConfig{
+		"scheme":      {Proxy: &netcom.ProxyConfig{URL: "ftp://proxy.plant.local"}},
+		"environment": {Proxy: &netcom.ProxyConfig{URL: "http://proxy.plant.local:3128", FromEnvironment: true}},
+		"http client": {Proxy: &netcom.ProxyConfig{URL: "http://proxy.plant.local:3128"}, HTTPClient: http.DefaultClient},
+	} {
+		_, err := netcom.NewClient(config)
+		assert.ErrorContains(t, err, "configuring proxy failed", name)
+	}
+}
@@ -0,0 +1,58 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	c := newTestClientWithConfig(t, netcom.ClientConfig{
+		Middlewares: []netcom.Middleware{netcom.WithRateLimit(50, 2)},
+	}, func(w http.ResponseWriter, r *http.Request) {})
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 4 {
+		resp, err := c.Get(ctx, "/orders")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	// the burst of two is free, the other two wait 20ms each
+	assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+
+	short, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+	defer cancel()
+	_, err := c.Get(short, "/orders")
+	assert.ErrorIs(t, err, netcom.ErrRequestFailed, "the budget is spent and the wait outlasts the context")
+
+	resp, err := c.Get(ctx, "/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestHostRateLimit(t *testing.T) {
+	srvA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srvA.Close()
+	srvB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srvB.Close()
+	c, err := netcom.NewClient(netcom.ClientConfig{})
+	require.NoError(t, err)
+	c.Use(netcom.WithHostRateLimit(1, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	for _, u := range []string{srvA.URL, srvB.URL} {
+		resp, err := c.Get(ctx, u)
+		require.NoError(t, err, "every host has a budget of its own")
+		resp.Body.Close()
+	}
+	_, err = c.Get(ctx, srvA.URL)
+	assert.Error(t, err, "the second request to a host waits a second")
+}
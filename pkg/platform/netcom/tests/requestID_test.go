@@ -0,0 +1,40 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDInjection(t *testing.T) {
+	var seen, traceparent string
+	c := newTestClientWithConfig(t, netcom.ClientConfig{RequestIDHeader: netcom.DefaultRequestIDHeader, Traceparent: true}, func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Get(netcom.DefaultRequestIDHeader)
+		traceparent = r.Header.Get(netcom.TraceparentHeader)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	ctx := netcom.ContextWithRequestID(context.Background(), "req-42")
+	ctx = netcom.ContextWithTraceparent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := c.Get(ctx, "/orders")
+	require.NoError(t, err)
+	assert.Equal(t, "req-42", seen)
+	assert.True(t, strings.HasPrefix(traceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-"))
+	assert.NotContains(t, traceparent, "00f067aa0ba902b7")
+
+	err = netcom.DecodeResponse(resp, nil)
+	assert.ErrorIs(t, err, netcom.ErrBadStatusCode)
+	assert.Contains(t, err.Error(), "request_id=req-42")
+
+	// without an ID in the context a new one is generated
+	resp, err = c.Get(context.Background(), "/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Len(t, seen, 36)
+	assert.Equal(t, seen, netcom.RequestID(resp.Request))
+}
@@ -0,0 +1,54 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseSizeLimit(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 2048)))
+	})
+	resp, err := c.Get(context.Background(), "/big")
+	require.NoError(t, err)
+	_, err = netcom.ReadResponseBody(resp, netcom.WithMaxBodySize(1024))
+	assert.ErrorIs(t, err, netcom.ErrResponseTooLarge)
+
+	resp, err = c.Get(context.Background(), "/big")
+	require.NoError(t, err)
+	body, err := netcom.ReadResponseBody(resp, netcom.WithMaxBodySize(2048))
+	require.NoError(t, err)
+	assert.Len(t, body, 2048)
+}
+
+func TestResponseSizeLimitFromConfig(t *testing.T) {
+	c := newTestClientWithConfig(t, netcom.ClientConfig{MaxResponseBytes: 64}, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"` + strings.Repeat("x", 512) + `"}`))
+	})
+	resp, err := c.Get(context.Background(), "/order")
+	require.NoError(t, err)
+	var out orderResp
+	assert.ErrorIs(t, netcom.DecodeResponse(resp, &out), netcom.ErrResponseTooLarge)
+}
+
+func TestBodyReadTimeout(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+	resp, err := c.Get(context.Background(), "/slow")
+	require.NoError(t, err)
+	_, err = netcom.ReadResponseBody(resp, netcom.WithBodyReadTimeout(50*time.Millisecond))
+	assert.ErrorIs(t, err, netcom.ErrBodyReadTimeout)
+}
@@ -0,0 +1,115 @@
+package netcom_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetry = netcom.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+// flakyHandler fails the first n requests with 503 and records the bodies it received
+func flakyHandler(n int32, calls *atomic.Int32, bodies *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if bodies != nil {
+			*bodies = append(*bodies, string(b))
+		}
+		if calls.Add(1) <= n {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id":"A1","status":"ok"}`))
+	}
+}
+
+func TestRetryIdempotentRequest(t *testing.T) {
+	var calls atomic.Int32
+	retry := fastRetry
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Retry: &retry}, flakyHandler(2, &calls, nil))
+	resp, err := c.Get(context.Background(), "/orders/A1")
+	require.NoError(t, err)
+	var out orderResp
+	require.NoError(t, netcom.DecodeResponse(resp, &out))
+	assert.Equal(t, "ok", out.Status)
+	assert.Equal(t, int32(3), calls.Load())
+
+	attempts := netcom.Attempts(resp)
+	require.Len(t, attempts, 3)
+	assert.Equal(t, http.StatusServiceUnavailable, attempts[0].Status)
+	assert.Greater(t, attempts[0].Backoff, time.Duration(0))
+	assert.Zero(t, attempts[2].Backoff)
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	retry := fastRetry
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Retry: &retry}, flakyHandler(10, &calls, nil))
+	resp, err := c.Get(context.Background(), "/orders/A1")
+	require.NoError(t, err)
+	err = netcom.DecodeResponse(resp, nil)
+	assert.ErrorIs(t, err, netcom.ErrBadStatusCode)
+	assert.Len(t, netcom.AttemptsFromError(err), 3)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRetryPostBodies(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	retry := fastRetry
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Retry: &retry}, flakyHandler(1, &calls, &bodies))
+
+	// POST is not idempotent and is sent once
+	resp, err := c.Post(context.Background(), "/orders", bytes.NewReader([]byte(`{"wo":"A1"}`)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// an idempotency key allows the retry and the body is sent again
+	calls.Store(0)
+	bodies = nil
+	resp, err = c.Post(context.Background(), "/orders", bytes.NewReader([]byte(`{"wo":"A2"}`)), netcom.WithSetHeader("Idempotency-Key", "wo-A2"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{`{"wo":"A2"}`, `{"wo":"A2"}`}, bodies)
+}
+
+func TestWithRetryPolicyOverridesClient(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, flakyHandler(1, &calls, nil))
+	resp, err := c.Get(context.Background(), "/orders/A1", netcom.WithRetryPolicy(fastRetry))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRetryConnectionErrors(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	retry := fastRetry
+	c, err := netcom.NewClient(netcom.ClientConfig{BaseURL: srv.URL, Retry: &retry})
+	require.NoError(t, err)
+	_, err = c.Get(context.Background(), "/orders")
+	assert.ErrorIs(t, err, netcom.ErrRequestFailed)
+	assert.Len(t, netcom.AttemptsFromError(err), 3)
+
+	// a cancelled context stops waiting for the next attempt
+	slow := netcom.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.Get(ctx, "/orders", netcom.WithRetryPolicy(slow))
+	assert.ErrorIs(t, err, netcom.ErrRequestFailed)
+	assert.Len(t, netcom.AttemptsFromError(err), 1)
+}
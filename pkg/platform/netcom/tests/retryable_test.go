@@ -0,0 +1,90 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryableError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/quota":
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/maintenance":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	})
+	ctx := context.Background()
+
+	resp, err := c.Get(ctx, "/quota")
+	require.NoError(t, err)
+	err = netcom.DecodeResponse(resp, nil)
+	assert.ErrorIs(t, err, netcom.ErrBadStatusCode)
+	var re *netcom.RetryableError
+	require.ErrorAs(t, err, &re)
+	assert.Equal(t, http.StatusTooManyRequests, re.Status)
+	wait, ok := netcom.RetryAfterFromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, wait)
+	assert.NotEmpty(t, netcom.AttemptsFromError(err))
+
+	resp, err = c.Get(ctx, "/maintenance")
+	require.NoError(t, err)
+	_, err = netcom.ReadResponseBody(resp)
+	wait, ok = netcom.RetryAfterFromError(err)
+	assert.True(t, ok)
+	assert.Zero(t, wait)
+
+	resp, err = c.Get(ctx, "/other")
+	require.NoError(t, err)
+	err = netcom.DecodeResponse(resp, nil)
+	assert.ErrorIs(t, err, netcom.ErrBadStatusCode)
+	_, ok = netcom.RetryAfterFromError(err)
+	assert.False(t, ok)
+
+	getOrder := netcom.Endpoint[struct{}, orderResp]{Method: http.MethodGet, Path: "/quota"}.MustBind(c)
+	_, err = getOrder(ctx, struct{}{})
+	assert.ErrorAs(t, err, &re)
+}
+
+func TestRetryMaxRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch {
+		case r.URL.Path == "/slow":
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case n == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	})
+	retry := netcom.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetryAfter: time.Second}
+	ctx := context.Background()
+
+	resp, err := c.Get(ctx, "/orders", netcom.WithRetryPolicy(retry))
+	require.NoError(t, err)
+	require.NoError(t, netcom.DecodeResponse(resp, nil))
+	assert.EqualValues(t, 2, calls.Load())
+
+	// an hour is beyond MaxRetryAfter: the response is returned at once instead of waiting or retrying early
+	calls.Store(0)
+	resp, err = c.Get(ctx, "/slow", netcom.WithRetryPolicy(retry))
+	require.NoError(t, err)
+	err = netcom.DecodeResponse(resp, nil)
+	wait, ok := netcom.RetryAfterFromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Hour, wait)
+	assert.EqualValues(t, 1, calls.Load())
+}
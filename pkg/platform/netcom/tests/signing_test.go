@@ -0,0 +1,72 @@
+package netcom_test
+
+import (
+	"context"
+	"crypto/sha512"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHMACSigning(t *testing.T) {
+	signing := netcom.HMACSigningConfig{
+		Key:   []byte("plant-secret"),
+		KeyID: "line-7",
+		Now:   func() time.Time { return time.Unix(1717200000, 0) },
+	}
+	var mu sync.Mutex
+	var attempts int
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Middlewares: []netcom.Middleware{netcom.WithHMACSigning(signing)}},
+		func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, "1717200000", r.Header.Get(netcom.DefaultTimestampHeader))
+			assert.Equal(t, "line-7", r.Header.Get(netcom.DefaultKeyIDHeader))
+			want := signing.Sign(r.Method, r.URL.RequestURI(), r.Header.Get(netcom.DefaultTimestampHeader), body)
+			assert.Equal(t, want, r.Header.Get(netcom.DefaultSignatureHeader))
+			mu.Lock()
+			defer mu.Unlock()
+			attempts++
+			if attempts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		})
+
+	resp, err := c.PostJSON(context.Background(), "/orders?plant=gradec", orderResp{ID: "A1"},
+		netcom.WithIdempotencyKey("A1"), netcom.WithRetryPolicy(fastRetry))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+
+	// the signature is a hex HMAC-SHA256 over method, request URI, timestamp and body
+	assert.Equal(t, "4d9a159cc0bf8c0b9e18ba80c2886024b1fabf3e2c78a2ad8cecbc3b01ce729d",
+		signing.Sign("GET", "/orders?plant=gradec", "1717200000", nil))
+	custom := netcom.HMACSigningConfig{Key: []byte("plant-secret"), Hash: sha512.New, Base64: true}
+	assert.Equal(t, "N/k+0qIhFrWyFUeDp+apBkPpNgZNoO8KsWd7w7o481CTVnAeW2OOJWZpKjphSLpk6Jtuem3dMkAV+QNYoVWz4w==",
+		custom.Sign("GET", "/orders?plant=gradec", "1717200000", []byte("{}")))
+}
+
+func TestHMACSigningHeaders(t *testing.T) {
+	signing := netcom.HMACSigningConfig{Key: []byte("plant-secret"), SignatureHeader: "X-MES-Signature", TimestampHeader: "X-MES-Time"}
+	c := newTestClientWithConfig(t, netcom.ClientConfig{Middlewares: []netcom.Middleware{netcom.WithHMACSigning(signing)}},
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.NotEmpty(t, r.Header.Get("X-MES-Signature"))
+			assert.NotEmpty(t, r.Header.Get("X-MES-Time"))
+			assert.Empty(t, r.Header.Get(netcom.DefaultKeyIDHeader))
+		})
+	resp, err := c.Get(context.Background(), "/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	unsigned := newTestClientWithConfig(t, netcom.ClientConfig{Middlewares: []netcom.Middleware{netcom.WithHMACSigning(netcom.HMACSigningConfig{})}},
+		func(w http.ResponseWriter, r *http.Request) {})
+	_, err = unsigned.Get(context.Background(), "/orders")
+	assert.ErrorContains(t, err, "HMAC key is empty")
+}
@@ -0,0 +1,108 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLOTracking(t *testing.T) {
+	var fail atomic.Bool
+	var alerts []netcom.SLOStatus
+	c := newTestClientWithConfig(t, netcom.ClientConfig{
+		SLO: &netcom.SLOConfig{
+			Objectives:        []netcom.SLO{{Endpoint: "orders", Objective: 0.9}},
+			BurnRateThreshold: 5,
+			BurnMinRequests:   5,
+			OnBurn:            func(s netcom.SLOStatus) { alerts = append(alerts, s) },
+		},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	get := func(opts ...netcom.RequestOption) {
+		resp, err := c.Get(context.Background(), "/orders", opts...)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	for range 9 {
+		get(netcom.WithEndpointName("orders"))
+	}
+	// untagged requests and endpoints without an objective are not tracked
+	fail.Store(true)
+	get()
+	get(netcom.WithEndpointName("stock"))
+	status, ok := c.SLOStatus("orders")
+	require.True(t, ok)
+	assert.Equal(t, int64(9), status.Requests)
+	assert.Equal(t, 1.0, status.BudgetRemaining)
+	assert.True(t, status.Healthy())
+	_, ok = c.SLOStatus("stock")
+	assert.False(t, ok)
+
+	get(netcom.WithEndpointName("orders"))
+	status, _ = c.SLOStatus("orders")
+	assert.InDelta(t, 0.9, status.SuccessRate, 1e-9)
+	assert.InDelta(t, 0.0, status.BudgetRemaining, 1e-9)
+	assert.False(t, status.Healthy())
+	assert.Empty(t, alerts, "the burn rate of 1 is below the threshold")
+
+	for range 10 {
+		get(netcom.WithEndpointName("orders"))
+	}
+	require.Len(t, alerts, 1, "alerts once per alert window")
+	assert.Equal(t, "orders", alerts[0].Endpoint)
+	assert.GreaterOrEqual(t, alerts[0].BurnRate, 5.0)
+	// success rate, budget, burn rate and objective of the one objective
+	assert.Equal(t, 4, testutil.CollectAndCount(c.Collector()))
+}
+
+func TestSLOEndpointName(t *testing.T) {
+	c := newTestClientWithConfig(t, netcom.ClientConfig{
+		Metrics: &netcom.MetricsConfig{Namespace: "plant"},
+		SLO:     &netcom.SLOConfig{Objectives: []netcom.SLO{{Endpoint: "get-order", Objective: 0.99}}},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"A1"}`))
+	})
+	call := netcom.Endpoint[struct{}, map[string]any]{Name: "get-order", Method: http.MethodGet, Path: "/orders"}.MustBind(c)
+	_, err := call(context.Background(), struct{}{})
+	require.NoError(t, err)
+
+	expected := `
+# HELP plant_slo_error_budget_remaining_ratio Share of the error budget left over the SLO window; negative once overspent.
+# TYPE plant_slo_error_budget_remaining_ratio gauge
+plant_slo_error_budget_remaining_ratio{endpoint="get-order"} 1
+# HELP plant_slo_success_ratio Share of good requests over the SLO window.
+# TYPE plant_slo_success_ratio gauge
+plant_slo_success_ratio{endpoint="get-order"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(c.Collector(), strings.NewReader(expected),
+		"plant_slo_error_budget_remaining_ratio", "plant_slo_success_ratio"))
+	assert.Equal(t, []netcom.SLOStatus{{
+		SLO:             netcom.SLO{Endpoint: "get-order", Objective: 0.99, Window: netcom.DefaultSLOWindow},
+		Requests:        1,
+		SuccessRate:     1,
+		BudgetRemaining: 1,
+	}}, c.SLOStatuses())
+}
+
+func TestSLOInvalid(t *testing.T) {
+	for _, objectives := range [][]netcom.SLO{
+		{{Endpoint: "orders", Objective: 1}},
+		{{Objective: 0.9}},
+		{{Endpoint: "orders", Objective: 0.9}, {Endpoint: "orders", Objective: 0.99}},
+	} {
+		_, err := netcom.NewClient(netcom.ClientConfig{SLO: &netcom.SLOConfig{Objectives: objectives}})
+		assert.ErrorIs(t, err, netcom.ErrInvalidSLO)
+	}
+}
@@ -0,0 +1,94 @@
+package netcom_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamSSE(t *testing.T) {
+	var connections atomic.Int32
+	var lastIDs []string
+	c := newTestClientWithConfig(t, netcom.ClientConfig{MaxResponseBytes: 16}, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		switch connections.Add(1) {
+		case 1:
+			w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+			fmt.Fprint(w, ": keep-alive\nretry: 5\n\n")
+			fmt.Fprint(w, "id: 1\nevent: order\ndata: {\"id\":\"A1\",\ndata:\"status\":\"released\"}\n\n")
+			w.(http.Flusher).Flush()
+			fmt.Fprint(w, "id: 2\r\ndata: line 4 stopped\r\n\r\n")
+			// the connection drops in the middle of an event
+			fmt.Fprint(w, "id: 3\ndata: cut off")
+		case 2:
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: first\ndata: second\r\r")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	var events []netcom.Event
+	err := c.StreamSSE(context.Background(), "/events", func(e netcom.Event) error {
+		events = append(events, e)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []netcom.Event{
+		{ID: "1", Type: "order", Data: "{\"id\":\"A1\",\n\"status\":\"released\"}"},
+		{ID: "2", Type: "message", Data: "line 4 stopped"},
+		{ID: "3", Type: "message", Data: "first\nsecond"},
+	}, events)
+	assert.Equal(t, []string{"", "3", "3"}, lastIDs, "reconnects resume after the last event ID")
+}
+
+func TestStreamSSEStops(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; r.Context().Err() == nil; i++ {
+			fmt.Fprintf(w, "id: %d\ndata: tick\n\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	stop := errors.New("enough")
+	var n int
+	err := c.StreamSSE(context.Background(), "/ticks", func(e netcom.Event) error {
+		if n++; n == 3 {
+			return stop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, stop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = c.StreamSSE(ctx, "/ticks", func(netcom.Event) error { return nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestStreamSSEErrors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	})
+	handler := func(netcom.Event) error { return nil }
+
+	assert.ErrorIs(t, c.StreamSSE(context.Background(), "/json", handler), netcom.ErrNotEventStream)
+	assert.ErrorIs(t, c.StreamSSE(context.Background(), "/events", handler), netcom.ErrBadStatusCode)
+}
@@ -0,0 +1,116 @@
+package netcom_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clientCertificate returns a self-signed client certificate and its key as PEM.
+func clientCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "line-7"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestClientMutualTLS(t *testing.T) {
+	certPEM, keyPEM := clientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(certPEM))
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "plant-ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	c, err := netcom.NewClient(netcom.ClientConfig{
+		BaseURL: srv.URL,
+		TLS:     &netcom.TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, MinVersion: tls.VersionTLS13},
+	})
+	require.NoError(t, err)
+	resp, err := c.Get(context.Background(), "/whoami")
+	require.NoError(t, err)
+	name, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "line-7", string(name))
+
+	// the server rejects clients without a certificate
+	anonymous, err := netcom.NewClient(netcom.ClientConfig{BaseURL: srv.URL, TLS: &netcom.TLSConfig{CAFile: caFile}})
+	require.NoError(t, err)
+	_, err = anonymous.Get(context.Background(), "/whoami")
+	assert.Error(t, err)
+
+	// the system roots do not know the test server
+	system, err := netcom.NewClient(netcom.ClientConfig{BaseURL: srv.URL, TLS: &netcom.TLSConfig{CertPEM: certPEM, KeyPEM: keyPEM}})
+	require.NoError(t, err)
+	_, err = system.Get(context.Background(), "/whoami")
+	assert.Error(t, err)
+
+	insecure, err := netcom.NewClient(netcom.ClientConfig{
+		BaseURL: srv.URL,
+		TLS:     &netcom.TLSConfig{CertPEM: certPEM, KeyPEM: keyPEM, InsecureSkipVerify: true},
+	})
+	require.NoError(t, err)
+	resp, err = insecure.Get(context.Background(), "/whoami")
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestClientTLSConfigErrors(t *testing.T) {
+	certPEM, keyPEM := clientCertificate(t)
+	for name, config := range map[string]netcom.ClientConfig{
+		"version":     {TLS: &netcom.TLSConfig{MinVersion: 0x0200}},
+		"ca":          {TLS: &netcom.TLSConfig{CAPEM: []byte("not a certificate")}},
+		"ca file":     {TLS: &netcom.TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		"key":         {TLS: &netcom.TLSConfig{CertPEM: certPEM}},
+		"both":        {TLS: &netcom.TLSConfig{CertPEM: certPEM, KeyPEM: keyPEM, CertFile: "client.pem", KeyFile: "client.key"}},
+		"http client": {TLS: &netcom.TLSConfig{}, HTTPClient: http.DefaultClient},
+	} {
+		_, err := netcom.NewClient(config)
+		assert.ErrorContains(t, err, "configuring TLS failed", name)
+	}
+}
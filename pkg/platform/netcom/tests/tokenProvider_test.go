@@ -0,0 +1,74 @@
+package netcom_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func echoAuthorization(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(r.Header.Get("Authorization")))
+}
+
+func TestStaticTokenProvider(t *testing.T) {
+	c := newTestClientWithConfig(t, netcom.ClientConfig{TokenProvider: netcom.StaticToken("api-key")}, echoAuthorization)
+	resp, err := c.Get(context.Background(), "/orders")
+	require.NoError(t, err)
+	body, err := netcom.ReadResponseBody(resp)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer api-key", body)
+}
+
+func TestTokenProviderError(t *testing.T) {
+	failing := netcom.TokenProviderFunc(func(ctx context.Context) (string, error) {
+		return "", errors.New("identity provider unreachable")
+	})
+	c := newTestClientWithConfig(t, netcom.ClientConfig{TokenProvider: failing}, echoAuthorization)
+	_, err := c.Get(context.Background(), "/orders")
+	assert.ErrorIs(t, err, netcom.ErrTokenFetchFailed)
+	assert.ErrorContains(t, err, "identity provider unreachable")
+}
+
+func TestCachingTokenProvider(t *testing.T) {
+	var fetches atomic.Int32
+	expiresIn := time.Hour
+	p := netcom.NewCachingTokenProvider(func(ctx context.Context) (string, time.Time, error) {
+		n := fetches.Add(1)
+		return fmt.Sprintf("token-%d", n), time.Now().Add(expiresIn), nil
+	}, 10*time.Minute)
+	ctx := context.Background()
+
+	for range 3 {
+		tok, err := p.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", tok)
+	}
+
+	p.Invalidate()
+	tok, err := p.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", tok)
+
+	// a token expiring within the refresh margin is replaced on every use
+	expiresIn = 5 * time.Minute
+	p.Invalidate()
+	first, _ := p.Token(ctx)
+	second, _ := p.Token(ctx)
+	assert.NotEqual(t, first, second)
+}
+
+func TestAuthAndTokenProviderExclusive(t *testing.T) {
+	_, err := netcom.NewClient(netcom.ClientConfig{
+		Auth:          credentials("https://login.example.com/token"),
+		TokenProvider: netcom.StaticToken("api-key"),
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package netcom_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func spanAttrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracingSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	var traceparent atomic.Value
+	var calls atomic.Int32
+	retry := fastRetry
+	c := newTestClientWithConfig(t, netcom.ClientConfig{
+		Tracing: &netcom.TracingConfig{TracerProvider: tp},
+		Retry:   &retry,
+	}, func(w http.ResponseWriter, r *http.Request) {
+		traceparent.Store(r.Header.Get("traceparent"))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	parentCtx, parent := tp.Tracer("test").Start(context.Background(), "sync orders")
+	resp, err := c.Get(parentCtx, "/orders/1001")
+	require.NoError(t, err)
+	resp.Body.Close()
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "GET", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Equal(t, codes.Error, span.Status().Code)
+
+	attrs := spanAttrs(span)
+	assert.Equal(t, "GET", attrs["http.request.method"].AsString())
+	assert.True(t, strings.HasSuffix(attrs["url.full"].AsString(), "/orders/1001"))
+	assert.Equal(t, int64(404), attrs["http.response.status_code"].AsInt64())
+	assert.Equal(t, int64(1), attrs["http.request.resend_count"].AsInt64())
+
+	// the server sees the client span as its parent
+	sent, _ := traceparent.Load().(string)
+	assert.Contains(t, sent, span.SpanContext().TraceID().String())
+	assert.Contains(t, sent, span.SpanContext().SpanID().String())
+}
+
+func TestTracingError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	c, err := netcom.NewClient(netcom.ClientConfig{Tracing: &netcom.TracingConfig{TracerProvider: tp}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Get(ctx, "http://127.0.0.1:1/orders")
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "canceled", spanAttrs(spans[0])["error.type"].AsString())
+	assert.NotEmpty(t, spans[0].Events())
+}
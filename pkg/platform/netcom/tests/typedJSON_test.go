@@ -0,0 +1,50 @@
+package netcom_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJSON(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		if r.URL.Path == "/orders/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]orderResp{{ID: "A1", Status: "open"}})
+	})
+
+	orders, err := netcom.GetJSON[[]orderResp](context.Background(), c, "/orders")
+	require.NoError(t, err)
+	assert.Equal(t, []orderResp{{ID: "A1", Status: "open"}}, orders)
+
+	_, err = netcom.GetJSON[orderResp](context.Background(), c, "/orders/missing")
+	assert.ErrorIs(t, err, netcom.ErrBadStatusCode)
+}
+
+func TestPostJSONTyped(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var in orderResp
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		if in.ID == "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		json.NewEncoder(w).Encode(orderResp{ID: in.ID, Status: "confirmed"})
+	})
+
+	out, err := netcom.PostJSONTyped[orderResp, orderResp](context.Background(), c, "/confirmations", orderResp{ID: "A1"})
+	require.NoError(t, err)
+	assert.Equal(t, orderResp{ID: "A1", Status: "confirmed"}, out)
+
+	out, err = netcom.PostJSONTyped[orderResp, orderResp](context.Background(), c, "/confirmations", orderResp{})
+	require.NoError(t, err)
+	assert.Zero(t, out)
+}
@@ -0,0 +1,51 @@
+package netcom_test
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostXML(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/xml", r.Header.Get("Content-Type"))
+		assert.Contains(t, r.Header.Get("Accept"), "application/xml")
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, xml.Header+`<order id="A1"><status>released</status></order>`, string(body))
+
+		// legacy systems label their XML as plain text in a latin-1 encoding
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><order id=\"A1\"><status>r\xe9serv\xe9</status></order>"))
+	})
+
+	resp, err := c.PostXML(context.Background(), "/orders", xmlOrder{ID: "A1", Status: "released"})
+	require.NoError(t, err)
+	var out xmlOrder
+	require.NoError(t, netcom.DecodeXMLResponse(resp, &out))
+	assert.Equal(t, "réservé", out.Status)
+
+	_, err = c.PostXML(context.Background(), "/orders", map[string]string{"id": "A1"})
+	assert.ErrorIs(t, err, netcom.ErrXMLMarshalFailed)
+}
+
+func TestPostXMLHeaderOverride(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/xml; charset=utf-8", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("<fault>busy</fault>"))
+	})
+	retry := netcom.RetryPolicy{MaxAttempts: 1}
+	resp, err := c.PostXML(context.Background(), "/soap", xmlOrder{ID: "A1"},
+		netcom.WithSetHeader("Content-Type", "text/xml; charset=utf-8"), netcom.WithRetryPolicy(retry))
+	require.NoError(t, err)
+	err = netcom.DecodeXMLResponse(resp, &xmlOrder{})
+	assert.ErrorIs(t, err, netcom.ErrBadStatusCode)
+	assert.Contains(t, err.Error(), "<fault>busy</fault>")
+}
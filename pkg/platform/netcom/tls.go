@@ -0,0 +1,78 @@
+package netcom
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSConfig configures the TLS connections of the default transport, e.g. to trust a plant CA or present a
+// client certificate to services requiring mutual TLS. Files are read once, when the client is created.
+type TLSConfig struct {
+	// CAFile is a PEM bundle of the certificate authorities trusted instead of the system roots.
+	CAFile string
+	// CAPEM is a PEM bundle trusted like CAFile; both can be given.
+	CAPEM []byte
+	// CertFile and KeyFile are the PEM client certificate (with its intermediates) and private key for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// CertPEM and KeyPEM hold the client certificate and key themselves, e.g. from a secret store,
+	// and can not be combined with CertFile and KeyFile.
+	CertPEM []byte
+	KeyPEM  []byte
+	// InsecureSkipVerify accepts any server certificate. It is meant for test benches only.
+	InsecureSkipVerify bool
+	// MinVersion is the lowest TLS version accepted, e.g. tls.VersionTLS13; zero means TLS 1.2.
+	MinVersion uint16
+}
+
+// tlsConfig builds the crypto/tls configuration.
+func (t TLSConfig) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: t.InsecureSkipVerify, MinVersion: t.MinVersion}
+	switch t.MinVersion {
+	case 0:
+		config.MinVersion = tls.VersionTLS12
+	case tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+	default:
+		return nil, fmt.Errorf("unknown minimum TLS version %#04x", t.MinVersion)
+	}
+
+	if t.CAFile != "" || len(t.CAPEM) > 0 {
+		pool := x509.NewCertPool()
+		if t.CAFile != "" {
+			bundle, err := os.ReadFile(t.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading CA bundle failed: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(bundle) {
+				return nil, fmt.Errorf("CA bundle '%s' holds no PEM certificate", t.CAFile)
+			}
+		}
+		if len(t.CAPEM) > 0 && !pool.AppendCertsFromPEM(t.CAPEM) {
+			return nil, errors.New("CAPEM holds no PEM certificate")
+		}
+		config.RootCAs = pool
+	}
+
+	fromFiles := t.CertFile != "" || t.KeyFile != ""
+	fromPEM := len(t.CertPEM) > 0 || len(t.KeyPEM) > 0
+	var cert tls.Certificate
+	var err error
+	switch {
+	case fromFiles && fromPEM:
+		return nil, errors.New("CertFile and KeyFile can not be combined with CertPEM and KeyPEM")
+	case fromFiles:
+		cert, err = tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	case fromPEM:
+		cert, err = tls.X509KeyPair(t.CertPEM, t.KeyPEM)
+	default:
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading client certificate failed: %w", err)
+	}
+	config.Certificates = []tls.Certificate{cert}
+	return config, nil
+}
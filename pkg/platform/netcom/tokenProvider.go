@@ -0,0 +1,84 @@
+package netcom
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// TokenProvider supplies the bearer token of a request. The client asks for a token on every attempt,
+// so implementations that talk to an identity provider should cache (see NewCachingTokenProvider).
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenProviderFunc adapts a function to TokenProvider.
+type TokenProviderFunc func(ctx context.Context) (string, error)
+
+// Token calls f(ctx).
+func (f TokenProviderFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticToken is a TokenProvider always returning the same token, e.g. an API key issued for a service.
+type StaticToken string
+
+// Token returns the token.
+func (t StaticToken) Token(context.Context) (string, error) {
+	if t == "" {
+		return "", errors.New("static token is empty")
+	}
+	return string(t), nil
+}
+
+// TokenFetchFunc obtains a new token with its expiry; a zero expiry means the token does not expire.
+type TokenFetchFunc func(ctx context.Context) (token string, expires time.Time, err error)
+
+// CachingTokenProvider caches the token of a TokenFetchFunc and fetches a new one only when the cached token
+// expires within the refresh margin or was invalidated. It is safe for concurrent use; concurrent callers
+// share a single fetch.
+type CachingTokenProvider struct {
+	fetch         TokenFetchFunc
+	refreshBefore time.Duration
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewCachingTokenProvider caches the tokens of fetch, refreshing them refreshBefore their expiry;
+// zero uses DefaultTokenRefreshBefore.
+func NewCachingTokenProvider(fetch TokenFetchFunc, refreshBefore time.Duration) *CachingTokenProvider {
+	if refreshBefore <= 0 {
+		refreshBefore = DefaultTokenRefreshBefore
+	}
+	return &CachingTokenProvider{fetch: fetch, refreshBefore: refreshBefore}
+}
+
+// Token returns the cached token or fetches a new one.
+func (p *CachingTokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" && (p.expires.IsZero() || time.Until(p.expires) > p.refreshBefore) {
+		return p.token, nil
+	}
+	token, expires, err := p.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	p.token, p.expires = token, expires
+	return token, nil
+}
+
+// Invalidate drops the cached token, e.g. after the API rejected it; the next Token call fetches a new one.
+func (p *CachingTokenProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.token, p.expires = "", time.Time{}
+}
+
+// invalidator is implemented by providers whose cached token can be dropped after a 401 response.
+type invalidator interface {
+	Invalidate()
+}
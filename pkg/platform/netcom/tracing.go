@@ -0,0 +1,93 @@
+package netcom
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ivanehh/go-boiler-lib/pkg/platform/netcom"
+
+// TracingConfig enables OpenTelemetry client spans around Client.Do.
+type TracingConfig struct {
+	// TracerProvider creates the spans; defaults to the global provider (otel.GetTracerProvider).
+	TracerProvider trace.TracerProvider
+	// Propagator injects the span context into the request headers; defaults to W3C trace context and baggage.
+	// It takes precedence over ClientConfig.Traceparent.
+	Propagator propagation.TextMapPropagator
+}
+
+// clientTracer holds what a traced client needs per request.
+type clientTracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func newClientTracer(config TracingConfig) *clientTracer {
+	tp := config.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	propagator := config.Propagator
+	if propagator == nil {
+		propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+	return &clientTracer{tracer: tp.Tracer(tracerName), propagator: propagator}
+}
+
+// do sends req through send inside a client span covering every attempt; the span context is injected into
+// the request headers so the server continues the trace.
+func (ct *clientTracer) do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx, span := ct.tracer.Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.Redacted()),
+			attribute.String("server.address", req.URL.Hostname()),
+		))
+	defer span.End()
+	req = req.Clone(ctx)
+	ct.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := send(req)
+	if err != nil {
+		if n := len(AttemptsFromError(err)); n > 1 {
+			span.SetAttributes(attribute.Int("http.request.resend_count", n-1))
+		}
+		span.SetAttributes(attribute.String("error.type", errorType(err)))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if n := len(Attempts(resp)); n > 1 {
+		span.SetAttributes(attribute.Int("http.request.resend_count", n-1))
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetAttributes(attribute.String("error.type", strconv.Itoa(resp.StatusCode)))
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// errorType names the kind of a request failure for the error.type attribute; "_OTHER" is the OpenTelemetry fallback.
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, ErrTokenFetchFailed):
+		return "token_fetch_failed"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+	return "_OTHER"
+}
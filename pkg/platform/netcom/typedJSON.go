@@ -0,0 +1,45 @@
+package netcom
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetJSON sends a GET request and decodes the response into a T with DecodeResponse, asking for JSON unless
+// the client profile or the options set another Accept header.
+func GetJSON[T any](ctx context.Context, c *Client, path string, options ...RequestOption) (T, error) {
+	resp, err := c.Get(ctx, path, append([]RequestOption{acceptJSON}, options...)...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return decodeTyped[T](resp)
+}
+
+// PostJSONTyped sends body as JSON with PostJSON and decodes the response into a Resp with DecodeResponse.
+func PostJSONTyped[Req, Resp any](ctx context.Context, c *Client, path string, body Req, options ...RequestOption) (Resp, error) {
+	resp, err := c.PostJSON(ctx, path, body, append([]RequestOption{acceptJSON}, options...)...)
+	if err != nil {
+		var zero Resp
+		return zero, err
+	}
+	return decodeTyped[Resp](resp)
+}
+
+// acceptJSON asks for JSON unless an Accept header is already set.
+func acceptJSON(req *http.Request) error {
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	return nil
+}
+
+// decodeTyped decodes the response into a T; a 204 No Content response yields the zero value.
+func decodeTyped[T any](resp *http.Response) (T, error) {
+	var out T
+	if resp.StatusCode == http.StatusNoContent {
+		return out, DecodeResponse(resp, nil)
+	}
+	err := DecodeResponse(resp, &out)
+	return out, err
+}
@@ -0,0 +1,39 @@
+package netcom
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrXMLMarshalFailed indicates a request body that could not be marshalled to XML.
+var ErrXMLMarshalFailed = errors.New("failed to marshal XML")
+
+// PostXML sends a POST request with the body marshalled from data as XML, preceded by the XML declaration.
+// It sets the "Content-Type" header to "application/xml" and asks for an XML response with "Accept";
+// options can override both with WithSetHeader, e.g. for "text/xml" SOAP endpoints.
+func (c *Client) PostXML(ctx context.Context, path string, data any, options ...RequestOption) (*http.Response, error) {
+	xmlData, err := xml.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrXMLMarshalFailed, err)
+	}
+	body := append([]byte(xml.Header), xmlData...)
+
+	finalOptions := []RequestOption{
+		WithSetHeader("Content-Type", "application/xml"),
+		WithSetHeader("Accept", "application/xml, text/xml"),
+	}
+	finalOptions = append(finalOptions, options...)
+
+	return c.Post(ctx, path, bytes.NewReader(body), finalOptions...)
+}
+
+// DecodeXMLResponse is DecodeResponse decoding the body as XML whatever its Content-Type, since legacy systems
+// often label XML as text/plain or text/html. Documents declaring another encoding than UTF-8, such as
+// ISO-8859-1 or windows-1252, are converted.
+func DecodeXMLResponse(resp *http.Response, v any, opts ...ResponseOption) error {
+	return DecodeResponse(resp, v, append(opts, WithDecoder(decodeXML))...)
+}
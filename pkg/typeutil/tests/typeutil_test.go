@@ -0,0 +1,56 @@
+package typeutil_test
+
+import (
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/go-boiler-lib/pkg/typeutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type station struct {
+	Line string `json:"line"`
+}
+
+type order struct {
+	Number   int        `json:"wo"`
+	Plant    string     `map:"plant_code" json:"plant"`
+	Note     string     `json:"note,omitempty"`
+	Secret   string     `json:"-"`
+	Station  station    `json:"station"`
+	Started  time.Time  `json:"started"`
+	Report   url.URL    `json:"report"`
+	Callback *url.URL   `json:"callback"`
+	Addr     netip.Addr `json:"addr"`
+	private  int
+}
+
+func TestToMap(t *testing.T) {
+	started := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := url.URL{Scheme: "https", Host: "mes.plant7", Path: "/reports/42"}
+	callback := &url.URL{Scheme: "https", Host: "mes.plant7", Path: "/done"}
+	addr := netip.MustParseAddr("10.0.7.1")
+	m := typeutil.ToMap(&order{
+		Number: 42, Plant: "gradec", Secret: "x", Station: station{Line: "L1"}, Started: started,
+		Report: report, Callback: callback, Addr: addr, private: 1,
+	})
+	// values with a text form of their own are not expanded into their fields
+	assert.Equal(t, map[string]any{
+		"wo":         42,
+		"plant_code": "gradec",
+		"station":    map[string]any{"line": "L1"},
+		"started":    started,
+		"report":     report,
+		"callback":   callback,
+		"addr":       addr,
+	}, m)
+	assert.Nil(t, typeutil.ToMap(42))
+}
+
+func TestAsStructurable(t *testing.T) {
+	s := typeutil.AsStructurable(station{Line: "L2"})
+	assert.Equal(t, map[string]any{"line": "L2"}, s.AsMap())
+	assert.JSONEq(t, `{"line":"L2"}`, string(s.AsJSON()))
+}
@@ -0,0 +1,152 @@
+// Package typeutil holds the structural interfaces shared across the library together with reflection based default implementations
+package typeutil
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+type Structurable interface {
+	Mapable
+	JSONable
+}
+
+type Mapable interface {
+	AsMap() map[string]any
+}
+
+type JSONable interface {
+	AsJSON() []byte
+}
+
+// MapTag is the struct tag consulted by ToMap; when absent the json tag is used, then the field name
+const MapTag = "map"
+
+// ToMap converts a struct (or pointer to struct) into a map; keys follow the `map` tag, then the `json` tag, then the field name.
+// A "-" tag skips the field, the omitempty option skips zero values and nested structs are converted recursively
+func ToMap(v any) map[string]any {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	return structToMap(rv)
+}
+
+func structToMap(rv reflect.Value) map[string]any {
+	rt := rv.Type()
+	m := make(map[string]any, rt.NumField())
+	for i := range rt.NumField() {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, omitEmpty, skip := fieldKey(sf)
+		if skip {
+			continue
+		}
+		fv := rv.Field(i)
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		m[name] = mapValue(fv)
+	}
+	return m
+}
+
+func fieldKey(sf reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag, ok := sf.Tag.Lookup(MapTag)
+	if !ok {
+		tag = sf.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if len(name) == 0 {
+		name = sf.Name
+	}
+	for _, o := range strings.Split(opts, ",") {
+		if o == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+func mapValue(fv reflect.Value) any {
+	switch fv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if fv.IsNil() {
+			return nil
+		}
+		if m, ok := fv.Interface().(Mapable); ok {
+			return m.AsMap()
+		}
+		if fv.Kind() == reflect.Pointer && hasTextForm(fv.Type()) {
+			return fv.Interface()
+		}
+		return mapValue(fv.Elem())
+	case reflect.Struct:
+		if m, ok := fv.Interface().(Mapable); ok {
+			return m.AsMap()
+		}
+		// types with their own text form (time.Time, url.URL...) are kept as-is
+		if hasTextForm(fv.Type()) {
+			return fv.Interface()
+		}
+		return structToMap(fv)
+	default:
+		return fv.Interface()
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	urlType           = reflect.TypeFor[url.URL]()
+)
+
+// hasTextForm reports whether values of t marshal to a text form of their own rather than to their fields;
+// url.URL has no marshaller but prints as its string
+func hasTextForm(t reflect.Type) bool {
+	if t == urlType || t == reflect.PointerTo(urlType) {
+		return true
+	}
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// ToJSON marshals v to JSON; values that can not be marshalled produce nil
+func ToJSON(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// structurable is the default Structurable implementation over any struct value
+type structurable struct {
+	v any
+}
+
+// AsStructurable adapts any struct to the Structurable interface using ToMap and ToJSON
+func AsStructurable(v any) Structurable {
+	return structurable{v: v}
+}
+
+func (s structurable) AsMap() map[string]any {
+	return ToMap(s.v)
+}
+
+func (s structurable) AsJSON() []byte {
+	return ToJSON(s.v)
+}